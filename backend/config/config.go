@@ -3,14 +3,32 @@ package config
 import (
 	"log"
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
 	Server struct {
-		Port string `yaml:"port"`
+		Port           string   `yaml:"port"`
+		TrustedProxies []string `yaml:"trusted_proxies"`
 	} `yaml:"server"`
+	Site struct {
+		Title       string `yaml:"title"`
+		Description string `yaml:"description"`
+		URL         string `yaml:"url"`     // 前端站点地址，用于生成文章、作者页面链接
+		APIURL      string `yaml:"api_url"` // 后端对外地址，用于订阅源等指向自身的链接
+	} `yaml:"site"`
+	Feed struct {
+		ItemCount int `yaml:"item_count"` // 订阅源中的文章数
+	} `yaml:"feed"`
+	Sitemap struct {
+		CacheTTL time.Duration `yaml:"cache_ttl"` // 文章变化时立即失效，此外每隔该时间重新生成
+		Robots   struct {
+			Disallow []string `yaml:"disallow"`
+			Content  string   `yaml:"content"` // 设置后直接作为 robots.txt 输出
+		} `yaml:"robots"`
+	} `yaml:"sitemap"`
 	Database struct {
 		Host     string `yaml:"host"`
 		Port     string `yaml:"port"`
@@ -19,8 +37,158 @@ type Config struct {
 		DBName   string `yaml:"dbname"`
 	} `yaml:"database"`
 	JWT struct {
-		Secret string `yaml:"secret"`
+		Secret       string         `yaml:"secret"` // 未配置 keys 时使用 HS256 共享密钥，仅建议用于开发环境
+		Issuer       string         `yaml:"issuer"`
+		Audience     string         `yaml:"audience"`
+		TTL          time.Duration  `yaml:"ttl"`
+		SigningKeyID string         `yaml:"signing_key_id"` // 用于签名的密钥，其余密钥只用于验证
+		Keys         []JWTKeyConfig `yaml:"keys"`
 	} `yaml:"jwt"`
+	Auth struct {
+		RequireEmailVerification bool          `yaml:"require_email_verification"`
+		VerifyTokenTTL           time.Duration `yaml:"verify_token_ttl"`
+		ResetTokenTTL            time.Duration `yaml:"reset_token_ttl"`
+		TwoFactor                struct {
+			Issuer           string        `yaml:"issuer"` // 认证器应用中显示的名称
+			RequireForAdmins bool          `yaml:"require_for_admins"`
+			ChallengeTTL     time.Duration `yaml:"challenge_ttl"`
+		} `yaml:"two_factor"`
+		Session struct {
+			LastSeenFlushInterval time.Duration `yaml:"last_seen_flush_interval"` // 会话最近活跃时间的批量写入间隔
+		} `yaml:"session"`
+	} `yaml:"auth"`
+	Password struct {
+		HashCost       int    `yaml:"hash_cost"` // bcrypt cost，调高后旧哈希在登录时自动升级
+		MinLength      int    `yaml:"min_length"`
+		MaxLength      int    `yaml:"max_length"`
+		MinCharClasses int    `yaml:"min_char_classes"` // 大写、小写、数字、符号中至少包含几类
+		DenylistFile   string `yaml:"denylist_file"`    // 禁用密码列表，每行一个
+	} `yaml:"password"`
+	Mail struct {
+		Driver      string `yaml:"driver"` // smtp 或 log
+		From        string `yaml:"from"`
+		LinkBaseURL string `yaml:"link_base_url"`
+		SMTP        struct {
+			Host     string `yaml:"host"`
+			Port     int    `yaml:"port"`
+			Username string `yaml:"username"`
+			Password string `yaml:"password"`
+		} `yaml:"smtp"`
+	} `yaml:"mail"`
+	OAuth struct {
+		// 第三方授权后跳回的前端地址前缀，完整地址为 {redirect_base_url}/{provider}/callback
+		RedirectBaseURL string                         `yaml:"redirect_base_url"`
+		StateTTL        time.Duration                  `yaml:"state_ttl"`
+		Providers       map[string]OAuthProviderConfig `yaml:"providers"`
+	} `yaml:"oauth"`
+	Redis struct {
+		Addr     string `yaml:"addr"`
+		Password string `yaml:"password"`
+		DB       int    `yaml:"db"`
+	} `yaml:"redis"`
+	Cache struct {
+		Driver     string        `yaml:"driver"` // memory、redis 或 none
+		Size       int           `yaml:"size"`   // memory 缓存的条目数上限
+		Prefix     string        `yaml:"prefix"` // redis 缓存 key 的前缀
+		ArticleTTL time.Duration `yaml:"article_ttl"`
+		ListTTL    time.Duration `yaml:"list_ttl"`
+	} `yaml:"cache"`
+	HTTPCache struct {
+		MaxAge        time.Duration `yaml:"max_age"` // 匿名请求响应的 Cache-Control max-age，登录用户的响应每次都需验证
+		ResponseCache struct {
+			Enabled    bool          `yaml:"enabled"`
+			MaxEntries int           `yaml:"max_entries"`
+			TTL        time.Duration `yaml:"ttl"`
+		} `yaml:"response_cache"`
+	} `yaml:"http_cache"`
+	RateLimit struct {
+		Store string `yaml:"store"` // memory 或 redis
+		IP    struct {
+			RequestsPerMinute int `yaml:"requests_per_minute"`
+			Burst             int `yaml:"burst"`
+		} `yaml:"ip"`
+		Account struct {
+			RequestsPerMinute int `yaml:"requests_per_minute"`
+			Burst             int `yaml:"burst"`
+		} `yaml:"account"`
+		Lockout struct {
+			MaxFailures  int           `yaml:"max_failures"`
+			BaseDuration time.Duration `yaml:"base_duration"`
+			MaxDuration  time.Duration `yaml:"max_duration"`
+			Window       time.Duration `yaml:"window"`
+		} `yaml:"lockout"`
+	} `yaml:"rate_limit"`
+	Views struct {
+		FlushInterval  time.Duration            `yaml:"flush_interval"` // 浏览数批量写入间隔
+		DedupWindow    time.Duration            `yaml:"dedup_window"`   // 同一访客在该时间内重复浏览只计一次
+		MaxTracked     int                      `yaml:"max_tracked"`    // 内存中去重记录的最大数量
+		PopularWindows map[string]time.Duration `yaml:"popular_windows"`
+	} `yaml:"views"`
+	Webhooks struct {
+		MaxAttempts         int           `yaml:"max_attempts"` // 包括首次投递在内的最多尝试次数
+		BaseBackoff         time.Duration `yaml:"base_backoff"` // 第一次重试的等待时间，之后每次翻倍
+		MaxBackoff          time.Duration `yaml:"max_backoff"`
+		Timeout             time.Duration `yaml:"timeout"`
+		PollInterval        time.Duration `yaml:"poll_interval"`
+		Concurrency         int           `yaml:"concurrency"`
+		AllowPrivateTargets bool          `yaml:"allow_private_targets"` // 允许推送到内网地址，仅用于开发环境
+		MaxPerUser          int           `yaml:"max_per_user"`
+	} `yaml:"webhooks"`
+	Outbox struct {
+		PollInterval time.Duration `yaml:"poll_interval"` // 检查待发布事件的间隔
+		BatchSize    int           `yaml:"batch_size"`
+		BaseBackoff  time.Duration `yaml:"base_backoff"` // 发布失败后第一次重试的等待时间，之后每次翻倍
+		MaxBackoff   time.Duration `yaml:"max_backoff"`
+		Retention    time.Duration `yaml:"retention"` // 已发布事件的保留时间
+	} `yaml:"outbox"`
+	Jobs struct {
+		PollInterval time.Duration  `yaml:"poll_interval"`
+		MaxAttempts  int            `yaml:"max_attempts"` // 任务未指定时的最多尝试次数
+		BaseBackoff  time.Duration  `yaml:"base_backoff"` // 第一次重试的等待时间，之后每次翻倍
+		MaxBackoff   time.Duration  `yaml:"max_backoff"`
+		Timeout      time.Duration  `yaml:"timeout"`     // 单个任务的执行超时
+		Retention    time.Duration  `yaml:"retention"`   // 成功任务的保留时间
+		Concurrency  int            `yaml:"concurrency"` // 未在 queues 中列出的队列同时执行的任务数
+		Queues       map[string]int `yaml:"queues"`      // 各队列同时执行的任务数
+	} `yaml:"jobs"`
+	Review struct {
+		RequireBeforePublish bool `yaml:"require_before_publish"` // 文章必须经审核员通过后才能发布
+	} `yaml:"review"`
+	Audit struct {
+		BufferSize    int           `yaml:"buffer_size"`    // 等待写库的事件数上限，超出时丢弃
+		RetentionDays int           `yaml:"retention_days"` // 审计事件保留天数
+		PruneInterval time.Duration `yaml:"prune_interval"`
+	} `yaml:"audit"`
+	Tracing struct {
+		Enabled     bool     `yaml:"enabled"`
+		ServiceName string   `yaml:"service_name"`
+		Exporter    string   `yaml:"exporter"` // otlp 或 stdout
+		Endpoint    string   `yaml:"endpoint"`
+		Insecure    bool     `yaml:"insecure"`
+		SampleRatio *float64 `yaml:"sample_ratio"` // 未配置时为 1.0，0 表示不采样
+	} `yaml:"tracing"`
+}
+
+// JWTKeyConfig JWT 签名密钥配置，轮换下线的旧密钥可只保留公钥用于验证
+type JWTKeyConfig struct {
+	ID             string `yaml:"id"`        // 写入令牌头部的 kid
+	Algorithm      string `yaml:"algorithm"` // RS256 或 EdDSA
+	PrivateKeyFile string `yaml:"private_key_file"`
+	PublicKeyFile  string `yaml:"public_key_file"`
+}
+
+// OAuthProviderConfig 第三方登录提供方配置，client_id 为空时不启用
+type OAuthProviderConfig struct {
+	Type         string   `yaml:"type"` // github 或 oidc
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	Issuer       string   `yaml:"issuer"` // oidc 使用，通过 /.well-known/openid-configuration 发现端点
+	Scopes       []string `yaml:"scopes"`
+	RedirectURL  string   `yaml:"redirect_url"` // 为空时由 redirect_base_url 生成
+	// 以下用于 GitHub Enterprise 或测试时替换默认端点
+	AuthURL  string `yaml:"auth_url"`
+	TokenURL string `yaml:"token_url"`
+	APIURL   string `yaml:"api_url"`
 }
 
 var AppConfig Config
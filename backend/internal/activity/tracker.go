@@ -0,0 +1,95 @@
+// Package activity 在内存中汇总会话的最近活跃时间，定期批量写入数据库，避免每个请求都写库
+package activity
+
+import (
+	"blog/config"
+	"blog/internal/repository"
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+const defaultFlushInterval = time.Minute
+
+// Store 批量保存最近活跃时间
+type Store interface {
+	UpdateLastSeen(ctx context.Context, lastSeen map[uint]time.Time) error
+}
+
+// Tracker 记录会话的最近活跃时间，同一会话在一个周期内只保留最新的时间
+type Tracker struct {
+	store    Store
+	interval time.Duration
+
+	mu      sync.Mutex
+	pending map[uint]time.Time
+}
+
+// NewTracker 创建活跃时间记录器，interval 为批量写入的间隔
+func NewTracker(store Store, interval time.Duration) *Tracker {
+	if interval <= 0 {
+		interval = defaultFlushInterval
+	}
+	return &Tracker{store: store, interval: interval, pending: map[uint]time.Time{}}
+}
+
+// Touch 记录会话在 at 时刻活跃
+func (t *Tracker) Touch(sessionID uint, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if at.After(t.pending[sessionID]) {
+		t.pending[sessionID] = at
+	}
+}
+
+// Flush 把尚未写入的活跃时间写入数据库，失败时放回等待下次写入
+func (t *Tracker) Flush(ctx context.Context) error {
+	t.mu.Lock()
+	batch := t.pending
+	t.pending = make(map[uint]time.Time, len(batch))
+	t.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	if err := t.store.UpdateLastSeen(ctx, batch); err != nil {
+		for id, at := range batch {
+			t.Touch(id, at)
+		}
+		return err
+	}
+	return nil
+}
+
+// Run 定期写入，直到 ctx 结束；结束前不会自动写入剩余数据，调用方需在退出时再调用 Flush
+func (t *Tracker) Run(ctx context.Context) {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := t.Flush(ctx); err != nil {
+				log.Printf("[WARN] failed to flush session activity: %v", err)
+			}
+		}
+	}
+}
+
+var tracker *Tracker
+
+// InitTracker 根据配置创建全局记录器
+func InitTracker() {
+	tracker = NewTracker(repository.NewSessionRepository(), config.AppConfig.Auth.Session.LastSeenFlushInterval)
+}
+
+// GetTracker 获取全局记录器，未初始化时使用默认配置创建
+func GetTracker() *Tracker {
+	if tracker == nil {
+		InitTracker()
+	}
+	return tracker
+}
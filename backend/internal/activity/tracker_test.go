@@ -0,0 +1,85 @@
+package activity
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore 记录每次批量写入的内容
+type memoryStore struct {
+	mu      sync.Mutex
+	batches []map[uint]time.Time
+	err     error
+}
+
+func (s *memoryStore) UpdateLastSeen(ctx context.Context, lastSeen map[uint]time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.batches = append(s.batches, lastSeen)
+	return nil
+}
+
+func TestTracker_Flush(t *testing.T) {
+	store := &memoryStore{}
+	tracker := NewTracker(store, time.Hour)
+	base := time.Now()
+
+	// 同一会话多次活跃只写入最新的时间
+	tracker.Touch(1, base)
+	tracker.Touch(1, base.Add(2*time.Second))
+	tracker.Touch(1, base.Add(time.Second))
+	tracker.Touch(2, base)
+
+	require.NoError(t, tracker.Flush(context.Background()))
+	require.Len(t, store.batches, 1)
+	assert.Equal(t, map[uint]time.Time{1: base.Add(2 * time.Second), 2: base}, store.batches[0])
+
+	// 没有新的活跃记录时不写库
+	require.NoError(t, tracker.Flush(context.Background()))
+	assert.Len(t, store.batches, 1)
+}
+
+func TestTracker_FlushFailureRetries(t *testing.T) {
+	store := &memoryStore{err: errors.New("db down")}
+	tracker := NewTracker(store, time.Hour)
+	at := time.Now()
+
+	tracker.Touch(1, at)
+	assert.Error(t, tracker.Flush(context.Background()))
+
+	// 写入失败的数据保留到下次写入
+	store.err = nil
+	require.NoError(t, tracker.Flush(context.Background()))
+	require.Len(t, store.batches, 1)
+	assert.Equal(t, at, store.batches[0][1])
+}
+
+func TestTracker_Run(t *testing.T) {
+	store := &memoryStore{}
+	tracker := NewTracker(store, 10*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		tracker.Run(ctx)
+		close(done)
+	}()
+
+	tracker.Touch(1, time.Now())
+	assert.Eventually(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		return len(store.batches) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,90 @@
+package apperr
+
+import (
+	"errors"
+	"net/http"
+)
+
+// 错误类别，业务错误通过 errors.Is 判断所属类别
+var (
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+
+	ErrTooManyRequests = errors.New("too many requests")
+)
+
+// CodeInternal 未归类错误统一使用的错误码
+const CodeInternal = "internal_error"
+
+// Internal 对客户端隐藏细节的内部错误
+var Internal = &Error{Code: CodeInternal, Message: "服务器内部错误"}
+
+// Error 携带稳定错误码的业务错误
+type Error struct {
+	Kind    error         // 错误类别，取值为上面的哨兵错误
+	Code    string        // 机器可读的错误码，如 article_not_found
+	Message string        // 默认提示信息
+	Err     error         // 底层错误，可为空
+	Args    []interface{} // 文案中的格式化参数，可为空
+}
+
+// New 创建业务错误
+func New(kind error, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// WithArgs 返回携带格式化参数的副本，用于“长度不能少于 %d 位”之类的提示
+func (e *Error) WithArgs(args ...interface{}) *Error {
+	return &Error{Kind: e.Kind, Code: e.Code, Message: e.Message, Err: e.Err, Args: args}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Code + ": " + e.Err.Error()
+	}
+	return e.Code
+}
+
+// Is 使包装后的错误仍能与原始定义匹配
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+func (e *Error) Unwrap() []error {
+	errs := make([]error, 0, 2)
+	for _, err := range []error{e.Kind, e.Err} {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Validation 将参数绑定/校验错误包装为业务错误
+func Validation(err error) *Error {
+	return &Error{Kind: ErrValidation, Code: "invalid_params", Message: "参数错误", Err: err}
+}
+
+// HTTPStatus 返回错误对应的 HTTP 状态码
+func HTTPStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, ErrTooManyRequests):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,152 @@
+// Package audit 异步写入安全审计事件，请求只把事件放入缓冲区，由后台批量写库
+package audit
+
+import (
+	"blog/config"
+	"blog/internal/clientinfo"
+	"blog/internal/model"
+	"blog/internal/repository"
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBufferSize    = 1024
+	defaultBatchSize     = 100
+	defaultFlushInterval = time.Second
+	flushTimeout         = 5 * time.Second
+)
+
+// Store 批量保存审计事件
+type Store interface {
+	CreateBatch(ctx context.Context, events []model.AuditEvent) error
+}
+
+// Recorder 记录审计事件，服务层只依赖该接口
+type Recorder interface {
+	Record(ctx context.Context, event model.AuditEvent)
+}
+
+// Logger 带缓冲的异步审计日志，缓冲区满时丢弃事件并打印告警，不阻塞请求
+type Logger struct {
+	store         Store
+	events        chan model.AuditEvent
+	batchSize     int
+	flushInterval time.Duration
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewLogger 创建审计日志，bufferSize 为可暂存的事件数
+func NewLogger(store Store, bufferSize int) *Logger {
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+	return &Logger{
+		store:         store,
+		events:        make(chan model.AuditEvent, bufferSize),
+		batchSize:     defaultBatchSize,
+		flushInterval: defaultFlushInterval,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+}
+
+// Record 补充请求方 IP、User-Agent 和时间后放入缓冲区
+func (l *Logger) Record(ctx context.Context, event model.AuditEvent) {
+	info := clientinfo.From(ctx)
+	if event.IP == "" {
+		event.IP = info.IP
+	}
+	if event.UserAgent == "" {
+		event.UserAgent = info.UserAgent
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	select {
+	case l.events <- event:
+	default:
+		log.Printf("[WARN] audit buffer full, dropping event %s by %q", event.Action, event.Actor)
+	}
+}
+
+// Run 在后台批量写入事件，直到调用 Close
+func (l *Logger) Run() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]model.AuditEvent, 0, l.batchSize)
+	for {
+		select {
+		case event := <-l.events:
+			batch = append(batch, event)
+			if len(batch) >= l.batchSize {
+				batch = l.flush(batch)
+			}
+		case <-ticker.C:
+			batch = l.flush(batch)
+		case <-l.stop:
+			// 写完缓冲区中剩余的事件再退出
+			for {
+				select {
+				case event := <-l.events:
+					batch = append(batch, event)
+					if len(batch) >= l.batchSize {
+						batch = l.flush(batch)
+					}
+				default:
+					l.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// Close 停止接收新的批次并等待剩余事件写入，ctx 结束时不再等待
+func (l *Logger) Close(ctx context.Context) error {
+	l.stopOnce.Do(func() { close(l.stop) })
+	select {
+	case <-l.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// flush 写入一批事件并返回清空后的切片，写入失败的事件只记录日志
+func (l *Logger) flush(batch []model.AuditEvent) []model.AuditEvent {
+	if len(batch) == 0 {
+		return batch
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+	if err := l.store.CreateBatch(ctx, batch); err != nil {
+		log.Printf("[ERROR] failed to write %d audit events: %v", len(batch), err)
+	}
+	// 新建切片，避免仓库实现持有底层数组时被覆盖
+	return make([]model.AuditEvent, 0, l.batchSize)
+}
+
+var logger *Logger
+
+// InitLogger 根据配置创建全局审计日志
+func InitLogger() {
+	logger = NewLogger(repository.NewAuditRepository(), config.AppConfig.Audit.BufferSize)
+}
+
+// GetLogger 获取全局审计日志，未初始化时使用默认配置创建
+func GetLogger() *Logger {
+	if logger == nil {
+		InitLogger()
+	}
+	return logger
+}
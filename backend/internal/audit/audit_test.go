@@ -0,0 +1,121 @@
+package audit
+
+import (
+	"blog/internal/clientinfo"
+	"blog/internal/model"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore 在内存中保存写入的事件
+type memoryStore struct {
+	mu      sync.Mutex
+	batches [][]model.AuditEvent
+	events  []model.AuditEvent
+}
+
+func (s *memoryStore) CreateBatch(ctx context.Context, events []model.AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, events)
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func (s *memoryStore) DeleteBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var kept []model.AuditEvent
+	var deleted int64
+	for _, e := range s.events {
+		if e.CreatedAt.Before(before) && deleted < int64(limit) {
+			deleted++
+			continue
+		}
+		kept = append(kept, e)
+	}
+	s.events = kept
+	return deleted, nil
+}
+
+func (s *memoryStore) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.events)
+}
+
+func TestLogger_RecordAndClose(t *testing.T) {
+	store := &memoryStore{}
+	logger := NewLogger(store, 10)
+	go logger.Run()
+
+	ctx := clientinfo.WithInfo(context.Background(), clientinfo.Info{IP: "203.0.113.7", UserAgent: "curl/8.0"})
+	logger.Record(ctx, model.AuditEvent{Action: model.AuditActionLogin, Outcome: model.AuditOutcomeFailure})
+	logger.Record(ctx, model.AuditEvent{Action: model.AuditActionLogin, Outcome: model.AuditOutcomeSuccess, IP: "198.51.100.1"})
+
+	// 关闭时写入缓冲区中剩余的事件
+	require.NoError(t, logger.Close(context.Background()))
+	require.Len(t, store.events, 2)
+	assert.Equal(t, "203.0.113.7", store.events[0].IP)
+	assert.Equal(t, "curl/8.0", store.events[0].UserAgent)
+	assert.False(t, store.events[0].CreatedAt.IsZero())
+	// 调用方显式指定的 IP 不被覆盖
+	assert.Equal(t, "198.51.100.1", store.events[1].IP)
+}
+
+func TestLogger_FlushOnBatchSize(t *testing.T) {
+	store := &memoryStore{}
+	logger := NewLogger(store, 10)
+	logger.batchSize = 2
+	logger.flushInterval = time.Hour
+	go logger.Run()
+	defer logger.Close(context.Background())
+
+	for i := 0; i < 4; i++ {
+		logger.Record(context.Background(), model.AuditEvent{Action: model.AuditActionArticleCreate})
+	}
+	assert.Eventually(t, func() bool { return store.count() == 4 }, time.Second, 10*time.Millisecond)
+}
+
+func TestLogger_DropsWhenBufferFull(t *testing.T) {
+	store := &memoryStore{}
+	logger := NewLogger(store, 1)
+
+	// 未启动后台写入时缓冲区很快写满，Record 不应阻塞
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 3; i++ {
+			logger.Record(context.Background(), model.AuditEvent{Action: model.AuditActionLogin})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Record blocked on a full buffer")
+	}
+
+	go logger.Run()
+	require.NoError(t, logger.Close(context.Background()))
+	assert.Len(t, store.events, 1)
+}
+
+func TestPrune(t *testing.T) {
+	store := &memoryStore{}
+	now := time.Now()
+	for i := 0; i < pruneBatchSize+5; i++ {
+		store.events = append(store.events, model.AuditEvent{CreatedAt: now.Add(-48 * time.Hour)})
+	}
+	store.events = append(store.events, model.AuditEvent{CreatedAt: now})
+
+	// 超过单批数量时分多次删除
+	n, err := Prune(context.Background(), store, now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(pruneBatchSize+5), n)
+	assert.Equal(t, 1, store.count())
+}
@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"blog/config"
+	"blog/internal/repository"
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	defaultRetention     = 180 * 24 * time.Hour
+	defaultPruneInterval = 24 * time.Hour
+	pruneBatchSize       = 1000
+)
+
+// Pruner 删除过期的审计事件
+type Pruner interface {
+	DeleteBefore(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// Prune 分批删除早于 before 的事件，返回删除总数
+func Prune(ctx context.Context, store Pruner, before time.Time) (int64, error) {
+	var total int64
+	for {
+		n, err := store.DeleteBefore(ctx, before, pruneBatchSize)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < pruneBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// RunRetention 启动时及之后每隔 interval 清理超过 retention 的事件，直到 ctx 结束
+func RunRetention(ctx context.Context, store Pruner, retention, interval time.Duration) {
+	if retention <= 0 {
+		retention = defaultRetention
+	}
+	if interval <= 0 {
+		interval = defaultPruneInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := Prune(ctx, store, time.Now().Add(-retention))
+		if err != nil && ctx.Err() == nil {
+			log.Printf("[WARN] failed to prune audit events: %v", err)
+		} else if n > 0 {
+			log.Printf("[INFO] pruned %d audit events", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDefaultRetention 按配置的保留天数清理全局审计事件表
+func RunDefaultRetention(ctx context.Context) {
+	cfg := config.AppConfig.Audit
+	RunRetention(ctx, repository.NewAuditRepository(),
+		time.Duration(cfg.RetentionDays)*24*time.Hour, cfg.PruneInterval)
+}
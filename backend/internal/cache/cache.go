@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"blog/config"
+	"context"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultSize 内存缓存默认容纳的条目数
+const defaultSize = 10000
+
+// Backend 缓存存储，值为序列化后的字节；读写失败时调用方应回退到数据库
+type Backend interface {
+	// Get 读取 key，不存在或已过期时 ok 为 false
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// Set 写入 key，ttl 不大于 0 时不过期
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete 删除 key，不存在时忽略
+	Delete(ctx context.Context, keys ...string) error
+}
+
+var backend Backend
+
+// InitBackend 根据配置初始化缓存存储，driver 为 none 时不启用缓存
+func InitBackend() {
+	switch config.AppConfig.Cache.Driver {
+	case "none":
+		backend = nil
+	case "redis":
+		client := redis.NewClient(&redis.Options{
+			Addr:     config.AppConfig.Redis.Addr,
+			Password: config.AppConfig.Redis.Password,
+			DB:       config.AppConfig.Redis.DB,
+		})
+		if err := client.Ping(context.Background()).Err(); err != nil {
+			log.Fatalf("Failed to connect to redis: %v", err)
+		}
+		backend = NewRedis(client, config.AppConfig.Cache.Prefix)
+	default:
+		backend = NewLRU(config.AppConfig.Cache.Size)
+	}
+}
+
+// GetBackend 获取缓存存储，未启用时返回 nil
+func GetBackend() Backend {
+	return backend
+}
@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("超出容量时淘汰最久未访问的条目", func(t *testing.T) {
+		c := NewLRU(2)
+		require.NoError(t, c.Set(ctx, "a", []byte("1"), 0))
+		require.NoError(t, c.Set(ctx, "b", []byte("2"), 0))
+		_, ok, _ := c.Get(ctx, "a")
+		require.True(t, ok)
+
+		require.NoError(t, c.Set(ctx, "c", []byte("3"), 0))
+		_, ok, _ = c.Get(ctx, "b")
+		assert.False(t, ok)
+		_, ok, _ = c.Get(ctx, "a")
+		assert.True(t, ok)
+		assert.Equal(t, 2, c.Len())
+	})
+
+	t.Run("过期后读不到", func(t *testing.T) {
+		c := NewLRU(10)
+		now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		c.now = func() time.Time { return now }
+		require.NoError(t, c.Set(ctx, "a", []byte("1"), time.Minute))
+
+		value, ok, _ := c.Get(ctx, "a")
+		require.True(t, ok)
+		assert.Equal(t, "1", string(value))
+
+		now = now.Add(time.Minute)
+		_, ok, _ = c.Get(ctx, "a")
+		assert.False(t, ok)
+		assert.Equal(t, 0, c.Len())
+	})
+
+	t.Run("删除", func(t *testing.T) {
+		c := NewLRU(10)
+		require.NoError(t, c.Set(ctx, "a", []byte("1"), 0))
+		require.NoError(t, c.Delete(ctx, "a", "missing"))
+		_, ok, _ := c.Get(ctx, "a")
+		assert.False(t, ok)
+	})
+}
+
+func TestRedis(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	c := NewRedis(redis.NewClient(&redis.Options{Addr: mr.Addr()}), "test:")
+
+	_, ok, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, c.Set(ctx, "a", []byte("1"), time.Minute))
+	assert.True(t, mr.Exists("test:a"))
+	value, ok, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "1", string(value))
+
+	mr.FastForward(time.Minute)
+	_, ok, err = c.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, c.Set(ctx, "b", []byte("2"), 0))
+	require.NoError(t, c.Delete(ctx, "b"))
+	assert.False(t, mr.Exists("test:b"))
+}
+
+func TestStats(t *testing.T) {
+	s := NewStats("test_stats")
+	assert.Same(t, s, NewStats("test_stats"))
+
+	s.Hit()
+	s.Hit()
+	s.Hit()
+	s.Miss()
+	snap := s.Snapshot()
+	assert.Equal(t, int64(3), snap.Hits)
+	assert.Equal(t, int64(1), snap.Misses)
+	assert.InDelta(t, 0.75, snap.HitRate, 1e-9)
+	assert.Contains(t, AllStats(), snap)
+}
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Stats 一类缓存数据的命中统计
+type Stats struct {
+	name   string
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+// StatsSnapshot 某一时刻的命中统计
+type StatsSnapshot struct {
+	Name    string  `json:"name"`
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	Errors  int64   `json:"errors"` // 缓存读写失败次数，失败时回退到数据库
+	HitRate float64 `json:"hit_rate"`
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]*Stats{}
+)
+
+// NewStats 返回名为 name 的统计，同名统计只创建一次
+func NewStats(name string) *Stats {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if s, ok := registry[name]; ok {
+		return s
+	}
+	s := &Stats{name: name}
+	registry[name] = s
+	return s
+}
+
+func (s *Stats) Hit()   { s.hits.Add(1) }
+func (s *Stats) Miss()  { s.misses.Add(1) }
+func (s *Stats) Error() { s.errors.Add(1) }
+
+// Snapshot 返回当前统计
+func (s *Stats) Snapshot() StatsSnapshot {
+	snap := StatsSnapshot{Name: s.name, Hits: s.hits.Load(), Misses: s.misses.Load(), Errors: s.errors.Load()}
+	if total := snap.Hits + snap.Misses; total > 0 {
+		snap.HitRate = float64(snap.Hits) / float64(total)
+	}
+	return snap
+}
+
+// AllStats 返回所有已注册的统计，按名称排序
+func AllStats() []StatsSnapshot {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	snaps := make([]StatsSnapshot, 0, len(registry))
+	for _, s := range registry {
+		snaps = append(snaps, s.Snapshot())
+	}
+	sort.Slice(snaps, func(i, j int) bool { return snaps[i].Name < snaps[j].Name })
+	return snaps
+}
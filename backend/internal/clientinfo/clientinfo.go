@@ -0,0 +1,33 @@
+// Package clientinfo 在 context 中传递请求方的 IP 和 User-Agent，供服务层记录会话和审计信息
+package clientinfo
+
+import (
+	"context"
+	"strings"
+)
+
+// maxUserAgentLength User-Agent 的最大保存长度
+const maxUserAgentLength = 255
+
+// Info 请求方信息
+type Info struct {
+	IP        string
+	UserAgent string
+}
+
+type infoKey struct{}
+
+// WithInfo 把请求方信息写入 context，过长的 User-Agent 会被截断
+func WithInfo(ctx context.Context, info Info) context.Context {
+	if len(info.UserAgent) > maxUserAgentLength {
+		// 去掉截断产生的半个多字节字符
+		info.UserAgent = strings.ToValidUTF8(info.UserAgent[:maxUserAgentLength], "")
+	}
+	return context.WithValue(ctx, infoKey{}, info)
+}
+
+// From 从 context 中获取请求方信息，不存在时返回空值
+func From(ctx context.Context) Info {
+	info, _ := ctx.Value(infoKey{}).(Info)
+	return info
+}
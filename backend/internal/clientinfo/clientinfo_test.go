@@ -0,0 +1,29 @@
+package clientinfo
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithInfo(t *testing.T) {
+	// 测试用例1：短 User-Agent 原样保存
+	t.Run("未超长", func(t *testing.T) {
+		ctx := WithInfo(context.Background(), Info{IP: "127.0.0.1", UserAgent: "curl/8.0"})
+		assert.Equal(t, Info{IP: "127.0.0.1", UserAgent: "curl/8.0"}, From(ctx))
+	})
+
+	// 测试用例2：截断位置落在多字节字符中间时不留下无效的 UTF-8
+	t.Run("按字符截断", func(t *testing.T) {
+		userAgent := "a" + strings.Repeat("浏", 100)
+		ctx := WithInfo(context.Background(), Info{UserAgent: userAgent})
+
+		got := From(ctx).UserAgent
+		assert.True(t, utf8.ValidString(got))
+		assert.LessOrEqual(t, len(got), maxUserAgentLength)
+		assert.Equal(t, "a"+strings.Repeat("浏", 84), got)
+	})
+}
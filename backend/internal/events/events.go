@@ -0,0 +1,130 @@
+// Package events 进程内的领域事件总线。写操作在同一事务内把事件写入 outbox，
+// 由 outbox 中继发布到总线，订阅方各自处理
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 事件类型
+const (
+	ArticleCreated   = "article.created"
+	ArticleUpdated   = "article.updated"
+	ArticlePublished = "article.published"
+	ArticleDeleted   = "article.deleted"
+	UserRegistered   = "user.registered"
+)
+
+// Types 全部事件类型
+var Types = []string{ArticleCreated, ArticleUpdated, ArticlePublished, ArticleDeleted, UserRegistered}
+
+// Event 领域事件，序列化后即为 webhook 的请求体
+type Event struct {
+	ID         string    `json:"id"` // 幂等键，同一事件可能被重复发布，订阅方按 ID 去重
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	OwnerID    uint      `json:"-"` // 事件所属的用户，如文章作者；用于判断谁有权收到该事件
+	Data       any       `json:"data"`
+}
+
+// ArticleData 文章事件的内容，不包含正文
+type ArticleData struct {
+	ID             uint     `json:"id"`
+	Title          string   `json:"title"`
+	Status         string   `json:"status"`
+	PreviousStatus string   `json:"previous_status,omitempty"`
+	AuthorID       uint     `json:"author_id"`
+	Tags           []string `json:"tags,omitempty"`
+	URL            string   `json:"url"`
+}
+
+// UserData 用户事件的内容
+type UserData struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+}
+
+// New 创建事件，生成唯一 ID 和发生时间
+func New(eventType string, ownerID uint, data any) Event {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return Event{
+		ID:         hex.EncodeToString(b),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		OwnerID:    ownerID,
+		Data:       data,
+	}
+}
+
+// Handler 事件处理函数，在发布方的调用栈中同步执行，耗时操作应自行转入后台。
+// 返回错误时整个事件会被重新发布
+type Handler func(ctx context.Context, event Event) error
+
+// Bus 按事件类型分发给订阅方
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+	all      []Handler
+}
+
+// NewBus 创建事件总线
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[string][]Handler)}
+}
+
+// Subscribe 订阅指定类型的事件，不指定类型时订阅全部事件
+func (b *Bus) Subscribe(handler Handler, types ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(types) == 0 {
+		b.all = append(b.all, handler)
+		return
+	}
+	for _, t := range types {
+		b.handlers[t] = append(b.handlers[t], handler)
+	}
+}
+
+// Publish 依次调用全部订阅方，某个订阅方出错或 panic 不影响其他订阅方，返回合并后的错误
+func (b *Bus) Publish(ctx context.Context, event Event) error {
+	b.mu.RLock()
+	handlers := make([]Handler, 0, len(b.all)+len(b.handlers[event.Type]))
+	handlers = append(handlers, b.all...)
+	handlers = append(handlers, b.handlers[event.Type]...)
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := dispatch(ctx, handler, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func dispatch(ctx context.Context, handler Handler, event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("event handler panic on %s %s: %v", event.Type, event.ID, r)
+		}
+	}()
+	return handler(ctx, event)
+}
+
+var bus *Bus
+
+// GetBus 获取全局事件总线
+func GetBus() *Bus {
+	if bus == nil {
+		bus = NewBus()
+	}
+	return bus
+}
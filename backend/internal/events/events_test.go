@@ -0,0 +1,38 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus(t *testing.T) {
+	bus := NewBus()
+	var all, published []string
+	bus.Subscribe(func(ctx context.Context, event Event) error {
+		all = append(all, event.Type)
+		return nil
+	})
+	bus.Subscribe(func(ctx context.Context, event Event) error {
+		panic("boom")
+	}, ArticlePublished)
+	bus.Subscribe(func(ctx context.Context, event Event) error {
+		published = append(published, event.ID)
+		return errors.New("failed")
+	}, ArticlePublished)
+
+	created := New(ArticleCreated, 1, ArticleData{ID: 1})
+	event := New(ArticlePublished, 1, ArticleData{ID: 1})
+	assert.NotEqual(t, created.ID, event.ID)
+
+	assert.NoError(t, bus.Publish(context.Background(), created))
+	// 出错或 panic 的订阅方不影响其他订阅方，错误合并后返回
+	err := bus.Publish(context.Background(), event)
+	assert.ErrorContains(t, err, "boom")
+	assert.ErrorContains(t, err, "failed")
+
+	assert.Equal(t, []string{ArticleCreated, ArticlePublished}, all)
+	assert.Equal(t, []string{event.ID}, published)
+}
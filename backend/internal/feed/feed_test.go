@@ -0,0 +1,102 @@
+package feed
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFeed(t *testing.T) *Feed {
+	content, err := RenderMarkdown("# Hello\n\n<script>alert(1)</script>\n\nTom & Jerry ]]> end")
+	require.NoError(t, err)
+	published := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	return &Feed{
+		Title:       "Blog <dev>",
+		Description: "Posts & notes",
+		Link:        "https://example.com",
+		SelfURL:     "https://api.example.com/feed.xml",
+		Updated:     published.Add(time.Hour),
+		Entries: []Entry{{
+			ID:          "https://example.com/articles/1",
+			Title:       `"Go" & <XML>`,
+			Link:        "https://example.com/articles/1",
+			Author:      "Tom",
+			Categories:  []string{"go"},
+			Summary:     Summary("Tom & Jerry"),
+			ContentHTML: content,
+			Published:   published,
+			Updated:     published.Add(time.Hour),
+		}},
+	}
+}
+
+func TestRenderMarkdownOmitsRawHTML(t *testing.T) {
+	html, err := RenderMarkdown("**bold**\n\n<script>alert(1)</script>")
+	require.NoError(t, err)
+	assert.Contains(t, html, "<strong>bold</strong>")
+	assert.NotContains(t, html, "<script>")
+}
+
+func TestSummary(t *testing.T) {
+	assert.Equal(t, "a b c", Summary(" a\n\nb   c "))
+	long := Summary(strings.Repeat("文", summaryLength+10))
+	assert.Equal(t, summaryLength+1, len([]rune(long)))
+}
+
+func TestFeed_RSS(t *testing.T) {
+	body, err := testFeed(t).RSS()
+	require.NoError(t, err)
+
+	// 文本被转义，正文放在 CDATA 中且能被标准解析器还原
+	assert.Contains(t, string(body), "&lt;dev&gt;")
+	assert.Contains(t, string(body), `<atom:link href="https://api.example.com/feed.xml" rel="self"`)
+
+	var doc struct {
+		Channel struct {
+			Title string `xml:"title"`
+			Items []struct {
+				Title   string `xml:"title"`
+				PubDate string `xml:"pubDate"`
+				Content string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	require.NoError(t, xml.Unmarshal(body, &doc))
+	assert.Equal(t, "Blog <dev>", doc.Channel.Title)
+	require.Len(t, doc.Channel.Items, 1)
+	assert.Equal(t, `"Go" & <XML>`, doc.Channel.Items[0].Title)
+	assert.Equal(t, "Fri, 02 Jan 2026 03:04:05 +0000", doc.Channel.Items[0].PubDate)
+	assert.Contains(t, doc.Channel.Items[0].Content, "<h1>Hello</h1>")
+	assert.Contains(t, doc.Channel.Items[0].Content, "Tom &amp; Jerry ]]&gt; end")
+}
+
+func TestFeed_Atom(t *testing.T) {
+	body, err := testFeed(t).Atom()
+	require.NoError(t, err)
+
+	var doc struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		Updated string   `xml:"updated"`
+		Entries []struct {
+			ID      string `xml:"id"`
+			Content struct {
+				Type string `xml:"type,attr"`
+				Body string `xml:",chardata"`
+			} `xml:"content"`
+			Category []struct {
+				Term string `xml:"term,attr"`
+			} `xml:"category"`
+		} `xml:"entry"`
+	}
+	require.NoError(t, xml.Unmarshal(body, &doc))
+	assert.Equal(t, "2026-01-02T04:04:05Z", doc.Updated)
+	require.Len(t, doc.Entries, 1)
+	assert.Equal(t, "https://example.com/articles/1", doc.Entries[0].ID)
+	assert.Equal(t, "html", doc.Entries[0].Content.Type)
+	assert.Contains(t, doc.Entries[0].Content.Body, "<h1>Hello</h1>")
+	assert.Equal(t, "go", doc.Entries[0].Category[0].Term)
+}
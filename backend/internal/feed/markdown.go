@@ -0,0 +1,35 @@
+package feed
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// summaryLength 摘要的最大字符数
+const summaryLength = 200
+
+// markdown 默认不输出原始 HTML，避免文章中的脚本进入订阅源
+var markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// RenderMarkdown 把 Markdown 正文渲染为 HTML
+func RenderMarkdown(source string) (string, error) {
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(source), &buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Summary 取正文开头的纯文本作为摘要，合并空白并按字符截断
+func Summary(source string) string {
+	text := strings.Join(strings.Fields(source), " ")
+	if utf8.RuneCountInString(text) <= summaryLength {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:summaryLength]) + "…"
+}
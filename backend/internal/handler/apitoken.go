@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"blog/internal/apperr"
+	"blog/internal/i18n"
+	"blog/internal/model"
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IAPITokenService interface {
+	Create(ctx context.Context, user *model.User, req *model.CreateAPITokenRequest) (*model.APITokenCreated, error)
+	List(ctx context.Context, user *model.User) ([]model.APIToken, error)
+	Revoke(ctx context.Context, user *model.User, id uint) error
+}
+
+type APITokenHandler struct {
+	tokenService IAPITokenService
+}
+
+func NewAPITokenHandler(tokenService IAPITokenService) *APITokenHandler {
+	return &APITokenHandler{tokenService: tokenService}
+}
+
+// APITokenURI 路径中的令牌 ID
+type APITokenURI struct {
+	ID uint `uri:"id" binding:"required,min=1"`
+}
+
+// Create 创建个人访问令牌
+func (h *APITokenHandler) Create(c *gin.Context) {
+	var req model.CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	token, err := h.tokenService.Create(c.Request.Context(), currentUser, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, Response{
+		Code:    http.StatusCreated,
+		Message: i18n.T(c, "api_token_created"),
+		Data:    token,
+	})
+}
+
+// List 获取个人访问令牌列表
+func (h *APITokenHandler) List(c *gin.Context) {
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.tokenService.List(c.Request.Context(), currentUser)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "get_success"),
+		Data:    tokens,
+	})
+}
+
+// Revoke 撤销个人访问令牌
+func (h *APITokenHandler) Revoke(c *gin.Context) {
+	var uri APITokenURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.tokenService.Revoke(c.Request.Context(), currentUser, uri.ID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "api_token_revoked"),
+	})
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"blog/internal/apperr"
+	"blog/internal/i18n"
 	"blog/internal/model"
 	"blog/internal/service"
 	"net/http"
@@ -18,18 +20,22 @@ func NewArticleHandler(articleService *service.ArticleService) *ArticleHandler {
 
 // 请求结构体
 type CreateArticleRequest struct {
-	Title   string   `json:"title" binding:"required"`
-	Content string   `json:"content" binding:"required"`
-	Status  string   `json:"status" binding:"required,oneof=draft published"`
-	Tags    []string `json:"tags"`
+	Title          string   `json:"title" binding:"required"`
+	Content        string   `json:"content" binding:"required"`
+	Status         string   `json:"status" binding:"required,oneof=draft published"`
+	Tags           []string `json:"tags"`
+	SeriesID       *uint    `json:"series_id"`
+	SeriesPosition int      `json:"series_position" binding:"min=0"`
 }
 
 type UpdateArticleRequest struct {
-	ID      uint     `json:"id" binding:"required"`
-	Title   string   `json:"title" binding:"required"`
-	Content string   `json:"content" binding:"required"`
-	Status  string   `json:"status" binding:"required,oneof=draft published"`
-	Tags    []string `json:"tags"`
+	ID             uint     `json:"id" binding:"required"`
+	Title          string   `json:"title" binding:"required"`
+	Content        string   `json:"content" binding:"required"`
+	Status         string   `json:"status" binding:"required,oneof=draft in_review approved rejected published archived"` // 状态变化按审核流程校验
+	Tags           []string `json:"tags"`
+	SeriesID       *uint    `json:"series_id"` // 未提供时保持不变，为 0 时移出系列
+	SeriesPosition int      `json:"series_position" binding:"min=0"`
 }
 
 type ListArticleRequest struct {
@@ -38,6 +44,7 @@ type ListArticleRequest struct {
 	Status   string `json:"status"`
 	AuthorID uint   `json:"author_id"`
 	Tag      string `json:"tag"`
+	Editable bool   `json:"editable"` // 只返回当前用户可以编辑的文章，忽略其他条件
 }
 
 // 响应结构体
@@ -47,24 +54,21 @@ type Response struct {
 	Data    interface{} `json:"data"`
 }
 
+// ErrNotLoggedIn 上下文中缺少当前用户
+var ErrNotLoggedIn = apperr.New(apperr.ErrUnauthorized, "not_logged_in", "未登录或登录已过期")
+
 // CreateArticle 创建文章
 func (h *ArticleHandler) CreateArticle(c *gin.Context) {
 	var req CreateArticleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, Response{
-			Code:    400,
-			Message: "参数错误: " + err.Error(),
-		})
+		c.Error(apperr.Validation(err))
 		return
 	}
 
 	// 获取当前用户
 	user, exists := c.Get("user")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, Response{
-			Code:    401,
-			Message: "未登录或登录已过期",
-		})
+		c.Error(ErrNotLoggedIn)
 		return
 	}
 	currentUser := user.(*model.User)
@@ -83,17 +87,14 @@ func (h *ArticleHandler) CreateArticle(c *gin.Context) {
 	}
 
 	// 创建文章
-	if err := h.articleService.CreateArticle(article); err != nil {
-		c.JSON(http.StatusInternalServerError, Response{
-			Code:    500,
-			Message: "创建文章失败: " + err.Error(),
-		})
+	if err := h.articleService.CreateArticle(c.Request.Context(), currentUser, article, seriesPlacement(req.SeriesID, req.SeriesPosition)); err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, Response{
 		Code:    201,
-		Message: "创建成功",
+		Message: i18n.T(c, "create_success"),
 		Data:    article,
 	})
 }
@@ -102,12 +103,17 @@ func (h *ArticleHandler) CreateArticle(c *gin.Context) {
 func (h *ArticleHandler) UpdateArticle(c *gin.Context) {
 	var req UpdateArticleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, Response{
-			Code:    400,
-			Message: "参数错误: " + err.Error(),
-		})
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	// 获取当前用户
+	user, exists := c.Get("user")
+	if !exists {
+		c.Error(ErrNotLoggedIn)
 		return
 	}
+	currentUser := user.(*model.User)
 
 	// 构建文章对象
 	article := &model.Article{
@@ -118,57 +124,54 @@ func (h *ArticleHandler) UpdateArticle(c *gin.Context) {
 	}
 
 	// 更新文章和标签
-	if err := h.articleService.UpdateArticleWithTags(article, req.Tags); err != nil {
-		c.JSON(http.StatusInternalServerError, Response{
-			Code:    500,
-			Message: "更新文章失败: " + err.Error(),
-		})
+	placement := seriesPlacement(req.SeriesID, req.SeriesPosition)
+	if err := h.articleService.UpdateArticleWithTags(c.Request.Context(), currentUser, article, req.Tags, placement); err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
-		Message: "更新成功",
+		Message: i18n.T(c, "update_success"),
 		Data:    article,
 	})
 }
 
+// seriesPlacement 请求中未提供系列时不调整文章所在系列
+func seriesPlacement(seriesID *uint, position int) *model.SeriesPlacement {
+	if seriesID == nil {
+		return nil
+	}
+	return &model.SeriesPlacement{SeriesID: *seriesID, Position: position}
+}
+
 // DeleteArticle 删除文章
 func (h *ArticleHandler) DeleteArticle(c *gin.Context) {
 	var req struct {
 		ID uint `json:"id" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, Response{
-			Code:    400,
-			Message: "参数错误: " + err.Error(),
-		})
+		c.Error(apperr.Validation(err))
 		return
 	}
 
 	// 获取当前用户
 	user, exists := c.Get("user")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, Response{
-			Code:    401,
-			Message: "未登录或登录已过期",
-		})
+		c.Error(ErrNotLoggedIn)
 		return
 	}
 	currentUser := user.(*model.User)
 
 	// 删除文章
-	if err := h.articleService.DeleteArticle(req.ID, currentUser.ID); err != nil {
-		c.JSON(http.StatusInternalServerError, Response{
-			Code:    500,
-			Message: "删除文章失败: " + err.Error(),
-		})
+	if err := h.articleService.DeleteArticle(c.Request.Context(), currentUser, req.ID); err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
-		Message: "删除成功",
+		Message: i18n.T(c, "delete_success"),
 	})
 }
 
@@ -178,26 +181,25 @@ func (h *ArticleHandler) GetArticle(c *gin.Context) {
 		ID uint `json:"id" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, Response{
-			Code:    400,
-			Message: "参数错误: " + err.Error(),
-		})
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
 	// 获取文章
-	article, err := h.articleService.GetArticle(req.ID)
+	article, err := h.articleService.GetArticle(c.Request.Context(), currentUser, req.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, Response{
-			Code:    500,
-			Message: "获取文章失败: " + err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, Response{
 		Code:    200,
-		Message: "获取成功",
+		Message: i18n.T(c, "get_success"),
 		Data:    article,
 	})
 }
@@ -206,32 +208,107 @@ func (h *ArticleHandler) GetArticle(c *gin.Context) {
 func (h *ArticleHandler) ListArticles(c *gin.Context) {
 	var req ListArticleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, Response{
-			Code:    400,
-			Message: "参数错误: " + err.Error(),
-		})
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
 	// 获取文章列表
+	var articles []model.Article
+	var total int64
+	var err error
+	if req.Editable {
+		articles, total, err = h.articleService.ListEditableArticles(c.Request.Context(), currentUser, req.Page, req.PageSize)
+	} else {
+		articles, total, err = h.articleService.ListArticles(
+			c.Request.Context(),
+			currentUser,
+			req.Page,
+			req.PageSize,
+			req.Status,
+			req.AuthorID,
+			req.Tag,
+		)
+	}
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: i18n.T(c, "get_success"),
+		Data: gin.H{
+			"total":    total,
+			"articles": articles,
+		},
+	})
+}
+
+// PopularQuery 热门文章查询参数
+type PopularQuery struct {
+	Window string `form:"window,default=week" binding:"max=20"`
+	Limit  int    `form:"limit,default=10" binding:"min=1,max=50"`
+}
+
+// PopularArticles 获取一段时间内浏览最多的文章
+func (h *ArticleHandler) PopularArticles(c *gin.Context) {
+	var query PopularQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	articles, err := h.articleService.PopularArticles(c.Request.Context(), query.Window, query.Limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "get_success"),
+		Data:    articles,
+	})
+}
+
+// PublicListQuery 公开文章列表查询参数，只返回已发布的文章
+type PublicListQuery struct {
+	Page     int    `form:"page,default=1" binding:"min=1"`
+	PageSize int    `form:"page_size,default=10" binding:"min=1,max=100"`
+	AuthorID uint   `form:"author_id"`
+	Tag      string `form:"tag" binding:"max=50"`
+}
+
+// ListPublishedArticles 公开的已发布文章列表，允许匿名访问
+func (h *ArticleHandler) ListPublishedArticles(c *gin.Context) {
+	var query PublicListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
 	articles, total, err := h.articleService.ListArticles(
-		req.Page,
-		req.PageSize,
-		req.Status,
-		req.AuthorID,
-		req.Tag,
+		c.Request.Context(),
+		optionalUser(c),
+		query.Page,
+		query.PageSize,
+		model.ArticleStatusPublished,
+		query.AuthorID,
+		query.Tag,
 	)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, Response{
-			Code:    500,
-			Message: "获取文章列表失败: " + err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, Response{
-		Code:    200,
-		Message: "获取成功",
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "get_success"),
 		Data: gin.H{
 			"total":    total,
 			"articles": articles,
@@ -239,6 +316,29 @@ func (h *ArticleHandler) ListArticles(c *gin.Context) {
 	})
 }
 
+// GetPublishedArticle 公开的文章详情，允许匿名访问
+func (h *ArticleHandler) GetPublishedArticle(c *gin.Context) {
+	var uri ArticleURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	article, err := h.articleService.GetArticle(c.Request.Context(), optionalUser(c), uri.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// 计数变化不更新 updated_at，客户端同时带 If-None-Match 时以 ETag 为准
+	c.Header("Last-Modified", article.UpdatedAt.UTC().Format(http.TimeFormat))
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "get_success"),
+		Data:    article,
+	})
+}
+
 // RegisterRoutes 注册路由
 func (h *ArticleHandler) RegisterRoutes(r *gin.Engine) {
 	api := r.Group("/api/v1/articles")
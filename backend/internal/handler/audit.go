@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"blog/internal/apperr"
+	"blog/internal/i18n"
+	"blog/internal/model"
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IAuditService interface {
+	List(ctx context.Context, query *model.AuditQuery) ([]model.AuditEvent, int64, error)
+}
+
+type AuditHandler struct {
+	auditService IAuditService
+}
+
+func NewAuditHandler(auditService IAuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// List 管理员按条件查询审计事件
+func (h *AuditHandler) List(c *gin.Context) {
+	var query model.AuditQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	events, total, err := h.auditService.List(c.Request.Context(), &query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "get_success"),
+		Data: gin.H{
+			"total":  total,
+			"events": events,
+		},
+	})
+}
@@ -1,15 +1,33 @@
 package handler
 
 import (
+	"blog/internal/apperr"
+	"blog/internal/i18n"
 	"blog/internal/model"
+	"context"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// 第三方登录的浏览器绑定 Cookie，只在授权和回调接口中发送
+const (
+	oauthBindingCookie = "oauth_binding"
+	oauthCookiePath    = "/api/v1/auth/oauth"
+)
+
 type IAuthService interface {
-	Register(req *model.RegisterRequest) (*model.User, error)
-	Login(req *model.LoginRequest) (*model.LoginResponse, error)
+	Register(ctx context.Context, req *model.RegisterRequest) (*model.User, error)
+	Login(ctx context.Context, req *model.LoginRequest) (*model.LoginResponse, error)
+	LoginTwoFactor(ctx context.Context, challenge, code string) (*model.LoginResponse, error)
+	VerifyEmail(ctx context.Context, token string) error
+	ResendVerification(ctx context.Context, user *model.User) error
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, password string) error
+	OAuthProviders() []string
+	OAuthStart(ctx context.Context, provider string) (*model.OAuthStartResponse, error)
+	OAuthCallback(ctx context.Context, provider, code, state, binding string) (*model.LoginResponse, error)
 }
 
 type AuthHandler struct {
@@ -25,27 +43,19 @@ func NewAuthHandler(authService IAuthService) *AuthHandler {
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req model.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, Response{
-			Code:    http.StatusBadRequest,
-			Message: "参数错误: " + err.Error(),
-			Data:    nil,
-		})
+		c.Error(apperr.Validation(err))
 		return
 	}
 
-	user, err := h.authService.Register(&req)
+	user, err := h.authService.Register(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, Response{
-			Code:    http.StatusBadRequest,
-			Message: "注册失败: " + err.Error(),
-			Data:    nil,
-		})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, Response{
 		Code:    http.StatusCreated,
-		Message: "注册成功",
+		Message: i18n.T(c, "register_success"),
 		Data:    user,
 	})
 }
@@ -53,36 +63,198 @@ func (h *AuthHandler) Register(c *gin.Context) {
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req model.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, Response{
-			Code:    http.StatusBadRequest,
-			Message: "参数错误: " + err.Error(),
-			Data:    nil,
-		})
+		c.Error(apperr.Validation(err))
 		return
 	}
 
-	response, err := h.authService.Login(&req)
+	response, err := h.authService.Login(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, Response{
-			Code:    http.StatusUnauthorized,
-			Message: "登录失败: " + err.Error(),
-			Data:    nil,
-		})
+		c.Error(err)
 		return
 	}
 
+	message := i18n.T(c, "login_success")
+	if response.TwoFactorRequired {
+		message = i18n.T(c, "two_factor_code_required")
+	}
 	c.JSON(http.StatusOK, Response{
 		Code:    http.StatusOK,
-		Message: "登录成功",
+		Message: message,
 		Data:    response,
 	})
 }
 
+// LoginTwoFactor 两步验证登录
+func (h *AuthHandler) LoginTwoFactor(c *gin.Context) {
+	var req model.LoginTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	response, err := h.authService.LoginTwoFactor(c.Request.Context(), req.ChallengeToken, req.Code)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "login_success"),
+		Data:    response,
+	})
+}
+
+// VerifyEmail 验证邮箱
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req model.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	if err := h.authService.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "email_verified"),
+		Data:    nil,
+	})
+}
+
+// ResendVerification 重新发送验证邮件
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.Error(ErrNotLoggedIn)
+		return
+	}
+
+	if err := h.authService.ResendVerification(c.Request.Context(), user.(*model.User)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "verify_email_sent"),
+		Data:    nil,
+	})
+}
+
+// ForgotPassword 发送密码重置邮件
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req model.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	if err := h.authService.ForgotPassword(c.Request.Context(), req.Email); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "password_reset_sent"),
+		Data:    nil,
+	})
+}
+
+// ResetPassword 重置密码
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req model.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	if err := h.authService.ResetPassword(c.Request.Context(), req.Token, req.Password); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "password_reset_success"),
+		Data:    nil,
+	})
+}
+
+// OAuthProviders 已启用的第三方登录方式
+func (h *AuthHandler) OAuthProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "get_success"),
+		Data:    h.authService.OAuthProviders(),
+	})
+}
+
+// OAuthStart 获取第三方登录授权地址，并把授权绑定值写入 Cookie
+func (h *AuthHandler) OAuthStart(c *gin.Context) {
+	response, err := h.authService.OAuthStart(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	setOAuthBinding(c, response.Binding, int(time.Until(response.ExpiresAt).Seconds()))
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "get_success"),
+		Data:    response,
+	})
+}
+
+// OAuthCallback 使用第三方回调的授权码登录
+func (h *AuthHandler) OAuthCallback(c *gin.Context) {
+	var req model.OAuthCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	// Cookie 缺失时按绑定不符处理
+	binding, _ := c.Cookie(oauthBindingCookie)
+	// state 已被消费，无论成功与否都清除绑定
+	setOAuthBinding(c, "", -1)
+
+	response, err := h.authService.OAuthCallback(c.Request.Context(), c.Param("provider"), req.Code, req.State, binding)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	message := i18n.T(c, "login_success")
+	if response.TwoFactorRequired {
+		message = i18n.T(c, "two_factor_code_required")
+	}
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: message,
+		Data:    response,
+	})
+}
+
+// setOAuthBinding 设置只在第三方登录接口中发送的绑定 Cookie，maxAge 小于 0 时删除
+func setOAuthBinding(c *gin.Context, value string, maxAge int) {
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthBindingCookie, value, maxAge, oauthCookiePath, "", secure, true)
+}
+
 // RegisterRoutes 注册路由
 func (h *AuthHandler) RegisterRoutes(r *gin.Engine) {
 	api := r.Group("/api/v1/auth")
 	{
 		api.POST("/register", h.Register)
 		api.POST("/login", h.Login)
+		api.POST("/verify", h.VerifyEmail)
+		api.POST("/forgot-password", h.ForgotPassword)
+		api.POST("/reset-password", h.ResetPassword)
 	}
 }
@@ -1,17 +1,22 @@
 package handler
 
 import (
+	"blog/internal/middleware"
 	"blog/internal/model"
+	"blog/internal/service"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockAuthService 模拟认证服务
@@ -19,7 +24,7 @@ type MockAuthService struct {
 	mock.Mock
 }
 
-func (m *MockAuthService) Register(req *model.RegisterRequest) (*model.User, error) {
+func (m *MockAuthService) Register(ctx context.Context, req *model.RegisterRequest) (*model.User, error) {
 	args := m.Called(req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -27,7 +32,7 @@ func (m *MockAuthService) Register(req *model.RegisterRequest) (*model.User, err
 	return args.Get(0).(*model.User), args.Error(1)
 }
 
-func (m *MockAuthService) Login(req *model.LoginRequest) (*model.LoginResponse, error) {
+func (m *MockAuthService) Login(ctx context.Context, req *model.LoginRequest) (*model.LoginResponse, error) {
 	args := m.Called(req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -35,9 +40,59 @@ func (m *MockAuthService) Login(req *model.LoginRequest) (*model.LoginResponse,
 	return args.Get(0).(*model.LoginResponse), args.Error(1)
 }
 
+func (m *MockAuthService) LoginTwoFactor(ctx context.Context, challenge, code string) (*model.LoginResponse, error) {
+	args := m.Called(challenge, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.LoginResponse), args.Error(1)
+}
+
+func (m *MockAuthService) VerifyEmail(ctx context.Context, token string) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ResendVerification(ctx context.Context, user *model.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ForgotPassword(ctx context.Context, email string) error {
+	args := m.Called(email)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ResetPassword(ctx context.Context, token, password string) error {
+	args := m.Called(token, password)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) OAuthProviders() []string {
+	args := m.Called()
+	return args.Get(0).([]string)
+}
+
+func (m *MockAuthService) OAuthStart(ctx context.Context, provider string) (*model.OAuthStartResponse, error) {
+	args := m.Called(provider)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.OAuthStartResponse), args.Error(1)
+}
+
+func (m *MockAuthService) OAuthCallback(ctx context.Context, provider, code, state, binding string) (*model.LoginResponse, error) {
+	args := m.Called(provider, code, state, binding)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.LoginResponse), args.Error(1)
+}
+
 func setupRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
+	r.Use(middleware.ErrorMiddleware())
 	return r
 }
 
@@ -72,10 +127,12 @@ func TestAuthHandler_Register(t *testing.T) {
 		router.ServeHTTP(w, request)
 
 		assert.Equal(t, http.StatusCreated, w.Code)
-		var response model.User
+		var response struct {
+			Data model.User `json:"data"`
+		}
 		json.Unmarshal(w.Body.Bytes(), &response)
-		assert.Equal(t, req.Username, response.Username)
-		assert.Equal(t, req.Email, response.Email)
+		assert.Equal(t, req.Username, response.Data.Username)
+		assert.Equal(t, req.Email, response.Data.Email)
 	})
 
 	// 测试用例2：无效的请求数据
@@ -100,29 +157,61 @@ func TestAuthHandler_Register(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
 
-	// 测试用例3：服务错误
-	// t.Run("服务错误", func(t *testing.T) {
-	// 	req := model.RegisterRequest{
-	// 		Username: "testuser",
-	// 		Email:    "test@example.com",
-	// 		Password: "password123",
-	// 	}
+	// 测试用例3：邮箱已存在
+	t.Run("邮箱已存在", func(t *testing.T) {
+		req := model.RegisterRequest{
+			Username: "testuser",
+			Email:    "existing@example.com",
+			Password: "password123",
+		}
+
+		mockService.On("Register", &req).Return(nil, service.ErrEmailExists)
+
+		router := setupRouter()
+		router.POST("/register", handler.Register)
+
+		jsonData, _ := json.Marshal(req)
+		reqBody := bytes.NewBuffer(jsonData)
+		w := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/register", reqBody)
+		request.Header.Set("Content-Type", "application/json")
+
+		router.ServeHTTP(w, request)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "email_exists", response["error_code"])
+	})
+
+	// 测试用例4：服务错误
+	t.Run("服务错误", func(t *testing.T) {
+		req := model.RegisterRequest{
+			Username: "testuser",
+			Email:    "broken@example.com",
+			Password: "password123",
+		}
 
-	// 	mockService.On("Register", &req).Return(nil, errors.New("service error"))
+		mockService.On("Register", &req).Return(nil, errors.New("Error 1045: Access denied for user 'root'"))
 
-	// 	router := setupRouter()
-	// 	router.POST("/register", handler.Register)
+		router := setupRouter()
+		router.POST("/register", handler.Register)
 
-	// 	jsonData, _ := json.Marshal(req)
-	// 	reqBody := bytes.NewBuffer(jsonData)
-	// 	w := httptest.NewRecorder()
-	// 	request, _ := http.NewRequest("POST", "/register", reqBody)
-	// 	request.Header.Set("Content-Type", "application/json")
+		jsonData, _ := json.Marshal(req)
+		reqBody := bytes.NewBuffer(jsonData)
+		w := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/register", reqBody)
+		request.Header.Set("Content-Type", "application/json")
 
-	// 	router.ServeHTTP(w, request)
+		router.ServeHTTP(w, request)
 
-	// 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	// })
+		// 内部错误不应泄露给客户端
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.NotContains(t, w.Body.String(), "Access denied")
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "internal_error", response["error_code"])
+	})
 }
 
 func TestAuthHandler_Login(t *testing.T) {
@@ -138,7 +227,7 @@ func TestAuthHandler_Login(t *testing.T) {
 
 		response := &model.LoginResponse{
 			Token: "test-token",
-			User: model.User{
+			User: &model.User{
 				Username: "testuser",
 				Email:    req.Email,
 			},
@@ -158,11 +247,13 @@ func TestAuthHandler_Login(t *testing.T) {
 		router.ServeHTTP(w, request)
 
 		assert.Equal(t, http.StatusOK, w.Code)
-		var loginResponse model.LoginResponse
+		var loginResponse struct {
+			Data model.LoginResponse `json:"data"`
+		}
 		json.Unmarshal(w.Body.Bytes(), &loginResponse)
-		assert.Equal(t, response.Token, loginResponse.Token)
-		assert.Equal(t, response.User.Username, loginResponse.User.Username)
-		assert.Equal(t, response.User.Email, loginResponse.User.Email)
+		assert.Equal(t, response.Token, loginResponse.Data.Token)
+		assert.Equal(t, response.User.Username, loginResponse.Data.User.Username)
+		assert.Equal(t, response.User.Email, loginResponse.Data.User.Email)
 	})
 
 	// 测试用例2：无效的请求数据
@@ -193,7 +284,7 @@ func TestAuthHandler_Login(t *testing.T) {
 			Password: "wrongpassword",
 		}
 
-		mockService.On("Login", &req).Return(nil, errors.New("invalid credentials"))
+		mockService.On("Login", &req).Return(nil, service.ErrInvalidCredentials)
 
 		router := setupRouter()
 		router.POST("/login", handler.Login)
@@ -209,3 +300,56 @@ func TestAuthHandler_Login(t *testing.T) {
 		assert.Equal(t, http.StatusUnauthorized, w.Code)
 	})
 }
+
+func TestAuthHandler_OAuthBindingCookie(t *testing.T) {
+	mockService := new(MockAuthService)
+	handler := NewAuthHandler(mockService)
+	router := setupRouter()
+	router.GET("/api/v1/auth/oauth/:provider", handler.OAuthStart)
+	router.POST("/api/v1/auth/oauth/:provider/callback", handler.OAuthCallback)
+
+	mockService.On("OAuthStart", "github").Return(&model.OAuthStartResponse{
+		AuthorizationURL: "https://github.example/authorize",
+		State:            "state-1",
+		ExpiresAt:        time.Now().Add(10 * time.Minute),
+		Binding:          "binding-1",
+	}, nil)
+	mockService.On("OAuthCallback", "github", "code-1", "state-1", "binding-1").
+		Return(&model.LoginResponse{Token: "jwt"}, nil)
+	mockService.On("OAuthCallback", "github", "code-1", "state-1", "").
+		Return(nil, service.ErrOAuthInvalidState)
+
+	// 绑定值只写入 HttpOnly Cookie，不出现在响应中
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/auth/oauth/github", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "binding-1")
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "binding-1", cookies[0].Value)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+	assert.Equal(t, "/api/v1/auth/oauth", cookies[0].Path)
+
+	callback := func(cookie *http.Cookie) *httptest.ResponseRecorder {
+		body := bytes.NewBufferString(`{"code":"code-1","state":"state-1"}`)
+		request := httptest.NewRequest("POST", "/api/v1/auth/oauth/github/callback", body)
+		request.Header.Set("Content-Type", "application/json")
+		if cookie != nil {
+			request.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, request)
+		return w
+	}
+
+	// 带回 Cookie 时登录成功，并清除 Cookie
+	w = callback(cookies[0])
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, w.Result().Cookies(), 1)
+	assert.Negative(t, w.Result().Cookies()[0].MaxAge)
+
+	// 其他浏览器提交同样的 code 和 state 时没有 Cookie
+	w = callback(nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
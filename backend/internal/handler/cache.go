@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"blog/internal/cache"
+	"blog/internal/i18n"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CacheHandler struct {
+	stats func() []cache.StatsSnapshot
+}
+
+func NewCacheHandler(stats func() []cache.StatsSnapshot) *CacheHandler {
+	return &CacheHandler{stats: stats}
+}
+
+// Stats 管理员查看各类缓存的命中统计
+func (h *CacheHandler) Stats(c *gin.Context) {
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "get_success"),
+		Data:    gin.H{"caches": h.stats()},
+	})
+}
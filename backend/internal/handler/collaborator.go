@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"blog/internal/apperr"
+	"blog/internal/i18n"
+	"blog/internal/model"
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ICollaboratorService interface {
+	List(ctx context.Context, user *model.User, articleID uint) ([]model.ArticleCollaborator, error)
+	Invite(ctx context.Context, user *model.User, articleID uint, req *model.InviteCollaboratorRequest) (*model.ArticleCollaborator, error)
+	Remove(ctx context.Context, user *model.User, articleID, userID uint) error
+}
+
+type CollaboratorHandler struct {
+	collaboratorService ICollaboratorService
+}
+
+func NewCollaboratorHandler(collaboratorService ICollaboratorService) *CollaboratorHandler {
+	return &CollaboratorHandler{collaboratorService: collaboratorService}
+}
+
+// CollaboratorURI 路径中的文章 ID 和协作者的用户 ID
+type CollaboratorURI struct {
+	ID     uint `uri:"id" binding:"required,min=1"`
+	UserID uint `uri:"user_id" binding:"required,min=1"`
+}
+
+// List 查看文章的协作者
+func (h *CollaboratorHandler) List(c *gin.Context) {
+	var uri ArticleURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	collaborators, err := h.collaboratorService.List(c.Request.Context(), currentUser, uri.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "get_success"),
+		Data:    gin.H{"collaborators": collaborators},
+	})
+}
+
+// Invite 添加协作者或修改其角色
+func (h *CollaboratorHandler) Invite(c *gin.Context) {
+	var uri ArticleURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	var req model.InviteCollaboratorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	collaborator, err := h.collaboratorService.Invite(c.Request.Context(), currentUser, uri.ID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "collaborator_invited"),
+		Data:    collaborator,
+	})
+}
+
+// Remove 移除协作者
+func (h *CollaboratorHandler) Remove(c *gin.Context) {
+	var uri CollaboratorURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.collaboratorService.Remove(c.Request.Context(), currentUser, uri.ID, uri.UserID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "delete_success"),
+	})
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"blog/internal/httpcache"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// writeConditional 输出响应并设置 ETag 和 Last-Modified，客户端缓存仍有效时返回 304
+func writeConditional(c *gin.Context, contentType string, body []byte, lastModified time.Time) {
+	etag := httpcache.ETag(body)
+
+	c.Header("ETag", etag)
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	if httpcache.NotModified(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, contentType, body)
+}
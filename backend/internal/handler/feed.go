@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"blog/internal/feed"
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IFeedService interface {
+	Build(ctx context.Context, username, tag, selfPath string) (*feed.Feed, error)
+}
+
+type FeedHandler struct {
+	feedService IFeedService
+}
+
+func NewFeedHandler(feedService IFeedService) *FeedHandler {
+	return &FeedHandler{feedService: feedService}
+}
+
+// RSS 输出 RSS 2.0 订阅源，路径中的 username 或 tag 用于筛选
+func (h *FeedHandler) RSS(c *gin.Context) {
+	h.serve(c, "application/rss+xml; charset=utf-8", (*feed.Feed).RSS)
+}
+
+// Atom 输出 Atom 订阅源
+func (h *FeedHandler) Atom(c *gin.Context) {
+	h.serve(c, "application/atom+xml; charset=utf-8", (*feed.Feed).Atom)
+}
+
+func (h *FeedHandler) serve(c *gin.Context, contentType string, render func(*feed.Feed) ([]byte, error)) {
+	f, err := h.feedService.Build(c.Request.Context(), c.Param("username"), c.Param("tag"), c.Request.URL.Path)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	body, err := render(f)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// 订阅器轮询频繁，允许共享缓存短时间缓存
+	c.Header("Cache-Control", "public, max-age=300")
+	writeConditional(c, contentType, body, f.Updated)
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"blog/internal/feed"
+	"blog/internal/service"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubFeedService 返回固定的订阅源
+type stubFeedService struct {
+	feed *feed.Feed
+	err  error
+	tag  string
+}
+
+func (s *stubFeedService) Build(ctx context.Context, username, tag, selfPath string) (*feed.Feed, error) {
+	s.tag = tag
+	return s.feed, s.err
+}
+
+func TestFeedHandler_ConditionalGet(t *testing.T) {
+	updated := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	svc := &stubFeedService{feed: &feed.Feed{Title: "Blog", Updated: updated}}
+	h := NewFeedHandler(svc)
+	r := setupRouter()
+	r.GET("/feed.xml", h.RSS)
+	r.GET("/tags/:tag/atom.xml", h.Atom)
+
+	send := func(path string, header map[string]string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("/feed.xml", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/rss+xml; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "Fri, 02 Jan 2026 03:04:05 GMT", w.Header().Get("Last-Modified"))
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	// ETag 一致时返回 304 且不带响应体
+	w = send("/feed.xml", map[string]string{"If-None-Match": `"other", ` + etag})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	// ETag 不一致时忽略 If-Modified-Since
+	w = send("/feed.xml", map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": "Fri, 02 Jan 2026 03:04:05 GMT"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	assert.Equal(t, http.StatusNotModified, send("/feed.xml", map[string]string{"If-Modified-Since": "Fri, 02 Jan 2026 03:04:05 GMT"}).Code)
+	assert.Equal(t, http.StatusOK, send("/feed.xml", map[string]string{"If-Modified-Since": "Fri, 02 Jan 2026 03:04:04 GMT"}).Code)
+
+	w = send("/tags/go/atom.xml", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/atom+xml; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "go", svc.tag)
+}
+
+func TestFeedHandler_UnknownAuthor(t *testing.T) {
+	h := NewFeedHandler(&stubFeedService{err: service.ErrUserNotFound})
+	r := setupRouter()
+	r.GET("/authors/:username/feed.xml", h.RSS)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/authors/ghost/feed.xml", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"blog/internal/apperr"
+	"blog/internal/i18n"
+	"blog/internal/model"
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IJobService interface {
+	List(ctx context.Context, query *model.JobQuery) ([]model.Job, int64, error)
+	Stats(ctx context.Context) ([]model.JobStat, error)
+	Get(ctx context.Context, id uint) (*model.Job, error)
+	Retry(ctx context.Context, admin *model.User, id uint) (*model.Job, error)
+}
+
+type JobHandler struct {
+	jobService IJobService
+}
+
+func NewJobHandler(jobService IJobService) *JobHandler {
+	return &JobHandler{jobService: jobService}
+}
+
+// JobURI 路径中的任务 ID
+type JobURI struct {
+	ID uint `uri:"id" binding:"required,min=1"`
+}
+
+// List 管理员按队列、类型和状态查询后台任务
+func (h *JobHandler) List(c *gin.Context) {
+	var query model.JobQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	jobs, total, err := h.jobService.List(c.Request.Context(), &query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "get_success"),
+		Data: gin.H{
+			"total": total,
+			"jobs":  jobs,
+		},
+	})
+}
+
+// Stats 管理员查看各队列中每种状态的任务数
+func (h *JobHandler) Stats(c *gin.Context) {
+	stats, err := h.jobService.Stats(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "get_success"),
+		Data:    gin.H{"stats": stats},
+	})
+}
+
+// Get 管理员查看单个任务
+func (h *JobHandler) Get(c *gin.Context) {
+	var uri JobURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	job, err := h.jobService.Get(c.Request.Context(), uri.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "get_success"),
+		Data:    job,
+	})
+}
+
+// Retry 管理员重试死信任务
+func (h *JobHandler) Retry(c *gin.Context) {
+	var uri JobURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.jobService.Retry(c.Request.Context(), currentUser, uri.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, Response{
+		Code:    http.StatusAccepted,
+		Message: i18n.T(c, "job_retry_queued"),
+		Data:    job,
+	})
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"blog/internal/jwtkeys"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKSHandler 公开访问令牌的验证公钥，供其他服务验证本服务签发的令牌
+type JWKSHandler struct {
+	keySet *jwtkeys.KeySet
+}
+
+func NewJWKSHandler(keySet *jwtkeys.KeySet) *JWKSHandler {
+	return &JWKSHandler{keySet: keySet}
+}
+
+// JWKS 按 RFC 7517 格式直接返回密钥集，不使用统一的 Response 包装
+func (h *JWKSHandler) JWKS(c *gin.Context) {
+	// 允许缓存，但不宜过长，以便轮换后新密钥尽快生效
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keySet.JWKS())
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"blog/internal/apperr"
+	"blog/internal/i18n"
+	"blog/internal/model"
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IReactionService interface {
+	SetReaction(ctx context.Context, user *model.User, articleID uint, kind string, active bool) (*model.ReactionResult, error)
+	ListBookmarks(ctx context.Context, user *model.User, page, pageSize int) ([]model.Article, int64, error)
+}
+
+type ReactionHandler struct {
+	reactionService IReactionService
+}
+
+func NewReactionHandler(reactionService IReactionService) *ReactionHandler {
+	return &ReactionHandler{reactionService: reactionService}
+}
+
+// ArticleURI 路径中的文章 ID
+type ArticleURI struct {
+	ID uint `uri:"id" binding:"required,min=1"`
+}
+
+// Like 点赞文章，重复点赞不报错
+func (h *ReactionHandler) Like(c *gin.Context) {
+	h.setReaction(c, model.ReactionLike, true)
+}
+
+// Unlike 取消点赞
+func (h *ReactionHandler) Unlike(c *gin.Context) {
+	h.setReaction(c, model.ReactionLike, false)
+}
+
+// Bookmark 收藏文章，重复收藏不报错
+func (h *ReactionHandler) Bookmark(c *gin.Context) {
+	h.setReaction(c, model.ReactionBookmark, true)
+}
+
+// Unbookmark 取消收藏
+func (h *ReactionHandler) Unbookmark(c *gin.Context) {
+	h.setReaction(c, model.ReactionBookmark, false)
+}
+
+// ListBookmarks 获取当前用户收藏的文章
+func (h *ReactionHandler) ListBookmarks(c *gin.Context) {
+	var query model.ReactionQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	articles, total, err := h.reactionService.ListBookmarks(c.Request.Context(), currentUser, query.Page, query.PageSize)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "get_success"),
+		Data: gin.H{
+			"total":    total,
+			"articles": articles,
+		},
+	})
+}
+
+func (h *ReactionHandler) setReaction(c *gin.Context, kind string, active bool) {
+	var uri ArticleURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.reactionService.SetReaction(c.Request.Context(), currentUser, uri.ID, kind, active)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "update_success"),
+		Data:    result,
+	})
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"blog/internal/apperr"
+	"blog/internal/i18n"
+	"blog/internal/model"
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IReviewService interface {
+	TransitionArticle(ctx context.Context, user *model.User, articleID uint, req *model.TransitionRequest) (*model.Article, error)
+	ListTransitions(ctx context.Context, user *model.User, articleID uint) ([]model.ArticleTransition, error)
+	ListReviewComments(ctx context.Context, user *model.User, articleID uint) ([]model.ReviewComment, error)
+	AddReviewComment(ctx context.Context, user *model.User, articleID uint, req *model.CreateReviewCommentRequest) (*model.ReviewComment, error)
+}
+
+type ReviewHandler struct {
+	reviewService IReviewService
+}
+
+func NewReviewHandler(reviewService IReviewService) *ReviewHandler {
+	return &ReviewHandler{reviewService: reviewService}
+}
+
+// Transition 按审核流程变更文章状态
+func (h *ReviewHandler) Transition(c *gin.Context) {
+	var uri ArticleURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	var req model.TransitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	article, err := h.reviewService.TransitionArticle(c.Request.Context(), currentUser, uri.ID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "status_changed"),
+		Data:    article,
+	})
+}
+
+// ListTransitions 查看文章的状态变更记录
+func (h *ReviewHandler) ListTransitions(c *gin.Context) {
+	var uri ArticleURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	transitions, err := h.reviewService.ListTransitions(c.Request.Context(), currentUser, uri.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "get_success"),
+		Data:    gin.H{"transitions": transitions},
+	})
+}
+
+// ListComments 查看文章的审核评论
+func (h *ReviewHandler) ListComments(c *gin.Context) {
+	var uri ArticleURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	comments, err := h.reviewService.ListReviewComments(c.Request.Context(), currentUser, uri.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "get_success"),
+		Data:    gin.H{"comments": comments},
+	})
+}
+
+// AddComment 发表审核评论
+func (h *ReviewHandler) AddComment(c *gin.Context) {
+	var uri ArticleURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	var req model.CreateReviewCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	comment, err := h.reviewService.AddReviewComment(c.Request.Context(), currentUser, uri.ID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, Response{
+		Code:    http.StatusCreated,
+		Message: i18n.T(c, "create_success"),
+		Data:    comment,
+	})
+}
@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"blog/internal/apperr"
+	"blog/internal/i18n"
+	"blog/internal/model"
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ISeriesService interface {
+	Create(ctx context.Context, user *model.User, req *model.CreateSeriesRequest) (*model.Series, error)
+	Get(ctx context.Context, viewer *model.User, id uint) (*model.SeriesDetail, error)
+	Update(ctx context.Context, user *model.User, id uint, req *model.UpdateSeriesRequest) (*model.Series, error)
+	Delete(ctx context.Context, user *model.User, id uint) error
+	AttachArticle(ctx context.Context, user *model.User, id uint, req *model.AttachSeriesArticleRequest) (*model.SeriesDetail, error)
+	DetachArticle(ctx context.Context, user *model.User, id, articleID uint) error
+	Reorder(ctx context.Context, user *model.User, id uint, req *model.ReorderSeriesRequest) (*model.SeriesDetail, error)
+}
+
+type SeriesHandler struct {
+	seriesService ISeriesService
+}
+
+func NewSeriesHandler(seriesService ISeriesService) *SeriesHandler {
+	return &SeriesHandler{seriesService: seriesService}
+}
+
+// SeriesURI 路径中的系列 ID
+type SeriesURI struct {
+	ID uint `uri:"id" binding:"required,min=1"`
+}
+
+// SeriesArticleURI 路径中的系列 ID 和文章 ID
+type SeriesArticleURI struct {
+	ID        uint `uri:"id" binding:"required,min=1"`
+	ArticleID uint `uri:"article_id" binding:"required,min=1"`
+}
+
+// Create 创建系列
+func (h *SeriesHandler) Create(c *gin.Context) {
+	var req model.CreateSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	series, err := h.seriesService.Create(c.Request.Context(), currentUser, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, Response{
+		Code:    http.StatusCreated,
+		Message: i18n.T(c, "create_success"),
+		Data:    series,
+	})
+}
+
+// Get 获取系列及其中的文章，允许匿名访问
+func (h *SeriesHandler) Get(c *gin.Context) {
+	var uri SeriesURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	series, err := h.seriesService.Get(c.Request.Context(), optionalUser(c), uri.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "get_success"),
+		Data:    series,
+	})
+}
+
+// Update 修改系列
+func (h *SeriesHandler) Update(c *gin.Context) {
+	var uri SeriesURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	var req model.UpdateSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	series, err := h.seriesService.Update(c.Request.Context(), currentUser, uri.ID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "update_success"),
+		Data:    series,
+	})
+}
+
+// Delete 删除系列
+func (h *SeriesHandler) Delete(c *gin.Context) {
+	var uri SeriesURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.seriesService.Delete(c.Request.Context(), currentUser, uri.ID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "delete_success"),
+	})
+}
+
+// AttachArticle 把文章加入系列或调整其位置
+func (h *SeriesHandler) AttachArticle(c *gin.Context) {
+	var uri SeriesURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	var req model.AttachSeriesArticleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	series, err := h.seriesService.AttachArticle(c.Request.Context(), currentUser, uri.ID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "update_success"),
+		Data:    series,
+	})
+}
+
+// DetachArticle 把文章移出系列
+func (h *SeriesHandler) DetachArticle(c *gin.Context) {
+	var uri SeriesArticleURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.seriesService.DetachArticle(c.Request.Context(), currentUser, uri.ID, uri.ArticleID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "delete_success"),
+	})
+}
+
+// Reorder 重新排列系列中的文章
+func (h *SeriesHandler) Reorder(c *gin.Context) {
+	var uri SeriesURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	var req model.ReorderSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	series, err := h.seriesService.Reorder(c.Request.Context(), currentUser, uri.ID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "update_success"),
+		Data:    series,
+	})
+}
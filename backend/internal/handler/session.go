@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"blog/internal/apperr"
+	"blog/internal/i18n"
+	"blog/internal/model"
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ISessionService interface {
+	List(ctx context.Context, user *model.User, currentID uint) ([]model.Session, error)
+	Revoke(ctx context.Context, user *model.User, id uint) error
+}
+
+type SessionHandler struct {
+	sessionService ISessionService
+}
+
+func NewSessionHandler(sessionService ISessionService) *SessionHandler {
+	return &SessionHandler{sessionService: sessionService}
+}
+
+// SessionURI 路径中的会话 ID
+type SessionURI struct {
+	ID uint `uri:"id" binding:"required,min=1"`
+}
+
+// List 获取当前用户已登录的设备
+func (h *SessionHandler) List(c *gin.Context) {
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var currentID uint
+	if session, ok := c.Get("session"); ok {
+		currentID = session.(*model.Session).ID
+	}
+
+	sessions, err := h.sessionService.List(c.Request.Context(), currentUser, currentID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "get_success"),
+		Data:    sessions,
+	})
+}
+
+// Revoke 退出指定设备上的登录
+func (h *SessionHandler) Revoke(c *gin.Context) {
+	var uri SessionURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.sessionService.Revoke(c.Request.Context(), currentUser, uri.ID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "session_revoked_success"),
+	})
+}
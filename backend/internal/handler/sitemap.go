@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"blog/internal/apperr"
+	"blog/internal/sitemap"
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrSitemapNotFound 请求的站点地图分页不存在
+var ErrSitemapNotFound = apperr.New(apperr.ErrNotFound, "sitemap_not_found", "站点地图不存在")
+
+type ISitemapCache interface {
+	Root(ctx context.Context) ([]byte, time.Time, error)
+	Page(ctx context.Context, n int) ([]byte, time.Time, bool, error)
+}
+
+type SitemapHandler struct {
+	cache ISitemapCache
+}
+
+func NewSitemapHandler(cache ISitemapCache) *SitemapHandler {
+	return &SitemapHandler{cache: cache}
+}
+
+const xmlContentType = "application/xml; charset=utf-8"
+
+// Sitemap 输出 /sitemap.xml，URL 过多时为站点地图索引
+func (h *SitemapHandler) Sitemap(c *gin.Context) {
+	body, lastMod, err := h.cache.Root(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=600")
+	writeConditional(c, xmlContentType, body, lastMod)
+}
+
+// Page 输出索引引用的分页文件，路径形如 /sitemaps/sitemap-2.xml
+func (h *SitemapHandler) Page(c *gin.Context) {
+	name := strings.TrimSuffix(strings.TrimPrefix(c.Param("file"), "sitemap-"), ".xml")
+	n, err := strconv.Atoi(name)
+	if err != nil || sitemap.PagePath(n) != "/sitemaps/"+c.Param("file") {
+		c.Error(ErrSitemapNotFound)
+		return
+	}
+
+	body, lastMod, ok, err := h.cache.Page(c.Request.Context(), n)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !ok {
+		c.Error(ErrSitemapNotFound)
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=600")
+	writeConditional(c, xmlContentType, body, lastMod)
+}
+
+// Robots 输出 robots.txt
+func (h *SitemapHandler) Robots(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	writeConditional(c, "text/plain; charset=utf-8", []byte(sitemap.Robots()), time.Time{})
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"blog/internal/apperr"
+	"blog/internal/i18n"
+	"blog/internal/model"
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ITwoFactorService interface {
+	Setup(ctx context.Context, user *model.User, password string) (*model.TwoFactorSetupResponse, error)
+	Enable(ctx context.Context, user *model.User, code string) ([]string, error)
+	Disable(ctx context.Context, user *model.User, password, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, user *model.User, code string) ([]string, error)
+}
+
+type TwoFactorHandler struct {
+	twoFactorService ITwoFactorService
+}
+
+func NewTwoFactorHandler(twoFactorService ITwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{twoFactorService: twoFactorService}
+}
+
+// Setup 获取两步验证密钥和二维码内容
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	var req model.TwoFactorSetupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	setup, err := h.twoFactorService.Setup(c.Request.Context(), currentUser, req.Password)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "get_success"),
+		Data:    setup,
+	})
+}
+
+// Enable 确认动态码并开启两步验证
+func (h *TwoFactorHandler) Enable(c *gin.Context) {
+	var req model.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.twoFactorService.Enable(c.Request.Context(), currentUser, req.Code)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "two_factor_enabled"),
+		Data:    model.RecoveryCodesResponse{RecoveryCodes: codes},
+	})
+}
+
+// Disable 关闭两步验证
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var req model.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.twoFactorService.Disable(c.Request.Context(), currentUser, req.Password, req.Code); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "two_factor_disabled"),
+	})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req model.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(c.Request.Context(), currentUser, req.Code)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "recovery_codes_regenerated"),
+		Data:    model.RecoveryCodesResponse{RecoveryCodes: codes},
+	})
+}
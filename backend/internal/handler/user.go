@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"blog/internal/apperr"
+	"blog/internal/i18n"
+	"blog/internal/model"
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IUserService interface {
+	GetCurrentUser(ctx context.Context, userID uint) (*model.User, error)
+	UpdateProfile(ctx context.Context, userID uint, req *model.UpdateProfileRequest) (*model.User, error)
+	ChangePassword(ctx context.Context, user *model.User, currentSessionID uint, req *model.ChangePasswordRequest) error
+	RequestEmailChange(ctx context.Context, user *model.User, newEmail, password string) error
+	ConfirmEmailChange(ctx context.Context, token string) error
+	GetPublicProfile(ctx context.Context, username string, page, pageSize int) (*model.PublicProfile, error)
+	ChangeRole(ctx context.Context, admin *model.User, userID uint, role string) error
+}
+
+type UserHandler struct {
+	userService IUserService
+}
+
+func NewUserHandler(userService IUserService) *UserHandler {
+	return &UserHandler{userService: userService}
+}
+
+// ProfileQuery 用户主页分页参数
+type ProfileQuery struct {
+	Page     int `form:"page,default=1" json:"page" binding:"min=1"`
+	PageSize int `form:"page_size,default=10" json:"page_size" binding:"min=1,max=100"`
+}
+
+// GetMe 获取当前用户资料
+func (h *UserHandler) GetMe(c *gin.Context) {
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.GetCurrentUser(c.Request.Context(), currentUser.ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "get_success"),
+		Data:    user,
+	})
+}
+
+// UpdateMe 更新当前用户资料
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	var req model.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.UpdateProfile(c.Request.Context(), currentUser.ID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "update_success"),
+		Data:    user,
+	})
+}
+
+// ChangePassword 修改密码
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	var req model.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	// 通过个人访问令牌修改时没有当前会话，全部会话都会被撤销
+	var currentID uint
+	if session, ok := c.Get("session"); ok {
+		currentID = session.(*model.Session).ID
+	}
+
+	if err := h.userService.ChangePassword(c.Request.Context(), currentUser, currentID, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "password_changed"),
+	})
+}
+
+// ChangeEmail 申请更换邮箱，向新邮箱发送确认邮件
+func (h *UserHandler) ChangeEmail(c *gin.Context) {
+	var req model.ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.RequestEmailChange(c.Request.Context(), currentUser, req.NewEmail, req.Password); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "email_change_sent"),
+	})
+}
+
+// ConfirmEmailChange 确认更换邮箱
+func (h *UserHandler) ConfirmEmailChange(c *gin.Context) {
+	var req model.ConfirmEmailChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	if err := h.userService.ConfirmEmailChange(c.Request.Context(), req.Token); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "email_changed"),
+	})
+}
+
+// GetProfile 获取用户公开主页
+func (h *UserHandler) GetProfile(c *gin.Context) {
+	var query ProfileQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	profile, err := h.userService.GetPublicProfile(c.Request.Context(), c.Param("username"), query.Page, query.PageSize)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "get_success"),
+		Data:    profile,
+	})
+}
+
+// UserURI 路径中的用户 ID
+type UserURI struct {
+	ID uint `uri:"id" binding:"required,min=1"`
+}
+
+// UpdateRole 管理员修改用户角色
+func (h *UserHandler) UpdateRole(c *gin.Context) {
+	var uri UserURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+	var req model.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.ChangeRole(c.Request.Context(), currentUser, uri.ID, req.Role); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "update_success"),
+	})
+}
+
+// currentUser 获取当前登录用户，不存在时记录错误
+// optionalUser 公开接口中的当前用户，匿名访问时为 nil
+func optionalUser(c *gin.Context) *model.User {
+	user, exists := c.Get("user")
+	if !exists {
+		return nil
+	}
+	return user.(*model.User)
+}
+
+func currentUser(c *gin.Context) (*model.User, bool) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.Error(ErrNotLoggedIn)
+		return nil, false
+	}
+	return user.(*model.User), true
+}
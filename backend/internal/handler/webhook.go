@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"blog/internal/apperr"
+	"blog/internal/i18n"
+	"blog/internal/model"
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IWebhookService interface {
+	Create(ctx context.Context, user *model.User, req *model.CreateWebhookRequest) (*model.WebhookCreated, error)
+	List(ctx context.Context, user *model.User) ([]model.Webhook, error)
+	Update(ctx context.Context, user *model.User, id uint, req *model.UpdateWebhookRequest) (*model.Webhook, error)
+	Delete(ctx context.Context, user *model.User, id uint) error
+	ListDeliveries(ctx context.Context, user *model.User, id uint, query *model.WebhookDeliveryQuery) ([]model.WebhookDelivery, int64, error)
+	Redeliver(ctx context.Context, user *model.User, id, deliveryID uint) (*model.WebhookDelivery, error)
+}
+
+type WebhookHandler struct {
+	webhookService IWebhookService
+}
+
+func NewWebhookHandler(webhookService IWebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+// WebhookURI 路径中的 webhook ID
+type WebhookURI struct {
+	ID uint `uri:"id" binding:"required,min=1"`
+}
+
+// WebhookDeliveryURI 路径中的 webhook ID 和投递记录 ID
+type WebhookDeliveryURI struct {
+	ID         uint `uri:"id" binding:"required,min=1"`
+	DeliveryID uint `uri:"delivery_id" binding:"required,min=1"`
+}
+
+// Create 创建 webhook
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var req model.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	hook, err := h.webhookService.Create(c.Request.Context(), currentUser, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, Response{
+		Code:    http.StatusCreated,
+		Message: i18n.T(c, "webhook_created"),
+		Data:    hook,
+	})
+}
+
+// List 获取 webhook 列表
+func (h *WebhookHandler) List(c *gin.Context) {
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	hooks, err := h.webhookService.List(c.Request.Context(), currentUser)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "get_success"),
+		Data:    hooks,
+	})
+}
+
+// Update 修改 webhook
+func (h *WebhookHandler) Update(c *gin.Context) {
+	var uri WebhookURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+	var req model.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	hook, err := h.webhookService.Update(c.Request.Context(), currentUser, uri.ID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "update_success"),
+		Data:    hook,
+	})
+}
+
+// Delete 删除 webhook
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	var uri WebhookURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.Delete(c.Request.Context(), currentUser, uri.ID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "delete_success"),
+	})
+}
+
+// ListDeliveries 查看 webhook 的投递记录
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	var uri WebhookURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+	var query model.WebhookDeliveryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	deliveries, total, err := h.webhookService.ListDeliveries(c.Request.Context(), currentUser, uri.ID, &query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    http.StatusOK,
+		Message: i18n.T(c, "get_success"),
+		Data: gin.H{
+			"total":      total,
+			"deliveries": deliveries,
+		},
+	})
+}
+
+// Redeliver 重新投递一次
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	var uri WebhookDeliveryURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.Error(apperr.Validation(err))
+		return
+	}
+
+	currentUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(c.Request.Context(), currentUser, uri.ID, uri.DeliveryID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, Response{
+		Code:    http.StatusAccepted,
+		Message: i18n.T(c, "webhook_redelivery_queued"),
+		Data:    delivery,
+	})
+}
@@ -0,0 +1,93 @@
+package httpcache
+
+import (
+	"blog/config"
+	"blog/internal/cache"
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultMaxEntries = 1000
+	defaultTTL        = 30 * time.Second
+)
+
+// Entry 缓存的响应，只缓存状态码为 200 的响应
+type Entry struct {
+	ContentType  string `json:"content_type"`
+	LastModified string `json:"last_modified,omitempty"`
+	Body         []byte `json:"body"`
+}
+
+// Store 进程内响应缓存。Invalidate 更换 key 的代号使全部条目失效，旧条目由 LRU 淘汰
+type Store struct {
+	lru        *cache.LRU
+	ttl        time.Duration
+	generation atomic.Uint64
+	stats      *cache.Stats
+}
+
+// NewStore 创建最多容纳 maxEntries 个响应的缓存
+func NewStore(maxEntries int, ttl time.Duration) *Store {
+	if maxEntries <= 0 {
+		maxEntries = defaultMaxEntries
+	}
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	return &Store{lru: cache.NewLRU(maxEntries), ttl: ttl, stats: cache.NewStats("http_response")}
+}
+
+// Get 读取缓存的响应
+func (s *Store) Get(key string) (*Entry, bool) {
+	data, ok, _ := s.lru.Get(context.Background(), s.key(key))
+	if !ok {
+		s.stats.Miss()
+		return nil, false
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		s.stats.Error()
+		return nil, false
+	}
+	s.stats.Hit()
+	return &entry, true
+}
+
+// Set 缓存响应
+func (s *Store) Set(key string, entry *Entry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		s.stats.Error()
+		return
+	}
+	s.lru.Set(context.Background(), s.key(key), data, s.ttl)
+}
+
+// Invalidate 使全部缓存的响应失效，文章变化时调用
+func (s *Store) Invalidate() {
+	s.generation.Add(1)
+}
+
+func (s *Store) key(key string) string {
+	return strconv.FormatUint(s.generation.Load(), 10) + "|" + key
+}
+
+var store *Store
+
+// InitStore 根据配置初始化响应缓存
+func InitStore() {
+	store = NewStore(config.AppConfig.HTTPCache.ResponseCache.MaxEntries, config.AppConfig.HTTPCache.ResponseCache.TTL)
+}
+
+// GetStore 获取响应缓存，未初始化时使用默认配置
+func GetStore() *Store {
+	if store == nil {
+		store = NewStore(0, 0)
+	}
+	return store
+}
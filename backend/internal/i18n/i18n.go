@@ -0,0 +1,102 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// 支持的语言
+const (
+	ZhCN = "zh-CN"
+	EnUS = "en-US"
+)
+
+// DefaultLocale 无法协商时使用的语言
+const DefaultLocale = ZhCN
+
+// ContextKey gin.Context 中保存当前语言的键
+const ContextKey = "locale"
+
+// supported 与 matcher 的顺序一一对应，第一个为默认语言
+var supported = []string{ZhCN, EnUS}
+
+var matcher = language.NewMatcher([]language.Tag{
+	language.SimplifiedChinese,
+	language.AmericanEnglish,
+})
+
+// Negotiate 根据 lang 参数或 Accept-Language 头选择语言，lang 参数优先
+func Negotiate(lang, acceptLanguage string) string {
+	if lang != "" {
+		if tag, err := language.Parse(lang); err == nil {
+			_, index, confidence := matcher.Match(tag)
+			if confidence != language.No {
+				return supported[index]
+			}
+		}
+	}
+
+	if acceptLanguage != "" {
+		tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+		if err == nil && len(tags) > 0 {
+			_, index, confidence := matcher.Match(tags...)
+			if confidence != language.No {
+				return supported[index]
+			}
+		}
+	}
+
+	return DefaultLocale
+}
+
+// FromContext 获取请求协商后的语言
+func FromContext(c *gin.Context) string {
+	if locale := c.GetString(ContextKey); locale != "" {
+		return locale
+	}
+	return DefaultLocale
+}
+
+type localeKey struct{}
+
+// WithLocale 把语言写入 context，供服务层生成邮件等内容使用
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFrom 从 context 中获取语言
+func LocaleFrom(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeKey{}).(string); ok && locale != "" {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// Message 获取指定语言的文案，缺失时回退到默认语言，仍缺失则返回 key
+func Message(locale, key string, args ...interface{}) string {
+	msg, ok := catalog[locale][key]
+	if !ok {
+		msg, ok = catalog[DefaultLocale][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// Has 判断文案是否已在目录中定义
+func Has(key string) bool {
+	_, ok := catalog[DefaultLocale][key]
+	return ok
+}
+
+// T 按请求语言获取文案
+func T(c *gin.Context, key string, args ...interface{}) string {
+	return Message(FromContext(c), key, args...)
+}
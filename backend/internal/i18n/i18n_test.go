@@ -0,0 +1,57 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name           string
+		lang           string
+		acceptLanguage string
+		want           string
+	}{
+		{"默认语言", "", "", ZhCN},
+		{"英文请求头", "", "en-US,en;q=0.9", EnUS},
+		{"仅语言部分", "", "en", EnUS},
+		{"按权重选择", "", "fr;q=0.9,zh-CN;q=0.8,en;q=0.7", ZhCN},
+		{"参数优先于请求头", "en-US", "zh-CN", EnUS},
+		{"无效参数回退到请求头", "!!", "en-GB", EnUS},
+		{"不支持的语言", "", "xx", ZhCN},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Negotiate(tt.lang, tt.acceptLanguage))
+		})
+	}
+}
+
+func TestCatalogComplete(t *testing.T) {
+	// 每个文案在所有语言中都必须有翻译
+	for locale, messages := range catalog {
+		for other := range catalog {
+			for key := range messages {
+				_, ok := catalog[other][key]
+				assert.True(t, ok, "%s 中的文案 %s 在 %s 中缺失", locale, key, other)
+			}
+		}
+	}
+}
+
+func TestTranslateValidation(t *testing.T) {
+	RegisterValidator()
+
+	req := struct {
+		Email string `json:"email" binding:"required,email"`
+	}{}
+	err := binding.Validator.ValidateStruct(&req)
+	assert.Error(t, err)
+
+	assert.Equal(t, "email为必填字段", TranslateValidation(ZhCN, err))
+	assert.Equal(t, "email is a required field", TranslateValidation(EnUS, err))
+	assert.Equal(t, "", TranslateValidation(EnUS, assert.AnError))
+}
@@ -0,0 +1,254 @@
+package i18n
+
+// catalog 文案目录：语言 -> 文案键 -> 文案
+// 错误文案的键与 apperr.Error 的错误码一致
+var catalog = map[string]map[string]string{
+	ZhCN: {
+		// 通用
+		"create_success":   "创建成功",
+		"update_success":   "更新成功",
+		"delete_success":   "删除成功",
+		"get_success":      "获取成功",
+		"register_success": "注册成功",
+		"login_success":    "登录成功",
+		"invalid_params":   "参数错误",
+		"internal_error":   "服务器内部错误",
+		"not_logged_in":    "未登录或登录已过期",
+
+		// 认证中间件
+		"missing_token":        "缺少认证头",
+		"invalid_token_format": "认证头格式错误",
+		"invalid_token":        "无效的令牌",
+		"invalid_token_claims": "无效的令牌声明",
+		"user_not_found":       "用户不存在",
+		"token_user_not_found": "令牌对应的用户不存在",
+
+		// 用户认证
+		"email_exists":        "邮箱已存在",
+		"invalid_credentials": "账号或密码错误",
+
+		// 密码策略
+		"password_too_short": "密码长度不能少于 %d 位",
+		"password_too_long":  "密码长度不能超过 %d 位",
+		"password_too_weak":  "密码需至少包含大写字母、小写字母、数字、符号中的 %d 类",
+		"password_breached":  "该密码过于常见或已在泄露的密码库中出现，请更换",
+
+		// 邮箱验证与密码重置
+		"verify_email_sent":        "验证邮件已发送",
+		"email_verified":           "邮箱验证成功",
+		"password_reset_sent":      "如果该邮箱已注册，重置邮件已发送",
+		"password_reset_success":   "密码重置成功",
+		"invalid_or_expired_token": "链接无效或已过期",
+		"email_already_verified":   "邮箱已验证",
+		"email_not_verified":       "请先验证邮箱后再发布文章",
+		"mail_verify_subject":      "请验证你的邮箱",
+		"mail_verify_body":         "%s，你好：\n\n请点击以下链接验证邮箱：\n%s\n\n链接 %s 内有效。如果这不是你本人的操作，请忽略本邮件。",
+		"mail_reset_subject":       "重置你的密码",
+		"mail_reset_body":          "%s，你好：\n\n请点击以下链接重置密码：\n%s\n\n链接 %s 内有效。如果这不是你本人的操作，请忽略本邮件，你的密码不会改变。",
+
+		// 用户资料
+		"username_exists":           "用户名已存在",
+		"incorrect_password":        "密码错误",
+		"same_email":                "新邮箱不能与当前邮箱相同",
+		"password_changed":          "密码修改成功",
+		"email_change_sent":         "确认邮件已发送至新邮箱",
+		"email_changed":             "邮箱更换成功",
+		"mail_change_email_subject": "确认更换邮箱",
+		"mail_change_email_body":    "%s，你好：\n\n请点击以下链接确认将此邮箱设为账号邮箱：\n%s\n\n链接 %s 内有效。如果这不是你本人的操作，请忽略本邮件。",
+
+		// 两步验证
+		"two_factor_code_required":     "请输入两步验证动态码",
+		"two_factor_enabled":           "两步验证已开启，请妥善保存恢复码",
+		"two_factor_disabled":          "两步验证已关闭",
+		"recovery_codes_regenerated":   "恢复码已重新生成，旧恢复码已失效",
+		"two_factor_already_enabled":   "两步验证已开启",
+		"two_factor_not_enabled":       "两步验证未开启",
+		"two_factor_not_setup":         "请先获取两步验证密钥",
+		"invalid_two_factor_code":      "动态码或恢复码错误",
+		"two_factor_challenge_invalid": "登录验证已失效，请重新登录",
+		"two_factor_required":          "管理员账号必须开启两步验证",
+		"two_factor_setup_required":    "请先开启两步验证",
+
+		// 第三方登录
+		"oauth_provider_not_found": "不支持该第三方登录方式",
+		"oauth_invalid_state":      "授权已失效，请重新登录",
+		"oauth_failed":             "第三方登录失败，请稍后重试",
+		"oauth_email_unverified":   "第三方账号没有已验证的邮箱，无法登录",
+
+		// 个人访问令牌
+		"api_token_created":     "令牌已创建，请立即复制保存，之后将无法再次查看",
+		"api_token_revoked":     "令牌已撤销",
+		"api_token_not_found":   "令牌不存在",
+		"too_many_api_tokens":   "最多只能创建 %d 个令牌",
+		"insufficient_scope":    "令牌缺少 %s 权限",
+		"api_token_not_allowed": "该操作不支持使用访问令牌，请登录后操作",
+
+		// 登录会话
+		"session_revoked":         "登录已失效，请重新登录",
+		"session_revoked_success": "已退出该设备的登录",
+		"session_not_found":       "会话不存在",
+
+		// 权限与审计
+		"permission_denied":      "无权访问",
+		"cannot_change_own_role": "不能修改自己的角色",
+		"invalid_audit_range":    "开始时间必须早于结束时间",
+
+		// webhook
+		"webhook_created":            "webhook 已创建，请立即保存签名密钥，之后将无法再次查看",
+		"webhook_redelivery_queued":  "已加入重新投递队列",
+		"webhook_not_found":          "webhook 不存在",
+		"webhook_delivery_not_found": "投递记录不存在",
+		"too_many_webhooks":          "最多只能创建 %d 个 webhook",
+		"invalid_webhook_url":        "webhook 地址必须是 http 或 https 地址",
+		"webhook_event_forbidden":    "无权订阅事件：%s",
+
+		// 后台任务
+		"job_retry_queued":  "任务已重新加入队列",
+		"job_not_found":     "任务不存在",
+		"job_not_retryable": "只能重试死信任务",
+
+		// 限流
+		"too_many_requests": "请求过于频繁，请稍后再试",
+		"account_locked":    "登录失败次数过多，账号已临时锁定",
+
+		// 文章
+		"article_not_found":      "文章不存在",
+		"article_forbidden":      "无权限操作该文章",
+		"invalid_popular_window": "不支持的统计范围：%s",
+		"sitemap_not_found":      "站点地图不存在",
+
+		// 文章审核
+		"invalid_status_transition": "文章不能从 %s 变更为 %s",
+		"transition_forbidden":      "无权限将文章变更为 %s",
+		"review_required":           "文章需要审核通过后才能发布",
+		"article_status_changed":    "文章状态已被修改，请刷新后重试",
+		"cannot_review_own_article": "不能审核自己编写的文章",
+		"status_changed":            "文章状态已更新",
+
+		// 文章协作者
+		"collaborator_not_found": "协作者不存在",
+		"cannot_invite_author":   "不能把作者添加为协作者",
+		"collaborator_invited":   "已添加协作者",
+
+		// 系列文章
+		"series_not_found":      "系列不存在",
+		"series_forbidden":      "无权限操作该系列",
+		"article_not_in_series": "文章不在该系列中",
+		"invalid_series_order":  "排序必须包含系列中的全部文章且不能重复",
+	},
+	EnUS: {
+		"create_success":   "Created successfully",
+		"update_success":   "Updated successfully",
+		"delete_success":   "Deleted successfully",
+		"get_success":      "Fetched successfully",
+		"register_success": "Registered successfully",
+		"login_success":    "Logged in successfully",
+		"invalid_params":   "Invalid parameters",
+		"internal_error":   "Internal server error",
+		"not_logged_in":    "Not logged in or session expired",
+
+		"missing_token":        "Authorization header is required",
+		"invalid_token_format": "Invalid authorization header format",
+		"invalid_token":        "Invalid token",
+		"invalid_token_claims": "Invalid token claims",
+		"user_not_found":       "User not found",
+		"token_user_not_found": "The user for this token no longer exists",
+
+		"email_exists":        "Email already exists",
+		"invalid_credentials": "Invalid account or password",
+
+		"password_too_short": "Password must be at least %d characters long",
+		"password_too_long":  "Password must be at most %d characters long",
+		"password_too_weak":  "Password must contain at least %d of: uppercase letters, lowercase letters, digits, symbols",
+		"password_breached":  "This password is too common or has appeared in a data breach, please choose another",
+
+		"verify_email_sent":        "Verification email sent",
+		"email_verified":           "Email verified successfully",
+		"password_reset_sent":      "If the email is registered, a reset link has been sent",
+		"password_reset_success":   "Password reset successfully",
+		"invalid_or_expired_token": "The link is invalid or has expired",
+		"email_already_verified":   "Email is already verified",
+		"email_not_verified":       "Please verify your email before publishing articles",
+		"mail_verify_subject":      "Verify your email address",
+		"mail_verify_body":         "Hi %s,\n\nPlease verify your email address by opening the link below:\n%s\n\nThe link is valid for %s. If you did not sign up, please ignore this email.",
+		"mail_reset_subject":       "Reset your password",
+		"mail_reset_body":          "Hi %s,\n\nOpen the link below to reset your password:\n%s\n\nThe link is valid for %s. If you did not request this, please ignore this email and your password will stay the same.",
+
+		"username_exists":           "Username already exists",
+		"incorrect_password":        "Incorrect password",
+		"same_email":                "The new email must differ from the current one",
+		"password_changed":          "Password changed successfully",
+		"email_change_sent":         "A confirmation email has been sent to the new address",
+		"email_changed":             "Email changed successfully",
+		"mail_change_email_subject": "Confirm your new email address",
+		"mail_change_email_body":    "Hi %s,\n\nOpen the link below to use this address as your account email:\n%s\n\nThe link is valid for %s. If you did not request this, please ignore this email.",
+
+		"two_factor_code_required":     "Please enter your two-factor authentication code",
+		"two_factor_enabled":           "Two-factor authentication enabled, please keep your recovery codes safe",
+		"two_factor_disabled":          "Two-factor authentication disabled",
+		"recovery_codes_regenerated":   "Recovery codes regenerated, the old ones no longer work",
+		"two_factor_already_enabled":   "Two-factor authentication is already enabled",
+		"two_factor_not_enabled":       "Two-factor authentication is not enabled",
+		"two_factor_not_setup":         "Please request a two-factor secret first",
+		"invalid_two_factor_code":      "Invalid authentication or recovery code",
+		"two_factor_challenge_invalid": "Your login session has expired, please sign in again",
+		"two_factor_required":          "Two-factor authentication is required for admin accounts",
+		"two_factor_setup_required":    "Please enable two-factor authentication first",
+
+		"oauth_provider_not_found": "This sign-in provider is not supported",
+		"oauth_invalid_state":      "Authorization has expired, please sign in again",
+		"oauth_failed":             "Third-party sign-in failed, please try again later",
+		"oauth_email_unverified":   "The third-party account has no verified email address",
+
+		"api_token_created":     "Token created, copy it now as it will not be shown again",
+		"api_token_revoked":     "Token revoked",
+		"api_token_not_found":   "Token not found",
+		"too_many_api_tokens":   "You can create at most %d tokens",
+		"insufficient_scope":    "The token is missing the %s scope",
+		"api_token_not_allowed": "Access tokens cannot be used for this operation, please sign in",
+
+		"session_revoked":         "Your session has ended, please sign in again",
+		"session_revoked_success": "Signed out of the device",
+		"session_not_found":       "Session not found",
+
+		"permission_denied":      "Permission denied",
+		"cannot_change_own_role": "You cannot change your own role",
+		"invalid_audit_range":    "The start time must be earlier than the end time",
+
+		"webhook_created":            "Webhook created, save the signing secret now as it will not be shown again",
+		"webhook_redelivery_queued":  "Redelivery queued",
+		"webhook_not_found":          "Webhook not found",
+		"webhook_delivery_not_found": "Delivery not found",
+		"too_many_webhooks":          "You can create at most %d webhooks",
+		"invalid_webhook_url":        "The webhook URL must be an http or https URL",
+		"webhook_event_forbidden":    "You are not allowed to subscribe to %s",
+
+		"job_retry_queued":  "Job queued for retry",
+		"job_not_found":     "Job not found",
+		"job_not_retryable": "Only dead jobs can be retried",
+
+		"too_many_requests": "Too many requests, please try again later",
+		"account_locked":    "Too many failed login attempts, the account is temporarily locked",
+
+		"article_not_found":      "Article not found",
+		"article_forbidden":      "You do not have permission to modify this article",
+		"invalid_popular_window": "Unsupported time window: %s",
+		"sitemap_not_found":      "Sitemap not found",
+
+		"invalid_status_transition": "An article cannot move from %s to %s",
+		"transition_forbidden":      "You do not have permission to move this article to %s",
+		"review_required":           "The article must be approved by a reviewer before it can be published",
+		"article_status_changed":    "The article status was changed by someone else, please refresh and try again",
+		"cannot_review_own_article": "You cannot review an article you wrote",
+		"status_changed":            "Article status updated",
+
+		"collaborator_not_found": "Collaborator not found",
+		"cannot_invite_author":   "The author cannot be added as a collaborator",
+		"collaborator_invited":   "Collaborator added",
+
+		"series_not_found":      "Series not found",
+		"series_forbidden":      "You do not have permission to modify this series",
+		"article_not_in_series": "The article is not part of this series",
+		"invalid_series_order":  "The order must list every article in the series exactly once",
+	},
+}
@@ -0,0 +1,72 @@
+package i18n
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	zh_translations "github.com/go-playground/validator/v10/translations/zh"
+)
+
+var (
+	validatorOnce sync.Once
+	uni           *ut.UniversalTranslator
+)
+
+// RegisterValidator 为 gin 的校验器注册中英文翻译，并使用 json 标签作为字段名
+// 需在首次校验请求之前调用，重复调用无副作用
+func RegisterValidator() {
+	validatorOnce.Do(func() {
+		zhLocale := zh.New()
+		uni = ut.New(zhLocale, zhLocale, en.New())
+
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" || name == "" {
+				return field.Name
+			}
+			return name
+		})
+
+		zhTrans, _ := uni.GetTranslator("zh")
+		enTrans, _ := uni.GetTranslator("en")
+		_ = zh_translations.RegisterDefaultTranslations(v, zhTrans)
+		_ = en_translations.RegisterDefaultTranslations(v, enTrans)
+	})
+}
+
+// TranslateValidation 将校验错误翻译为指定语言，非校验错误返回空字符串
+func TranslateValidation(locale string, err error) string {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return ""
+	}
+
+	RegisterValidator()
+	trans, _ := uni.GetTranslator(translatorLocale(locale))
+
+	messages := make([]string, 0, len(errs))
+	for _, fe := range errs {
+		messages = append(messages, fe.Translate(trans))
+	}
+	return strings.Join(messages, "; ")
+}
+
+func translatorLocale(locale string) string {
+	if locale == EnUS {
+		return "en"
+	}
+	return "zh"
+}
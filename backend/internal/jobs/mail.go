@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"blog/internal/mailer"
+	"context"
+)
+
+// SendMail 发送邮件的任务，邮件中的链接包含令牌，发送成功后清空负载
+var SendMail = Definition[mailer.Message]{Type: "mail.send", Queue: "mail", Sensitive: true}
+
+// Mailer 把邮件放入任务队列，由后台任务发送，请求不再等待邮件服务器
+type Mailer struct {
+	queue *Queue
+}
+
+// NewMailer 创建使用任务队列发送邮件的 Mailer
+func NewMailer(queue *Queue) *Mailer {
+	return &Mailer{queue: queue}
+}
+
+func (m *Mailer) Send(ctx context.Context, msg mailer.Message) error {
+	_, err := SendMail.Enqueue(ctx, m.queue, msg)
+	return err
+}
+
+// GetMailer 获取使用全局任务队列的 Mailer
+func GetMailer() mailer.Mailer {
+	return NewMailer(GetQueue())
+}
@@ -0,0 +1,152 @@
+// Package jobs 基于数据库的后台任务队列。任务写入 jobs 表后由 worker 按队列取出执行，
+// 失败时按指数退避重试，超过次数后转为死信等待管理员处理；同一任务可能被执行多次，处理函数应保证幂等
+package jobs
+
+import (
+	"blog/config"
+	"blog/internal/model"
+	"blog/internal/repository"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// DefaultQueue 未指定队列的任务所在的队列
+const DefaultQueue = "default"
+
+const defaultMaxAttempts = 5
+
+// ErrDuplicate 相同唯一键的任务已存在
+var ErrDuplicate = errors.New("jobs: duplicate unique key")
+
+// Store 保存任务及执行结果
+type Store interface {
+	Create(ctx context.Context, job *model.Job) (bool, error)
+	Claim(ctx context.Context, queue string, types []string, now time.Time, lease time.Duration, limit int) ([]model.Job, error)
+	Finish(ctx context.Context, job *model.Job) error
+	DeleteSucceededBefore(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// Definition 一种任务，T 为负载类型，序列化为 JSON 保存
+type Definition[T any] struct {
+	Type        string
+	Queue       string // 为空时使用 DefaultQueue
+	MaxAttempts int    // 包括首次执行在内的最多尝试次数，为 0 时使用队列的默认值
+	Sensitive   bool   // 负载包含令牌等敏感信息，执行成功后清空
+}
+
+func (d Definition[T]) queue() string {
+	if d.Queue == "" {
+		return DefaultQueue
+	}
+	return d.Queue
+}
+
+// Enqueue 把任务放入队列，设置了唯一键且已存在时返回 ErrDuplicate
+func (d Definition[T]) Enqueue(ctx context.Context, q *Queue, payload T, opts ...Option) (*model.Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	job := &model.Job{
+		Queue:       d.queue(),
+		Type:        d.Type,
+		Payload:     string(data),
+		Status:      model.JobPending,
+		MaxAttempts: d.MaxAttempts,
+		RunAt:       q.now(),
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = q.maxAttempts
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+	return job, q.create(ctx, job)
+}
+
+// Option 放入任务时的选项
+type Option func(job *model.Job)
+
+// RunAt 到指定时间后才执行
+func RunAt(t time.Time) Option {
+	return func(job *model.Job) { job.RunAt = t }
+}
+
+// Delay 延迟 d 后执行
+func Delay(d time.Duration) Option {
+	return func(job *model.Job) { job.RunAt = job.RunAt.Add(d) }
+}
+
+// UniqueKey 相同唯一键的任务在表中只保留一个，成功的任务被清理前不能再次放入
+func UniqueKey(key string) Option {
+	return func(job *model.Job) { job.UniqueKey = &key }
+}
+
+// Queue 放入任务的入口
+type Queue struct {
+	store       Store
+	maxAttempts int
+	now         func() time.Time
+}
+
+// NewQueue 创建任务入口，maxAttempts 为任务未指定时的最多尝试次数
+func NewQueue(store Store, maxAttempts int) *Queue {
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	return &Queue{store: store, maxAttempts: maxAttempts, now: time.Now}
+}
+
+func (q *Queue) create(ctx context.Context, job *model.Job) error {
+	created, err := q.store.Create(ctx, job)
+	if err != nil {
+		return err
+	}
+	if !created {
+		return ErrDuplicate
+	}
+	return nil
+}
+
+// permanentError 不再重试的错误
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent 标记错误不可重试，任务直接转为死信
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+func isPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+var queue *Queue
+
+// InitQueue 根据配置创建全局任务入口
+func InitQueue() {
+	queue = NewQueue(repository.NewJobRepository(), config.AppConfig.Jobs.MaxAttempts)
+}
+
+// GetQueue 获取全局任务入口，未初始化时使用默认配置创建
+func GetQueue() *Queue {
+	if queue == nil {
+		InitQueue()
+	}
+	return queue
+}
@@ -0,0 +1,394 @@
+package jobs
+
+import (
+	"blog/config"
+	"blog/internal/mailer"
+	"blog/internal/model"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPollInterval = time.Second
+	defaultBaseBackoff  = 10 * time.Second
+	defaultMaxBackoff   = time.Hour
+	defaultTimeout      = 5 * time.Minute
+	defaultRetention    = 7 * 24 * time.Hour
+	defaultConcurrency  = 2
+	pruneBatchSize      = 1000
+	maxErrorLength      = 1000
+)
+
+// Options worker 配置
+type Options struct {
+	PollInterval time.Duration
+	BaseBackoff  time.Duration // 第一次重试的等待时间，之后每次翻倍
+	MaxBackoff   time.Duration
+	Timeout      time.Duration  // 单个任务的执行超时
+	Retention    time.Duration  // 成功任务的保留时间
+	Concurrency  int            // 未在 Queues 中列出的队列同时执行的任务数
+	Queues       map[string]int // 各队列同时执行的任务数
+}
+
+type handler struct {
+	queue     string
+	sensitive bool
+	run       func(ctx context.Context, payload []byte) error
+}
+
+type schedule struct {
+	interval time.Duration
+	enqueue  func(ctx context.Context, slot time.Time) error
+	last     time.Time
+}
+
+// Worker 按队列取出任务交给注册的处理函数执行，每个队列的并发数单独限制
+type Worker struct {
+	store     Store
+	queue     *Queue
+	opts      Options
+	handlers  map[string]handler
+	schedules []*schedule
+	now       func() time.Time
+
+	// 执行中任务的上下文，Close 等待超时后取消
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewWorker 创建 worker，处理函数须在 Run 之前注册
+func NewWorker(store Store, queue *Queue, opts Options) *Worker {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+	if opts.BaseBackoff <= 0 {
+		opts.BaseBackoff = defaultBaseBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = defaultMaxBackoff
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = defaultRetention
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultConcurrency
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Worker{
+		store:    store,
+		queue:    queue,
+		opts:     opts,
+		handlers: make(map[string]handler),
+		now:      time.Now,
+		ctx:      ctx,
+		cancel:   cancel,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Handle 注册任务的处理函数，负载按 T 解码；返回 Permanent 包装的错误时不再重试
+func Handle[T any](w *Worker, d Definition[T], fn func(ctx context.Context, payload T) error) {
+	if _, ok := w.handlers[d.Type]; ok {
+		panic("jobs: handler already registered for " + d.Type)
+	}
+	w.handlers[d.Type] = handler{
+		queue:     d.queue(),
+		sensitive: d.Sensitive,
+		run: func(ctx context.Context, data []byte) error {
+			var payload T
+			if err := json.Unmarshal(data, &payload); err != nil {
+				return Permanent(fmt.Errorf("decode payload: %w", err))
+			}
+			return fn(ctx, payload)
+		},
+	}
+}
+
+// Every 每隔 interval 放入一次任务，时间段按 interval 对齐；
+// 多个实例以时间段作为唯一键去重，同一时间段只执行一次
+func Every[T any](w *Worker, d Definition[T], interval time.Duration, payload T) {
+	w.schedules = append(w.schedules, &schedule{
+		interval: interval,
+		enqueue: func(ctx context.Context, slot time.Time) error {
+			key := d.Type + "@" + strconv.FormatInt(slot.Unix(), 10)
+			_, err := d.Enqueue(ctx, w.queue, payload, RunAt(slot), UniqueKey(key))
+			return err
+		},
+	})
+}
+
+// Run 在后台执行任务和定时任务，直到调用 Close
+func (w *Worker) Run() {
+	defer close(w.done)
+
+	var wg sync.WaitGroup
+	for name, types := range w.queueTypes() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.runQueue(name, types)
+		}()
+	}
+	if len(w.schedules) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.runSchedules()
+		}()
+	}
+	wg.Wait()
+}
+
+// Close 停止取出新任务，等待执行中的任务完成；ctx 结束时取消执行中的任务，这些任务稍后重试
+func (w *Worker) Close(ctx context.Context) error {
+	w.stopOnce.Do(func() { close(w.stop) })
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		w.cancel()
+		return ctx.Err()
+	}
+}
+
+// queueTypes 各队列中已注册处理函数的任务类型
+func (w *Worker) queueTypes() map[string][]string {
+	queues := make(map[string][]string)
+	for jobType, h := range w.handlers {
+		queues[h.queue] = append(queues[h.queue], jobType)
+	}
+	for _, types := range queues {
+		sort.Strings(types)
+	}
+	return queues
+}
+
+func (w *Worker) concurrency(queue string) int {
+	if n := w.opts.Queues[queue]; n > 0 {
+		return n
+	}
+	return w.opts.Concurrency
+}
+
+// runQueue 轮询一个队列，空闲的执行槽位有多少就取多少个任务
+func (w *Worker) runQueue(name string, types []string) {
+	slots := make(chan struct{}, w.concurrency(name))
+	var running sync.WaitGroup
+	defer running.Wait()
+
+	ticker := time.NewTicker(w.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.stop:
+			return
+		}
+
+		for {
+			free := cap(slots) - len(slots)
+			if free == 0 {
+				break
+			}
+			jobs, err := w.store.Claim(w.ctx, name, types, w.now(), w.lease(), free)
+			if err != nil {
+				log.Printf("[ERROR] failed to claim jobs from queue %s: %v", name, err)
+				break
+			}
+			for i := range jobs {
+				slots <- struct{}{}
+				running.Add(1)
+				go func(job *model.Job) {
+					defer func() {
+						<-slots
+						running.Done()
+					}()
+					w.process(job)
+				}(&jobs[i])
+			}
+			if len(jobs) < free {
+				break
+			}
+		}
+	}
+}
+
+// lease 执行中任务的锁定时间，超过后视为 worker 已崩溃
+func (w *Worker) lease() time.Duration {
+	return w.opts.Timeout + time.Minute
+}
+
+// RunDue 同步执行一个队列中所有到期的任务，返回执行的任务数
+func (w *Worker) RunDue(ctx context.Context, queue string) (int, error) {
+	types := w.queueTypes()[queue]
+	if len(types) == 0 {
+		return 0, nil
+	}
+
+	total := 0
+	limit := w.concurrency(queue)
+	for {
+		jobs, err := w.store.Claim(ctx, queue, types, w.now(), w.lease(), limit)
+		if err != nil {
+			return total, err
+		}
+		for i := range jobs {
+			w.process(&jobs[i])
+		}
+		total += len(jobs)
+		if len(jobs) < limit {
+			return total, nil
+		}
+	}
+}
+
+// process 执行一个任务并保存结果：成功、退避后重试或转为死信
+func (w *Worker) process(job *model.Job) {
+	h := w.handlers[job.Type]
+	err := w.execute(h, job)
+
+	now := w.now()
+	job.LockedUntil = nil
+	switch {
+	case err == nil:
+		job.Status = model.JobSucceeded
+		job.FinishedAt = &now
+		job.LastError = ""
+		if h.sensitive {
+			job.Payload = ""
+		}
+	case isPermanent(err) || job.Attempts >= job.MaxAttempts:
+		job.Status = model.JobDead
+		job.FinishedAt = &now
+		job.LastError = truncate(err.Error())
+		log.Printf("[ERROR] job %d %s failed after %d attempts: %v", job.ID, job.Type, job.Attempts, err)
+	default:
+		job.Status = model.JobPending
+		job.RunAt = now.Add(w.Backoff(job.Attempts))
+		job.LastError = truncate(err.Error())
+		log.Printf("[WARN] job %d %s failed (attempt %d): %v", job.ID, job.Type, job.Attempts, err)
+	}
+
+	// 执行中的任务被取消时仍要保存结果
+	if err := w.store.Finish(context.WithoutCancel(w.ctx), job); err != nil {
+		log.Printf("[ERROR] failed to save job %d: %v", job.ID, err)
+	}
+}
+
+// execute 在超时限制内执行处理函数，panic 视为失败
+func (w *Worker) execute(h handler, job *model.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	ctx, cancel := context.WithTimeout(w.ctx, w.opts.Timeout)
+	defer cancel()
+	return h.run(ctx, []byte(job.Payload))
+}
+
+// runSchedules 到达新的时间段时放入定时任务
+func (w *Worker) runSchedules() {
+	ticker := time.NewTicker(w.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		w.enqueueScheduled(w.ctx)
+		select {
+		case <-ticker.C:
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+func (w *Worker) enqueueScheduled(ctx context.Context) {
+	now := w.now()
+	for _, s := range w.schedules {
+		slot := now.Truncate(s.interval)
+		if slot.Equal(s.last) {
+			continue
+		}
+		// 其他实例已放入时同样视为完成
+		if err := s.enqueue(ctx, slot); err != nil && !errors.Is(err, ErrDuplicate) {
+			log.Printf("[ERROR] failed to enqueue scheduled job: %v", err)
+			continue
+		}
+		s.last = slot
+	}
+}
+
+// Backoff 第 attempt 次失败后的等待时间
+func (w *Worker) Backoff(attempt int) time.Duration {
+	backoff := w.opts.BaseBackoff
+	for i := 1; i < attempt && backoff < w.opts.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, w.opts.MaxBackoff)
+}
+
+// truncate 按字节截断并去掉被截断的不完整字符
+func truncate(s string) string {
+	if len(s) > maxErrorLength {
+		s = s[:maxErrorLength]
+	}
+	return strings.ToValidUTF8(s, "")
+}
+
+// PruneJobs 清理超过保留时间的成功任务，每小时执行一次
+var PruneJobs = Definition[struct{}]{Type: "jobs.prune"}
+
+// prune 删除超过保留时间的成功任务
+func (w *Worker) prune(ctx context.Context, _ struct{}) error {
+	before := w.now().Add(-w.opts.Retention)
+	for {
+		n, err := w.store.DeleteSucceededBefore(ctx, before, pruneBatchSize)
+		if err != nil || n < pruneBatchSize {
+			return err
+		}
+	}
+}
+
+var worker *Worker
+
+// InitWorker 根据配置创建全局 worker 并注册内置任务
+func InitWorker() {
+	cfg := config.AppConfig.Jobs
+	worker = NewWorker(GetQueue().store, GetQueue(), Options{
+		PollInterval: cfg.PollInterval,
+		BaseBackoff:  cfg.BaseBackoff,
+		MaxBackoff:   cfg.MaxBackoff,
+		Timeout:      cfg.Timeout,
+		Retention:    cfg.Retention,
+		Concurrency:  cfg.Concurrency,
+		Queues:       cfg.Queues,
+	})
+	Handle(worker, PruneJobs, worker.prune)
+	Every(worker, PruneJobs, time.Hour, struct{}{})
+	Handle(worker, SendMail, mailer.GetMailer().Send)
+}
+
+// GetWorker 获取全局 worker，未初始化时使用默认配置创建
+func GetWorker() *Worker {
+	if worker == nil {
+		InitWorker()
+	}
+	return worker
+}
@@ -0,0 +1,350 @@
+package jobs
+
+import (
+	"blog/internal/mailer"
+	"blog/internal/model"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore 内存中的 jobs 表
+type memoryStore struct {
+	mu   sync.Mutex
+	jobs []model.Job
+}
+
+func (s *memoryStore) Create(ctx context.Context, job *model.Job) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if job.UniqueKey != nil {
+		for _, j := range s.jobs {
+			if j.UniqueKey != nil && *j.UniqueKey == *job.UniqueKey {
+				return false, nil
+			}
+		}
+	}
+	job.ID = uint(len(s.jobs) + 1)
+	s.jobs = append(s.jobs, *job)
+	return true, nil
+}
+
+func (s *memoryStore) Claim(ctx context.Context, queue string, types []string, now time.Time, lease time.Duration, limit int) ([]model.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var claimed []model.Job
+	for i := range s.jobs {
+		j := &s.jobs[i]
+		if len(claimed) == limit || j.Queue != queue || !slices.Contains(types, j.Type) {
+			continue
+		}
+		due := j.Status == model.JobPending && !j.RunAt.After(now)
+		expired := j.Status == model.JobRunning && !j.LockedUntil.After(now)
+		if !due && !expired {
+			continue
+		}
+		lockedUntil := now.Add(lease)
+		j.Status = model.JobRunning
+		j.Attempts++
+		j.LockedUntil = &lockedUntil
+		claimed = append(claimed, *j)
+	}
+	return claimed, nil
+}
+
+func (s *memoryStore) Finish(ctx context.Context, job *model.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID-1] = *job
+	return nil
+}
+
+func (s *memoryStore) DeleteSucceededBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var deleted int64
+	for i := range s.jobs {
+		j := &s.jobs[i]
+		if j.Status == model.JobSucceeded && j.FinishedAt.Before(before) && deleted < int64(limit) {
+			// 保留位置以便按 ID 下标访问
+			j.Status = "deleted"
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (s *memoryStore) get(id uint) model.Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jobs[id-1]
+}
+
+var testJob = Definition[string]{Type: "test.echo", MaxAttempts: 3}
+
+func newTestWorker(store *memoryStore, now *time.Time) *Worker {
+	q := NewQueue(store, 5)
+	q.now = func() time.Time { return *now }
+	w := NewWorker(store, q, Options{BaseBackoff: time.Minute, MaxBackoff: 10 * time.Minute, Timeout: time.Second})
+	w.now = func() time.Time { return *now }
+	return w
+}
+
+func TestWorker_RunsJobWithDecodedPayload(t *testing.T) {
+	store := &memoryStore{}
+	now := time.Now()
+	w := newTestWorker(store, &now)
+	var got string
+	Handle(w, testJob, func(ctx context.Context, payload string) error {
+		got = payload
+		return nil
+	})
+
+	job, err := testJob.Enqueue(context.Background(), w.queue, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultQueue, job.Queue)
+	assert.Equal(t, 3, job.MaxAttempts)
+
+	n, err := w.RunDue(context.Background(), DefaultQueue)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "hello", got)
+
+	saved := store.get(job.ID)
+	assert.Equal(t, model.JobSucceeded, saved.Status)
+	assert.Equal(t, 1, saved.Attempts)
+	assert.Nil(t, saved.LockedUntil)
+	assert.NotNil(t, saved.FinishedAt)
+}
+
+func TestWorker_RetriesWithBackoffThenDeadLetters(t *testing.T) {
+	store := &memoryStore{}
+	now := time.Now()
+	w := newTestWorker(store, &now)
+	Handle(w, testJob, func(ctx context.Context, payload string) error {
+		return errors.New("smtp unavailable")
+	})
+
+	job, err := testJob.Enqueue(context.Background(), w.queue, "hello")
+	require.NoError(t, err)
+
+	_, err = w.RunDue(context.Background(), DefaultQueue)
+	require.NoError(t, err)
+	saved := store.get(job.ID)
+	assert.Equal(t, model.JobPending, saved.Status)
+	assert.Equal(t, now.Add(time.Minute), saved.RunAt)
+	assert.Equal(t, "smtp unavailable", saved.LastError)
+
+	// 退避时间未到时不执行
+	n, err := w.RunDue(context.Background(), DefaultQueue)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	now = now.Add(time.Minute)
+	_, err = w.RunDue(context.Background(), DefaultQueue)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(2*time.Minute), store.get(job.ID).RunAt)
+
+	now = now.Add(2 * time.Minute)
+	_, err = w.RunDue(context.Background(), DefaultQueue)
+	require.NoError(t, err)
+	saved = store.get(job.ID)
+	assert.Equal(t, model.JobDead, saved.Status)
+	assert.Equal(t, 3, saved.Attempts)
+	assert.NotNil(t, saved.FinishedAt)
+}
+
+func TestWorker_PermanentErrorAndPanicHandling(t *testing.T) {
+	store := &memoryStore{}
+	now := time.Now()
+	w := newTestWorker(store, &now)
+	Handle(w, testJob, func(ctx context.Context, payload string) error {
+		if payload == "panic" {
+			panic("boom")
+		}
+		return Permanent(errors.New("invalid recipient"))
+	})
+
+	permanent, err := testJob.Enqueue(context.Background(), w.queue, "bad")
+	require.NoError(t, err)
+	panicking, err := testJob.Enqueue(context.Background(), w.queue, "panic")
+	require.NoError(t, err)
+
+	_, err = w.RunDue(context.Background(), DefaultQueue)
+	require.NoError(t, err)
+
+	assert.Equal(t, model.JobDead, store.get(permanent.ID).Status)
+	assert.Equal(t, 1, store.get(permanent.ID).Attempts)
+	assert.Equal(t, model.JobPending, store.get(panicking.ID).Status)
+	assert.Equal(t, "panic: boom", store.get(panicking.ID).LastError)
+}
+
+func TestWorker_UndecodablePayloadIsDeadLettered(t *testing.T) {
+	store := &memoryStore{}
+	now := time.Now()
+	w := newTestWorker(store, &now)
+	Handle(w, testJob, func(ctx context.Context, payload string) error { return nil })
+
+	_, err := store.Create(context.Background(), &model.Job{
+		Queue: DefaultQueue, Type: testJob.Type, Payload: "{", Status: model.JobPending, MaxAttempts: 3, RunAt: now,
+	})
+	require.NoError(t, err)
+
+	_, err = w.RunDue(context.Background(), DefaultQueue)
+	require.NoError(t, err)
+	assert.Equal(t, model.JobDead, store.get(1).Status)
+}
+
+func TestWorker_ClearsSensitivePayloadOnSuccess(t *testing.T) {
+	store := &memoryStore{}
+	now := time.Now()
+	w := newTestWorker(store, &now)
+	sent := mailer.NewMemoryMailer()
+	Handle(w, SendMail, sent.Send)
+
+	m := NewMailer(w.queue)
+	require.NoError(t, m.Send(context.Background(), mailer.Message{To: "a@example.com", Subject: "Reset", Body: "token=secret"}))
+
+	n, err := w.RunDue(context.Background(), "mail")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	require.Len(t, sent.Messages(), 1)
+	assert.Equal(t, "token=secret", sent.Messages()[0].Body)
+	assert.Empty(t, store.get(1).Payload)
+}
+
+func TestWorker_DelayedAndUniqueJobs(t *testing.T) {
+	store := &memoryStore{}
+	now := time.Now()
+	w := newTestWorker(store, &now)
+	Handle(w, testJob, func(ctx context.Context, payload string) error { return nil })
+
+	_, err := testJob.Enqueue(context.Background(), w.queue, "later", Delay(time.Hour), UniqueKey("k"))
+	require.NoError(t, err)
+	_, err = testJob.Enqueue(context.Background(), w.queue, "again", UniqueKey("k"))
+	assert.ErrorIs(t, err, ErrDuplicate)
+
+	n, err := w.RunDue(context.Background(), DefaultQueue)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	now = now.Add(time.Hour)
+	n, err = w.RunDue(context.Background(), DefaultQueue)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestWorker_ScheduledJobsEnqueuedOncePerSlot(t *testing.T) {
+	store := &memoryStore{}
+	now := time.Date(2026, 1, 1, 10, 15, 0, 0, time.UTC)
+	w := newTestWorker(store, &now)
+	other := newTestWorker(store, &now)
+	Every(w, testJob, time.Hour, "tick")
+	Every(other, testJob, time.Hour, "tick")
+
+	// 两个实例在同一时间段只放入一个任务
+	w.enqueueScheduled(context.Background())
+	other.enqueueScheduled(context.Background())
+	w.enqueueScheduled(context.Background())
+	require.Len(t, store.jobs, 1)
+	assert.Equal(t, time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC), store.jobs[0].RunAt)
+
+	now = now.Add(time.Hour)
+	w.enqueueScheduled(context.Background())
+	assert.Len(t, store.jobs, 2)
+}
+
+func TestWorker_PruneDeletesOldSucceededJobs(t *testing.T) {
+	store := &memoryStore{}
+	now := time.Now()
+	w := newTestWorker(store, &now)
+	old := now.Add(-8 * 24 * time.Hour)
+	recent := now.Add(-time.Hour)
+	store.jobs = []model.Job{
+		{ID: 1, Status: model.JobSucceeded, FinishedAt: &old},
+		{ID: 2, Status: model.JobSucceeded, FinishedAt: &recent},
+		{ID: 3, Status: model.JobDead, FinishedAt: &old},
+	}
+
+	require.NoError(t, w.prune(context.Background(), struct{}{}))
+	assert.Equal(t, "deleted", store.get(1).Status)
+	assert.Equal(t, model.JobSucceeded, store.get(2).Status)
+	assert.Equal(t, model.JobDead, store.get(3).Status)
+}
+
+func TestWorker_Backoff(t *testing.T) {
+	w := NewWorker(&memoryStore{}, nil, Options{BaseBackoff: time.Second, MaxBackoff: 5 * time.Second})
+	assert.Equal(t, time.Second, w.Backoff(1))
+	assert.Equal(t, 2*time.Second, w.Backoff(2))
+	assert.Equal(t, 4*time.Second, w.Backoff(3))
+	assert.Equal(t, 5*time.Second, w.Backoff(4))
+	assert.Equal(t, 5*time.Second, w.Backoff(30))
+}
+
+func TestWorker_RunRespectsQueueConcurrency(t *testing.T) {
+	store := &memoryStore{}
+	q := NewQueue(store, 1)
+	w := NewWorker(store, q, Options{PollInterval: 5 * time.Millisecond, Queues: map[string]int{DefaultQueue: 2}})
+
+	var running, peak, done atomic.Int32
+	release := make(chan struct{})
+	Handle(w, testJob, func(ctx context.Context, payload string) error {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		<-release
+		running.Add(-1)
+		done.Add(1)
+		return nil
+	})
+	for range 5 {
+		_, err := testJob.Enqueue(context.Background(), q, "x")
+		require.NoError(t, err)
+	}
+
+	go w.Run()
+	require.Eventually(t, func() bool { return running.Load() == 2 }, time.Second, 5*time.Millisecond)
+	close(release)
+	require.Eventually(t, func() bool { return done.Load() == 5 }, time.Second, 5*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, w.Close(ctx))
+	assert.Equal(t, int32(2), peak.Load())
+}
+
+func TestWorker_CloseCancelsRunningJobsAfterTimeout(t *testing.T) {
+	store := &memoryStore{}
+	q := NewQueue(store, 3)
+	w := NewWorker(store, q, Options{PollInterval: 5 * time.Millisecond})
+
+	started := make(chan struct{})
+	Handle(w, testJob, func(ctx context.Context, payload string) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	job, err := testJob.Enqueue(context.Background(), q, "slow")
+	require.NoError(t, err)
+
+	go w.Run()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, w.Close(ctx), context.DeadlineExceeded)
+
+	// 被取消的任务保存为待重试
+	require.Eventually(t, func() bool { return store.get(job.ID).Status == model.JobPending }, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,76 @@
+// Package jwtkeys 管理访问令牌的签名与验证密钥，支持 HS256、RS256 和 EdDSA 以及多密钥轮换
+package jwtkeys
+
+import (
+	"blog/config"
+	"log"
+)
+
+// hmacKeyID 未配置非对称密钥时 HS256 共享密钥使用的 kid
+const hmacKeyID = "hs256"
+
+// 未配置时的默认签发者和受众
+const (
+	defaultIssuer   = "blog"
+	defaultAudience = "blog-api"
+)
+
+var keySet *KeySet
+
+// Init 根据配置加载密钥集，配置错误时直接退出
+func Init() {
+	ks, err := FromConfig()
+	if err != nil {
+		log.Fatalf("Failed to load JWT keys: %v", err)
+	}
+	keySet = ks
+}
+
+// FromConfig 根据配置创建密钥集，未配置 keys 时使用 jwt.secret 作为 HS256 密钥
+func FromConfig() (*KeySet, error) {
+	cfg := config.AppConfig.JWT
+	opts := Options{Issuer: cfg.Issuer, Audience: cfg.Audience, TTL: cfg.TTL}
+	if opts.Issuer == "" {
+		opts.Issuer = defaultIssuer
+	}
+	if opts.Audience == "" {
+		opts.Audience = defaultAudience
+	}
+
+	if len(cfg.Keys) == 0 {
+		key, err := NewHMACKey(hmacKeyID, []byte(cfg.Secret))
+		if err != nil {
+			return nil, err
+		}
+		return NewKeySet(hmacKeyID, opts, key)
+	}
+
+	keys := make([]*Key, 0, len(cfg.Keys))
+	for _, kc := range cfg.Keys {
+		key, err := LoadKey(kc.ID, kc.Algorithm, kc.PrivateKeyFile, kc.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	signingID := cfg.SigningKeyID
+	if signingID == "" {
+		signingID = cfg.Keys[0].ID
+	}
+	return NewKeySet(signingID, opts, keys...)
+}
+
+// GetKeySet 获取密钥集，未初始化时根据当前配置创建
+func GetKeySet() *KeySet {
+	if keySet == nil {
+		Init()
+	}
+	return keySet
+}
+
+// SetKeySet 替换当前密钥集，返回原来的密钥集，供测试使用
+func SetKeySet(ks *KeySet) *KeySet {
+	previous := keySet
+	keySet = ks
+	return previous
+}
@@ -0,0 +1,220 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testOptions = Options{Issuer: "blog", Audience: "blog-api", TTL: time.Hour}
+
+func newEd25519Key(t *testing.T, id string) *Key {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key, err := NewPrivateKey(id, AlgEdDSA, private)
+	require.NoError(t, err)
+	return key
+}
+
+func newRSAKey(t *testing.T, id string) (*Key, *rsa.PrivateKey) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key, err := NewPrivateKey(id, AlgRS256, private)
+	require.NoError(t, err)
+	return key, private
+}
+
+func TestKeySet_SignAndParse(t *testing.T) {
+	rsaKey, _ := newRSAKey(t, "rsa")
+	for _, key := range []*Key{newEd25519Key(t, "ed"), rsaKey} {
+		t.Run(key.Algorithm, func(t *testing.T) {
+			ks, err := NewKeySet(key.ID, testOptions, key)
+			require.NoError(t, err)
+
+			tokenString, err := ks.Sign(jwt.MapClaims{"user_id": 1})
+			require.NoError(t, err)
+
+			token, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
+			require.NoError(t, err)
+			assert.Equal(t, key.ID, token.Header["kid"])
+			assert.Equal(t, key.Algorithm, token.Header["alg"])
+
+			claims, err := ks.Parse(tokenString)
+			require.NoError(t, err)
+			assert.Equal(t, float64(1), claims["user_id"])
+			assert.Equal(t, "blog", claims["iss"])
+			assert.Equal(t, "blog-api", claims["aud"])
+		})
+	}
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	oldKey := newEd25519Key(t, "2026-04")
+	newKey := newEd25519Key(t, "2026-10")
+
+	oldSet, err := NewKeySet("2026-04", testOptions, oldKey)
+	require.NoError(t, err)
+	issuedBefore, err := oldSet.Sign(jwt.MapClaims{"user_id": 1})
+	require.NoError(t, err)
+
+	// 轮换后旧密钥只保留公钥，仍能验证之前签发的令牌
+	retired, err := NewPublicKey(oldKey.ID, oldKey.Algorithm, oldKey.public)
+	require.NoError(t, err)
+	rotated, err := NewKeySet("2026-10", testOptions, newKey, retired)
+	require.NoError(t, err)
+
+	_, err = rotated.Parse(issuedBefore)
+	assert.NoError(t, err)
+
+	issuedAfter, err := rotated.Sign(jwt.MapClaims{"user_id": 1})
+	require.NoError(t, err)
+	_, err = rotated.Parse(issuedAfter)
+	assert.NoError(t, err)
+
+	// 旧密钥删除后，旧令牌失效
+	_, err = oldSet.Parse(issuedAfter)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	// 只有公钥的密钥不能用于签名
+	_, err = NewKeySet("2026-04", testOptions, retired)
+	assert.Error(t, err)
+}
+
+func TestKeySet_RejectsInvalidTokens(t *testing.T) {
+	rsaKey, rsaPrivate := newRSAKey(t, "rsa")
+	ks, err := NewKeySet("rsa", testOptions, rsaKey)
+	require.NoError(t, err)
+
+	sign := func(method jwt.SigningMethod, kid string, claims jwt.MapClaims, key interface{}) string {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{"iss": "blog", "aud": "blog-api", "exp": time.Now().Add(time.Hour).Unix(), "user_id": 1}
+	}
+
+	// 作为对照，手工签发的合法令牌可以通过验证
+	_, err = ks.Parse(sign(jwt.SigningMethodRS256, "rsa", valid(), rsaPrivate))
+	require.NoError(t, err)
+
+	// 把公开的 RSA 公钥当作 HMAC 密钥伪造令牌
+	publicDER, err := x509.MarshalPKIXPublicKey(&rsaPrivate.PublicKey)
+	require.NoError(t, err)
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	wrongIssuer := valid()
+	wrongIssuer["iss"] = "evil"
+	wrongAudience := valid()
+	wrongAudience["aud"] = "other-service"
+	expired := valid()
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	noExpiry := valid()
+	delete(noExpiry, "exp")
+
+	cases := map[string]string{
+		"算法混淆":   sign(jwt.SigningMethodHS256, "rsa", valid(), publicPEM),
+		"none算法": sign(jwt.SigningMethodNone, "rsa", valid(), jwt.UnsafeAllowNoneSignatureType),
+		"未知kid":  sign(jwt.SigningMethodRS256, "other", valid(), rsaPrivate),
+		"签发者错误":  sign(jwt.SigningMethodRS256, "rsa", wrongIssuer, rsaPrivate),
+		"受众错误":   sign(jwt.SigningMethodRS256, "rsa", wrongAudience, rsaPrivate),
+		"已过期":    sign(jwt.SigningMethodRS256, "rsa", expired, rsaPrivate),
+		"缺少过期时间": sign(jwt.SigningMethodRS256, "rsa", noExpiry, rsaPrivate),
+	}
+	for name, tokenString := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ks.Parse(tokenString)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestKeySet_AlgorithmBoundToKey(t *testing.T) {
+	hmacKey, err := NewHMACKey("hs", []byte("secret"))
+	require.NoError(t, err)
+	edKey := newEd25519Key(t, "ed")
+	ks, err := NewKeySet("ed", testOptions, edKey, hmacKey)
+	require.NoError(t, err)
+
+	// HS256 在允许的算法中，但 kid 指向的是 EdDSA 密钥
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iss": "blog", "aud": "blog-api", "exp": time.Now().Add(time.Hour).Unix(),
+	})
+	token.Header["kid"] = "ed"
+	signed, err := token.SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	_, err = ks.Parse(signed)
+	assert.Error(t, err)
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	hmacKey, err := NewHMACKey("hs", []byte("secret"))
+	require.NoError(t, err)
+	rsaKey, _ := newRSAKey(t, "rsa")
+	ks, err := NewKeySet("rsa", testOptions, newEd25519Key(t, "ed"), rsaKey, hmacKey)
+	require.NoError(t, err)
+
+	set := ks.JWKS()
+	// 共享密钥不能公开
+	require.Len(t, set.Keys, 2)
+	assert.Equal(t, "ed", set.Keys[0].KeyID)
+	assert.Equal(t, "OKP", set.Keys[0].KeyType)
+	assert.Equal(t, "Ed25519", set.Keys[0].Curve)
+	assert.NotEmpty(t, set.Keys[0].X)
+	assert.Equal(t, "rsa", set.Keys[1].KeyID)
+	assert.Equal(t, "RSA", set.Keys[1].KeyType)
+	assert.Equal(t, "AQAB", set.Keys[1].E)
+}
+
+func TestLoadKey(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, blockType string, der []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+		return path
+	}
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+	pkix, err := x509.MarshalPKIXPublicKey(public)
+	require.NoError(t, err)
+	rsaPrivate, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	key, err := LoadKey("ed", AlgEdDSA, write("ed.pem", "PRIVATE KEY", pkcs8), "")
+	require.NoError(t, err)
+	assert.True(t, key.CanSign())
+
+	key, err = LoadKey("ed-pub", AlgEdDSA, "", write("ed.pub.pem", "PUBLIC KEY", pkix))
+	require.NoError(t, err)
+	assert.False(t, key.CanSign())
+
+	key, err = LoadKey("rsa", AlgRS256, write("rsa.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaPrivate)), "")
+	require.NoError(t, err)
+	assert.True(t, key.CanSign())
+
+	// 算法与密钥类型不匹配
+	_, err = LoadKey("ed", AlgRS256, filepath.Join(dir, "ed.pem"), "")
+	assert.Error(t, err)
+
+	// 过短的 RSA 密钥
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	_, err = NewPrivateKey("weak", AlgRS256, weak)
+	assert.Error(t, err)
+}
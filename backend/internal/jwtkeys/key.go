@@ -0,0 +1,138 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 支持的签名算法
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// minRSABits RSA 密钥的最小长度
+const minRSABits = 2048
+
+// Key 一个签名或验证密钥，算法与密钥绑定，验证时不接受令牌声明的其他算法
+type Key struct {
+	ID        string
+	Algorithm string
+	method    jwt.SigningMethod
+	private   interface{} // 只用于验证的密钥为 nil
+	public    interface{}
+}
+
+// CanSign 是否持有私钥
+func (k *Key) CanSign() bool {
+	return k.private != nil
+}
+
+// NewHMACKey 创建 HS256 共享密钥，只能用于本服务自签自验，不会出现在 JWKS 中
+func NewHMACKey(id string, secret []byte) (*Key, error) {
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("jwt key %s: empty secret", id)
+	}
+	return &Key{ID: id, Algorithm: AlgHS256, method: jwt.SigningMethodHS256, private: secret, public: secret}, nil
+}
+
+// NewPrivateKey 由私钥创建签名密钥，公钥从私钥推导
+func NewPrivateKey(id, alg string, private crypto.Signer) (*Key, error) {
+	key, err := NewPublicKey(id, alg, private.Public())
+	if err != nil {
+		return nil, err
+	}
+	key.private = private
+	return key, nil
+}
+
+// NewPublicKey 创建只用于验证的密钥
+func NewPublicKey(id, alg string, public crypto.PublicKey) (*Key, error) {
+	if id == "" {
+		return nil, fmt.Errorf("jwt key: missing id")
+	}
+
+	switch alg {
+	case AlgRS256:
+		pub, ok := public.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("jwt key %s: %s requires an RSA key", id, alg)
+		}
+		if pub.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("jwt key %s: RSA key must be at least %d bits", id, minRSABits)
+		}
+		return &Key{ID: id, Algorithm: alg, method: jwt.SigningMethodRS256, public: pub}, nil
+	case AlgEdDSA:
+		pub, ok := public.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("jwt key %s: %s requires an Ed25519 key", id, alg)
+		}
+		return &Key{ID: id, Algorithm: alg, method: jwt.SigningMethodEdDSA, public: pub}, nil
+	default:
+		return nil, fmt.Errorf("jwt key %s: unsupported algorithm %q", id, alg)
+	}
+}
+
+// LoadKey 从 PEM 文件加载密钥，配置了私钥时优先使用私钥
+func LoadKey(id, alg, privateKeyFile, publicKeyFile string) (*Key, error) {
+	switch {
+	case privateKeyFile != "":
+		block, err := readPEM(privateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", id, err)
+		}
+		private, err := parsePrivateKey(block)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", id, err)
+		}
+		return NewPrivateKey(id, alg, private)
+	case publicKeyFile != "":
+		block, err := readPEM(publicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", id, err)
+		}
+		public, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", id, err)
+		}
+		return NewPublicKey(id, alg, public)
+	default:
+		return nil, fmt.Errorf("jwt key %s: private_key_file or public_key_file is required", id)
+	}
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", path)
+	}
+	return block, nil
+}
+
+// parsePrivateKey 支持 PKCS#8 和 PKCS#1（BEGIN RSA PRIVATE KEY）格式
+func parsePrivateKey(block *pem.Block) (crypto.Signer, error) {
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signer, nil
+}
@@ -0,0 +1,181 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 默认的令牌有效期和时钟偏差容忍度
+const (
+	defaultTTL = 24 * time.Hour
+	leeway     = 30 * time.Second
+)
+
+// ErrUnknownKey 令牌的 kid 不在当前密钥集中，可能是已下线的密钥或伪造的令牌
+var ErrUnknownKey = errors.New("jwtkeys: unknown key id")
+
+// KeySet 签名密钥和全部验证密钥
+type KeySet struct {
+	signing  *Key
+	keys     map[string]*Key
+	issuer   string
+	audience string
+	ttl      time.Duration
+}
+
+// Options 令牌的签发者、受众和有效期
+type Options struct {
+	Issuer   string
+	Audience string
+	TTL      time.Duration
+}
+
+// NewKeySet 创建密钥集，signingID 指定的密钥必须持有私钥
+func NewKeySet(signingID string, opts Options, keys ...*Key) (*KeySet, error) {
+	if opts.Issuer == "" || opts.Audience == "" {
+		return nil, errors.New("jwtkeys: issuer and audience are required")
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = defaultTTL
+	}
+
+	ks := &KeySet{
+		keys:     make(map[string]*Key, len(keys)),
+		issuer:   opts.Issuer,
+		audience: opts.Audience,
+		ttl:      opts.TTL,
+	}
+	for _, key := range keys {
+		if _, dup := ks.keys[key.ID]; dup {
+			return nil, fmt.Errorf("jwtkeys: duplicate key id %q", key.ID)
+		}
+		ks.keys[key.ID] = key
+	}
+
+	signing, ok := ks.keys[signingID]
+	if !ok {
+		return nil, fmt.Errorf("jwtkeys: signing key %q not found", signingID)
+	}
+	if !signing.CanSign() {
+		return nil, fmt.Errorf("jwtkeys: signing key %q has no private key", signingID)
+	}
+	ks.signing = signing
+	return ks, nil
+}
+
+// TTL 令牌有效期
+func (ks *KeySet) TTL() time.Duration {
+	return ks.ttl
+}
+
+// Sign 使用当前签名密钥签发令牌，自动补充 iss、aud、iat、exp 和 kid
+func (ks *KeySet) Sign(claims jwt.MapClaims) (string, error) {
+	now := time.Now()
+	signed := jwt.MapClaims{
+		"iss": ks.issuer,
+		"aud": ks.audience,
+		"iat": now.Unix(),
+		"exp": now.Add(ks.ttl).Unix(),
+	}
+	for k, v := range claims {
+		signed[k] = v
+	}
+
+	token := jwt.NewWithClaims(ks.signing.method, signed)
+	token.Header["kid"] = ks.signing.ID
+	return token.SignedString(ks.signing.private)
+}
+
+// Parse 验证令牌并返回声明
+// 只接受密钥集中配置的算法，且令牌声明的算法必须与 kid 对应密钥的算法一致，
+// 避免把 RSA 公钥当作 HMAC 密钥之类的算法混淆攻击
+func (ks *KeySet) Parse(tokenString string) (jwt.MapClaims, error) {
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(ks.algorithms()),
+		jwt.WithIssuer(ks.issuer),
+		jwt.WithAudience(ks.audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(leeway),
+	)
+
+	claims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := ks.keys[kid]
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		if token.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("jwtkeys: key %q does not accept %s", kid, token.Method.Alg())
+		}
+		return key.public, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// algorithms 密钥集中出现的全部算法
+func (ks *KeySet) algorithms() []string {
+	seen := map[string]bool{}
+	var algs []string
+	for _, key := range ks.keys {
+		if !seen[key.Algorithm] {
+			seen[key.Algorithm] = true
+			algs = append(algs, key.Algorithm)
+		}
+	}
+	return algs
+}
+
+// JWK JSON Web Key（RFC 7517）中用到的字段
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JWKS JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS 公开全部非对称验证密钥，HMAC 共享密钥不会公开
+func (ks *KeySet) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, key := range ks.keys {
+		jwk := JWK{KeyID: key.ID, Algorithm: key.Algorithm, Use: "sig"}
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].KeyID < set.Keys[j].KeyID })
+	return set
+}
@@ -0,0 +1,91 @@
+package mailer
+
+import (
+	"blog/config"
+	"context"
+	"log"
+	"sync"
+)
+
+// Message 待发送的邮件
+type Message struct {
+	To      string
+	Subject string
+	Body    string // 纯文本正文
+}
+
+// Mailer 邮件发送接口
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+var mailer Mailer
+
+// InitMailer 根据配置初始化邮件发送器
+func InitMailer() {
+	switch config.AppConfig.Mail.Driver {
+	case "smtp":
+		mailer = NewSMTPMailer()
+	default:
+		mailer = NewLogMailer()
+	}
+}
+
+// GetMailer 获取邮件发送器，未初始化时只记录日志
+func GetMailer() Mailer {
+	if mailer == nil {
+		mailer = NewLogMailer()
+	}
+	return mailer
+}
+
+// LogMailer 只把邮件写入日志，用于开发环境
+type LogMailer struct{}
+
+// NewLogMailer 创建日志邮件发送器
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	log.Printf("[MAIL] to=%s subject=%q\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// MemoryMailer 把邮件保存在内存中，供测试断言
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewMemoryMailer 创建内存邮件发送器
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (m *MemoryMailer) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// Messages 返回已发送邮件的副本
+func (m *MemoryMailer) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Message(nil), m.messages...)
+}
+
+// Last 返回最后一封邮件
+func (m *MemoryMailer) Last() (Message, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.messages) == 0 {
+		return Message{}, false
+	}
+	return m.messages[len(m.messages)-1], true
+}
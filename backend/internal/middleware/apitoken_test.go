@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"blog/internal/model"
+	"blog/internal/repository"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAPITokenRepository 模拟个人访问令牌仓库
+type MockAPITokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPITokenRepository) Create(ctx context.Context, token *model.APIToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockAPITokenRepository) FindByHash(ctx context.Context, tokenHash string) (*model.APIToken, error) {
+	args := m.Called(tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.APIToken), args.Error(1)
+}
+
+func (m *MockAPITokenRepository) ListActiveByUser(ctx context.Context, userID uint) ([]model.APIToken, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]model.APIToken), args.Error(1)
+}
+
+func (m *MockAPITokenRepository) CountActiveByUser(ctx context.Context, userID uint) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAPITokenRepository) Revoke(ctx context.Context, id, userID uint) (bool, error) {
+	args := m.Called(id, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAPITokenRepository) RevokeAllByUser(ctx context.Context, userID uint) (int64, error) {
+	args := m.Called(userID)
+	return int64(args.Int(0)), args.Error(1)
+}
+
+func (m *MockAPITokenRepository) TouchLastUsed(ctx context.Context, id uint, at time.Time) error {
+	args := m.Called(id, at)
+	return args.Error(0)
+}
+
+func TestAuthMiddleware_APIToken(t *testing.T) {
+	const raw = model.APITokenPrefix + "secret"
+	sum := sha256.Sum256([]byte(raw))
+	tokenHash := hex.EncodeToString(sum[:])
+	user := &model.User{ID: 1, Username: "ci"}
+
+	// serve 以仓库中查到的 token 携带令牌请求 path，/articles 要求 articles:write 权限
+	serve := func(t *testing.T, token *model.APIToken, path string) (*httptest.ResponseRecorder, *MockAPITokenRepository) {
+		mockTokens := new(MockAPITokenRepository)
+		mockUsers := new(MockUserRepository)
+		originalTokens, originalUsers := repository.NewAPITokenRepository, repository.NewUserRepository
+		repository.NewAPITokenRepository = func() repository.IAPITokenRepository { return mockTokens }
+		repository.NewUserRepository = func() repository.IUserRepository { return mockUsers }
+		t.Cleanup(func() {
+			repository.NewAPITokenRepository, repository.NewUserRepository = originalTokens, originalUsers
+		})
+
+		if token != nil {
+			mockTokens.On("FindByHash", tokenHash).Return(token, nil)
+		} else {
+			mockTokens.On("FindByHash", tokenHash).Return(nil, nil)
+		}
+		mockTokens.On("TouchLastUsed", mock.Anything, mock.Anything).Return(nil)
+		mockUsers.On("FindByID", user.ID).Return(user, nil)
+
+		router := setupRouter()
+		router.Use(AuthMiddleware())
+		router.POST("/articles", RequireScope(model.ScopeArticlesWrite), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		router.POST("/password", RejectAPIToken(), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", path, nil)
+		req.Header.Set("Authorization", "Bearer "+raw)
+		router.ServeHTTP(w, req)
+		return w, mockTokens
+	}
+
+	// 测试用例1：拥有权限的令牌通过，并记录最近使用时间
+	t.Run("权限匹配", func(t *testing.T) {
+		w, mockTokens := serve(t, &model.APIToken{ID: 3, UserID: 1, Scopes: []string{model.ScopeArticlesWrite}}, "/articles")
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockTokens.AssertCalled(t, "TouchLastUsed", uint(3), mock.Anything)
+	})
+
+	// 测试用例2：刚使用过的令牌不重复写入最近使用时间
+	t.Run("最近使用时间节流", func(t *testing.T) {
+		lastUsed := time.Now().Add(-10 * time.Second)
+		w, mockTokens := serve(t, &model.APIToken{ID: 3, UserID: 1, Scopes: []string{model.ScopeArticlesWrite}, LastUsedAt: &lastUsed}, "/articles")
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockTokens.AssertNotCalled(t, "TouchLastUsed", mock.Anything, mock.Anything)
+	})
+
+	// 测试用例3：缺少权限
+	t.Run("缺少权限", func(t *testing.T) {
+		w, _ := serve(t, &model.APIToken{ID: 3, UserID: 1, Scopes: []string{model.ScopeArticlesRead}}, "/articles")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "insufficient_scope")
+	})
+
+	// 测试用例4：敏感操作不允许使用令牌
+	t.Run("敏感操作", func(t *testing.T) {
+		w, _ := serve(t, &model.APIToken{ID: 3, UserID: 1, Scopes: []string{model.ScopeProfileWrite}}, "/password")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	// 测试用例5：已撤销、已过期或不存在的令牌
+	t.Run("令牌无效", func(t *testing.T) {
+		revokedAt := time.Now().Add(-time.Hour)
+		expiresAt := time.Now().Add(-time.Minute)
+		for _, token := range []*model.APIToken{
+			{ID: 3, UserID: 1, Scopes: []string{model.ScopeArticlesWrite}, RevokedAt: &revokedAt},
+			{ID: 3, UserID: 1, Scopes: []string{model.ScopeArticlesWrite}, ExpiresAt: &expiresAt},
+			nil,
+		} {
+			w, _ := serve(t, token, "/articles")
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		}
+	})
+}
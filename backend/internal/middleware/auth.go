@@ -1,60 +1,99 @@
 package middleware
 
 import (
-	"blog/config"
+	"blog/internal/activity"
+	"blog/internal/apperr"
+	"blog/internal/jwtkeys"
+	"blog/internal/model"
 	"blog/internal/repository"
-	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
+// 认证失败错误
+var (
+	ErrMissingToken       = apperr.New(apperr.ErrUnauthorized, "missing_token", "缺少认证头")
+	ErrInvalidTokenFormat = apperr.New(apperr.ErrUnauthorized, "invalid_token_format", "认证头格式错误")
+	ErrInvalidToken       = apperr.New(apperr.ErrUnauthorized, "invalid_token", "无效的令牌")
+	ErrInvalidTokenClaims = apperr.New(apperr.ErrUnauthorized, "invalid_token_claims", "无效的令牌声明")
+	ErrUserNotFound       = apperr.New(apperr.ErrUnauthorized, "token_user_not_found", "令牌对应的用户不存在")
+	ErrSessionRevoked     = apperr.New(apperr.ErrUnauthorized, "session_revoked", "登录已失效，请重新登录")
+)
+
+// OptionalAuthMiddleware 用于公开接口：带认证头时按 AuthMiddleware 校验，未带时以匿名身份继续
+func OptionalAuthMiddleware() gin.HandlerFunc {
+	auth := AuthMiddleware()
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		auth(c)
+	}
+}
+
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
-			c.Abort()
+			abortWithError(c, ErrMissingToken)
 			return
 		}
 
 		// 检查 Bearer token 格式
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
-			c.Abort()
+			abortWithError(c, ErrInvalidTokenFormat)
 			return
 		}
 
-		tokenString := parts[1]
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return []byte(config.AppConfig.JWT.Secret), nil
-		})
+		// 个人访问令牌以固定前缀开头，其余按 JWT 处理
+		if strings.HasPrefix(parts[1], model.APITokenPrefix) {
+			authenticateAPIToken(c, parts[1])
+			return
+		}
+
+		// 签名算法、kid、iss、aud 和有效期都由密钥集校验
+		claims, err := jwtkeys.GetKeySet().Parse(parts[1])
+		if err != nil {
+			abortWithError(c, ErrInvalidToken)
+			return
+		}
 
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
+		rawUserID, ok := claims["user_id"].(float64)
+		sessionID, _ := claims["sid"].(string)
+		if !ok || sessionID == "" {
+			abortWithError(c, ErrInvalidTokenClaims)
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-			c.Abort()
+		userID := uint(rawUserID)
+
+		// 会话被撤销或已过期时，即使令牌本身未过期也不再接受
+		now := time.Now()
+		session, err := repository.NewSessionRepository().FindByTokenID(c.Request.Context(), sessionID)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		if session == nil || session.UserID != userID || !session.Active(now) {
+			abortWithError(c, ErrSessionRevoked)
 			return
 		}
 
-		userID := uint(claims["user_id"].(float64))
 		userRepo := repository.NewUserRepository()
-		user, err := userRepo.FindByID(userID)
+		user, err := userRepo.FindByID(c.Request.Context(), userID)
 		if err != nil || user == nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
-			c.Abort()
+			abortWithError(c, ErrUserNotFound)
 			return
 		}
 
+		activity.GetTracker().Touch(session.ID, now)
+
 		c.Set("user", user)
+		c.Set("session", session)
 		c.Next()
 	}
 }
@@ -1,17 +1,22 @@
 package middleware
 
 import (
-	"blog/config"
+	"blog/internal/jwtkeys"
 	"blog/internal/model"
 	"blog/internal/repository"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockUserRepository 模拟用户仓库
@@ -19,12 +24,12 @@ type MockUserRepository struct {
 	mock.Mock
 }
 
-func (m *MockUserRepository) Create(user *model.User) error {
+func (m *MockUserRepository) Create(ctx context.Context, user *model.User, emit repository.UserEventsFunc) error {
 	args := m.Called(user)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) FindByEmail(email string) (*model.User, error) {
+func (m *MockUserRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
 	args := m.Called(email)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -32,7 +37,7 @@ func (m *MockUserRepository) FindByEmail(email string) (*model.User, error) {
 	return args.Get(0).(*model.User), args.Error(1)
 }
 
-func (m *MockUserRepository) FindByID(id uint) (*model.User, error) {
+func (m *MockUserRepository) FindByID(ctx context.Context, id uint) (*model.User, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -40,27 +45,148 @@ func (m *MockUserRepository) FindByID(id uint) (*model.User, error) {
 	return args.Get(0).(*model.User), args.Error(1)
 }
 
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, id uint, password string) error {
+	args := m.Called(id, password)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) MarkEmailVerified(ctx context.Context, id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) FindByUsername(ctx context.Context, username string) (*model.User, error) {
+	args := m.Called(username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateProfile(ctx context.Context, id uint, updates map[string]interface{}) error {
+	args := m.Called(id, updates)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateEmail(ctx context.Context, id uint, email string) error {
+	args := m.Called(id, email)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SetTOTPSecret(ctx context.Context, id uint, secret string) error {
+	args := m.Called(id, secret)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) EnableTwoFactor(ctx context.Context, id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) DisableTwoFactor(ctx context.Context, id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) AdvanceTOTPStep(ctx context.Context, id uint, step int64) (bool, error) {
+	args := m.Called(id, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateRole(ctx context.Context, id uint, role string) error {
+	args := m.Called(id, role)
+	return args.Error(0)
+}
+
 func setupRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
 	return r
 }
 
-func generateTestToken(userID uint, secret string) string {
-	claims := jwt.MapClaims{
+// withTestKeySet 安装测试用的 EdDSA 密钥集，结束后恢复
+func withTestKeySet(t *testing.T) *jwtkeys.KeySet {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key, err := jwtkeys.NewPrivateKey("test", jwtkeys.AlgEdDSA, private)
+	require.NoError(t, err)
+	ks, err := jwtkeys.NewKeySet("test", jwtkeys.Options{Issuer: "blog", Audience: "blog-api"}, key)
+	require.NoError(t, err)
+
+	previous := jwtkeys.SetKeySet(ks)
+	t.Cleanup(func() { jwtkeys.SetKeySet(previous) })
+	return ks
+}
+
+// MockSessionRepository 模拟会话仓库
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepository) Create(ctx context.Context, session *model.Session) error {
+	args := m.Called(session)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) FindByTokenID(ctx context.Context, tokenID string) (*model.Session, error) {
+	args := m.Called(tokenID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) ListActiveByUser(ctx context.Context, userID uint) ([]model.Session, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]model.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) Revoke(ctx context.Context, id, userID uint) (bool, error) {
+	args := m.Called(id, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSessionRepository) RevokeAllByUser(ctx context.Context, userID, exceptID uint) (int64, error) {
+	args := m.Called(userID, exceptID)
+	return int64(args.Int(0)), args.Error(1)
+}
+
+func (m *MockSessionRepository) UpdateLastSeen(ctx context.Context, lastSeen map[uint]time.Time) error {
+	args := m.Called(lastSeen)
+	return args.Error(0)
+}
+
+// testSessionID 测试令牌中的 sid
+const testSessionID = "session-1"
+
+// withSession 让令牌中的 sid 对应给定的会话，session 为 nil 表示会话不存在
+func withSession(t *testing.T, session *model.Session) {
+	mockSessions := new(MockSessionRepository)
+	if session != nil {
+		mockSessions.On("FindByTokenID", testSessionID).Return(session, nil)
+	} else {
+		mockSessions.On("FindByTokenID", testSessionID).Return(nil, nil)
+	}
+
+	original := repository.NewSessionRepository
+	repository.NewSessionRepository = func() repository.ISessionRepository { return mockSessions }
+	t.Cleanup(func() { repository.NewSessionRepository = original })
+}
+
+func generateTestToken(t *testing.T, ks *jwtkeys.KeySet, userID uint) string {
+	tokenString, err := ks.Sign(jwt.MapClaims{
+		"sid":      testSessionID,
 		"user_id":  userID,
 		"username": "testuser",
 		"email":    "test@example.com",
 		"role":     "user",
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, _ := token.SignedString([]byte(secret))
+	})
+	require.NoError(t, err)
 	return tokenString
 }
 
 func TestAuthMiddleware(t *testing.T) {
-	// 初始化配置
-	config.AppConfig.JWT.Secret = "test-secret"
+	ks := withTestKeySet(t)
 
 	// 测试用例1：缺少认证头
 	t.Run("缺少认证头", func(t *testing.T) {
@@ -109,8 +235,62 @@ func TestAuthMiddleware(t *testing.T) {
 		assert.Equal(t, http.StatusUnauthorized, w.Code)
 	})
 
-	// 测试用例4：用户不存在
+	// 测试用例4：使用共享密钥签名的 HS256 令牌不被接受
+	t.Run("算法不匹配", func(t *testing.T) {
+		router := setupRouter()
+		router.Use(AuthMiddleware())
+		router.GET("/test", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"user_id": 1,
+			"iss":     "blog",
+			"aud":     "blog-api",
+			"exp":     time.Now().Add(time.Hour).Unix(),
+		})
+		token.Header["kid"] = "test"
+		tokenString, err := token.SignedString([]byte("test-secret"))
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/test", nil)
+		req.Header.Set("Authorization", "Bearer "+tokenString)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	// 测试用例5：会话已撤销或不存在时拒绝未过期的令牌
+	t.Run("会话已撤销", func(t *testing.T) {
+		revokedAt := time.Now()
+		for _, session := range []*model.Session{
+			{ID: 1, UserID: 1, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt},
+			{ID: 1, UserID: 1, ExpiresAt: time.Now().Add(-time.Minute)},
+			{ID: 1, UserID: 2, ExpiresAt: time.Now().Add(time.Hour)},
+			nil,
+		} {
+			withSession(t, session)
+
+			router := setupRouter()
+			router.Use(AuthMiddleware())
+			router.GET("/test", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/test", nil)
+			req.Header.Set("Authorization", "Bearer "+generateTestToken(t, ks, 1))
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Contains(t, w.Body.String(), "session_revoked")
+		}
+	})
+
+	// 测试用例6：用户不存在
 	t.Run("用户不存在", func(t *testing.T) {
+		withSession(t, &model.Session{ID: 1, UserID: 1, ExpiresAt: time.Now().Add(time.Hour)})
 		mockRepo := new(MockUserRepository)
 		originalNewUserRepository := repository.NewUserRepository
 		repository.NewUserRepository = func() repository.IUserRepository {
@@ -120,7 +300,7 @@ func TestAuthMiddleware(t *testing.T) {
 			repository.NewUserRepository = originalNewUserRepository
 		}()
 
-		tokenString := generateTestToken(1, config.AppConfig.JWT.Secret)
+		tokenString := generateTestToken(t, ks, 1)
 
 		router := setupRouter()
 		router.Use(AuthMiddleware())
@@ -136,10 +316,12 @@ func TestAuthMiddleware(t *testing.T) {
 		router.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "token_user_not_found")
 	})
 
-	// 测试用例5：成功认证
+	// 测试用例7：成功认证
 	t.Run("成功认证", func(t *testing.T) {
+		withSession(t, &model.Session{ID: 1, UserID: 1, ExpiresAt: time.Now().Add(time.Hour)})
 		mockRepo := new(MockUserRepository)
 		originalNewUserRepository := repository.NewUserRepository
 		repository.NewUserRepository = func() repository.IUserRepository {
@@ -149,7 +331,7 @@ func TestAuthMiddleware(t *testing.T) {
 			repository.NewUserRepository = originalNewUserRepository
 		}()
 
-		tokenString := generateTestToken(1, config.AppConfig.JWT.Secret)
+		tokenString := generateTestToken(t, ks, 1)
 
 		user := &model.User{
 			ID:       1,
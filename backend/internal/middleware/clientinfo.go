@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"blog/internal/clientinfo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientInfoMiddleware 把客户端 IP 和 User-Agent 写入请求 context
+// IP 取自 gin 的 ClientIP，只信任 server.trusted_proxies 中的代理
+func ClientInfoMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(clientinfo.WithInfo(c.Request.Context(), clientinfo.Info{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}))
+		c.Next()
+	}
+}
@@ -9,6 +9,9 @@ func CORSMiddleware() gin.HandlerFunc {
 		origin := c.Request.Header.Get("Origin")
 		if origin == "" {
 			origin = "*"
+		} else {
+			// 第三方登录的绑定 Cookie 需要随跨域请求发送，SameSite=Lax 保证跨站页面无法携带
+			c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		}
 
 		c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
@@ -24,4 +27,4 @@ func CORSMiddleware() gin.HandlerFunc {
 
 		c.Next()
 	}
-}
\ No newline at end of file
+}
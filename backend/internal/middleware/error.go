@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"blog/internal/apperr"
+	"blog/internal/i18n"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errorResponse 错误响应结构，与 handler.Response 字段保持一致并附带错误码
+type errorResponse struct {
+	Code      int         `json:"code"`
+	Message   string      `json:"message"`
+	ErrorCode string      `json:"error_code"`
+	Data      interface{} `json:"data"`
+}
+
+// ErrorMiddleware 将处理器通过 c.Error 上报的错误统一转换为响应
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		status := apperr.HTTPStatus(err)
+
+		var appErr *apperr.Error
+		if status == http.StatusInternalServerError || !errors.As(err, &appErr) {
+			// 内部错误只记录日志，不向客户端暴露细节
+			log.Printf("[ERROR] %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+			appErr = apperr.Internal
+			status = http.StatusInternalServerError
+		}
+
+		setRetryAfter(c, err)
+		c.JSON(status, errorResponse{
+			Code:      status,
+			Message:   localizedMessage(c, appErr),
+			ErrorCode: appErr.Code,
+		})
+	}
+}
+
+// abortWithError 中断请求并以统一格式返回错误
+func abortWithError(c *gin.Context, err error) {
+	var appErr *apperr.Error
+	if !errors.As(err, &appErr) {
+		// 与 ErrorMiddleware 一致，内部错误只记录日志，不向客户端暴露细节
+		log.Printf("[ERROR] %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		appErr = apperr.Internal
+	}
+
+	status := apperr.HTTPStatus(appErr)
+	setRetryAfter(c, err)
+	c.AbortWithStatusJSON(status, errorResponse{
+		Code:      status,
+		Message:   localizedMessage(c, appErr),
+		ErrorCode: appErr.Code,
+	})
+}
+
+// setRetryAfter 错误携带重试等待时长时设置 Retry-After 头（单位为秒，向上取整）
+func setRetryAfter(c *gin.Context, err error) {
+	var retry interface{ RetryAfter() time.Duration }
+	if !errors.As(err, &retry) || retry.RetryAfter() <= 0 {
+		return
+	}
+	seconds := int(math.Ceil(retry.RetryAfter().Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+}
+
+// localizedMessage 按请求语言生成错误提示，参数错误附带翻译后的校验详情
+func localizedMessage(c *gin.Context, appErr *apperr.Error) string {
+	message := appErr.Message
+	if i18n.Has(appErr.Code) {
+		message = i18n.T(c, appErr.Code, appErr.Args...)
+	} else if len(appErr.Args) > 0 {
+		message = fmt.Sprintf(message, appErr.Args...)
+	}
+
+	if errors.Is(appErr, apperr.ErrValidation) && appErr.Err != nil {
+		detail := i18n.TranslateValidation(i18n.FromContext(c), appErr.Err)
+		if detail == "" {
+			detail = appErr.Err.Error()
+		}
+		message += ": " + detail
+	}
+	return message
+}
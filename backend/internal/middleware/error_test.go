@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAbortWithError(t *testing.T) {
+	// 测试用例1：非业务错误返回 500，原始错误只写入日志
+	t.Run("内部错误记录日志", func(t *testing.T) {
+		var buf bytes.Buffer
+		original := log.Writer()
+		log.SetOutput(&buf)
+		t.Cleanup(func() { log.SetOutput(original) })
+
+		router := setupRouter()
+		router.GET("/fail", func(c *gin.Context) {
+			abortWithError(c, errors.New("dial tcp: connection refused"))
+		})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/fail", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.NotContains(t, w.Body.String(), "connection refused")
+		assert.Contains(t, buf.String(), "GET /fail: dial tcp: connection refused")
+	})
+
+	// 测试用例2：业务错误按原样返回，不记录日志
+	t.Run("业务错误", func(t *testing.T) {
+		var buf bytes.Buffer
+		original := log.Writer()
+		log.SetOutput(&buf)
+		t.Cleanup(func() { log.SetOutput(original) })
+
+		router := setupRouter()
+		router.GET("/fail", func(c *gin.Context) {
+			abortWithError(c, ErrInvalidToken)
+		})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/fail", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Empty(t, buf.String())
+	})
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"blog/internal/httpcache"
+	"blog/internal/i18n"
+	"bytes"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bufferedWriter 暂存处理器输出，便于在写出前计算 ETag
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status  int
+	written bool
+	body    bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if !w.written {
+		w.status = code
+		w.written = true
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int   { return w.status }
+func (w *bufferedWriter) Size() int     { return w.body.Len() }
+func (w *bufferedWriter) Written() bool { return w.written }
+
+// HTTPCache 为公开的 GET 接口计算 ETag、处理条件请求并设置 Cache-Control。
+// 匿名响应可被公共缓存保存 maxAge；带认证头的响应包含个人信息，只允许私有缓存且每次验证。
+// store 不为 nil 时在进程内缓存匿名响应，按路由、查询参数和语言区分
+func HTTPCache(maxAge time.Duration, store *httpcache.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		anonymous := c.GetHeader("Authorization") == ""
+		c.Header("Vary", "Authorization, Accept-Language")
+
+		var key string
+		if anonymous && store != nil {
+			key = responseCacheKey(c)
+			if entry, ok := store.Get(key); ok {
+				c.Abort()
+				c.Header("Content-Type", entry.ContentType)
+				if entry.LastModified != "" {
+					c.Header("Last-Modified", entry.LastModified)
+				}
+				writeCacheable(c, maxAge, anonymous, entry.Body)
+				return
+			}
+		}
+
+		w := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		// 处理器上报了错误时由 ErrorMiddleware 输出
+		if !w.written {
+			return
+		}
+		if w.status != http.StatusOK {
+			c.Writer.WriteHeader(w.status)
+			c.Writer.Write(w.body.Bytes())
+			return
+		}
+
+		if key != "" {
+			store.Set(key, &httpcache.Entry{
+				ContentType:  c.Writer.Header().Get("Content-Type"),
+				LastModified: c.Writer.Header().Get("Last-Modified"),
+				Body:         w.body.Bytes(),
+			})
+		}
+		writeCacheable(c, maxAge, anonymous, w.body.Bytes())
+	}
+}
+
+// writeCacheable 设置缓存相关的响应头，客户端缓存仍有效时返回 304
+func writeCacheable(c *gin.Context, maxAge time.Duration, anonymous bool, body []byte) {
+	if anonymous {
+		c.Header("Cache-Control", "public, max-age="+strconv.Itoa(int(maxAge.Seconds())))
+	} else {
+		c.Header("Cache-Control", "private, no-cache")
+	}
+
+	etag := httpcache.ETag(body)
+	c.Header("ETag", etag)
+	lastModified, _ := http.ParseTime(c.Writer.Header().Get("Last-Modified"))
+	if httpcache.NotModified(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+
+	c.Status(http.StatusOK)
+	if c.Request.Method == http.MethodHead {
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	c.Writer.Write(body)
+}
+
+// responseCacheKey 路径加排序后的查询参数，响应文案随语言变化，因此也计入语言
+func responseCacheKey(c *gin.Context) string {
+	return c.Request.URL.Path + "?" + c.Request.URL.Query().Encode() + "|" + c.GetString(i18n.ContextKey)
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"blog/internal/apperr"
+	"blog/internal/httpcache"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPCache(t *testing.T) {
+	lastModified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	newRouter := func(store *httpcache.Store, calls *int) *gin.Engine {
+		router := setupRouter()
+		router.Use(ErrorMiddleware())
+		router.GET("/articles", HTTPCache(time.Minute, store), func(c *gin.Context) {
+			*calls++
+			if c.Query("fail") != "" {
+				c.Error(apperr.New(apperr.ErrNotFound, "article_not_found", "文章不存在"))
+				return
+			}
+			c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+			c.JSON(http.StatusOK, gin.H{"page": c.Query("page")})
+		})
+		return router
+	}
+	send := func(router *gin.Engine, target string, header http.Header) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", target, nil)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("匿名响应可公共缓存，ETag 匹配时返回 304", func(t *testing.T) {
+		var calls int
+		router := newRouter(nil, &calls)
+
+		w := send(router, "/articles?page=1", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "public, max-age=60", w.Header().Get("Cache-Control"))
+		assert.Contains(t, w.Header().Get("Vary"), "Authorization")
+		etag := w.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+		assert.JSONEq(t, `{"page":"1"}`, w.Body.String())
+
+		w = send(router, "/articles?page=1", http.Header{"If-None-Match": {etag}})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+
+		w = send(router, "/articles?page=1", http.Header{"If-Modified-Since": {lastModified.Format(http.TimeFormat)}})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+
+		w = send(router, "/articles?page=2", http.Header{"If-None-Match": {etag}})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 4, calls)
+	})
+
+	t.Run("登录用户的响应只允许私有缓存", func(t *testing.T) {
+		var calls int
+		router := newRouter(nil, &calls)
+
+		w := send(router, "/articles", http.Header{"Authorization": {"Bearer token"}})
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "private, no-cache", w.Header().Get("Cache-Control"))
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+	})
+
+	t.Run("错误响应交给 ErrorMiddleware 且不缓存", func(t *testing.T) {
+		var calls int
+		router := newRouter(httpcache.NewStore(10, time.Minute), &calls)
+
+		for i := 0; i < 2; i++ {
+			w := send(router, "/articles?fail=1", nil)
+			assert.Equal(t, http.StatusNotFound, w.Code)
+			assert.Contains(t, w.Body.String(), "article_not_found")
+			assert.Empty(t, w.Header().Get("ETag"))
+		}
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("匿名响应按路径和查询参数缓存，失效后重新生成", func(t *testing.T) {
+		var calls int
+		store := httpcache.NewStore(10, time.Minute)
+		router := newRouter(store, &calls)
+
+		first := send(router, "/articles?page=1", nil)
+		cached := send(router, "/articles?page=1", nil)
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, first.Body.String(), cached.Body.String())
+		assert.Equal(t, first.Header().Get("ETag"), cached.Header().Get("ETag"))
+		assert.Equal(t, "application/json; charset=utf-8", cached.Header().Get("Content-Type"))
+		assert.Equal(t, lastModified.Format(http.TimeFormat), cached.Header().Get("Last-Modified"))
+
+		w := send(router, "/articles?page=1", http.Header{"If-None-Match": {first.Header().Get("ETag")}})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Equal(t, 1, calls)
+
+		send(router, "/articles?page=2", nil)
+		send(router, "/articles?page=1", http.Header{"Authorization": {"Bearer token"}})
+		assert.Equal(t, 3, calls)
+
+		store.Invalidate()
+		send(router, "/articles?page=1", nil)
+		assert.Equal(t, 4, calls)
+	})
+}
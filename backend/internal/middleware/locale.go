@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"blog/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LocaleMiddleware 根据 lang 参数或 Accept-Language 头协商响应语言
+func LocaleMiddleware() gin.HandlerFunc {
+	i18n.RegisterValidator()
+
+	return func(c *gin.Context) {
+		locale := i18n.Negotiate(c.Query("lang"), c.GetHeader("Accept-Language"))
+		c.Set(i18n.ContextKey, locale)
+		c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), locale))
+		c.Header("Content-Language", locale)
+		c.Next()
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocaleMiddleware(t *testing.T) {
+	newRouter := func() *gin.Engine {
+		router := setupRouter()
+		router.Use(LocaleMiddleware())
+		router.Use(AuthMiddleware())
+		router.GET("/test", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		return router
+	}
+
+	tests := []struct {
+		name           string
+		url            string
+		acceptLanguage string
+		wantLocale     string
+		wantMessage    string
+	}{
+		{"默认中文", "/test", "", "zh-CN", "缺少认证头"},
+		{"请求头协商英文", "/test", "en-US,en;q=0.9", "en-US", "Authorization header is required"},
+		{"参数优先", "/test?lang=zh-CN", "en-US", "zh-CN", "缺少认证头"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			newRouter().ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Equal(t, tt.wantLocale, w.Header().Get("Content-Language"))
+
+			var response errorResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			assert.Equal(t, tt.wantMessage, response.Message)
+			assert.Equal(t, "missing_token", response.ErrorCode)
+		})
+	}
+}
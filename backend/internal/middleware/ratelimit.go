@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"blog/internal/ratelimit"
+	"log"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitMiddleware 按客户端 IP 和路由进行令牌桶限流
+func RateLimitMiddleware(store ratelimit.Store, limit ratelimit.Limit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !limit.Enabled() {
+			c.Next()
+			return
+		}
+
+		key := "ip:" + c.FullPath() + ":" + c.ClientIP()
+		allowed, wait, err := store.Allow(c.Request.Context(), key, limit)
+		if err != nil {
+			// 存储不可用时放行，避免限流组件故障影响正常请求
+			log.Printf("[WARN] rate limit store unavailable: %v", err)
+			c.Next()
+			return
+		}
+		if !allowed {
+			abortWithError(c, &ratelimit.LimitError{Err: ratelimit.ErrTooManyRequests, Wait: wait})
+			return
+		}
+
+		c.Next()
+	}
+}
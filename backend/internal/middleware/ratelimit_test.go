@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"blog/internal/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	router := setupRouter()
+	router.Use(RateLimitMiddleware(ratelimit.NewMemoryStore(), ratelimit.Limit{Rate: 0.5, Burst: 2}))
+	router.POST("/login", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	send := func(remoteAddr string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/login", nil)
+		req.RemoteAddr = remoteAddr
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, send("10.0.0.1:1234").Code)
+	assert.Equal(t, http.StatusOK, send("10.0.0.1:1234").Code)
+
+	// 超出限制返回 429 并带 Retry-After
+	w := send("10.0.0.1:1234")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "too_many_requests")
+
+	// 其他 IP 不受影响
+	assert.Equal(t, http.StatusOK, send("10.0.0.2:1234").Code)
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"blog/internal/apperr"
+	"blog/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrPermissionDenied 当前用户角色无权访问
+var ErrPermissionDenied = apperr.New(apperr.ErrForbidden, "permission_denied", "无权访问")
+
+// RequireRole 只允许指定角色的用户访问，需放在 AuthMiddleware 之后
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get("user")
+		user, ok := value.(*model.User)
+		if !exists || !ok || user.Role != role {
+			abortWithError(c, ErrPermissionDenied)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"blog/internal/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireRole(t *testing.T) {
+	send := func(user *model.User) *httptest.ResponseRecorder {
+		router := setupRouter()
+		router.Use(func(c *gin.Context) {
+			if user != nil {
+				c.Set("user", user)
+			}
+			c.Next()
+		}, RequireRole(model.RoleAdmin))
+		router.GET("/test", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/test", nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, send(&model.User{ID: 1, Role: model.RoleAdmin}).Code)
+
+	w := send(&model.User{ID: 2, Role: model.RoleUser})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "permission_denied")
+
+	assert.Equal(t, http.StatusForbidden, send(nil).Code)
+}
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"blog/config"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// TracingMiddleware 为每个请求创建服务端 span，并把 trace 上下文写入 c.Request.Context()
+func TracingMiddleware() gin.HandlerFunc {
+	serviceName := config.AppConfig.Tracing.ServiceName
+	if serviceName == "" {
+		serviceName = "blog-backend"
+	}
+	return otelgin.Middleware(serviceName)
+}
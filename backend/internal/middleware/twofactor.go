@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"blog/config"
+	"blog/internal/apperr"
+	"blog/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrTwoFactorSetupRequired 管理员尚未开启两步验证
+var ErrTwoFactorSetupRequired = apperr.New(apperr.ErrForbidden, "two_factor_setup_required", "请先开启两步验证")
+
+// TwoFactorMiddleware 配置要求管理员开启两步验证时，拦截尚未开启的管理员，需放在 AuthMiddleware 之后
+// 两步验证设置相关的路由不应使用该中间件，否则管理员无法完成设置
+func TwoFactorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !config.AppConfig.Auth.TwoFactor.RequireForAdmins {
+			c.Next()
+			return
+		}
+
+		value, exists := c.Get("user")
+		user, ok := value.(*model.User)
+		if exists && ok && user.Role == model.RoleAdmin && !user.TwoFactorEnabled {
+			abortWithError(c, ErrTwoFactorSetupRequired)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"blog/config"
+	"blog/internal/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTwoFactorMiddleware(t *testing.T) {
+	original := config.AppConfig.Auth.TwoFactor.RequireForAdmins
+	t.Cleanup(func() { config.AppConfig.Auth.TwoFactor.RequireForAdmins = original })
+
+	send := func(user *model.User) *httptest.ResponseRecorder {
+		router := setupRouter()
+		router.Use(func(c *gin.Context) {
+			c.Set("user", user)
+			c.Next()
+		}, TwoFactorMiddleware())
+		router.GET("/test", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/test", nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	admin := &model.User{ID: 1, Role: model.RoleAdmin}
+	member := &model.User{ID: 2, Role: model.RoleUser}
+
+	// 未开启配置时不做限制
+	config.AppConfig.Auth.TwoFactor.RequireForAdmins = false
+	assert.Equal(t, http.StatusOK, send(admin).Code)
+
+	// 开启后未设置两步验证的管理员被拦截，普通用户不受影响
+	config.AppConfig.Auth.TwoFactor.RequireForAdmins = true
+	w := send(admin)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "two_factor_setup_required")
+	assert.Equal(t, http.StatusOK, send(member).Code)
+
+	admin.TwoFactorEnabled = true
+	assert.Equal(t, http.StatusOK, send(admin).Code)
+}
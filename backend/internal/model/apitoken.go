@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+// 个人访问令牌的权限范围
+const (
+	ScopeArticlesRead  = "articles:read"
+	ScopeArticlesWrite = "articles:write"
+	ScopeProfileRead   = "profile:read"
+	ScopeProfileWrite  = "profile:write"
+)
+
+// APITokenPrefix 个人访问令牌的固定前缀，用于与 JWT 区分，也便于密钥扫描工具识别
+const APITokenPrefix = "blog_pat_"
+
+// APIToken 个人访问令牌，供脚本和 CI 使用，只保存哈希值
+type APIToken struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"-"`
+	Name       string     `gorm:"type:varchar(50);not null" json:"name"`
+	Prefix     string     `gorm:"type:varchar(16);not null" json:"prefix"` // 令牌开头几位，便于用户辨认
+	TokenHash  string     `gorm:"type:char(64);uniqueIndex;not null" json:"-"`
+	Scopes     []string   `gorm:"type:varchar(255);serializer:json" json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"` // 为空表示永不过期
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// TableName 指定个人访问令牌表名
+func (APIToken) TableName() string {
+	return "api_tokens"
+}
+
+// Active 令牌未撤销且未过期
+func (t *APIToken) Active(now time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || now.Before(*t.ExpiresAt))
+}
+
+// HasScope 令牌是否拥有指定权限
+func (t *APIToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateAPITokenRequest 创建个人访问令牌，expires_in_days 为空表示永不过期
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name" binding:"required,max=50"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,dive,oneof=articles:read articles:write profile:read profile:write"`
+	ExpiresInDays *int     `json:"expires_in_days" binding:"omitempty,min=1,max=365"`
+}
+
+// APITokenCreated 新建的令牌，明文只在创建时返回一次
+type APITokenCreated struct {
+	Token string `json:"token"`
+	*APIToken
+}
@@ -6,18 +6,38 @@ import (
 	"gorm.io/gorm"
 )
 
+// 文章状态，状态之间的流转规则见 service 中的审核工作流
+const (
+	ArticleStatusDraft     = "draft"
+	ArticleStatusInReview  = "in_review"
+	ArticleStatusApproved  = "approved"
+	ArticleStatusRejected  = "rejected"
+	ArticleStatusPublished = "published"
+	ArticleStatusArchived  = "archived"
+)
+
 // Article 文章模型
 type Article struct {
 	ID        uint           `gorm:"primarykey" json:"id"`
 	Title     string         `gorm:"type:varchar(200);not null" json:"title"`
 	Content   string         `gorm:"type:text;not null" json:"content"`
 	Status    string         `gorm:"type:varchar(20);default:draft" json:"status"`
-	AuthorID  uint          `gorm:"not null" json:"author_id"`
-	Author    User          `gorm:"foreignKey:AuthorID" json:"author"`
-	Tags      []Tag         `gorm:"many2many:article_tags;" json:"tags"`
+	AuthorID  uint           `gorm:"not null" json:"author_id"`
+	Author    PublicUser     `gorm:"foreignKey:AuthorID" json:"author"`
+	Tags      []Tag          `gorm:"many2many:article_tags;" json:"tags"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
+
+	// 点赞、收藏数冗余在文章表，由 reaction 仓库在同一事务内维护
+	LikeCount     int64 `gorm:"not null;default:0" json:"like_count"`
+	BookmarkCount int64 `gorm:"not null;default:0" json:"bookmark_count"`
+	ViewCount     int64 `gorm:"not null;default:0" json:"view_count"` // 浏览数由 viewcount 批量写入
+	// 当前查看者是否点赞、收藏，不入库
+	Liked      bool `gorm:"-" json:"liked"`
+	Bookmarked bool `gorm:"-" json:"bookmarked"`
+	// 文章属于系列时的上一篇、下一篇，只在详情中返回
+	Series *SeriesNav `gorm:"-" json:"series,omitempty"`
 }
 
 // Tag 标签模型
@@ -27,27 +47,10 @@ type Tag struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
-// ArticleRequest 文章请求基础结构
-type ArticleRequest struct {
-	Title   string   `json:"title" binding:"required,min=1,max=200"`
-	Content string   `json:"content" binding:"required"`
-	Status  string   `json:"status" binding:"required,oneof=draft published"`
-	Tags    []string `json:"tags"`
-}
-
-// UpdateArticleRequest 更新文章请求
-type UpdateArticleRequest struct {
-	ID uint `json:"id" binding:"required"`
-	ArticleRequest
-}
-
-// ListArticleRequest 文章列表请求
-type ListArticleRequest struct {
-	Page     int    `json:"page" binding:"required,min=1"`
-	PageSize int    `json:"page_size" binding:"required,min=1,max=100"`
-	Status   string `json:"status"`
-	AuthorID uint   `json:"author_id"`
-	Tag      string `json:"tag"`
+// ArticleViewer 查询文章列表的用户，为空时视为匿名访问，只能看到已发布的文章
+type ArticleViewer struct {
+	UserID   uint
+	Reviewer bool // 审核员还可以看到除草稿以外的未发布文章
 }
 
 // TableName 指定文章表名
@@ -58,4 +61,4 @@ func (Article) TableName() string {
 // TableName 指定标签表名
 func (Tag) TableName() string {
 	return "tags"
-}
\ No newline at end of file
+}
@@ -0,0 +1,85 @@
+package model
+
+import "time"
+
+// 审计事件结果
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// 审计事件动作
+const (
+	AuditActionLogin          = "auth.login"
+	AuditActionLoginTwoFactor = "auth.login_2fa"
+	AuditActionOAuthLogin     = "auth.oauth_login"
+	AuditActionRegister       = "auth.register"
+	AuditActionPasswordReset  = "auth.password_reset"
+	AuditActionRoleChange     = "user.role_change"
+	AuditActionArticleCreate  = "article.create"
+	AuditActionArticleUpdate  = "article.update"
+	AuditActionArticleDelete  = "article.delete"
+	AuditActionArticleStatus  = "article.status_change"
+	AuditActionJobRetry       = "job.retry"
+
+	AuditActionCollaboratorInvite = "article.collaborator_invite"
+	AuditActionCollaboratorRemove = "article.collaborator_remove"
+
+	AuditActionPasswordChange     = "user.password_change"
+	AuditActionEmailChangeRequest = "user.email_change_request"
+	AuditActionEmailChange        = "user.email_change"
+	AuditActionTwoFactorEnable    = "user.2fa_enable"
+	AuditActionTwoFactorDisable   = "user.2fa_disable"
+	AuditActionRecoveryCodesRenew = "user.recovery_codes_regenerate"
+	AuditActionSessionRevoke      = "session.revoke"
+	AuditActionAPITokenCreate     = "api_token.create"
+	AuditActionAPITokenRevoke     = "api_token.revoke"
+)
+
+// 审计事件目标类型
+const (
+	AuditTargetUser    = "user"
+	AuditTargetArticle = "article"
+	AuditTargetJob     = "job"
+	AuditTargetSession = "session"
+	AuditTargetToken   = "api_token"
+)
+
+// AuditEvent 安全审计事件，只追加不修改，过期后由保留策略清理
+type AuditEvent struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ActorID    uint      `gorm:"index" json:"actor_id"`          // 操作者用户 ID，未识别出用户时为 0
+	Actor      string    `gorm:"type:varchar(100)" json:"actor"` // 操作者用户名，登录失败时为尝试的账号
+	Action     string    `gorm:"type:varchar(50);index;not null" json:"action"`
+	TargetType string    `gorm:"type:varchar(30)" json:"target_type"`
+	TargetID   string    `gorm:"type:varchar(64)" json:"target_id"`
+	IP         string    `gorm:"type:varchar(45);index" json:"ip"`
+	UserAgent  string    `gorm:"type:varchar(255)" json:"user_agent"`
+	Outcome    string    `gorm:"type:varchar(10);not null" json:"outcome"`
+	Detail     string    `gorm:"type:varchar(255)" json:"detail"` // 失败原因等补充信息
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+}
+
+// TableName 指定审计事件表名
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
+
+// AuditQuery 审计事件查询条件，零值字段不参与过滤
+type AuditQuery struct {
+	ActorID    uint       `form:"actor_id"`
+	Action     string     `form:"action" binding:"max=50"`
+	Outcome    string     `form:"outcome" binding:"omitempty,oneof=success failure"`
+	TargetType string     `form:"target_type" binding:"max=30"`
+	TargetID   string     `form:"target_id" binding:"max=64"`
+	IP         string     `form:"ip" binding:"max=45"`
+	Since      *time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Until      *time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"`
+	Page       int        `form:"page,default=1" binding:"min=1"`
+	PageSize   int        `form:"page_size,default=20" binding:"min=1,max=100"`
+}
+
+// UpdateRoleRequest 管理员修改用户角色请求
+type UpdateRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=user reviewer admin"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// 文章协作者角色：编辑者可以修改文章，查看者只能查看草稿；删除文章和管理协作者只有作者可以操作
+const (
+	CollaboratorEditor = "editor"
+	CollaboratorViewer = "viewer"
+)
+
+// ArticleCollaborator 文章的协作者，同一用户在同一文章中只有一个角色
+type ArticleCollaborator struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	ArticleID uint       `gorm:"not null;uniqueIndex:idx_collaborator_article_user,priority:1" json:"article_id"`
+	UserID    uint       `gorm:"not null;uniqueIndex:idx_collaborator_article_user,priority:2;index" json:"user_id"`
+	User      PublicUser `gorm:"foreignKey:UserID" json:"user"`
+	Role      string     `gorm:"type:varchar(20);not null" json:"role"`
+	InvitedBy uint       `gorm:"not null" json:"invited_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// TableName 指定文章协作者表名
+func (ArticleCollaborator) TableName() string {
+	return "article_collaborators"
+}
+
+// InviteCollaboratorRequest 邀请协作者，用户已是协作者时修改其角色
+type InviteCollaboratorRequest struct {
+	Username string `json:"username" binding:"required,max=50"`
+	Role     string `json:"role" binding:"required,oneof=editor viewer"`
+}
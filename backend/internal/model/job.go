@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+// 后台任务状态
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobDead      = "dead" // 超过重试次数或不可重试的失败，需要管理员处理
+)
+
+// Job 持久化的后台任务，由 worker 按队列取出执行，失败时按指数退避重试
+type Job struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Queue       string     `gorm:"type:varchar(50);not null;index:idx_jobs_due,priority:1" json:"queue"`
+	Type        string     `gorm:"type:varchar(100);not null;index" json:"type"`
+	Payload     string     `gorm:"type:text;not null" json:"-"` // 可能包含邮件中的令牌等敏感信息，不通过接口返回
+	Status      string     `gorm:"type:varchar(20);not null;index:idx_jobs_due,priority:2" json:"status"`
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts int        `gorm:"not null" json:"max_attempts"`
+	RunAt       time.Time  `gorm:"not null;index:idx_jobs_due,priority:3" json:"run_at"` // 到该时间后才会执行，用于延迟任务和重试
+	LockedUntil *time.Time `json:"locked_until,omitempty"`                               // 执行中的任务超过该时间仍未完成时视为 worker 已崩溃，重新执行
+	UniqueKey   *string    `gorm:"type:varchar(191);uniqueIndex" json:"unique_key,omitempty"`
+	LastError   string     `gorm:"type:varchar(1000)" json:"last_error,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName 指定后台任务表名
+func (Job) TableName() string {
+	return "jobs"
+}
+
+// JobQuery 后台任务查询条件，零值字段不参与过滤
+type JobQuery struct {
+	Queue    string `form:"queue" binding:"max=50"`
+	Type     string `form:"type" binding:"max=100"`
+	Status   string `form:"status" binding:"omitempty,oneof=pending running succeeded dead"`
+	Page     int    `form:"page,default=1" binding:"min=1"`
+	PageSize int    `form:"page_size,default=20" binding:"min=1,max=100"`
+}
+
+// JobStat 某个队列中某种状态的任务数
+type JobStat struct {
+	Queue  string `json:"queue"`
+	Status string `json:"status"`
+	Count  int64  `json:"count"`
+}
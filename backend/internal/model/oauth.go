@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// OAuthState 发起第三方登录时保存的授权状态，回调时核销，只保存 state 的哈希值
+type OAuthState struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	StateHash    string    `gorm:"type:char(64);uniqueIndex;not null" json:"-"`
+	Provider     string    `gorm:"type:varchar(30);not null" json:"provider"`
+	CodeVerifier string    `gorm:"type:varchar(128);not null" json:"-"`
+	Nonce        string    `gorm:"type:varchar(64)" json:"-"`
+	BindingHash  string    `gorm:"type:char(64);not null;default:''" json:"-"` // 发起授权的浏览器 Cookie 中绑定值的哈希
+	ExpiresAt    time.Time `gorm:"not null" json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName 指定授权状态表名
+func (OAuthState) TableName() string {
+	return "oauth_states"
+}
+
+// UserIdentity 关联到本地用户的第三方账号，同一提供方的账号只能关联一个用户
+type UserIdentity struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	Provider  string    `gorm:"type:varchar(30);not null;uniqueIndex:idx_provider_subject" json:"provider"`
+	Subject   string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_provider_subject" json:"-"`
+	Email     string    `gorm:"type:varchar(100)" json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 指定第三方账号表名
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
+
+// OAuthStartResponse 第三方登录授权地址，前端跳转后由提供方回调到 redirect_url
+type OAuthStartResponse struct {
+	AuthorizationURL string    `json:"authorization_url"`
+	State            string    `json:"state"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	Binding          string    `json:"-"` // 由处理器写入 HttpOnly Cookie，回调时必须带回，不出现在响应中
+}
+
+// OAuthCallbackRequest 前端回调页收到的授权码和 state
+type OAuthCallbackRequest struct {
+	Code  string `json:"code" binding:"required,max=512"`
+	State string `json:"state" binding:"required,max=128"`
+}
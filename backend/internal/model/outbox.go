@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// OutboxMessage 与业务数据在同一事务内写入的待发布事件，由中继发布后标记完成。
+// 发布可能重复，EventID 即幂等键
+type OutboxMessage struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	EventID       string     `gorm:"type:varchar(32);not null;uniqueIndex" json:"event_id"`
+	EventType     string     `gorm:"type:varchar(50);not null" json:"event_type"`
+	OwnerID       uint       `gorm:"not null;default:0" json:"owner_id"`
+	Payload       string     `gorm:"type:text;not null" json:"payload"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"not null;index:idx_outbox_pending,priority:2" json:"next_attempt_at"`
+	LastError     string     `gorm:"type:varchar(500)" json:"last_error,omitempty"`
+	PublishedAt   *time.Time `gorm:"index:idx_outbox_pending,priority:1" json:"published_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// TableName 指定 outbox 表名
+func (OutboxMessage) TableName() string {
+	return "outbox_messages"
+}
@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// 文章互动类型
+const (
+	ReactionLike     = "like"
+	ReactionBookmark = "bookmark"
+)
+
+// ArticleReaction 用户对文章的点赞或收藏，同一用户对同一文章的同类互动只有一条
+type ArticleReaction struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_reaction_user_article_kind,priority:1" json:"-"`
+	ArticleID uint      `gorm:"not null;uniqueIndex:idx_reaction_user_article_kind,priority:2;index" json:"article_id"`
+	Kind      string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_reaction_user_article_kind,priority:3" json:"kind"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 指定文章互动表名
+func (ArticleReaction) TableName() string {
+	return "article_reactions"
+}
+
+// ReactionResult 点赞或收藏后的状态
+type ReactionResult struct {
+	Active bool  `json:"active"` // 当前用户是否已点赞或收藏
+	Count  int64 `json:"count"`  // 文章的点赞或收藏总数
+}
+
+// ReactionQuery 我的收藏分页参数
+type ReactionQuery struct {
+	Page     int `form:"page,default=1" binding:"min=1"`
+	PageSize int `form:"page_size,default=10" binding:"min=1,max=100"`
+}
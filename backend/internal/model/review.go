@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// ArticleTransition 文章状态变更记录，只追加不修改
+type ArticleTransition struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	ArticleID  uint       `gorm:"not null;index" json:"article_id"`
+	FromStatus string     `gorm:"type:varchar(20);not null" json:"from_status"`
+	ToStatus   string     `gorm:"type:varchar(20);not null" json:"to_status"`
+	ActorID    uint       `gorm:"not null" json:"actor_id"`
+	Actor      PublicUser `gorm:"foreignKey:ActorID" json:"actor"`
+	Comment    string     `gorm:"type:varchar(1000)" json:"comment"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// TableName 指定文章状态变更记录表名
+func (ArticleTransition) TableName() string {
+	return "article_transitions"
+}
+
+// ReviewComment 审核过程中审核员和作者对文章的评论，只有参与审核的人可见
+type ReviewComment struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	ArticleID uint       `gorm:"not null;index" json:"article_id"`
+	AuthorID  uint       `gorm:"not null" json:"author_id"`
+	Author    PublicUser `gorm:"foreignKey:AuthorID" json:"author"`
+	Body      string     `gorm:"type:text;not null" json:"body"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName 指定审核评论表名
+func (ReviewComment) TableName() string {
+	return "article_review_comments"
+}
+
+// TransitionRequest 变更文章状态，驳回时可以附上原因
+type TransitionRequest struct {
+	Status  string `json:"status" binding:"required,oneof=draft in_review approved rejected published archived"`
+	Comment string `json:"comment" binding:"max=1000"`
+}
+
+// CreateReviewCommentRequest 发表审核评论
+type CreateReviewCommentRequest struct {
+	Body string `json:"body" binding:"required,min=1,max=5000"`
+}
@@ -0,0 +1,85 @@
+package model
+
+import "time"
+
+// Series 系列文章，例如多篇组成的教程，文章按 Position 排列
+type Series struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Title       string    `gorm:"type:varchar(200);not null" json:"title"`
+	Description string    `gorm:"type:varchar(1000)" json:"description"`
+	AuthorID    uint      `gorm:"not null;index" json:"author_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName 指定系列表名
+func (Series) TableName() string {
+	return "series"
+}
+
+// SeriesArticle 文章在系列中的位置，一篇文章最多属于一个系列
+type SeriesArticle struct {
+	ID        uint `gorm:"primaryKey"`
+	SeriesID  uint `gorm:"not null;index:idx_series_articles_position,priority:1"`
+	ArticleID uint `gorm:"not null;uniqueIndex"`
+	Position  int  `gorm:"not null;index:idx_series_articles_position,priority:2"` // 从 1 开始
+	CreatedAt time.Time
+}
+
+// TableName 指定系列文章表名
+func (SeriesArticle) TableName() string {
+	return "series_articles"
+}
+
+// SeriesEntry 系列中的一篇文章
+type SeriesEntry struct {
+	ArticleID uint   `json:"article_id"`
+	Title     string `json:"title"`
+	Status    string `json:"status"`
+	Position  int    `json:"position"`
+}
+
+// SeriesDetail 系列及其中按顺序排列的文章
+type SeriesDetail struct {
+	Series
+	Articles []SeriesEntry `json:"articles"`
+}
+
+// SeriesNav 文章详情中的系列导航
+type SeriesNav struct {
+	ID       uint         `json:"id"`
+	Title    string       `json:"title"`
+	Position int          `json:"position"` // 当前文章是第几篇，从 1 开始
+	Total    int          `json:"total"`
+	Prev     *SeriesEntry `json:"prev"`
+	Next     *SeriesEntry `json:"next"`
+}
+
+// CreateSeriesRequest 创建系列
+type CreateSeriesRequest struct {
+	Title       string `json:"title" binding:"required,min=1,max=200"`
+	Description string `json:"description" binding:"max=1000"`
+}
+
+// UpdateSeriesRequest 修改系列，未提供的字段保持不变
+type UpdateSeriesRequest struct {
+	Title       *string `json:"title" binding:"omitempty,min=1,max=200"`
+	Description *string `json:"description" binding:"omitempty,max=1000"`
+}
+
+// AttachSeriesArticleRequest 把文章加入系列，已在其他系列中时移动过来
+type AttachSeriesArticleRequest struct {
+	ArticleID uint `json:"article_id" binding:"required,min=1"`
+	Position  int  `json:"position" binding:"min=0"` // 从 1 开始，为 0 或超过篇数时放在最后
+}
+
+// ReorderSeriesRequest 按给定顺序重新排列系列中的全部文章
+type ReorderSeriesRequest struct {
+	ArticleIDs []uint `json:"article_ids" binding:"required,min=1,dive,min=1"`
+}
+
+// SeriesPlacement 创建或修改文章时指定的系列位置，SeriesID 为 0 时移出系列
+type SeriesPlacement struct {
+	SeriesID uint
+	Position int // 从 1 开始，为 0 时放在最后
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// Session 一次登录产生的会话，访问令牌通过 sid 声明关联到会话，撤销会话即可让令牌失效
+type Session struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"-"`
+	TokenID    string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"` // 写入访问令牌的 sid
+	UserAgent  string     `gorm:"type:varchar(255)" json:"user_agent"`
+	IP         string     `gorm:"type:varchar(45)" json:"ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt  *time.Time `json:"-"`
+	Current    bool       `gorm:"-" json:"current"` // 是否为发起请求的会话
+}
+
+// TableName 指定会话表名
+func (Session) TableName() string {
+	return "sessions"
+}
+
+// Active 会话未撤销且未过期
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// SitemapArticle 站点地图中的文章
+type SitemapArticle struct {
+	ID        uint
+	UpdatedAt time.Time
+}
+
+// SitemapTag 站点地图中的标签页，LastMod 为该标签下文章的最近更新时间
+type SitemapTag struct {
+	Name    string
+	LastMod time.Time
+}
+
+// SitemapAuthor 站点地图中的作者主页，LastMod 为其文章的最近更新时间
+type SitemapAuthor struct {
+	Username string
+	LastMod  time.Time
+}
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// 一次性令牌用途
+const (
+	TokenPurposeVerifyEmail   = "verify_email"
+	TokenPurposeResetPassword = "reset_password"
+	TokenPurposeChangeEmail   = "change_email"
+	// 密码验证通过后、两步验证完成前使用的登录挑战令牌
+	TokenPurposeLoginChallenge = "login_challenge"
+)
+
+// UserToken 发给用户的一次性令牌（邮箱验证、密码重置），只保存哈希值
+type UserToken struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	Purpose   string     `gorm:"type:varchar(30);not null" json:"purpose"`
+	TokenHash string     `gorm:"type:char(64);uniqueIndex;not null" json:"-"`
+	Payload   string     `gorm:"type:varchar(255)" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName 指定令牌表名
+func (UserToken) TableName() string {
+	return "user_tokens"
+}
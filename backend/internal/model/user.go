@@ -1,34 +1,134 @@
 package model
 
 import (
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// 用户角色
+const (
+	RoleUser     = "user"
+	RoleReviewer = "reviewer" // 审核其他用户提交的文章
+	RoleAdmin    = "admin"
+)
+
 type User struct {
-	ID        uint           `gorm:"primaryKey" json:"id"`
-	Username  string         `gorm:"unique;not null;type:varchar(50)" json:"username"`
-	Email     string         `gorm:"unique;not null;type:varchar(100)" json:"email"`
-	Password  string         `gorm:"not null;type:varchar(100)" json:"-"`
-	Role      string         `gorm:"default:user;type:varchar(20)" json:"role"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID            uint   `gorm:"primaryKey" json:"id"`
+	Username      string `gorm:"unique;not null;type:varchar(50)" json:"username"`
+	Email         string `gorm:"unique;not null;type:varchar(100)" json:"email,omitempty"`
+	Password      string `gorm:"not null;type:varchar(100)" json:"-"`
+	Role          string `gorm:"default:user;type:varchar(20)" json:"role"`
+	EmailVerified bool   `gorm:"default:false" json:"email_verified"`
+	DisplayName   string `gorm:"type:varchar(50)" json:"display_name"`
+	Bio           string `gorm:"type:varchar(500)" json:"bio"`
+	AvatarURL     string `gorm:"type:varchar(255)" json:"avatar_url"`
+	Website       string `gorm:"type:varchar(255)" json:"website"`
+	// 两步验证：TOTPSecret 在确认前即写入，TwoFactorEnabled 为 true 才生效
+	TwoFactorEnabled bool           `gorm:"default:false" json:"two_factor_enabled"`
+	TOTPSecret       string         `gorm:"type:varchar(64)" json:"-"`
+	TOTPLastStep     int64          `gorm:"default:0" json:"-"` // 最近一次使用的动态码周期，防止重放
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// PublicUser 对外展示的用户信息，只读映射 users 表，不包含邮箱、角色等账号信息；
+// 文章作者等公开返回的关联使用该类型，表结构由 User 维护
+type PublicUser struct {
+	ID          uint   `json:"id"`
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	AvatarURL   string `json:"avatar_url"`
+
+	DeletedAt gorm.DeletedAt `json:"-"` // 与 User 一样不加载已删除的用户
+}
+
+// TableName 与 User 共用 users 表
+func (PublicUser) TableName() string {
+	return "users"
+}
+
+// Public 用户对外展示的信息
+func (u *User) Public() PublicUser {
+	return PublicUser{ID: u.ID, Username: u.Username, DisplayName: u.DisplayName, AvatarURL: u.AvatarURL}
 }
 
+// RegisterRequest 注册请求，用户名不能包含 @ 以便与邮箱区分；密码强度由密码策略校验
 type RegisterRequest struct {
-	Username string `json:"username" binding:"required,min=3,max=50"`
+	Username string `json:"username" binding:"required,min=3,max=50,excludes=@"`
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required"`
 }
 
+// LoginRequest 登录请求，account 可以是用户名或邮箱，email 字段保留以兼容旧客户端
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
+	Account  string `json:"account" binding:"required_without=Email,max=100"`
+	Email    string `json:"email" binding:"omitempty,email"`
 	Password string `json:"password" binding:"required"`
 }
 
+// Identifier 返回登录使用的用户名或邮箱
+func (r *LoginRequest) Identifier() string {
+	if r.Account != "" {
+		return strings.TrimSpace(r.Account)
+	}
+	return strings.TrimSpace(r.Email)
+}
+
+// LoginResponse 登录结果，开启两步验证时只返回 challenge_token，验证动态码后才签发 token
 type LoginResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+	Token             string `json:"token,omitempty"`
+	User              *User  `json:"user,omitempty"`
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// UpdateProfileRequest 更新个人资料请求，未传的字段保持不变
+type UpdateProfileRequest struct {
+	DisplayName *string `json:"display_name" binding:"omitempty,max=50"`
+	Bio         *string `json:"bio" binding:"omitempty,max=500"`
+	AvatarURL   *string `json:"avatar_url" binding:"omitempty,http_url,max=255"`
+	Website     *string `json:"website" binding:"omitempty,http_url,max=255"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword     string `json:"old_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+	RevokeAPITokens bool   `json:"revoke_api_tokens"` // 同时撤销全部个人访问令牌
+}
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" binding:"required,email,max=100"`
+	Password string `json:"password" binding:"required"`
+}
+
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// PublicProfile 公开的用户主页，不包含邮箱等隐私信息
+type PublicProfile struct {
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name"`
+	Bio         string    `json:"bio"`
+	AvatarURL   string    `json:"avatar_url"`
+	Website     string    `json:"website"`
+	CreatedAt   time.Time `json:"created_at"`
+	Articles    []Article `json:"articles"`
+	Total       int64     `json:"total"`
 }
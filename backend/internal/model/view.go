@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// ArticleViewStat 文章每小时的浏览数，用于统计一段时间内的热门文章
+type ArticleViewStat struct {
+	ArticleID uint      `gorm:"primaryKey;autoIncrement:false"`
+	Bucket    time.Time `gorm:"primaryKey;index"` // 整点时间（UTC）
+	Views     int64     `gorm:"not null;default:0"`
+}
+
+// TableName 指定浏览统计表名
+func (ArticleViewStat) TableName() string {
+	return "article_view_stats"
+}
+
+// ViewBucket 一篇文章在某个整点小时内的浏览
+type ViewBucket struct {
+	ArticleID uint
+	Hour      time.Time
+}
+
+// PopularArticle 热门文章及其在统计时间范围内的浏览数
+type PopularArticle struct {
+	Article Article `json:"article"`
+	Views   int64   `json:"views"`
+}
@@ -0,0 +1,89 @@
+package model
+
+import "time"
+
+// webhook 投递状态
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook 用户配置的事件推送地址，请求体使用 Secret 做 HMAC-SHA256 签名
+type Webhook struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"-"`
+	Owner     User      `gorm:"foreignKey:UserID" json:"-"`
+	URL       string    `gorm:"type:varchar(500);not null" json:"url"`
+	Secret    string    `gorm:"type:varchar(100);not null" json:"-"`
+	Events    []string  `gorm:"type:varchar(255);serializer:json" json:"events"`
+	Active    bool      `gorm:"not null;default:true" json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定 webhook 表名
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// Subscribes 是否订阅了该类型的事件
+func (w *Webhook) Subscribes(eventType string) bool {
+	for _, e := range w.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery 一次事件推送及其重试状态，重新投递时新建一条记录
+type WebhookDelivery struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	WebhookID      uint       `gorm:"not null;index" json:"webhook_id"`
+	EventID        string     `gorm:"type:varchar(32);not null;index" json:"event_id"`
+	EventType      string     `gorm:"type:varchar(50);not null" json:"event_type"`
+	Payload        string     `gorm:"type:text;not null" json:"payload"`
+	Status         string     `gorm:"type:varchar(20);not null;index:idx_webhook_deliveries_due,priority:1" json:"status"`
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  *time.Time `gorm:"index:idx_webhook_deliveries_due,priority:2" json:"next_attempt_at,omitempty"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	ResponseBody   string     `gorm:"type:varchar(1000)" json:"response_body,omitempty"` // 截断保存，便于排查
+	Error          string     `gorm:"type:varchar(500)" json:"error,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	RedeliveryOf   *uint      `json:"redelivery_of,omitempty"`
+	DedupKey       *string    `gorm:"type:varchar(64);uniqueIndex" json:"-"` // 首次投递时为 webhook 与事件的组合，事件重复发布时不会重复创建
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName 指定 webhook 投递记录表名
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// CreateWebhookRequest 创建 webhook
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required,url,max=500"`
+	Events []string `json:"events" binding:"required,min=1,dive,oneof=article.created article.updated article.published article.deleted user.registered"`
+	Active *bool    `json:"active"`
+}
+
+// UpdateWebhookRequest 修改 webhook，未提供的字段保持不变
+type UpdateWebhookRequest struct {
+	URL    *string  `json:"url" binding:"omitempty,url,max=500"`
+	Events []string `json:"events" binding:"omitempty,min=1,dive,oneof=article.created article.updated article.published article.deleted user.registered"`
+	Active *bool    `json:"active"`
+}
+
+// WebhookCreated 新建的 webhook，签名密钥只在创建时返回一次
+type WebhookCreated struct {
+	Secret string `json:"secret"`
+	*Webhook
+}
+
+// WebhookDeliveryQuery 投递记录分页参数
+type WebhookDeliveryQuery struct {
+	Page     int `form:"page,default=1" binding:"min=1"`
+	PageSize int `form:"page_size,default=20" binding:"min=1,max=100"`
+}
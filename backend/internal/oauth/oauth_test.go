@@ -0,0 +1,95 @@
+package oauth
+
+import (
+	"blog/config"
+	"blog/internal/oauth/oauthtest"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+const redirectURL = "http://localhost:3000/auth/oauth/test/callback"
+
+var testUser = oauthtest.User{ID: 42, Login: "octocat", Name: "The Octocat", Email: "octocat@example.com", EmailVerified: true}
+
+func TestGitHubProvider(t *testing.T) {
+	server := oauthtest.NewServer(testUser)
+	defer server.Close()
+
+	provider := NewGitHubProvider("github", config.OAuthProviderConfig{
+		ClientID:     oauthtest.ClientID,
+		ClientSecret: oauthtest.ClientSecret,
+		RedirectURL:  redirectURL,
+		AuthURL:      server.URL + "/authorize",
+		TokenURL:     server.URL + "/token",
+		APIURL:       server.URL,
+	})
+	ctx := context.Background()
+	verifier := oauth2.GenerateVerifier()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", verifier, "")
+	require.NoError(t, err)
+	code, state, err := server.Authorize(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, "state-1", state)
+
+	// PKCE 校验码不匹配时换取失败
+	_, err = provider.Exchange(ctx, code, oauth2.GenerateVerifier(), "")
+	assert.Error(t, err)
+
+	code, _, err = server.Authorize(authURL)
+	require.NoError(t, err)
+	identity, err := provider.Exchange(ctx, code, verifier, "")
+	require.NoError(t, err)
+	assert.Equal(t, "github", identity.Provider)
+	assert.Equal(t, "42", identity.Subject)
+	assert.Equal(t, "octocat", identity.Username)
+	// 取主邮箱而不是第一个邮箱
+	assert.Equal(t, "octocat@example.com", identity.Email)
+	assert.True(t, identity.EmailVerified)
+}
+
+func TestOIDCProvider(t *testing.T) {
+	server := oauthtest.NewServer(testUser)
+	defer server.Close()
+
+	provider := NewOIDCProvider("sso", config.OAuthProviderConfig{
+		ClientID:     oauthtest.ClientID,
+		ClientSecret: oauthtest.ClientSecret,
+		Issuer:       server.URL,
+		RedirectURL:  redirectURL,
+	})
+	ctx := context.Background()
+	verifier := oauth2.GenerateVerifier()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", verifier, "nonce-1")
+	require.NoError(t, err)
+	code, _, err := server.Authorize(authURL)
+	require.NoError(t, err)
+
+	identity, err := provider.Exchange(ctx, code, verifier, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, "42", identity.Subject)
+	assert.Equal(t, "octocat@example.com", identity.Email)
+	assert.True(t, identity.EmailVerified)
+	assert.Equal(t, "octocat", identity.Username)
+
+	// nonce 不一致说明 ID Token 不是本次授权签发的
+	code, _, err = server.Authorize(authURL)
+	require.NoError(t, err)
+	_, err = provider.Exchange(ctx, code, verifier, "other-nonce")
+	assert.ErrorContains(t, err, "nonce")
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry(NewGitHubProvider("github", config.OAuthProviderConfig{}), NewOIDCProvider("google", config.OAuthProviderConfig{}))
+	assert.Equal(t, []string{"github", "google"}, registry.Names())
+
+	_, ok := registry.Get("github")
+	assert.True(t, ok)
+	_, ok = registry.Get("gitlab")
+	assert.False(t, ok)
+}
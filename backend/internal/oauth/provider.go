@@ -0,0 +1,101 @@
+// Package oauth 封装第三方 OAuth2 / OIDC 登录，授权码流程统一使用 PKCE
+package oauth
+
+import (
+	"blog/config"
+	"context"
+	"log"
+	"sort"
+	"strings"
+)
+
+// Identity 第三方账号信息
+type Identity struct {
+	Provider      string
+	Subject       string // 提供方内唯一且不变的用户 ID
+	Email         string
+	EmailVerified bool
+	Username      string
+	Name          string
+	AvatarURL     string
+}
+
+// Provider 第三方登录提供方
+type Provider interface {
+	// Name 配置中的提供方名称，如 github
+	Name() string
+	// AuthCodeURL 生成授权地址，verifier 为 PKCE 校验码，nonce 仅 OIDC 使用
+	AuthCodeURL(ctx context.Context, state, verifier, nonce string) (string, error)
+	// Exchange 用授权码换取令牌并获取第三方账号信息
+	Exchange(ctx context.Context, code, verifier, nonce string) (*Identity, error)
+}
+
+// Registry 已启用的提供方
+type Registry struct {
+	providers map[string]Provider
+}
+
+// NewRegistry 创建提供方注册表
+func NewRegistry(providers ...Provider) *Registry {
+	r := &Registry{providers: make(map[string]Provider, len(providers))}
+	for _, p := range providers {
+		r.providers[p.Name()] = p
+	}
+	return r
+}
+
+// Get 按名称获取提供方
+func (r *Registry) Get(name string) (Provider, bool) {
+	if r == nil {
+		return nil, false
+	}
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// Names 已启用的提供方名称，按字母排序
+func (r *Registry) Names() []string {
+	if r == nil {
+		return []string{}
+	}
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var registry *Registry
+
+// InitProviders 根据配置初始化提供方，未配置 client_id 的提供方不启用
+func InitProviders() {
+	cfg := config.AppConfig.OAuth
+	var providers []Provider
+	for name, pc := range cfg.Providers {
+		if pc.ClientID == "" {
+			continue
+		}
+		if pc.RedirectURL == "" {
+			pc.RedirectURL = strings.TrimRight(cfg.RedirectBaseURL, "/") + "/" + name + "/callback"
+		}
+
+		switch pc.Type {
+		case "github":
+			providers = append(providers, NewGitHubProvider(name, pc))
+		case "oidc":
+			providers = append(providers, NewOIDCProvider(name, pc))
+		default:
+			log.Fatalf("Unsupported oauth provider type %q for %s", pc.Type, name)
+		}
+	}
+	registry = NewRegistry(providers...)
+}
+
+// GetRegistry 获取提供方注册表，未初始化时为空
+func GetRegistry() *Registry {
+	if registry == nil {
+		registry = NewRegistry()
+	}
+	return registry
+}
@@ -0,0 +1,228 @@
+// Package outbox 发布与业务数据在同一事务内写入 outbox 表的事件。
+// 事件发布成功后才标记完成，中继崩溃或发布失败时会重新发布（至少一次），订阅方按事件 ID 去重
+package outbox
+
+import (
+	"blog/config"
+	"blog/internal/events"
+	"blog/internal/model"
+	"blog/internal/repository"
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 100
+	defaultBaseBackoff  = 5 * time.Second
+	defaultMaxBackoff   = 10 * time.Minute
+	defaultRetention    = 7 * 24 * time.Hour
+	defaultLease        = time.Minute
+	pruneInterval       = time.Hour
+	pruneBatchSize      = 1000
+	maxErrorLength      = 500
+)
+
+// Store 读取待发布的事件并保存发布结果
+type Store interface {
+	ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.OutboxMessage, error)
+	MarkPublished(ctx context.Context, id uint, at time.Time) error
+	MarkFailed(ctx context.Context, id uint, attempts int, next time.Time, lastError string) error
+	DeletePublishedBefore(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// Options 中继配置
+type Options struct {
+	PollInterval time.Duration
+	BatchSize    int
+	BaseBackoff  time.Duration // 第一次重试的等待时间，之后每次翻倍
+	MaxBackoff   time.Duration
+	Retention    time.Duration // 已发布事件的保留时间
+	Lease        time.Duration // 取出的事件在该时间内不会被其他实例再次取出
+}
+
+// Relay 轮询 outbox 表，按写入顺序把事件发布到 Sink
+type Relay struct {
+	store Store
+	sink  Sink
+	opts  Options
+	now   func() time.Time
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewRelay 创建中继
+func NewRelay(store Store, sink Sink, opts Options) *Relay {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	if opts.BaseBackoff <= 0 {
+		opts.BaseBackoff = defaultBaseBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = defaultMaxBackoff
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = defaultRetention
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = defaultLease
+	}
+	return &Relay{
+		store: store,
+		sink:  sink,
+		opts:  opts,
+		now:   time.Now,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+}
+
+// Run 在后台发布事件并定期清理已发布的事件，直到调用 Close
+func (r *Relay) Run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.opts.PollInterval)
+	defer ticker.Stop()
+	prune := time.NewTicker(pruneInterval)
+	defer prune.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := r.PublishPending(context.Background()); err != nil {
+				log.Printf("[ERROR] failed to claim outbox events: %v", err)
+			}
+		case <-prune.C:
+			if _, err := r.Prune(context.Background()); err != nil {
+				log.Printf("[ERROR] failed to prune outbox events: %v", err)
+			}
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// Close 停止中继，正在发布的一批事件处理完后返回，ctx 结束时不再等待
+func (r *Relay) Close(ctx context.Context) error {
+	r.stopOnce.Do(func() { close(r.stop) })
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// PublishPending 发布所有到期的事件，返回处理的事件数
+func (r *Relay) PublishPending(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		batch, err := r.store.ClaimPending(ctx, r.now(), r.opts.Lease, r.opts.BatchSize)
+		if err != nil {
+			return total, err
+		}
+		for i := range batch {
+			r.publish(ctx, &batch[i])
+		}
+
+		total += len(batch)
+		if len(batch) < r.opts.BatchSize {
+			return total, nil
+		}
+	}
+}
+
+// publish 发布一条事件并保存结果；标记失败时事件在租约到期后重新发布
+func (r *Relay) publish(ctx context.Context, message *model.OutboxMessage) {
+	event, err := decode(message)
+	if err == nil {
+		err = r.sink.Publish(ctx, event)
+	}
+
+	if err == nil {
+		if err := r.store.MarkPublished(ctx, message.ID, r.now()); err != nil {
+			log.Printf("[ERROR] failed to mark outbox event %s published: %v", message.EventID, err)
+		}
+		return
+	}
+
+	attempts := message.Attempts + 1
+	log.Printf("[WARN] failed to publish outbox event %s %s (attempt %d): %v", message.EventType, message.EventID, attempts, err)
+	next := r.now().Add(r.Backoff(attempts))
+	if err := r.store.MarkFailed(ctx, message.ID, attempts, next, truncate(err.Error())); err != nil {
+		log.Printf("[ERROR] failed to save outbox event %s: %v", message.EventID, err)
+	}
+}
+
+// Prune 删除超过保留时间的已发布事件，返回删除的条数
+func (r *Relay) Prune(ctx context.Context) (int64, error) {
+	before := r.now().Add(-r.opts.Retention)
+	var total int64
+	for {
+		n, err := r.store.DeletePublishedBefore(ctx, before, pruneBatchSize)
+		total += n
+		if err != nil || n < pruneBatchSize {
+			return total, err
+		}
+	}
+}
+
+// Backoff 第 attempt 次失败后的等待时间
+func (r *Relay) Backoff(attempt int) time.Duration {
+	backoff := r.opts.BaseBackoff
+	for i := 1; i < attempt && backoff < r.opts.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, r.opts.MaxBackoff)
+}
+
+// decode 还原写入时的事件，Data 保持原始 JSON，再次序列化时内容不变
+func decode(message *model.OutboxMessage) (events.Event, error) {
+	var data json.RawMessage
+	event := events.Event{Data: &data}
+	if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+		return events.Event{}, err
+	}
+	event.OwnerID = message.OwnerID
+	return event, nil
+}
+
+// truncate 按字节截断并去掉被截断的不完整字符
+func truncate(s string) string {
+	if len(s) > maxErrorLength {
+		s = s[:maxErrorLength]
+	}
+	return strings.ToValidUTF8(s, "")
+}
+
+var relay *Relay
+
+// InitRelay 根据配置创建全局中继，事件发布到全局事件总线
+func InitRelay() {
+	cfg := config.AppConfig.Outbox
+	relay = NewRelay(repository.NewOutboxRepository(), events.GetBus(), Options{
+		PollInterval: cfg.PollInterval,
+		BatchSize:    cfg.BatchSize,
+		BaseBackoff:  cfg.BaseBackoff,
+		MaxBackoff:   cfg.MaxBackoff,
+		Retention:    cfg.Retention,
+	})
+}
+
+// GetRelay 获取全局中继，未初始化时使用默认配置创建
+func GetRelay() *Relay {
+	if relay == nil {
+		InitRelay()
+	}
+	return relay
+}
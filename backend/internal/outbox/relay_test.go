@@ -0,0 +1,180 @@
+package outbox
+
+import (
+	"blog/internal/events"
+	"blog/internal/model"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore 内存中的 outbox 表
+type memoryStore struct {
+	mu       sync.Mutex
+	messages []model.OutboxMessage
+}
+
+func (s *memoryStore) add(t *testing.T, event events.Event) {
+	payload, err := json.Marshal(event)
+	require.NoError(t, err)
+	s.messages = append(s.messages, model.OutboxMessage{
+		ID:            uint(len(s.messages) + 1),
+		EventID:       event.ID,
+		EventType:     event.Type,
+		OwnerID:       event.OwnerID,
+		Payload:       string(payload),
+		NextAttemptAt: event.OccurredAt,
+	})
+}
+
+func (s *memoryStore) ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.OutboxMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []model.OutboxMessage
+	for i := range s.messages {
+		m := &s.messages[i]
+		if m.PublishedAt == nil && !m.NextAttemptAt.After(now) && len(due) < limit {
+			due = append(due, *m)
+			m.NextAttemptAt = now.Add(lease)
+		}
+	}
+	return due, nil
+}
+
+func (s *memoryStore) MarkPublished(ctx context.Context, id uint, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages[id-1].PublishedAt = &at
+	s.messages[id-1].LastError = ""
+	return nil
+}
+
+func (s *memoryStore) MarkFailed(ctx context.Context, id uint, attempts int, next time.Time, lastError string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages[id-1].Attempts = attempts
+	s.messages[id-1].NextAttemptAt = next
+	s.messages[id-1].LastError = lastError
+	return nil
+}
+
+func (s *memoryStore) DeletePublishedBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var kept []model.OutboxMessage
+	var deleted int64
+	for _, m := range s.messages {
+		if m.PublishedAt != nil && m.PublishedAt.Before(before) && deleted < int64(limit) {
+			deleted++
+			continue
+		}
+		kept = append(kept, m)
+	}
+	s.messages = kept
+	return deleted, nil
+}
+
+func TestRelay_PublishPending(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	store := &memoryStore{}
+	sink := NewMemorySink()
+	relay := NewRelay(store, sink, Options{BatchSize: 2, BaseBackoff: time.Second, MaxBackoff: 3 * time.Second})
+	relay.now = func() time.Time { return now }
+
+	first := events.New(events.ArticleCreated, 7, events.ArticleData{ID: 1, Title: "hello"})
+	first.OccurredAt = now
+	second := events.New(events.ArticlePublished, 7, events.ArticleData{ID: 1})
+	second.OccurredAt = now
+	third := events.New(events.UserRegistered, 8, events.UserData{ID: 8})
+	third.OccurredAt = now
+	for _, event := range []events.Event{first, second, third} {
+		store.add(t, event)
+	}
+
+	t.Run("按写入顺序发布并还原事件", func(t *testing.T) {
+		n, err := relay.PublishPending(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 3, n)
+
+		published := sink.Events()
+		require.Len(t, published, 3)
+		assert.Equal(t, []string{first.ID, second.ID, third.ID}, []string{published[0].ID, published[1].ID, published[2].ID})
+		assert.Equal(t, uint(7), published[0].OwnerID)
+		assert.Equal(t, first.OccurredAt, published[0].OccurredAt)
+
+		// 重新序列化后与写入时的内容一致
+		original, _ := json.Marshal(first)
+		restored, _ := json.Marshal(published[0])
+		assert.JSONEq(t, string(original), string(restored))
+
+		n, _ = relay.PublishPending(context.Background())
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("发布失败时退避后重新发布", func(t *testing.T) {
+		event := events.New(events.ArticleDeleted, 7, events.ArticleData{ID: 1})
+		event.OccurredAt = now
+		store.add(t, event)
+
+		sink.SetError(errors.New("subscriber down"))
+		_, err := relay.PublishPending(context.Background())
+		require.NoError(t, err)
+		message := store.messages[3]
+		assert.Nil(t, message.PublishedAt)
+		assert.Equal(t, 1, message.Attempts)
+		assert.Equal(t, "subscriber down", message.LastError)
+		assert.Equal(t, now.Add(time.Second), message.NextAttemptAt)
+
+		// 未到重试时间不会再次发布
+		n, _ := relay.PublishPending(context.Background())
+		assert.Equal(t, 0, n)
+
+		sink.SetError(nil)
+		now = now.Add(time.Second)
+		n, err = relay.PublishPending(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.NotNil(t, store.messages[3].PublishedAt)
+		assert.Equal(t, event.ID, sink.Events()[3].ID)
+	})
+
+	t.Run("清理超过保留时间的已发布事件", func(t *testing.T) {
+		store.add(t, events.New(events.ArticleUpdated, 7, nil))
+
+		now = now.Add(defaultRetention + time.Second)
+		n, err := relay.Prune(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), n)
+		// 未发布的事件不会被清理
+		require.Len(t, store.messages, 1)
+		assert.Nil(t, store.messages[0].PublishedAt)
+	})
+}
+
+func TestRelay_Backoff(t *testing.T) {
+	relay := NewRelay(&memoryStore{}, NewMemorySink(), Options{BaseBackoff: time.Second, MaxBackoff: 5 * time.Second})
+	assert.Equal(t, time.Second, relay.Backoff(1))
+	assert.Equal(t, 2*time.Second, relay.Backoff(2))
+	assert.Equal(t, 4*time.Second, relay.Backoff(3))
+	assert.Equal(t, 5*time.Second, relay.Backoff(4))
+}
+
+func TestRelay_RunAndClose(t *testing.T) {
+	store := &memoryStore{}
+	sink := NewMemorySink()
+	store.add(t, events.New(events.ArticleCreated, 1, nil))
+	relay := NewRelay(store, sink, Options{PollInterval: 10 * time.Millisecond})
+
+	go relay.Run()
+	require.Eventually(t, func() bool { return len(sink.Events()) == 1 }, time.Second, 5*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, relay.Close(ctx))
+}
@@ -0,0 +1,50 @@
+package outbox
+
+import (
+	"blog/internal/events"
+	"context"
+	"sync"
+)
+
+// Sink 中继发布事件的目标，返回错误时事件稍后重新发布。
+// 同一事件可能被发布多次，实现方应按事件 ID 去重；*events.Bus 即为进程内的实现
+type Sink interface {
+	Publish(ctx context.Context, event events.Event) error
+}
+
+// MemorySink 把事件保存在内存中，用于测试
+type MemorySink struct {
+	mu     sync.Mutex
+	events []events.Event
+	err    error
+}
+
+// NewMemorySink 创建内存中的发布目标
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+// Publish 记录事件，设置了错误时返回该错误且不记录
+func (s *MemorySink) Publish(ctx context.Context, event events.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.events = append(s.events, event)
+	return nil
+}
+
+// SetError 之后的发布都返回 err，传入 nil 恢复正常
+func (s *MemorySink) SetError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// Events 按发布顺序返回收到的事件，包括重复发布的
+func (s *MemorySink) Events() []events.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]events.Event(nil), s.events...)
+}
@@ -0,0 +1,80 @@
+package password
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcryptMaxBytes bcrypt 只处理前 72 个字节，超出部分直接拒绝而不是静默截断
+const bcryptMaxBytes = 72
+
+// ErrMismatch 密码与哈希不匹配
+var ErrMismatch = errors.New("password mismatch")
+
+// Hasher 密码哈希算法
+type Hasher interface {
+	// Hash 生成密码哈希
+	Hash(password string) (string, error)
+	// Compare 校验密码，不匹配时返回 ErrMismatch
+	Compare(hash, password string) error
+	// NeedsRehash 哈希参数低于当前配置时返回 true，登录成功后据此透明升级
+	NeedsRehash(hash string) bool
+}
+
+// BcryptHasher 使用 bcrypt 的哈希算法，cost 可配置
+type BcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher 创建 bcrypt 哈希算法，cost 为 0 时使用默认值，超出范围时取边界值
+func NewBcryptHasher(cost int) *BcryptHasher {
+	switch {
+	case cost == 0:
+		cost = bcrypt.DefaultCost
+	case cost < bcrypt.MinCost:
+		cost = bcrypt.MinCost
+	case cost > bcrypt.MaxCost:
+		cost = bcrypt.MaxCost
+	}
+	return &BcryptHasher{cost: cost}
+}
+
+// Cost 当前使用的 cost
+func (h *BcryptHasher) Cost() int {
+	return h.cost
+}
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	if len(password) > bcryptMaxBytes {
+		return "", ErrTooLong.WithArgs(bcryptMaxBytes)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (h *BcryptHasher) Compare(hash, password string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) || errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return ErrMismatch
+	}
+	// 哈希格式损坏等情况同样视为不匹配，但保留原因便于排查
+	return errors.Join(ErrMismatch, err)
+}
+
+func (h *BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return false
+	}
+	return cost < h.cost
+}
+
+// 编译期检查
+var _ Hasher = (*BcryptHasher)(nil)
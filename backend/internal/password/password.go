@@ -0,0 +1,65 @@
+package password
+
+import (
+	"blog/config"
+	"blog/internal/apperr"
+	"log"
+)
+
+// 未配置时的默认策略
+const (
+	defaultMinLength = 8
+	defaultMaxLength = 64
+)
+
+// 密码策略相关错误，文案参数见各自的 WithArgs 调用
+var (
+	ErrTooShort = apperr.New(apperr.ErrValidation, "password_too_short", "密码长度不能少于 %d 位")
+	ErrTooLong  = apperr.New(apperr.ErrValidation, "password_too_long", "密码长度不能超过 %d 位")
+	ErrTooWeak  = apperr.New(apperr.ErrValidation, "password_too_weak", "密码需至少包含大写字母、小写字母、数字、符号中的 %d 类")
+	ErrBreached = apperr.New(apperr.ErrValidation, "password_breached", "该密码过于常见或已在泄露的密码库中出现，请更换")
+)
+
+var (
+	hasher Hasher
+	policy *Policy
+)
+
+// Init 根据配置初始化哈希算法和密码策略
+func Init() {
+	cfg := config.AppConfig.Password
+	hasher = NewBcryptHasher(cfg.HashCost)
+
+	policy = &Policy{
+		MinLength:  cfg.MinLength,
+		MaxLength:  cfg.MaxLength,
+		MinClasses: cfg.MinCharClasses,
+	}
+	if policy.MinLength <= 0 {
+		policy.MinLength = defaultMinLength
+	}
+	if policy.MaxLength <= 0 {
+		policy.MaxLength = defaultMaxLength
+	}
+	if cfg.DenylistFile != "" {
+		if err := policy.LoadDenylist(cfg.DenylistFile); err != nil {
+			log.Fatalf("Failed to load password denylist: %v", err)
+		}
+	}
+}
+
+// GetHasher 获取哈希算法，未初始化时使用默认 cost 的 bcrypt
+func GetHasher() Hasher {
+	if hasher == nil {
+		hasher = NewBcryptHasher(0)
+	}
+	return hasher
+}
+
+// GetPolicy 获取密码策略，未初始化时只限制默认长度
+func GetPolicy() *Policy {
+	if policy == nil {
+		policy = &Policy{MinLength: defaultMinLength, MaxLength: defaultMaxLength}
+	}
+	return policy
+}
@@ -0,0 +1,87 @@
+package password
+
+import (
+	"blog/internal/apperr"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPolicy_Validate(t *testing.T) {
+	policy := &Policy{MinLength: 8, MaxLength: 20, MinClasses: 3}
+	policy.SetDenylist([]string{"Passw0rd!", "  ", "Qwerty123!"})
+
+	tests := []struct {
+		password string
+		want     error
+	}{
+		{"Ab1!", ErrTooShort},
+		{strings.Repeat("Ab1!", 6), ErrTooLong},
+		{"abcdefgh1", ErrTooWeak},
+		{"passw0rd!", ErrBreached}, // 忽略大小写
+		{"QWERTY123!", ErrBreached},
+		{"Correct-horse1", nil},
+		{"中文密码Abc123", nil}, // 中文按字符计数，并计为小写字母类
+	}
+	for _, tt := range tests {
+		err := policy.Validate(tt.password)
+		if tt.want == nil {
+			assert.NoError(t, err, tt.password)
+			continue
+		}
+		assert.ErrorIs(t, err, tt.want, tt.password)
+		assert.ErrorIs(t, err, apperr.ErrValidation, tt.password)
+	}
+
+	// 错误携带提示中的参数
+	var appErr *apperr.Error
+	require.ErrorAs(t, policy.Validate("short"), &appErr)
+	assert.Equal(t, []interface{}{8}, appErr.Args)
+
+	// nil 策略不做限制
+	var none *Policy
+	assert.NoError(t, none.Validate(""))
+}
+
+func TestPolicy_LoadDenylist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "denylist.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# comment\nletmein\n\nDragon2024\n"), 0o600))
+
+	policy := &Policy{}
+	require.NoError(t, policy.LoadDenylist(path))
+	assert.ErrorIs(t, policy.Validate("LetMeIn"), ErrBreached)
+	assert.ErrorIs(t, policy.Validate("dragon2024"), ErrBreached)
+	assert.NoError(t, policy.Validate("# comment"))
+
+	assert.Error(t, policy.LoadDenylist(filepath.Join(t.TempDir(), "missing.txt")))
+}
+
+func TestBcryptHasher(t *testing.T) {
+	low := NewBcryptHasher(bcrypt.MinCost)
+	high := NewBcryptHasher(bcrypt.MinCost + 1)
+
+	hash, err := low.Hash("secret")
+	require.NoError(t, err)
+	assert.NoError(t, low.Compare(hash, "secret"))
+	assert.ErrorIs(t, low.Compare(hash, "wrong"), ErrMismatch)
+	assert.ErrorIs(t, low.Compare("not-a-hash", "secret"), ErrMismatch)
+
+	// cost 调高后旧哈希需要升级，反之不需要
+	assert.False(t, low.NeedsRehash(hash))
+	assert.True(t, high.NeedsRehash(hash))
+	highHash, err := high.Hash("secret")
+	require.NoError(t, err)
+	assert.False(t, low.NeedsRehash(highHash))
+
+	// 超过 bcrypt 上限的密码直接拒绝而不是截断
+	_, err = low.Hash(strings.Repeat("a", 73))
+	assert.ErrorIs(t, err, ErrTooLong)
+
+	assert.Equal(t, bcrypt.DefaultCost, NewBcryptHasher(0).Cost())
+	assert.Equal(t, bcrypt.MaxCost, NewBcryptHasher(100).Cost())
+}
@@ -0,0 +1,108 @@
+package password
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 字符类别总数：大写字母、小写字母、数字、符号
+const charClassCount = 4
+
+// Policy 密码强度策略，零值不做任何限制
+type Policy struct {
+	MinLength  int // 最少字符数
+	MaxLength  int // 最多字符数，0 表示不限制
+	MinClasses int // 至少包含的字符类别数
+	denylist   map[string]struct{}
+}
+
+// SetDenylist 设置禁用密码列表，比较时忽略大小写
+func (p *Policy) SetDenylist(passwords []string) {
+	p.denylist = make(map[string]struct{}, len(passwords))
+	for _, pw := range passwords {
+		if pw = strings.TrimSpace(pw); pw != "" {
+			p.denylist[strings.ToLower(pw)] = struct{}{}
+		}
+	}
+}
+
+// LoadDenylist 从本地文件加载禁用密码列表，每行一个，# 开头的行为注释
+func (p *Policy) LoadDenylist(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var passwords []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords = append(passwords, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	p.SetDenylist(passwords)
+	return nil
+}
+
+// Validate 校验密码是否符合策略，nil 策略视为不限制
+func (p *Policy) Validate(password string) error {
+	if p == nil {
+		return nil
+	}
+
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		return ErrTooShort.WithArgs(p.MinLength)
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		return ErrTooLong.WithArgs(p.MaxLength)
+	}
+
+	minClasses := p.MinClasses
+	if minClasses > charClassCount {
+		minClasses = charClassCount
+	}
+	if charClasses(password) < minClasses {
+		return ErrTooWeak.WithArgs(minClasses)
+	}
+
+	if _, ok := p.denylist[strings.ToLower(password)]; ok {
+		return ErrBreached
+	}
+	return nil
+}
+
+// charClasses 统计密码包含的字符类别数，无大小写之分的文字计为小写字母
+func charClasses(password string) int {
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLetter(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	count := 0
+	for _, has := range []bool{upper, lower, digit, symbol} {
+		if has {
+			count++
+		}
+	}
+	return count
+}
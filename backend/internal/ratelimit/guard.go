@@ -0,0 +1,136 @@
+package ratelimit
+
+import (
+	"blog/config"
+	"blog/internal/apperr"
+	"context"
+	"log"
+	"strings"
+	"time"
+)
+
+// 限流错误
+var (
+	ErrTooManyRequests = apperr.New(apperr.ErrTooManyRequests, "too_many_requests", "请求过于频繁，请稍后再试")
+	ErrAccountLocked   = apperr.New(apperr.ErrTooManyRequests, "account_locked", "登录失败次数过多，账号已临时锁定")
+)
+
+// LimitError 被限流时返回的错误，携带建议的重试等待时长
+type LimitError struct {
+	Err  *apperr.Error
+	Wait time.Duration
+}
+
+func (e *LimitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *LimitError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfter 建议客户端等待的时长
+func (e *LimitError) RetryAfter() time.Duration {
+	return e.Wait
+}
+
+// LockoutPolicy 连续登录失败后的锁定策略
+type LockoutPolicy struct {
+	MaxFailures  int           // 触发锁定的失败次数
+	BaseDuration time.Duration // 首次锁定时长，此后每次失败翻倍
+	MaxDuration  time.Duration // 锁定时长上限
+	Window       time.Duration // 失败次数的统计窗口
+}
+
+// lockDuration 计算第 failures 次失败后的锁定时长，未达到阈值时为 0
+func (p LockoutPolicy) lockDuration(failures int) time.Duration {
+	if p.MaxFailures <= 0 || failures < p.MaxFailures {
+		return 0
+	}
+	d := p.BaseDuration
+	for i := p.MaxFailures; i < failures; i++ {
+		d *= 2
+		if p.MaxDuration > 0 && d >= p.MaxDuration {
+			return p.MaxDuration
+		}
+	}
+	return d
+}
+
+// LoginGuard 按账号对登录进行限流和失败锁定
+type LoginGuard struct {
+	store  Store
+	limit  Limit
+	policy LockoutPolicy
+}
+
+// NewLoginGuard 创建登录防护
+func NewLoginGuard(store Store, limit Limit, policy LockoutPolicy) *LoginGuard {
+	return &LoginGuard{store: store, limit: limit, policy: policy}
+}
+
+// NewDefaultLoginGuard 使用配置创建登录防护
+func NewDefaultLoginGuard() *LoginGuard {
+	cfg := config.AppConfig.RateLimit
+	return NewLoginGuard(
+		GetStore(),
+		PerMinute(cfg.Account.RequestsPerMinute, cfg.Account.Burst),
+		LockoutPolicy{
+			MaxFailures:  cfg.Lockout.MaxFailures,
+			BaseDuration: cfg.Lockout.BaseDuration,
+			MaxDuration:  cfg.Lockout.MaxDuration,
+			Window:       cfg.Lockout.Window,
+		},
+	)
+}
+
+// Check 登录前检查账号是否被锁定或超出频率限制
+// 存储不可用时放行，避免限流组件故障导致无法登录
+func (g *LoginGuard) Check(ctx context.Context, account string) error {
+	key := accountKey(account)
+
+	locked, err := g.store.LockedFor(ctx, key)
+	if err != nil {
+		log.Printf("[WARN] rate limit store unavailable: %v", err)
+		return nil
+	}
+	if locked > 0 {
+		return &LimitError{Err: ErrAccountLocked, Wait: locked}
+	}
+
+	if !g.limit.Enabled() {
+		return nil
+	}
+	allowed, wait, err := g.store.Allow(ctx, key, g.limit)
+	if err != nil {
+		log.Printf("[WARN] rate limit store unavailable: %v", err)
+		return nil
+	}
+	if !allowed {
+		return &LimitError{Err: ErrTooManyRequests, Wait: wait}
+	}
+	return nil
+}
+
+// Failed 记录一次登录失败，达到阈值后按指数退避锁定账号
+func (g *LoginGuard) Failed(ctx context.Context, account string) error {
+	key := accountKey(account)
+
+	failures, err := g.store.Fail(ctx, key, g.policy.Window)
+	if err != nil {
+		return err
+	}
+	if d := g.policy.lockDuration(failures); d > 0 {
+		return g.store.Lock(ctx, key, d)
+	}
+	return nil
+}
+
+// Succeeded 登录成功后清除失败记录
+func (g *LoginGuard) Succeeded(ctx context.Context, account string) error {
+	return g.store.Reset(ctx, accountKey(account))
+}
+
+func accountKey(account string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(account))
+}
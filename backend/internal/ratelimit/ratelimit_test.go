@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+	"blog/internal/apperr"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_Allow(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	limit := Limit{Rate: 1, Burst: 2}
+
+	// 桶容量内的请求放行
+	for i := 0; i < 2; i++ {
+		allowed, _, err := s.Allow(ctx, "k", limit)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+
+	// 超出容量被拒绝并给出等待时长
+	allowed, wait, err := s.Allow(ctx, "k", limit)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, time.Second, wait)
+
+	// 补充令牌后再次放行
+	now = now.Add(time.Second)
+	allowed, _, err = s.Allow(ctx, "k", limit)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	// 不同 key 互不影响
+	allowed, _, err = s.Allow(ctx, "other", limit)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestLockoutPolicy(t *testing.T) {
+	p := LockoutPolicy{MaxFailures: 3, BaseDuration: time.Minute, MaxDuration: 10 * time.Minute}
+
+	assert.Equal(t, time.Duration(0), p.lockDuration(2))
+	assert.Equal(t, time.Minute, p.lockDuration(3))
+	assert.Equal(t, 2*time.Minute, p.lockDuration(4))
+	assert.Equal(t, 8*time.Minute, p.lockDuration(6))
+	assert.Equal(t, 10*time.Minute, p.lockDuration(7))
+	assert.Equal(t, 10*time.Minute, p.lockDuration(50))
+}
+
+// testLoginGuard 在给定存储上验证登录防护的行为
+func testLoginGuard(t *testing.T, store Store) {
+	ctx := context.Background()
+	guard := NewLoginGuard(store, Limit{Rate: 100, Burst: 100}, LockoutPolicy{
+		MaxFailures:  2,
+		BaseDuration: time.Minute,
+		MaxDuration:  time.Hour,
+		Window:       time.Hour,
+	})
+
+	require.NoError(t, guard.Check(ctx, "User@Example.com"))
+	require.NoError(t, guard.Failed(ctx, "user@example.com"))
+	require.NoError(t, guard.Check(ctx, "user@example.com"))
+	require.NoError(t, guard.Failed(ctx, "user@example.com"))
+
+	// 达到阈值后账号被锁定，账号名不区分大小写
+	err := guard.Check(ctx, "USER@example.com")
+	var limitErr *LimitError
+	require.ErrorAs(t, err, &limitErr)
+	assert.ErrorIs(t, err, ErrAccountLocked)
+	assert.ErrorIs(t, err, apperr.ErrTooManyRequests)
+	assert.InDelta(t, time.Minute, limitErr.RetryAfter(), float64(time.Second))
+
+	// 其他账号不受影响
+	require.NoError(t, guard.Check(ctx, "other@example.com"))
+
+	// 成功登录后清除锁定
+	require.NoError(t, guard.Succeeded(ctx, "user@example.com"))
+	require.NoError(t, guard.Check(ctx, "user@example.com"))
+}
+
+func TestLoginGuard_MemoryStore(t *testing.T) {
+	testLoginGuard(t, NewMemoryStore())
+}
+
+func TestLoginGuard_RedisStore(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	testLoginGuard(t, NewRedisStore(client))
+}
+
+func TestLoginGuard_AccountLimit(t *testing.T) {
+	ctx := context.Background()
+	guard := NewLoginGuard(NewMemoryStore(), Limit{Rate: 0.1, Burst: 1}, LockoutPolicy{})
+
+	require.NoError(t, guard.Check(ctx, "user@example.com"))
+	err := guard.Check(ctx, "user@example.com")
+	assert.ErrorIs(t, err, ErrTooManyRequests)
+}
+
+func TestRedisStore_Allow(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	s := NewRedisStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+
+	limit := Limit{Rate: 0.5, Burst: 2}
+	for i := 0; i < 2; i++ {
+		allowed, _, err := s.Allow(ctx, "k", limit)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+
+	allowed, wait, err := s.Allow(ctx, "k", limit)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.InDelta(t, 2*time.Second, wait, float64(100*time.Millisecond))
+}
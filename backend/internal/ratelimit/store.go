@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"blog/config"
+	"context"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Limit 令牌桶参数
+type Limit struct {
+	Rate  float64 // 每秒补充的令牌数
+	Burst int     // 桶容量
+}
+
+// PerMinute 按每分钟请求数构造令牌桶参数，burst 不大于 0 时等于每分钟请求数
+func PerMinute(requests, burst int) Limit {
+	if burst <= 0 {
+		burst = requests
+	}
+	return Limit{Rate: float64(requests) / 60, Burst: burst}
+}
+
+// Enabled 是否启用限流
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// Store 限流和锁定状态的存储
+type Store interface {
+	// Allow 从 key 对应的令牌桶中取一个令牌，拒绝时返回需要等待的时长
+	Allow(ctx context.Context, key string, limit Limit) (bool, time.Duration, error)
+	// Fail 记录一次失败，返回 window 内累计的失败次数
+	Fail(ctx context.Context, key string, window time.Duration) (int, error)
+	// Lock 锁定 key 一段时间
+	Lock(ctx context.Context, key string, d time.Duration) error
+	// LockedFor 返回剩余锁定时长，未锁定时为 0
+	LockedFor(ctx context.Context, key string) (time.Duration, error)
+	// Reset 清除 key 的失败次数和锁定状态
+	Reset(ctx context.Context, key string) error
+}
+
+var store Store
+
+// InitStore 根据配置初始化限流存储
+func InitStore() {
+	switch config.AppConfig.RateLimit.Store {
+	case "redis":
+		client := redis.NewClient(&redis.Options{
+			Addr:     config.AppConfig.Redis.Addr,
+			Password: config.AppConfig.Redis.Password,
+			DB:       config.AppConfig.Redis.DB,
+		})
+		if err := client.Ping(context.Background()).Err(); err != nil {
+			log.Fatalf("Failed to connect to redis: %v", err)
+		}
+		store = NewRedisStore(client)
+	default:
+		store = NewMemoryStore()
+	}
+}
+
+// GetStore 获取限流存储，未初始化时使用内存存储
+func GetStore() Store {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return store
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"blog/internal/model"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type APITokenRepository struct {
+	db *gorm.DB
+}
+
+func (r *APITokenRepository) Create(ctx context.Context, token *model.APIToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+// FindByHash 按哈希查找令牌，包括已撤销和已过期的令牌，不存在时返回 nil
+func (r *APITokenRepository) FindByHash(ctx context.Context, tokenHash string) (*model.APIToken, error) {
+	var token model.APIToken
+	err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ListActiveByUser 列出用户未撤销的令牌，已过期的令牌仍会列出以便用户清理
+func (r *APITokenRepository) ListActiveByUser(ctx context.Context, userID uint) ([]model.APIToken, error) {
+	var tokens []model.APIToken
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+// CountActiveByUser 统计用户未撤销的令牌数量
+func (r *APITokenRepository) CountActiveByUser(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.APIToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// Revoke 撤销用户自己的令牌，令牌不存在或已撤销时返回 false
+func (r *APITokenRepository) Revoke(ctx context.Context, id, userID uint) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.APIToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// RevokeAllByUser 撤销用户全部未撤销的令牌，返回撤销的数量
+func (r *APITokenRepository) RevokeAllByUser(ctx context.Context, userID uint) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&model.APIToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// TouchLastUsed 更新令牌的最近使用时间
+func (r *APITokenRepository) TouchLastUsed(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.APIToken{}).
+		Where("id = ?", id).
+		Update("last_used_at", at).Error
+}
@@ -2,18 +2,24 @@ package repository
 
 import (
 	"blog/internal/model"
+	"context"
+	"errors"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrArticleStatusChanged 保存时文章状态已不是调用方读到的状态（被并发修改），事务中的修改全部回滚
+var ErrArticleStatusChanged = errors.New("article status changed concurrently")
+
 // ArticleRepository 实现 IArticleRepository 接口
 type ArticleRepository struct {
 	db *gorm.DB
 }
 
-// Create 创建文章
-func (r *ArticleRepository) Create(article *model.Article) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
+// Create 创建文章，placement 不为空时在同一事务中放入系列
+func (r *ArticleRepository) Create(ctx context.Context, article *model.Article, placement *model.SeriesPlacement, emit ArticleEventsFunc) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// 处理标签
 		var tags []model.Tag
 		for _, tagName := range article.Tags {
@@ -47,18 +53,26 @@ func (r *ArticleRepository) Create(article *model.Article) error {
 			}
 		}
 
+		if err := place(tx, article.ID, placement); err != nil {
+			return err
+		}
+
 		// 重新加载文章信息（包括标签）
 		if err := tx.Preload("Tags").First(article, article.ID).Error; err != nil {
 			return err
 		}
 
-		return nil
+		return writeOutbox(tx, emit.build(article))
 	})
 }
 
-// Update 更新文章
-func (r *ArticleRepository) Update(article *model.Article) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
+// Update 更新文章，article.Status 为调用方读到的状态；transition 不为空时在同一事务中变更状态并写入变更记录
+func (r *ArticleRepository) Update(ctx context.Context, article *model.Article, transition *model.ArticleTransition, emit ArticleEventsFunc) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := applyTransition(tx, article, transition); err != nil {
+			return err
+		}
+
 		// 更新文章基本信息
 		if err := tx.Model(article).Updates(map[string]interface{}{
 			"title":   article.Title,
@@ -99,43 +113,59 @@ func (r *ArticleRepository) Update(article *model.Article) error {
 			}
 		}
 
+		if err := createTransition(tx, transition); err != nil {
+			return err
+		}
+
 		// 重新加载文章信息（包括标签）
 		if err := tx.Preload("Tags").First(article, article.ID).Error; err != nil {
 			return err
 		}
 
-		return nil
+		return writeOutbox(tx, emit.build(article))
 	})
 }
 
 // Delete 删除文章（软删除）
-func (r *ArticleRepository) Delete(id uint, authorID uint) error {
-	return r.db.Where("id = ? AND author_id = ?", id, authorID).Delete(&model.Article{}).Error
+func (r *ArticleRepository) Delete(ctx context.Context, id uint, authorID uint, emit ArticleEventsFunc) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND author_id = ?", id, authorID).Delete(&model.Article{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return writeOutbox(tx, emit.build(&model.Article{ID: id, AuthorID: authorID}))
+	})
 }
 
 // FindByID 通过ID查找文章
-func (r *ArticleRepository) FindByID(id uint) (*model.Article, error) {
+func (r *ArticleRepository) FindByID(ctx context.Context, id uint) (*model.Article, error) {
 	var article model.Article
-	err := r.db.Preload("Author").Preload("Tags").First(&article, id).Error
+	err := r.db.WithContext(ctx).Preload("Author").Preload("Tags").First(&article, id).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
 		return nil, err
 	}
 	return &article, nil
 }
 
-// List 获取文章列表
-func (r *ArticleRepository) List(page, pageSize int, status string, authorID uint, tag string) ([]model.Article, int64, error) {
+// List 获取文章列表，未发布的文章只返回 viewer 作为作者、协作者或审核员可见的
+func (r *ArticleRepository) List(ctx context.Context, page, pageSize int, status string, authorID uint, tag string, viewer *model.ArticleViewer) ([]model.Article, int64, error) {
 	var articles []model.Article
 	var total int64
 
-	query := r.db.Model(&model.Article{})
+	query := r.db.WithContext(ctx).Model(&model.Article{})
 
 	// 添加查询条件
 	if status != "" {
-		query = query.Where("status = ?", status)
+		query = query.Where("articles.status = ?", status)
+	}
+	if status != model.ArticleStatusPublished {
+		query = query.Where(r.visibleTo(viewer))
 	}
 	if authorID != 0 {
-		query = query.Where("author_id = ?", authorID)
+		query = query.Where("articles.author_id = ?", authorID)
 	}
 	if tag != "" {
 		query = query.Joins("JOIN article_tags ON articles.id = article_tags.article_id").
@@ -162,9 +192,13 @@ func (r *ArticleRepository) List(page, pageSize int, status string, authorID uin
 	return articles, total, nil
 }
 
-// UpdateTags 更新文章和标签
-func (r *ArticleRepository) UpdateTags(article *model.Article, tags []string) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
+// UpdateTags 更新文章和标签，placement 不为空时在同一事务中调整所在系列；状态的处理与 Update 相同
+func (r *ArticleRepository) UpdateTags(ctx context.Context, article *model.Article, tags []string, placement *model.SeriesPlacement, transition *model.ArticleTransition, emit ArticleEventsFunc) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := applyTransition(tx, article, transition); err != nil {
+			return err
+		}
+
 		// 更新文章基本信息
 		if err := tx.Model(article).Updates(map[string]interface{}{
 			"title":   article.Title,
@@ -192,11 +226,86 @@ func (r *ArticleRepository) UpdateTags(article *model.Article, tags []string) er
 			}
 		}
 
+		if err := place(tx, article.ID, placement); err != nil {
+			return err
+		}
+		if err := createTransition(tx, transition); err != nil {
+			return err
+		}
+
 		// 重新加载文章信息（包括标签）
 		if err := tx.Preload("Tags").First(article, article.ID).Error; err != nil {
 			return err
 		}
 
-		return nil
+		return writeOutbox(tx, emit.build(article))
+	})
+}
+
+// Transition 变更文章状态并写入变更记录；文章当前状态已不是 FromStatus（被并发修改）时不做任何修改，返回 nil
+func (r *ArticleRepository) Transition(ctx context.Context, transition *model.ArticleTransition, emit ArticleEventsFunc) (*model.Article, error) {
+	var article *model.Article
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Article{}).
+			Where("id = ? AND status = ?", transition.ArticleID, transition.FromStatus).
+			Update("status", transition.ToStatus)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if err := tx.Create(transition).Error; err != nil {
+			return err
+		}
+
+		article = &model.Article{}
+		if err := tx.Preload("Author").Preload("Tags").First(article, transition.ArticleID).Error; err != nil {
+			return err
+		}
+		return writeOutbox(tx, emit.build(article))
 	})
-}
\ No newline at end of file
+	if err != nil {
+		return nil, err
+	}
+	return article, nil
+}
+
+// applyTransition 锁定文章行并确认状态仍是 article.Status，避免覆盖并发的审核结果；
+// transition 不为空时把 article.Status 改为目标状态，随内容一起写入
+func applyTransition(tx *gorm.DB, article *model.Article, transition *model.ArticleTransition) error {
+	var current model.Article
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "status").
+		First(&current, article.ID).Error
+	if err != nil {
+		return err
+	}
+	if current.Status != article.Status {
+		return ErrArticleStatusChanged
+	}
+	if transition != nil {
+		article.Status = transition.ToStatus
+	}
+	return nil
+}
+
+// createTransition 写入状态变更记录，transition 为空时不写
+func createTransition(tx *gorm.DB, transition *model.ArticleTransition) error {
+	if transition == nil {
+		return nil
+	}
+	return tx.Create(transition).Error
+}
+
+// visibleTo viewer 可见文章的查询条件：已发布的文章，自己的文章，参与协作的文章，审核员还包括提交过审核的文章
+func (r *ArticleRepository) visibleTo(viewer *model.ArticleViewer) *gorm.DB {
+	visible := r.db.Where("articles.status = ?", model.ArticleStatusPublished)
+	if viewer == nil {
+		return visible
+	}
+	visible = visible.
+		Or("articles.author_id = ?", viewer.UserID).
+		Or("articles.id IN (?)", collaboratingOn(r.db, viewer.UserID))
+	if viewer.Reviewer {
+		visible = visible.Or("articles.status <> ?", model.ArticleStatusDraft)
+	}
+	return visible
+}
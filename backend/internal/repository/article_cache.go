@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"blog/internal/cache"
+	"blog/internal/model"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultArticleCacheTTL = 5 * time.Minute
+	defaultListCacheTTL    = time.Minute
+	articleListVersionKey  = "articles:ver"
+)
+
+// ArticleCacheOptions 文章缓存配置
+type ArticleCacheOptions struct {
+	ArticleTTL time.Duration // 单篇文章的缓存时间，详情中的点赞数、浏览数同样不触发失效，最长延迟一个 TTL
+	ListTTL    time.Duration // 文章列表的缓存时间，点赞数、浏览数等计数不触发失效，只随过期刷新
+}
+
+// CachedArticleRepository 为文章仓库的读操作加上缓存，写操作成功后使相关缓存失效。
+// 列表缓存的 key 带有版本号，任意文章变化时更换版本号，旧列表随过期自然淘汰。
+// 点赞数、浏览数由其他仓库直接更新，列表和详情中的计数都只随过期刷新。
+// 作者修改昵称、头像时由服务层调用 InvalidateLists，详情中的作者资料最长延迟一个 ArticleTTL。
+// 查库与失效并发时可能写回旧数据，最长保留一个 TTL
+type CachedArticleRepository struct {
+	next    IArticleRepository
+	backend cache.Backend
+	opts    ArticleCacheOptions
+	group   singleflight.Group
+
+	articleStats *cache.Stats
+	listStats    *cache.Stats
+}
+
+// cachedList 文章列表的缓存内容
+type cachedList struct {
+	Articles []model.Article `json:"articles"`
+	Total    int64           `json:"total"`
+}
+
+// NewCachedArticleRepository 创建带缓存的文章仓库
+func NewCachedArticleRepository(next IArticleRepository, backend cache.Backend, opts ArticleCacheOptions) *CachedArticleRepository {
+	if opts.ArticleTTL <= 0 {
+		opts.ArticleTTL = defaultArticleCacheTTL
+	}
+	if opts.ListTTL <= 0 {
+		opts.ListTTL = defaultListCacheTTL
+	}
+	return &CachedArticleRepository{
+		next:         next,
+		backend:      backend,
+		opts:         opts,
+		articleStats: cache.NewStats("article"),
+		listStats:    cache.NewStats("article_list"),
+	}
+}
+
+func (r *CachedArticleRepository) Create(ctx context.Context, article *model.Article, placement *model.SeriesPlacement, emit ArticleEventsFunc) error {
+	if err := r.next.Create(ctx, article, placement, emit); err != nil {
+		return err
+	}
+	r.invalidate(ctx, 0)
+	return nil
+}
+
+func (r *CachedArticleRepository) Update(ctx context.Context, article *model.Article, transition *model.ArticleTransition, emit ArticleEventsFunc) error {
+	err := r.next.Update(ctx, article, transition, emit)
+	// 失败时事务可能已部分生效（如连接中断），同样使缓存失效
+	r.invalidate(ctx, article.ID)
+	return err
+}
+
+func (r *CachedArticleRepository) UpdateTags(ctx context.Context, article *model.Article, tags []string, placement *model.SeriesPlacement, transition *model.ArticleTransition, emit ArticleEventsFunc) error {
+	err := r.next.UpdateTags(ctx, article, tags, placement, transition, emit)
+	r.invalidate(ctx, article.ID)
+	return err
+}
+
+func (r *CachedArticleRepository) Transition(ctx context.Context, transition *model.ArticleTransition, emit ArticleEventsFunc) (*model.Article, error) {
+	article, err := r.next.Transition(ctx, transition, emit)
+	r.invalidate(ctx, transition.ArticleID)
+	return article, err
+}
+
+func (r *CachedArticleRepository) Delete(ctx context.Context, id uint, authorID uint, emit ArticleEventsFunc) error {
+	err := r.next.Delete(ctx, id, authorID, emit)
+	r.invalidate(ctx, id)
+	return err
+}
+
+// FindByID 先读缓存，未命中时查库；文章不存在时不缓存，返回的计数可能落后一个 ArticleTTL
+func (r *CachedArticleRepository) FindByID(ctx context.Context, id uint) (*model.Article, error) {
+	key := "article:" + strconv.FormatUint(uint64(id), 10)
+	data, err := r.load(ctx, key, r.opts.ArticleTTL, r.articleStats, func(ctx context.Context) (any, error) {
+		article, err := r.next.FindByID(ctx, id)
+		if article == nil || err != nil {
+			return nil, err
+		}
+		return article, nil
+	})
+	if data == nil || err != nil {
+		return nil, err
+	}
+
+	var article model.Article
+	if err := json.Unmarshal(data, &article); err != nil {
+		return nil, err
+	}
+	return &article, nil
+}
+
+// List 先读缓存，未命中时查库
+func (r *CachedArticleRepository) List(ctx context.Context, page, pageSize int, status string, authorID uint, tag string, viewer *model.ArticleViewer) ([]model.Article, int64, error) {
+	// 登录用户的列表可能包含只有其可见的未发布文章，不缓存
+	if viewer != nil && status != model.ArticleStatusPublished {
+		return r.next.List(ctx, page, pageSize, status, authorID, tag, viewer)
+	}
+	key := fmt.Sprintf("articles:list:%s:%d:%d:%s:%d:%s", r.listVersion(ctx), page, pageSize, status, authorID, url.QueryEscape(tag))
+	data, err := r.load(ctx, key, r.opts.ListTTL, r.listStats, func(ctx context.Context) (any, error) {
+		articles, total, err := r.next.List(ctx, page, pageSize, status, authorID, tag, nil)
+		if err != nil {
+			return nil, err
+		}
+		return cachedList{Articles: articles, Total: total}, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var list cachedList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, 0, err
+	}
+	return list.Articles, list.Total, nil
+}
+
+// load 读取缓存，未命中时同一 key 的并发请求只查一次库。
+// 返回序列化后的数据，每个调用方各自反序列化，避免共享同一个对象；fetch 返回 nil 时结果为 nil
+func (r *CachedArticleRepository) load(ctx context.Context, key string, ttl time.Duration, stats *cache.Stats, fetch func(ctx context.Context) (any, error)) ([]byte, error) {
+	if data, ok, err := r.backend.Get(ctx, key); err != nil {
+		stats.Error()
+		log.Printf("article cache: get %s: %v", key, err)
+	} else if ok {
+		stats.Hit()
+		return data, nil
+	}
+	stats.Miss()
+
+	v, err, _ := r.group.Do(key, func() (any, error) {
+		// 查询不受发起者取消的影响，否则共享结果的其他请求会一起失败
+		ctx := context.WithoutCancel(ctx)
+		value, err := fetch(ctx)
+		if value == nil || err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.backend.Set(ctx, key, data, ttl); err != nil {
+			stats.Error()
+			log.Printf("article cache: set %s: %v", key, err)
+		}
+		return data, nil
+	})
+	if v == nil || err != nil {
+		return nil, err
+	}
+	return v.([]byte), nil
+}
+
+// listVersion 当前列表缓存的版本号，不存在时生成一个
+func (r *CachedArticleRepository) listVersion(ctx context.Context) string {
+	data, ok, err := r.backend.Get(ctx, articleListVersionKey)
+	if err != nil {
+		r.listStats.Error()
+		log.Printf("article cache: get %s: %v", articleListVersionKey, err)
+	}
+	if ok {
+		return string(data)
+	}
+
+	version := strconv.FormatInt(time.Now().UnixNano(), 36)
+	if err := r.backend.Set(ctx, articleListVersionKey, []byte(version), 0); err != nil {
+		r.listStats.Error()
+		log.Printf("article cache: set %s: %v", articleListVersionKey, err)
+	}
+	return version
+}
+
+// InvalidateLists 更换列表版本号，使全部文章列表缓存失效
+func (r *CachedArticleRepository) InvalidateLists(ctx context.Context) {
+	r.invalidate(ctx, 0)
+}
+
+// invalidate 删除文章缓存并更换列表版本号，id 为 0 时只处理列表
+func (r *CachedArticleRepository) invalidate(ctx context.Context, id uint) {
+	keys := []string{articleListVersionKey}
+	if id != 0 {
+		keys = append(keys, "article:"+strconv.FormatUint(uint64(id), 10))
+	}
+	if err := r.backend.Delete(context.WithoutCancel(ctx), keys...); err != nil {
+		log.Printf("article cache: invalidate %v: %v", keys, err)
+	}
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"blog/internal/cache"
+	"blog/internal/model"
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingArticleRepository 记录查库次数的文章仓库
+type countingArticleRepository struct {
+	IArticleRepository
+	articles  map[uint]*model.Article
+	finds     atomic.Int32
+	lists     atomic.Int32
+	findDelay time.Duration
+}
+
+func (r *countingArticleRepository) FindByID(ctx context.Context, id uint) (*model.Article, error) {
+	r.finds.Add(1)
+	time.Sleep(r.findDelay)
+	article, ok := r.articles[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *article
+	return &copied, nil
+}
+
+func (r *countingArticleRepository) List(ctx context.Context, page, pageSize int, status string, authorID uint, tag string, viewer *model.ArticleViewer) ([]model.Article, int64, error) {
+	r.lists.Add(1)
+	var articles []model.Article
+	for _, article := range r.articles {
+		articles = append(articles, *article)
+	}
+	return articles, int64(len(articles)), nil
+}
+
+func (r *countingArticleRepository) Update(ctx context.Context, article *model.Article, transition *model.ArticleTransition, emit ArticleEventsFunc) error {
+	r.articles[article.ID] = article
+	return nil
+}
+
+func (r *countingArticleRepository) Create(ctx context.Context, article *model.Article, placement *model.SeriesPlacement, emit ArticleEventsFunc) error {
+	r.articles[article.ID] = article
+	return nil
+}
+
+func newTestArticleCache() (*CachedArticleRepository, *countingArticleRepository) {
+	next := &countingArticleRepository{articles: map[uint]*model.Article{
+		1: {ID: 1, Title: "first", Tags: []model.Tag{{Name: "go"}}},
+	}}
+	return NewCachedArticleRepository(next, cache.NewLRU(100), ArticleCacheOptions{}), next
+}
+
+func TestCachedArticleRepository(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("命中后不再查库，返回独立的副本", func(t *testing.T) {
+		repo, next := newTestArticleCache()
+		hits := repo.articleStats.Snapshot().Hits
+
+		first, err := repo.FindByID(ctx, 1)
+		require.NoError(t, err)
+		first.Liked = true
+		second, err := repo.FindByID(ctx, 1)
+		require.NoError(t, err)
+
+		assert.Equal(t, int32(1), next.finds.Load())
+		assert.Equal(t, "first", second.Title)
+		assert.Equal(t, "go", second.Tags[0].Name)
+		assert.False(t, second.Liked)
+		assert.Equal(t, hits+1, repo.articleStats.Snapshot().Hits)
+	})
+
+	t.Run("不存在的文章不缓存", func(t *testing.T) {
+		repo, next := newTestArticleCache()
+		for i := 0; i < 2; i++ {
+			article, err := repo.FindByID(ctx, 2)
+			require.NoError(t, err)
+			assert.Nil(t, article)
+		}
+		assert.Equal(t, int32(2), next.finds.Load())
+	})
+
+	t.Run("并发未命中只查一次库", func(t *testing.T) {
+		repo, next := newTestArticleCache()
+		next.findDelay = 50 * time.Millisecond
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				article, err := repo.FindByID(ctx, 1)
+				assert.NoError(t, err)
+				assert.Equal(t, "first", article.Title)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), next.finds.Load())
+	})
+
+	t.Run("更新后文章和列表缓存失效", func(t *testing.T) {
+		repo, next := newTestArticleCache()
+		_, _, err := repo.List(ctx, 1, 10, "", 0, "", nil)
+		require.NoError(t, err)
+		_, err = repo.FindByID(ctx, 1)
+		require.NoError(t, err)
+		_, _, err = repo.List(ctx, 1, 10, "", 0, "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), next.lists.Load())
+
+		require.NoError(t, repo.Update(ctx, &model.Article{ID: 1, Title: "changed"}, nil, nil))
+
+		article, err := repo.FindByID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "changed", article.Title)
+		articles, total, err := repo.List(ctx, 1, 10, "", 0, "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, "changed", articles[0].Title)
+		assert.Equal(t, int32(2), next.finds.Load())
+		assert.Equal(t, int32(2), next.lists.Load())
+	})
+
+	t.Run("新建文章后列表缓存失效", func(t *testing.T) {
+		repo, next := newTestArticleCache()
+		_, _, err := repo.List(ctx, 1, 10, "", 0, "", nil)
+		require.NoError(t, err)
+
+		require.NoError(t, repo.Create(ctx, &model.Article{ID: 2, Title: "second"}, nil, nil))
+
+		_, total, err := repo.List(ctx, 1, 10, "", 0, "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Equal(t, int32(2), next.lists.Load())
+	})
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"blog/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestArticleRepository_VisibleTo(t *testing.T) {
+	testDB := newDryRunDB(t)
+	repo := &ArticleRepository{db: testDB}
+	listSQL := func(viewer *model.ArticleViewer) string {
+		return testDB.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			var articles []model.Article
+			return tx.Model(&model.Article{}).Where(repo.visibleTo(viewer)).Find(&articles)
+		})
+	}
+
+	// 匿名访问只能看到已发布的文章
+	assert.Contains(t, listSQL(nil), "WHERE articles.status = 'published' AND")
+
+	// 登录用户还能看到自己的文章和参与协作的文章
+	sql := listSQL(&model.ArticleViewer{UserID: 7})
+	assert.Contains(t, sql, "(articles.status = 'published' OR articles.author_id = 7 OR articles.id IN "+
+		"(SELECT `article_id` FROM `article_collaborators` WHERE user_id = 7))")
+	assert.NotContains(t, sql, "<> 'draft'")
+
+	// 审核员还能看到提交过审核的文章
+	assert.Contains(t, listSQL(&model.ArticleViewer{UserID: 7, Reviewer: true}), "OR articles.status <> 'draft')")
+}
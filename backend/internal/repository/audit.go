@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"blog/internal/model"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+// CreateBatch 批量写入审计事件
+func (r *AuditRepository) CreateBatch(ctx context.Context, events []model.AuditEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).CreateInBatches(events, 100).Error
+}
+
+// List 按条件分页查询审计事件，最新的在前
+func (r *AuditRepository) List(ctx context.Context, q *model.AuditQuery) ([]model.AuditEvent, int64, error) {
+	var events []model.AuditEvent
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.AuditEvent{})
+	if q.ActorID != 0 {
+		query = query.Where("actor_id = ?", q.ActorID)
+	}
+	if q.Action != "" {
+		query = query.Where("action = ?", q.Action)
+	}
+	if q.Outcome != "" {
+		query = query.Where("outcome = ?", q.Outcome)
+	}
+	if q.TargetType != "" {
+		query = query.Where("target_type = ?", q.TargetType)
+	}
+	if q.TargetID != "" {
+		query = query.Where("target_id = ?", q.TargetID)
+	}
+	if q.IP != "" {
+		query = query.Where("ip = ?", q.IP)
+	}
+	if q.Since != nil {
+		query = query.Where("created_at >= ?", *q.Since)
+	}
+	if q.Until != nil {
+		query = query.Where("created_at < ?", *q.Until)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("created_at DESC, id DESC").
+		Offset((q.Page - 1) * q.PageSize).
+		Limit(q.PageSize).
+		Find(&events).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+// DeleteBefore 删除早于 before 的事件，每次最多删除 limit 条，避免长时间锁表
+func (r *AuditRepository) DeleteBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("created_at < ?", before).
+		Limit(limit).
+		Delete(&model.AuditEvent{})
+	return result.RowsAffected, result.Error
+}
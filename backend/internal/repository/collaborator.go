@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"blog/internal/model"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CollaboratorRepository struct {
+	db *gorm.DB
+}
+
+// Upsert 添加协作者，已存在时更新角色
+func (r *CollaboratorRepository) Upsert(ctx context.Context, collaborator *model.ArticleCollaborator) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "article_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "invited_by", "updated_at"}),
+	}).Create(collaborator).Error
+}
+
+// Remove 移除协作者，不存在时返回 false
+func (r *CollaboratorRepository) Remove(ctx context.Context, articleID, userID uint) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("article_id = ? AND user_id = ?", articleID, userID).
+		Delete(&model.ArticleCollaborator{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListByArticle 列出文章的协作者，先加入的在前
+func (r *CollaboratorRepository) ListByArticle(ctx context.Context, articleID uint) ([]model.ArticleCollaborator, error) {
+	var collaborators []model.ArticleCollaborator
+	err := r.db.WithContext(ctx).Preload("User").
+		Where("article_id = ?", articleID).
+		Order("id").
+		Find(&collaborators).Error
+	return collaborators, err
+}
+
+// FindRole 用户在文章中的协作者角色，不是协作者时返回空字符串
+func (r *CollaboratorRepository) FindRole(ctx context.Context, articleID, userID uint) (string, error) {
+	var collaborator model.ArticleCollaborator
+	err := r.db.WithContext(ctx).
+		Where("article_id = ? AND user_id = ?", articleID, userID).
+		First(&collaborator).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return collaborator.Role, nil
+}
+
+// ListEditable 分页列出用户可以编辑的文章：自己的文章和作为编辑者参与的文章，最近更新的在前
+func (r *CollaboratorRepository) ListEditable(ctx context.Context, userID uint, page, pageSize int) ([]model.Article, int64, error) {
+	var articles []model.Article
+	var total int64
+
+	editing := collaboratingOn(r.db, userID, model.CollaboratorEditor)
+	query := r.db.WithContext(ctx).Model(&model.Article{}).
+		Where("author_id = ? OR id IN (?)", userID, editing)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Author").Preload("Tags").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Order("updated_at DESC").
+		Find(&articles).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return articles, total, nil
+}
+
+// collaboratingOn 用户参与协作的文章 ID 子查询，roles 为空时包括所有角色
+func collaboratingOn(db *gorm.DB, userID uint, roles ...string) *gorm.DB {
+	query := db.Model(&model.ArticleCollaborator{}).
+		Select("article_id").
+		Where("user_id = ?", userID)
+	if len(roles) > 0 {
+		query = query.Where("role IN ?", roles)
+	}
+	return query
+}
@@ -2,9 +2,12 @@ package repository
 
 import (
 	"blog/config"
+	"blog/internal/cache"
 	"blog/internal/model"
+	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
@@ -14,6 +17,24 @@ var (
 	db           *gorm.DB
 	userRepo     *UserRepository
 	articleRepo  *ArticleRepository
+	tokenRepo    *TokenRepository
+	recoveryRepo *RecoveryCodeRepository
+	stateRepo    *OAuthStateRepository
+	identityRepo *IdentityRepository
+	apiTokenRepo *APITokenRepository
+	sessionRepo  *SessionRepository
+	auditRepo    *AuditRepository
+	reactionRepo *ReactionRepository
+	viewRepo     *ViewRepository
+	sitemapRepo  *SitemapRepository
+	webhookRepo  *WebhookRepository
+	outboxRepo   *OutboxRepository
+	jobRepo      *JobRepository
+	seriesRepo   *SeriesRepository
+	collabRepo   *CollaboratorRepository
+	reviewRepo   *ReviewRepository
+
+	cachedArticleRepo *CachedArticleRepository
 )
 
 // InitDB 初始化数据库连接
@@ -32,6 +53,11 @@ func InitDB() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	// 注册链路追踪插件
+	if err := db.Use(TracingPlugin{}); err != nil {
+		log.Fatalf("Failed to register tracing plugin: %v", err)
+	}
+
 	// 设置连接池
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -48,6 +74,24 @@ func InitDB() {
 		&model.User{},
 		&model.Article{},
 		&model.Tag{},
+		&model.UserToken{},
+		&model.RecoveryCode{},
+		&model.OAuthState{},
+		&model.UserIdentity{},
+		&model.APIToken{},
+		&model.Session{},
+		&model.AuditEvent{},
+		&model.ArticleReaction{},
+		&model.ArticleViewStat{},
+		&model.Webhook{},
+		&model.WebhookDelivery{},
+		&model.OutboxMessage{},
+		&model.Job{},
+		&model.Series{},
+		&model.SeriesArticle{},
+		&model.ArticleCollaborator{},
+		&model.ArticleTransition{},
+		&model.ReviewComment{},
 	)
 	if err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
@@ -56,23 +100,191 @@ func InitDB() {
 	// 初始化仓储实例
 	userRepo = &UserRepository{db: db}
 	articleRepo = &ArticleRepository{db: db}
+	tokenRepo = &TokenRepository{db: db}
+	recoveryRepo = &RecoveryCodeRepository{db: db}
+	stateRepo = &OAuthStateRepository{db: db}
+	identityRepo = &IdentityRepository{db: db}
+	apiTokenRepo = &APITokenRepository{db: db}
+	sessionRepo = &SessionRepository{db: db}
+	auditRepo = &AuditRepository{db: db}
+	reactionRepo = &ReactionRepository{db: db}
+	viewRepo = &ViewRepository{db: db}
+	sitemapRepo = &SitemapRepository{db: db}
+	webhookRepo = &WebhookRepository{db: db}
+	outboxRepo = &OutboxRepository{db: db}
+	jobRepo = &JobRepository{db: db}
+	seriesRepo = &SeriesRepository{db: db}
+	collabRepo = &CollaboratorRepository{db: db}
+	reviewRepo = &ReviewRepository{db: db}
 }
 
 // IUserRepository 用户仓库接口
 type IUserRepository interface {
-	Create(user *model.User) error
-	FindByEmail(email string) (*model.User, error)
-	FindByID(id uint) (*model.User, error)
+	Create(ctx context.Context, user *model.User, emit UserEventsFunc) error
+	FindByEmail(ctx context.Context, email string) (*model.User, error)
+	FindByID(ctx context.Context, id uint) (*model.User, error)
+	FindByUsername(ctx context.Context, username string) (*model.User, error)
+	UpdatePassword(ctx context.Context, id uint, passwordHash string) error
+	MarkEmailVerified(ctx context.Context, id uint) error
+	UpdateProfile(ctx context.Context, id uint, fields map[string]interface{}) error
+	UpdateEmail(ctx context.Context, id uint, email string) error
+	SetTOTPSecret(ctx context.Context, id uint, secret string) error
+	EnableTwoFactor(ctx context.Context, id uint) error
+	DisableTwoFactor(ctx context.Context, id uint) error
+	AdvanceTOTPStep(ctx context.Context, id uint, step int64) (bool, error)
+	UpdateRole(ctx context.Context, id uint, role string) error
 }
 
 // IArticleRepository 文章仓库接口
 type IArticleRepository interface {
-	Create(article *model.Article) error
-	Update(article *model.Article) error
-	Delete(id uint, authorID uint) error
-	FindByID(id uint) (*model.Article, error)
-	List(page, pageSize int, status string, authorID uint, tag string) ([]model.Article, int64, error)
-	UpdateTags(article *model.Article, tags []string) error
+	Create(ctx context.Context, article *model.Article, placement *model.SeriesPlacement, emit ArticleEventsFunc) error
+	Update(ctx context.Context, article *model.Article, transition *model.ArticleTransition, emit ArticleEventsFunc) error
+	Delete(ctx context.Context, id uint, authorID uint, emit ArticleEventsFunc) error
+	FindByID(ctx context.Context, id uint) (*model.Article, error)
+	List(ctx context.Context, page, pageSize int, status string, authorID uint, tag string, viewer *model.ArticleViewer) ([]model.Article, int64, error)
+	UpdateTags(ctx context.Context, article *model.Article, tags []string, placement *model.SeriesPlacement, transition *model.ArticleTransition, emit ArticleEventsFunc) error
+	Transition(ctx context.Context, transition *model.ArticleTransition, emit ArticleEventsFunc) (*model.Article, error)
+}
+
+// ITokenRepository 一次性令牌仓库接口
+type ITokenRepository interface {
+	Create(ctx context.Context, token *model.UserToken) error
+	Consume(ctx context.Context, purpose, tokenHash string) (*model.UserToken, error)
+	DeleteByUser(ctx context.Context, userID uint, purpose string) error
+}
+
+// IRecoveryCodeRepository 两步验证恢复码仓库接口
+type IRecoveryCodeRepository interface {
+	Replace(ctx context.Context, userID uint, codeHashes []string) error
+	Consume(ctx context.Context, userID uint, codeHash string) (bool, error)
+	DeleteByUser(ctx context.Context, userID uint) error
+}
+
+// IOAuthStateRepository 第三方登录授权状态仓库接口
+type IOAuthStateRepository interface {
+	Create(ctx context.Context, state *model.OAuthState) error
+	Consume(ctx context.Context, stateHash string) (*model.OAuthState, error)
+}
+
+// IIdentityRepository 第三方账号关联仓库接口
+type IIdentityRepository interface {
+	Find(ctx context.Context, provider, subject string) (*model.UserIdentity, error)
+	Create(ctx context.Context, identity *model.UserIdentity) error
+}
+
+// IAPITokenRepository 个人访问令牌仓库接口
+type IAPITokenRepository interface {
+	Create(ctx context.Context, token *model.APIToken) error
+	FindByHash(ctx context.Context, tokenHash string) (*model.APIToken, error)
+	ListActiveByUser(ctx context.Context, userID uint) ([]model.APIToken, error)
+	CountActiveByUser(ctx context.Context, userID uint) (int64, error)
+	Revoke(ctx context.Context, id, userID uint) (bool, error)
+	RevokeAllByUser(ctx context.Context, userID uint) (int64, error)
+	TouchLastUsed(ctx context.Context, id uint, at time.Time) error
+}
+
+// ISessionRepository 登录会话仓库接口
+type ISessionRepository interface {
+	Create(ctx context.Context, session *model.Session) error
+	FindByTokenID(ctx context.Context, tokenID string) (*model.Session, error)
+	ListActiveByUser(ctx context.Context, userID uint) ([]model.Session, error)
+	Revoke(ctx context.Context, id, userID uint) (bool, error)
+	RevokeAllByUser(ctx context.Context, userID, exceptID uint) (int64, error)
+	UpdateLastSeen(ctx context.Context, lastSeen map[uint]time.Time) error
+}
+
+// IAuditRepository 审计事件仓库接口
+type IAuditRepository interface {
+	CreateBatch(ctx context.Context, events []model.AuditEvent) error
+	List(ctx context.Context, query *model.AuditQuery) ([]model.AuditEvent, int64, error)
+	DeleteBefore(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// IReactionRepository 文章点赞、收藏仓库接口
+type IReactionRepository interface {
+	Add(ctx context.Context, userID, articleID uint, kind string) (int64, error)
+	Remove(ctx context.Context, userID, articleID uint, kind string) (int64, error)
+	FindByUser(ctx context.Context, userID uint, articleIDs []uint) ([]model.ArticleReaction, error)
+	ListBookmarked(ctx context.Context, userID uint, page, pageSize int) ([]model.Article, int64, error)
+}
+
+// IViewRepository 文章浏览统计仓库接口
+type IViewRepository interface {
+	IncrementViews(ctx context.Context, counts map[model.ViewBucket]int64) error
+	Popular(ctx context.Context, since time.Time, limit int) ([]model.PopularArticle, error)
+	DeleteStatsBefore(ctx context.Context, before time.Time) error
+}
+
+// ISitemapRepository 站点地图数据仓库接口
+type ISitemapRepository interface {
+	Articles(ctx context.Context) ([]model.SitemapArticle, error)
+	Tags(ctx context.Context) ([]model.SitemapTag, error)
+	Authors(ctx context.Context) ([]model.SitemapAuthor, error)
+}
+
+// IWebhookRepository webhook 及投递记录仓库接口
+type IWebhookRepository interface {
+	Create(ctx context.Context, hook *model.Webhook) error
+	FindByID(ctx context.Context, id uint) (*model.Webhook, error)
+	ListByUser(ctx context.Context, userID uint) ([]model.Webhook, error)
+	CountByUser(ctx context.Context, userID uint) (int64, error)
+	ListActive(ctx context.Context) ([]model.Webhook, error)
+	Update(ctx context.Context, hook *model.Webhook) error
+	Delete(ctx context.Context, id, userID uint) (bool, error)
+	CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, webhookID uint, page, pageSize int) ([]model.WebhookDelivery, int64, error)
+	FindDelivery(ctx context.Context, webhookID, id uint) (*model.WebhookDelivery, error)
+}
+
+// IOutboxRepository outbox 事件仓库接口
+type IOutboxRepository interface {
+	ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.OutboxMessage, error)
+	MarkPublished(ctx context.Context, id uint, at time.Time) error
+	MarkFailed(ctx context.Context, id uint, attempts int, next time.Time, lastError string) error
+	DeletePublishedBefore(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// IJobRepository 后台任务仓库接口
+type IJobRepository interface {
+	Create(ctx context.Context, job *model.Job) (bool, error)
+	Claim(ctx context.Context, queue string, types []string, now time.Time, lease time.Duration, limit int) ([]model.Job, error)
+	Finish(ctx context.Context, job *model.Job) error
+	FindByID(ctx context.Context, id uint) (*model.Job, error)
+	List(ctx context.Context, query *model.JobQuery) ([]model.Job, int64, error)
+	Stats(ctx context.Context) ([]model.JobStat, error)
+	Retry(ctx context.Context, id uint, now time.Time) (bool, error)
+	DeleteSucceededBefore(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// ISeriesRepository 系列文章仓库接口
+type ISeriesRepository interface {
+	Create(ctx context.Context, series *model.Series) error
+	FindByID(ctx context.Context, id uint) (*model.Series, error)
+	Update(ctx context.Context, series *model.Series) error
+	Delete(ctx context.Context, id uint) error
+	ListEntries(ctx context.Context, seriesID uint) ([]model.SeriesEntry, error)
+	FindByArticle(ctx context.Context, articleID uint) (*model.SeriesArticle, error)
+	Attach(ctx context.Context, seriesID, articleID uint, position int) error
+	Detach(ctx context.Context, articleID uint) (bool, error)
+	Reorder(ctx context.Context, seriesID uint, articleIDs []uint) error
+}
+
+// ICollaboratorRepository 文章协作者仓库接口
+type ICollaboratorRepository interface {
+	Upsert(ctx context.Context, collaborator *model.ArticleCollaborator) error
+	Remove(ctx context.Context, articleID, userID uint) (bool, error)
+	ListByArticle(ctx context.Context, articleID uint) ([]model.ArticleCollaborator, error)
+	FindRole(ctx context.Context, articleID, userID uint) (string, error)
+	ListEditable(ctx context.Context, userID uint, page, pageSize int) ([]model.Article, int64, error)
+}
+
+// IReviewRepository 文章审核仓库接口
+type IReviewRepository interface {
+	ListTransitions(ctx context.Context, articleID uint) ([]model.ArticleTransition, error)
+	CreateComment(ctx context.Context, comment *model.ReviewComment) error
+	ListComments(ctx context.Context, articleID uint) ([]model.ReviewComment, error)
 }
 
 // NewUserRepository 创建用户仓库的函数类型
@@ -81,6 +293,12 @@ type NewUserRepositoryFunc func() IUserRepository
 // NewArticleRepository 创建文章仓库的函数类型
 type NewArticleRepositoryFunc func() IArticleRepository
 
+// NewTokenRepository 创建令牌仓库的函数类型
+type NewTokenRepositoryFunc func() ITokenRepository
+
+// NewRecoveryCodeRepository 创建恢复码仓库的函数类型
+type NewRecoveryCodeRepositoryFunc func() IRecoveryCodeRepository
+
 // NewUserRepository 创建用户仓库的默认实现
 var NewUserRepository NewUserRepositoryFunc = func() IUserRepository {
 	if userRepo == nil {
@@ -89,12 +307,30 @@ var NewUserRepository NewUserRepositoryFunc = func() IUserRepository {
 	return userRepo
 }
 
-// NewArticleRepository 创建文章仓库的默认实现
+// NewArticleRepository 创建文章仓库的默认实现，启用缓存时返回带缓存的仓库
 var NewArticleRepository NewArticleRepositoryFunc = func() IArticleRepository {
 	if articleRepo == nil {
 		articleRepo = &ArticleRepository{db: db}
 	}
-	return articleRepo
+	backend := cache.GetBackend()
+	if backend == nil {
+		return articleRepo
+	}
+	if cachedArticleRepo == nil {
+		cachedArticleRepo = NewCachedArticleRepository(articleRepo, backend, ArticleCacheOptions{
+			ArticleTTL: config.AppConfig.Cache.ArticleTTL,
+			ListTTL:    config.AppConfig.Cache.ListTTL,
+		})
+	}
+	return cachedArticleRepo
+}
+
+// NewTokenRepository 创建令牌仓库的默认实现
+var NewTokenRepository NewTokenRepositoryFunc = func() ITokenRepository {
+	if tokenRepo == nil {
+		tokenRepo = &TokenRepository{db: db}
+	}
+	return tokenRepo
 }
 
 // GetDB 获取数据库连接
@@ -116,4 +352,166 @@ func GetArticleRepository() *ArticleRepository {
 		articleRepo = &ArticleRepository{db: db}
 	}
 	return articleRepo
-}
\ No newline at end of file
+}
+
+// NewRecoveryCodeRepository 创建恢复码仓库的默认实现
+var NewRecoveryCodeRepository NewRecoveryCodeRepositoryFunc = func() IRecoveryCodeRepository {
+	if recoveryRepo == nil {
+		recoveryRepo = &RecoveryCodeRepository{db: db}
+	}
+	return recoveryRepo
+}
+
+// NewOAuthStateRepository 创建授权状态仓库的函数类型
+type NewOAuthStateRepositoryFunc func() IOAuthStateRepository
+
+// NewIdentityRepository 创建第三方账号仓库的函数类型
+type NewIdentityRepositoryFunc func() IIdentityRepository
+
+// NewOAuthStateRepository 创建授权状态仓库的默认实现
+var NewOAuthStateRepository NewOAuthStateRepositoryFunc = func() IOAuthStateRepository {
+	if stateRepo == nil {
+		stateRepo = &OAuthStateRepository{db: db}
+	}
+	return stateRepo
+}
+
+// NewIdentityRepository 创建第三方账号仓库的默认实现
+var NewIdentityRepository NewIdentityRepositoryFunc = func() IIdentityRepository {
+	if identityRepo == nil {
+		identityRepo = &IdentityRepository{db: db}
+	}
+	return identityRepo
+}
+
+// NewAPITokenRepository 创建个人访问令牌仓库的函数类型
+type NewAPITokenRepositoryFunc func() IAPITokenRepository
+
+// NewAPITokenRepository 创建个人访问令牌仓库的默认实现
+var NewAPITokenRepository NewAPITokenRepositoryFunc = func() IAPITokenRepository {
+	if apiTokenRepo == nil {
+		apiTokenRepo = &APITokenRepository{db: db}
+	}
+	return apiTokenRepo
+}
+
+// NewSessionRepository 创建会话仓库的函数类型
+type NewSessionRepositoryFunc func() ISessionRepository
+
+// NewSessionRepository 创建会话仓库的默认实现
+var NewSessionRepository NewSessionRepositoryFunc = func() ISessionRepository {
+	if sessionRepo == nil {
+		sessionRepo = &SessionRepository{db: db}
+	}
+	return sessionRepo
+}
+
+// NewAuditRepository 创建审计事件仓库的函数类型
+type NewAuditRepositoryFunc func() IAuditRepository
+
+// NewAuditRepository 创建审计事件仓库的默认实现
+var NewAuditRepository NewAuditRepositoryFunc = func() IAuditRepository {
+	if auditRepo == nil {
+		auditRepo = &AuditRepository{db: db}
+	}
+	return auditRepo
+}
+
+// NewReactionRepository 创建文章互动仓库的函数类型
+type NewReactionRepositoryFunc func() IReactionRepository
+
+// NewReactionRepository 创建文章互动仓库的默认实现
+var NewReactionRepository NewReactionRepositoryFunc = func() IReactionRepository {
+	if reactionRepo == nil {
+		reactionRepo = &ReactionRepository{db: db}
+	}
+	return reactionRepo
+}
+
+// NewViewRepository 创建浏览统计仓库的函数类型
+type NewViewRepositoryFunc func() IViewRepository
+
+// NewViewRepository 创建浏览统计仓库的默认实现
+var NewViewRepository NewViewRepositoryFunc = func() IViewRepository {
+	if viewRepo == nil {
+		viewRepo = &ViewRepository{db: db}
+	}
+	return viewRepo
+}
+
+// NewSitemapRepository 创建站点地图仓库的函数类型
+type NewSitemapRepositoryFunc func() ISitemapRepository
+
+// NewSitemapRepository 创建站点地图仓库的默认实现
+var NewSitemapRepository NewSitemapRepositoryFunc = func() ISitemapRepository {
+	if sitemapRepo == nil {
+		sitemapRepo = &SitemapRepository{db: db}
+	}
+	return sitemapRepo
+}
+
+// NewWebhookRepository 创建 webhook 仓库的函数类型
+type NewWebhookRepositoryFunc func() IWebhookRepository
+
+// NewWebhookRepository 创建 webhook 仓库的默认实现
+var NewWebhookRepository NewWebhookRepositoryFunc = func() IWebhookRepository {
+	if webhookRepo == nil {
+		webhookRepo = &WebhookRepository{db: db}
+	}
+	return webhookRepo
+}
+
+// NewOutboxRepository 创建 outbox 事件仓库的函数类型
+type NewOutboxRepositoryFunc func() IOutboxRepository
+
+// NewOutboxRepository 创建 outbox 事件仓库的默认实现
+var NewOutboxRepository NewOutboxRepositoryFunc = func() IOutboxRepository {
+	if outboxRepo == nil {
+		outboxRepo = &OutboxRepository{db: db}
+	}
+	return outboxRepo
+}
+
+// NewJobRepository 创建后台任务仓库的函数类型
+type NewJobRepositoryFunc func() IJobRepository
+
+// NewJobRepository 创建后台任务仓库的默认实现
+var NewJobRepository NewJobRepositoryFunc = func() IJobRepository {
+	if jobRepo == nil {
+		jobRepo = &JobRepository{db: db}
+	}
+	return jobRepo
+}
+
+// NewSeriesRepository 创建系列文章仓库的函数类型
+type NewSeriesRepositoryFunc func() ISeriesRepository
+
+// NewSeriesRepository 创建系列文章仓库的默认实现
+var NewSeriesRepository NewSeriesRepositoryFunc = func() ISeriesRepository {
+	if seriesRepo == nil {
+		seriesRepo = &SeriesRepository{db: db}
+	}
+	return seriesRepo
+}
+
+// NewCollaboratorRepository 创建文章协作者仓库的函数类型
+type NewCollaboratorRepositoryFunc func() ICollaboratorRepository
+
+// NewCollaboratorRepository 创建文章协作者仓库的默认实现
+var NewCollaboratorRepository NewCollaboratorRepositoryFunc = func() ICollaboratorRepository {
+	if collabRepo == nil {
+		collabRepo = &CollaboratorRepository{db: db}
+	}
+	return collabRepo
+}
+
+// NewReviewRepository 创建文章审核仓库的函数类型
+type NewReviewRepositoryFunc func() IReviewRepository
+
+// NewReviewRepository 创建文章审核仓库的默认实现
+var NewReviewRepository NewReviewRepositoryFunc = func() IReviewRepository {
+	if reviewRepo == nil {
+		reviewRepo = &ReviewRepository{db: db}
+	}
+	return reviewRepo
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"blog/internal/model"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type JobRepository struct {
+	db *gorm.DB
+}
+
+// Create 保存任务；UniqueKey 已存在时不创建，返回 false
+func (r *JobRepository) Create(ctx context.Context, job *model.Job) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(job)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Claim 取出队列中到期的任务以及锁已过期的执行中任务，标记为执行中并增加尝试次数，
+// 多个 worker 同时取时跳过已被锁定的行
+func (r *JobRepository) Claim(ctx context.Context, queue string, types []string, now time.Time, lease time.Duration, limit int) ([]model.Job, error) {
+	var jobs []model.Job
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("queue = ? AND type IN ?", queue, types).
+			Where(tx.Where("status = ? AND run_at <= ?", model.JobPending, now).
+				Or("status = ? AND locked_until <= ?", model.JobRunning, now)).
+			Order("run_at, id").
+			Limit(limit).
+			Find(&jobs).Error
+		if err != nil || len(jobs) == 0 {
+			return err
+		}
+
+		lockedUntil := now.Add(lease)
+		ids := make([]uint, len(jobs))
+		for i := range jobs {
+			ids[i] = jobs[i].ID
+			jobs[i].Status = model.JobRunning
+			jobs[i].Attempts++
+			jobs[i].LockedUntil = &lockedUntil
+		}
+		return tx.Model(&model.Job{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":       model.JobRunning,
+			"attempts":     gorm.Expr("attempts + 1"),
+			"locked_until": lockedUntil,
+		}).Error
+	})
+	return jobs, err
+}
+
+// Finish 保存一次执行的结果
+func (r *JobRepository) Finish(ctx context.Context, job *model.Job) error {
+	return r.db.WithContext(ctx).Model(job).
+		Select("status", "payload", "run_at", "locked_until", "last_error", "finished_at").
+		Updates(job).Error
+}
+
+// FindByID 通过 ID 查找任务
+func (r *JobRepository) FindByID(ctx context.Context, id uint) (*model.Job, error) {
+	var job model.Job
+	err := r.db.WithContext(ctx).First(&job, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// List 按条件分页查询任务，最新的在前
+func (r *JobRepository) List(ctx context.Context, q *model.JobQuery) ([]model.Job, int64, error) {
+	var jobs []model.Job
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.Job{})
+	if q.Queue != "" {
+		query = query.Where("queue = ?", q.Queue)
+	}
+	if q.Type != "" {
+		query = query.Where("type = ?", q.Type)
+	}
+	if q.Status != "" {
+		query = query.Where("status = ?", q.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").
+		Offset((q.Page - 1) * q.PageSize).
+		Limit(q.PageSize).
+		Find(&jobs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return jobs, total, nil
+}
+
+// Stats 按队列和状态统计任务数
+func (r *JobRepository) Stats(ctx context.Context) ([]model.JobStat, error) {
+	var stats []model.JobStat
+	err := r.db.WithContext(ctx).Model(&model.Job{}).
+		Select("queue, status, COUNT(*) AS count").
+		Group("queue, status").
+		Order("queue, status").
+		Scan(&stats).Error
+	return stats, err
+}
+
+// Retry 把死信任务重新放回队列并清零尝试次数，任务不是死信时返回 false
+func (r *JobRepository) Retry(ctx context.Context, id uint, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.Job{}).
+		Where("id = ? AND status = ?", id, model.JobDead).
+		Updates(map[string]interface{}{
+			"status":      model.JobPending,
+			"attempts":    0,
+			"run_at":      now,
+			"finished_at": nil,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteSucceededBefore 删除早于 before 完成的成功任务，每次最多删除 limit 条
+func (r *JobRepository) DeleteSucceededBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("status = ? AND finished_at < ?", model.JobSucceeded, before).
+		Limit(limit).
+		Delete(&model.Job{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"blog/internal/model"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type OAuthStateRepository struct {
+	db *gorm.DB
+}
+
+func (r *OAuthStateRepository) Create(ctx context.Context, state *model.OAuthState) error {
+	return r.db.WithContext(ctx).Create(state).Error
+}
+
+// Consume 取出并删除未过期的授权状态，不存在或已过期时返回 nil
+func (r *OAuthStateRepository) Consume(ctx context.Context, stateHash string) (*model.OAuthState, error) {
+	var state model.OAuthState
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("state_hash = ? AND expires_at > ?", stateHash, time.Now()).First(&state).Error; err != nil {
+			return err
+		}
+
+		// 删除成功的请求才能继续，保证 state 只能使用一次
+		result := tx.Delete(&model.OAuthState{}, state.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &state, nil
+}
+
+type IdentityRepository struct {
+	db *gorm.DB
+}
+
+// Find 按提供方和第三方用户 ID 查找关联，不存在时返回 nil
+func (r *IdentityRepository) Find(ctx context.Context, provider, subject string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	err := r.db.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *IdentityRepository) Create(ctx context.Context, identity *model.UserIdentity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"blog/internal/events"
+	"blog/internal/model"
+	"context"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ArticleEventsFunc 在写文章的事务内、数据写入后调用，返回需要写入 outbox 的事件；为 nil 时不写事件
+type ArticleEventsFunc func(article *model.Article) []events.Event
+
+// UserEventsFunc 在写用户的事务内、数据写入后调用，返回需要写入 outbox 的事件；为 nil 时不写事件
+type UserEventsFunc func(user *model.User) []events.Event
+
+func (f ArticleEventsFunc) build(article *model.Article) []events.Event {
+	if f == nil {
+		return nil
+	}
+	return f(article)
+}
+
+func (f UserEventsFunc) build(user *model.User) []events.Event {
+	if f == nil {
+		return nil
+	}
+	return f(user)
+}
+
+// writeOutbox 在调用方的事务内写入事件，与业务数据一起提交或回滚
+func writeOutbox(tx *gorm.DB, evts []events.Event) error {
+	if len(evts) == 0 {
+		return nil
+	}
+	messages := make([]model.OutboxMessage, len(evts))
+	for i, event := range evts {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		messages[i] = model.OutboxMessage{
+			EventID:       event.ID,
+			EventType:     event.Type,
+			OwnerID:       event.OwnerID,
+			Payload:       string(payload),
+			NextAttemptAt: event.OccurredAt,
+		}
+	}
+	return tx.Create(&messages).Error
+}
+
+type OutboxRepository struct {
+	db *gorm.DB
+}
+
+// ClaimPending 按写入顺序取出到期未发布的事件，并把下次尝试时间推迟 lease，
+// 多个实例同时取时跳过已被锁定的行；中继崩溃时事件在 lease 后重新到期
+func (r *OutboxRepository) ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.OutboxMessage, error) {
+	var messages []model.OutboxMessage
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL AND next_attempt_at <= ?", now).
+			Order("id").
+			Limit(limit).
+			Find(&messages).Error
+		if err != nil || len(messages) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(messages))
+		for i := range messages {
+			ids[i] = messages[i].ID
+		}
+		return tx.Model(&model.OutboxMessage{}).Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	return messages, err
+}
+
+// MarkPublished 标记事件已发布
+func (r *OutboxRepository) MarkPublished(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.OutboxMessage{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"published_at": at, "last_error": ""}).Error
+}
+
+// MarkFailed 记录一次发布失败并安排下一次尝试
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id uint, attempts int, next time.Time, lastError string) error {
+	return r.db.WithContext(ctx).Model(&model.OutboxMessage{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"attempts": attempts, "next_attempt_at": next, "last_error": lastError}).Error
+}
+
+// DeletePublishedBefore 删除早于 before 发布的事件，每次最多删除 limit 条
+func (r *OutboxRepository) DeletePublishedBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("published_at IS NOT NULL AND published_at < ?", before).
+		Limit(limit).
+		Delete(&model.OutboxMessage{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"blog/internal/model"
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReactionRepository struct {
+	db *gorm.DB
+}
+
+// reactionCounterColumn 互动类型对应的文章计数列
+func reactionCounterColumn(kind string) (string, error) {
+	switch kind {
+	case model.ReactionLike:
+		return "like_count", nil
+	case model.ReactionBookmark:
+		return "bookmark_count", nil
+	default:
+		return "", fmt.Errorf("unknown reaction kind: %s", kind)
+	}
+}
+
+// Add 添加互动，已存在时不重复计数，返回文章最新的计数
+func (r *ReactionRepository) Add(ctx context.Context, userID, articleID uint, kind string) (int64, error) {
+	column, err := reactionCounterColumn(kind)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.ArticleReaction{
+			UserID:    userID,
+			ArticleID: articleID,
+			Kind:      kind,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		// 只有真正插入了记录才增加计数，重复请求保持幂等
+		if result.RowsAffected > 0 {
+			if err := tx.Model(&model.Article{}).Where("id = ?", articleID).
+				UpdateColumn(column, gorm.Expr(column+" + 1")).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&model.Article{}).Where("id = ?", articleID).Select(column).Scan(&count).Error
+	})
+	return count, err
+}
+
+// Remove 取消互动，不存在时不重复扣减，返回文章最新的计数
+func (r *ReactionRepository) Remove(ctx context.Context, userID, articleID uint, kind string) (int64, error) {
+	column, err := reactionCounterColumn(kind)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ? AND article_id = ? AND kind = ?", userID, articleID, kind).
+			Delete(&model.ArticleReaction{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			if err := tx.Model(&model.Article{}).Where("id = ? AND "+column+" > 0", articleID).
+				UpdateColumn(column, gorm.Expr(column+" - 1")).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&model.Article{}).Where("id = ?", articleID).Select(column).Scan(&count).Error
+	})
+	return count, err
+}
+
+// FindByUser 查找用户在给定文章上的全部互动
+func (r *ReactionRepository) FindByUser(ctx context.Context, userID uint, articleIDs []uint) ([]model.ArticleReaction, error) {
+	var reactions []model.ArticleReaction
+	if len(articleIDs) == 0 {
+		return reactions, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND article_id IN ?", userID, articleIDs).
+		Find(&reactions).Error
+	return reactions, err
+}
+
+// ListBookmarked 分页列出用户收藏的文章，最近收藏的在前；只包含已发布的文章以及用户自己或参与协作的草稿
+func (r *ReactionRepository) ListBookmarked(ctx context.Context, userID uint, page, pageSize int) ([]model.Article, int64, error) {
+	var articles []model.Article
+	var total int64
+
+	collaborating := r.db.Model(&model.ArticleCollaborator{}).Select("article_id").Where("user_id = ?", userID)
+	query := r.db.WithContext(ctx).Model(&model.Article{}).
+		Joins("JOIN article_reactions ON article_reactions.article_id = articles.id").
+		Where("article_reactions.user_id = ? AND article_reactions.kind = ?", userID, model.ReactionBookmark).
+		Where("articles.status = ? OR articles.author_id = ? OR articles.id IN (?)", model.ArticleStatusPublished, userID, collaborating)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Author").Preload("Tags").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Order("article_reactions.created_at DESC").
+		Find(&articles).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return articles, total, nil
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"blog/internal/model"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type ReviewRepository struct {
+	db *gorm.DB
+}
+
+// ListTransitions 列出文章的状态变更记录，按发生顺序
+func (r *ReviewRepository) ListTransitions(ctx context.Context, articleID uint) ([]model.ArticleTransition, error) {
+	var transitions []model.ArticleTransition
+	err := r.db.WithContext(ctx).Preload("Actor").
+		Where("article_id = ?", articleID).
+		Order("id").
+		Find(&transitions).Error
+	return transitions, err
+}
+
+// CreateComment 添加审核评论
+func (r *ReviewRepository) CreateComment(ctx context.Context, comment *model.ReviewComment) error {
+	return r.db.WithContext(ctx).Create(comment).Error
+}
+
+// ListComments 列出文章的审核评论，先发表的在前
+func (r *ReviewRepository) ListComments(ctx context.Context, articleID uint) ([]model.ReviewComment, error) {
+	var comments []model.ReviewComment
+	err := r.db.WithContext(ctx).Preload("Author").
+		Where("article_id = ?", articleID).
+		Order("id").
+		Find(&comments).Error
+	return comments, err
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"blog/internal/model"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrSeriesNotFound 把文章放入系列时系列已被删除
+var ErrSeriesNotFound = errors.New("series not found")
+
+type SeriesRepository struct {
+	db *gorm.DB
+}
+
+// Create 创建系列
+func (r *SeriesRepository) Create(ctx context.Context, series *model.Series) error {
+	return r.db.WithContext(ctx).Create(series).Error
+}
+
+// FindByID 通过 ID 查找系列
+func (r *SeriesRepository) FindByID(ctx context.Context, id uint) (*model.Series, error) {
+	var series model.Series
+	err := r.db.WithContext(ctx).First(&series, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &series, nil
+}
+
+// Update 保存系列的标题和简介
+func (r *SeriesRepository) Update(ctx context.Context, series *model.Series) error {
+	return r.db.WithContext(ctx).Model(series).
+		Select("title", "description").
+		Updates(series).Error
+}
+
+// Delete 删除系列，其中的文章保留但不再属于任何系列；
+// 先删除系列行，与同时加入文章的事务（见 attach）互斥
+func (r *SeriesRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&model.Series{}, id).Error; err != nil {
+			return err
+		}
+		return tx.Where("series_id = ?", id).Delete(&model.SeriesArticle{}).Error
+	})
+}
+
+// ListEntries 按顺序列出系列中未删除的文章
+func (r *SeriesRepository) ListEntries(ctx context.Context, seriesID uint) ([]model.SeriesEntry, error) {
+	var entries []model.SeriesEntry
+	err := r.db.WithContext(ctx).Model(&model.SeriesArticle{}).
+		Select("series_articles.article_id, articles.title, articles.status, series_articles.position").
+		Joins("JOIN articles ON articles.id = series_articles.article_id AND articles.deleted_at IS NULL").
+		Where("series_articles.series_id = ?", seriesID).
+		Order("series_articles.position, series_articles.id").
+		Scan(&entries).Error
+	return entries, err
+}
+
+// FindByArticle 查找文章所在的系列，不属于任何系列时返回 nil
+func (r *SeriesRepository) FindByArticle(ctx context.Context, articleID uint) (*model.SeriesArticle, error) {
+	var membership model.SeriesArticle
+	err := r.db.WithContext(ctx).Where("article_id = ?", articleID).First(&membership).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &membership, nil
+}
+
+// Attach 把文章放到系列的 position 处，之后的文章依次后移；
+// position 为 0 或超过篇数时放在最后。文章原来在其他系列中时先从原系列移出
+func (r *SeriesRepository) Attach(ctx context.Context, seriesID, articleID uint, position int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return attach(tx, seriesID, articleID, position)
+	})
+}
+
+// Detach 把文章移出所在系列，之后的文章依次前移；文章不在系列中时返回 false
+func (r *SeriesRepository) Detach(ctx context.Context, articleID uint) (bool, error) {
+	var detached bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&model.SeriesArticle{}).Where("article_id = ?", articleID).Count(&count).Error; err != nil {
+			return err
+		}
+		detached = count > 0
+		return detach(tx, articleID)
+	})
+	return detached, err
+}
+
+// Reorder 按 articleIDs 的顺序重新编号，调用方保证其与系列中的文章一致
+func (r *SeriesRepository) Reorder(ctx context.Context, seriesID uint, articleIDs []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, articleID := range articleIDs {
+			err := tx.Model(&model.SeriesArticle{}).
+				Where("series_id = ? AND article_id = ?", seriesID, articleID).
+				UpdateColumn("position", i+1).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// attach 在事务中把文章放到系列的 position 处；锁定系列行，系列已被删除时返回 ErrSeriesNotFound
+func attach(tx *gorm.DB, seriesID, articleID uint, position int) error {
+	var series model.Series
+	err := tx.Clauses(clause.Locking{Strength: "SHARE"}).Select("id").First(&series, seriesID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrSeriesNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := detach(tx, articleID); err != nil {
+		return err
+	}
+
+	var last int
+	err = tx.Model(&model.SeriesArticle{}).
+		Where("series_id = ?", seriesID).
+		Select("COALESCE(MAX(position), 0)").
+		Scan(&last).Error
+	if err != nil {
+		return err
+	}
+	if position <= 0 || position > last {
+		position = last + 1
+	} else if err := tx.Model(&model.SeriesArticle{}).
+		Where("series_id = ? AND position >= ?", seriesID, position).
+		UpdateColumn("position", gorm.Expr("position + 1")).Error; err != nil {
+		return err
+	}
+
+	return tx.Create(&model.SeriesArticle{
+		SeriesID:  seriesID,
+		ArticleID: articleID,
+		Position:  position,
+	}).Error
+}
+
+// place 在事务中按 placement 调整文章所在系列，为空时保持不变
+func place(tx *gorm.DB, articleID uint, placement *model.SeriesPlacement) error {
+	if placement == nil {
+		return nil
+	}
+	if placement.SeriesID == 0 {
+		return detach(tx, articleID)
+	}
+	return attach(tx, placement.SeriesID, articleID, placement.Position)
+}
+
+// detach 在事务中删除文章的系列关系并补上空出的位置
+func detach(tx *gorm.DB, articleID uint) error {
+	var membership model.SeriesArticle
+	err := tx.Where("article_id = ?", articleID).First(&membership).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := tx.Delete(&membership).Error; err != nil {
+		return err
+	}
+	return tx.Model(&model.SeriesArticle{}).
+		Where("series_id = ? AND position > ?", membership.SeriesID, membership.Position).
+		UpdateColumn("position", gorm.Expr("position - 1")).Error
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"blog/internal/model"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+func (r *SessionRepository) Create(ctx context.Context, session *model.Session) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+// FindByTokenID 按访问令牌中的 sid 查找会话，不存在时返回 nil
+func (r *SessionRepository) FindByTokenID(ctx context.Context, tokenID string) (*model.Session, error) {
+	var session model.Session
+	err := r.db.WithContext(ctx).Where("token_id = ?", tokenID).First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListActiveByUser 列出用户未撤销且未过期的会话，最近活跃的在前
+func (r *SessionRepository) ListActiveByUser(ctx context.Context, userID uint) ([]model.Session, error) {
+	var sessions []model.Session
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// Revoke 撤销用户自己的会话，会话不存在或已撤销时返回 false
+func (r *SessionRepository) Revoke(ctx context.Context, id, userID uint) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// RevokeAllByUser 撤销用户全部未撤销的会话，exceptID 不为 0 时保留该会话，返回撤销的数量
+func (r *SessionRepository) RevokeAllByUser(ctx context.Context, userID, exceptID uint) (int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID)
+	if exceptID != 0 {
+		query = query.Where("id <> ?", exceptID)
+	}
+	result := query.Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// UpdateLastSeen 用一条语句批量更新多个会话的最近活跃时间
+func (r *SessionRepository) UpdateLastSeen(ctx context.Context, lastSeen map[uint]time.Time) error {
+	if len(lastSeen) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(lastSeen))
+	args := make([]interface{}, 0, len(lastSeen)*2)
+	var expr strings.Builder
+	expr.WriteString("CASE id")
+	for id, at := range lastSeen {
+		ids = append(ids, id)
+		args = append(args, id, at)
+		expr.WriteString(" WHEN ? THEN ?")
+	}
+	expr.WriteString(" ELSE last_seen_at END")
+
+	// 只向后更新，避免较早的批次覆盖较新的时间
+	return r.db.WithContext(ctx).Model(&model.Session{}).
+		Where("id IN ?", ids).
+		Update("last_seen_at", gorm.Expr("GREATEST(last_seen_at, "+expr.String()+")", args...)).Error
+}
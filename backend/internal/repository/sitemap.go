@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"blog/internal/model"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type SitemapRepository struct {
+	db *gorm.DB
+}
+
+// Articles 列出全部已发布文章的 ID 和更新时间
+func (r *SitemapRepository) Articles(ctx context.Context) ([]model.SitemapArticle, error) {
+	var articles []model.SitemapArticle
+	err := r.db.WithContext(ctx).Model(&model.Article{}).
+		Select("id, updated_at").
+		Where("status = ?", model.ArticleStatusPublished).
+		Order("id").
+		Scan(&articles).Error
+	return articles, err
+}
+
+// Tags 列出有已发布文章的标签
+func (r *SitemapRepository) Tags(ctx context.Context) ([]model.SitemapTag, error) {
+	var tags []model.SitemapTag
+	err := r.db.WithContext(ctx).Table("tags").
+		Select("tags.name AS name, MAX(articles.updated_at) AS last_mod").
+		Joins("JOIN article_tags ON article_tags.tag_id = tags.id").
+		Joins("JOIN articles ON articles.id = article_tags.article_id").
+		Where("articles.status = ? AND articles.deleted_at IS NULL", model.ArticleStatusPublished).
+		Group("tags.id, tags.name").
+		Order("tags.name").
+		Scan(&tags).Error
+	return tags, err
+}
+
+// Authors 列出有已发布文章的作者
+func (r *SitemapRepository) Authors(ctx context.Context) ([]model.SitemapAuthor, error) {
+	var authors []model.SitemapAuthor
+	err := r.db.WithContext(ctx).Table("users").
+		Select("users.username AS username, MAX(articles.updated_at) AS last_mod").
+		Joins("JOIN articles ON articles.author_id = users.id").
+		Where("articles.status = ? AND articles.deleted_at IS NULL", model.ArticleStatusPublished).
+		Group("users.id, users.username").
+		Order("users.username").
+		Scan(&authors).Error
+	return authors, err
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"blog/internal/model"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TokenRepository struct {
+	db *gorm.DB
+}
+
+func (r *TokenRepository) Create(ctx context.Context, token *model.UserToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+// Consume 核销未使用且未过期的令牌，令牌无效时返回 nil
+func (r *TokenRepository) Consume(ctx context.Context, purpose, tokenHash string) (*model.UserToken, error) {
+	var token model.UserToken
+	now := time.Now()
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", tokenHash, purpose, now).
+			First(&token).Error
+		if err != nil {
+			return err
+		}
+
+		// 条件更新保证并发请求中只有一个能核销成功
+		result := tx.Model(&model.UserToken{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		token.UsedAt = &now
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// DeleteByUser 删除用户某种用途的全部令牌
+func (r *TokenRepository) DeleteByUser(ctx context.Context, userID uint, purpose string) error {
+	return r.db.WithContext(ctx).
+		Where("user_id = ? AND purpose = ?", userID, purpose).
+		Delete(&model.UserToken{}).Error
+}
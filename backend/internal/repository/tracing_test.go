@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"blog/internal/model"
+	"blog/internal/tracing"
+	"blog/internal/tracing/tracingtest"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// newDryRunDB 创建不连接数据库、只生成 SQL 的 gorm 实例
+func newDryRunDB(t *testing.T) *gorm.DB {
+	dialector := mysql.New(mysql.Config{
+		DSN:                       "user:pass@tcp(127.0.0.1:3306)/blog?parseTime=True",
+		SkipInitializeWithVersion: true,
+	})
+	testDB, err := gorm.Open(dialector, &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+	return testDB
+}
+
+func TestTracingPlugin(t *testing.T) {
+	exporter, shutdown := tracingtest.NewInMemoryTracer()
+	defer shutdown(context.Background())
+
+	testDB := newDryRunDB(t)
+	require.NoError(t, testDB.Use(TracingPlugin{}))
+
+	ctx, parent := tracing.Start(context.Background(), "parent")
+	var user model.User
+	testDB.WithContext(ctx).Where("email = ?", "test@example.com").First(&user)
+	parent.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	querySpan := spans[0]
+	assert.Equal(t, "gorm.query", querySpan.Name)
+	assert.Equal(t, spans[1].SpanContext.SpanID(), querySpan.Parent.SpanID())
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range querySpan.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	assert.Equal(t, "mysql", attrs["db.system"].AsString())
+	assert.Equal(t, "users", attrs["db.sql.table"].AsString())
+	assert.Contains(t, attrs["db.statement"].AsString(), "SELECT * FROM `users` WHERE email = ?")
+}
@@ -2,9 +2,9 @@ package repository
 
 import (
 	"blog/internal/model"
+	"context"
 	"errors"
 
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -12,20 +12,31 @@ type UserRepository struct {
 	db *gorm.DB
 }
 
-func (r *UserRepository) Create(user *model.User) error {
-	// 加密密码
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+// Create 创建用户，user.Password 须为已哈希的密码
+func (r *UserRepository) Create(ctx context.Context, user *model.User, emit UserEventsFunc) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return writeOutbox(tx, emit.build(user))
+	})
+}
+
+func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
+	var user model.User
+	err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
 	if err != nil {
-		return err
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
 	}
-	user.Password = string(hashedPassword)
-
-	return r.db.Create(user).Error
+	return &user, nil
 }
 
-func (r *UserRepository) FindByEmail(email string) (*model.User, error) {
+func (r *UserRepository) FindByID(ctx context.Context, id uint) (*model.User, error) {
 	var user model.User
-	err := r.db.Where("email = ?", email).First(&user).Error
+	err := r.db.WithContext(ctx).First(&user, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -35,9 +46,22 @@ func (r *UserRepository) FindByEmail(email string) (*model.User, error) {
 	return &user, nil
 }
 
-func (r *UserRepository) FindByID(id uint) (*model.User, error) {
+// UpdatePassword 更新用户密码哈希
+func (r *UserRepository) UpdatePassword(ctx context.Context, id uint, passwordHash string) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).
+		Update("password", passwordHash).Error
+}
+
+// MarkEmailVerified 将用户邮箱标记为已验证
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).
+		Update("email_verified", true).Error
+}
+
+// FindByUsername 通过用户名查找用户
+func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*model.User, error) {
 	var user model.User
-	err := r.db.First(&user, id).Error
+	err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -46,3 +70,56 @@ func (r *UserRepository) FindByID(id uint) (*model.User, error) {
 	}
 	return &user, nil
 }
+
+// UpdateProfile 更新个人资料字段
+func (r *UserRepository) UpdateProfile(ctx context.Context, id uint, fields map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// UpdateEmail 更换邮箱，新邮箱已通过验证
+func (r *UserRepository) UpdateEmail(ctx context.Context, id uint, email string) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"email":          email,
+		"email_verified": true,
+	}).Error
+}
+
+// SetTOTPSecret 保存待确认的 TOTP 密钥，确认前两步验证不生效
+func (r *UserRepository) SetTOTPSecret(ctx context.Context, id uint, secret string) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"totp_secret":        secret,
+		"totp_last_step":     0,
+		"two_factor_enabled": false,
+	}).Error
+}
+
+// EnableTwoFactor 开启两步验证
+func (r *UserRepository) EnableTwoFactor(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).
+		Update("two_factor_enabled", true).Error
+}
+
+// DisableTwoFactor 关闭两步验证并清除密钥
+func (r *UserRepository) DisableTwoFactor(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"totp_secret":        "",
+		"totp_last_step":     0,
+		"two_factor_enabled": false,
+	}).Error
+}
+
+// AdvanceTOTPStep 记录已使用的动态码周期，周期不大于上次记录时返回 false，用于拒绝重放
+func (r *UserRepository) AdvanceTOTPStep(ctx context.Context, id uint, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND totp_last_step < ?", id, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// UpdateRole 修改用户角色
+func (r *UserRepository) UpdateRole(ctx context.Context, id uint, role string) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("role", role).Error
+}
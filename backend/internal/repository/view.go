@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"blog/internal/model"
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ViewRepository struct {
+	db *gorm.DB
+}
+
+// IncrementViews 在一个事务中累加文章总浏览数和每小时统计
+func (r *ViewRepository) IncrementViews(ctx context.Context, counts map[model.ViewBucket]int64) error {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	totals := make(map[uint]int64)
+	stats := make([]model.ArticleViewStat, 0, len(counts))
+	for bucket, n := range counts {
+		totals[bucket.ArticleID] += n
+		stats = append(stats, model.ArticleViewStat{ArticleID: bucket.ArticleID, Bucket: bucket.Hour, Views: n})
+	}
+
+	ids := make([]uint, 0, len(totals))
+	args := make([]interface{}, 0, len(totals)*2)
+	var expr strings.Builder
+	expr.WriteString("view_count + CASE id")
+	for id, n := range totals {
+		ids = append(ids, id)
+		args = append(args, id, n)
+		expr.WriteString(" WHEN ? THEN ?")
+	}
+	expr.WriteString(" ELSE 0 END")
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 使用 UpdateColumn，浏览不改变文章的 updated_at
+		if err := tx.Model(&model.Article{}).Where("id IN ?", ids).
+			UpdateColumn("view_count", gorm.Expr(expr.String(), args...)).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "article_id"}, {Name: "bucket"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"views": gorm.Expr("views + VALUES(views)")}),
+		}).Create(&stats).Error
+	})
+}
+
+// Popular 统计 since 之后浏览最多的已发布文章，结果用于公开接口，作者只加载公开信息
+func (r *ViewRepository) Popular(ctx context.Context, since time.Time, limit int) ([]model.PopularArticle, error) {
+	var rows []struct {
+		ArticleID uint
+		Views     int64
+	}
+	err := r.db.WithContext(ctx).Model(&model.ArticleViewStat{}).
+		Select("article_view_stats.article_id, SUM(article_view_stats.views) AS views").
+		Joins("JOIN articles ON articles.id = article_view_stats.article_id").
+		Where("article_view_stats.bucket >= ?", since).
+		Where("articles.status = ? AND articles.deleted_at IS NULL", model.ArticleStatusPublished).
+		Group("article_view_stats.article_id").
+		Order("views DESC, article_view_stats.article_id DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+
+	ids := make([]uint, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ArticleID
+	}
+	var articles []model.Article
+	if err := r.db.WithContext(ctx).Preload("Author").Preload("Tags").
+		Where("id IN ?", ids).Find(&articles).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]model.Article, len(articles))
+	for _, article := range articles {
+		byID[article.ID] = article
+	}
+
+	// 按浏览数排序返回
+	popular := make([]model.PopularArticle, 0, len(rows))
+	for _, row := range rows {
+		if article, ok := byID[row.ArticleID]; ok {
+			popular = append(popular, model.PopularArticle{Article: article, Views: row.Views})
+		}
+	}
+	return popular, nil
+}
+
+// DeleteStatsBefore 删除早于 before 的每小时统计，文章总浏览数不受影响
+func (r *ViewRepository) DeleteStatsBefore(ctx context.Context, before time.Time) error {
+	return r.db.WithContext(ctx).Where("bucket < ?", before).Delete(&model.ArticleViewStat{}).Error
+}
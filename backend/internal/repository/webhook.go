@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"blog/internal/model"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, hook *model.Webhook) error {
+	return r.db.WithContext(ctx).Omit("Owner").Create(hook).Error
+}
+
+// FindByID 查找 webhook 及其所有者，不存在时返回 nil
+func (r *WebhookRepository) FindByID(ctx context.Context, id uint) (*model.Webhook, error) {
+	var hook model.Webhook
+	err := r.db.WithContext(ctx).Preload("Owner").First(&hook, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &hook, nil
+}
+
+func (r *WebhookRepository) ListByUser(ctx context.Context, userID uint) ([]model.Webhook, error) {
+	var hooks []model.Webhook
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&hooks).Error
+	return hooks, err
+}
+
+func (r *WebhookRepository) CountByUser(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Webhook{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// ListActive 列出所有启用的 webhook 及其所有者，由调用方按事件类型和权限筛选
+func (r *WebhookRepository) ListActive(ctx context.Context) ([]model.Webhook, error) {
+	var hooks []model.Webhook
+	err := r.db.WithContext(ctx).Preload("Owner").Where("active = ?", true).Find(&hooks).Error
+	return hooks, err
+}
+
+func (r *WebhookRepository) Update(ctx context.Context, hook *model.Webhook) error {
+	return r.db.WithContext(ctx).Model(hook).Select("url", "events", "active").Updates(hook).Error
+}
+
+// Delete 删除用户自己的 webhook 及其投递记录，不存在时返回 false
+func (r *WebhookRepository) Delete(ctx context.Context, id, userID uint) (bool, error) {
+	var deleted bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&model.Webhook{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		deleted = true
+		return tx.Where("webhook_id = ?", id).Delete(&model.WebhookDelivery{}).Error
+	})
+	return deleted, err
+}
+
+func (r *WebhookRepository) CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	// 同一事件重复发布时按 dedup_key 跳过已创建的投递
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error
+}
+
+// ClaimDueDeliveries 取出到期的待投递记录，并把下次尝试时间推迟 lease，
+// 多个实例同时取时跳过已被锁定的行；处理者崩溃时记录在 lease 后重新到期
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", model.WebhookDeliveryPending, now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].ID
+		}
+		return tx.Model(&model.WebhookDelivery{}).Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	return deliveries, err
+}
+
+// UpdateDelivery 保存一次投递尝试的结果
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Model(delivery).
+		Select("status", "attempts", "next_attempt_at", "response_status", "response_body", "error", "delivered_at").
+		Updates(delivery).Error
+}
+
+// ListDeliveries 按时间倒序列出 webhook 的投递记录
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, webhookID uint, page, pageSize int) ([]model.WebhookDelivery, int64, error) {
+	var deliveries []model.WebhookDelivery
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&deliveries).Error
+	return deliveries, total, err
+}
+
+// FindDelivery 查找 webhook 下的投递记录，不存在时返回 nil
+func (r *WebhookRepository) FindDelivery(ctx context.Context, webhookID, id uint) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	err := r.db.WithContext(ctx).Where("webhook_id = ?", webhookID).First(&delivery, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"blog/internal/audit"
+	"blog/internal/model"
+	"blog/internal/repository"
+	"blog/internal/tracing"
+	"context"
+	"time"
+)
+
+const (
+	// maxAPITokensPerUser 每个用户最多可同时持有的令牌数量
+	maxAPITokensPerUser = 20
+	// apiTokenDisplayLength 列表中展示的令牌开头长度（含前缀）
+	apiTokenDisplayLength = len(model.APITokenPrefix) + 4
+)
+
+type APITokenService struct {
+	tokenRepo repository.IAPITokenRepository
+	auditor   audit.Recorder
+}
+
+func NewAPITokenService() *APITokenService {
+	return &APITokenService{
+		tokenRepo: repository.NewAPITokenRepository(),
+		auditor:   audit.GetLogger(),
+	}
+}
+
+// Create 创建个人访问令牌，明文令牌只在返回值中出现一次
+func (s *APITokenService) Create(ctx context.Context, user *model.User, req *model.CreateAPITokenRequest) (_ *model.APITokenCreated, err error) {
+	ctx, span := tracing.Start(ctx, "APITokenService.Create")
+	defer func() { tracing.End(span, err) }()
+
+	var tokenID uint
+	defer func() { s.audit(ctx, model.AuditActionAPITokenCreate, user, tokenID, err) }()
+
+	count, err := s.tokenRepo.CountActiveByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxAPITokensPerUser {
+		return nil, ErrTooManyAPITokens.WithArgs(maxAPITokensPerUser)
+	}
+
+	raw, err := newRawToken()
+	if err != nil {
+		return nil, err
+	}
+	raw = model.APITokenPrefix + raw
+
+	token := &model.APIToken{
+		UserID:    user.ID,
+		Name:      req.Name,
+		Prefix:    raw[:apiTokenDisplayLength],
+		TokenHash: hashToken(raw),
+		Scopes:    uniqueScopes(req.Scopes),
+	}
+	if req.ExpiresInDays != nil {
+		expiresAt := time.Now().AddDate(0, 0, *req.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return nil, err
+	}
+	tokenID = token.ID
+	return &model.APITokenCreated{Token: raw, APIToken: token}, nil
+}
+
+// List 列出用户未撤销的令牌
+func (s *APITokenService) List(ctx context.Context, user *model.User) (_ []model.APIToken, err error) {
+	ctx, span := tracing.Start(ctx, "APITokenService.List")
+	defer func() { tracing.End(span, err) }()
+
+	return s.tokenRepo.ListActiveByUser(ctx, user.ID)
+}
+
+// Revoke 撤销令牌，只能撤销自己的令牌
+func (s *APITokenService) Revoke(ctx context.Context, user *model.User, id uint) (err error) {
+	ctx, span := tracing.Start(ctx, "APITokenService.Revoke")
+	defer func() { tracing.End(span, err) }()
+	defer func() { s.audit(ctx, model.AuditActionAPITokenRevoke, user, id, err) }()
+
+	revoked, err := s.tokenRepo.Revoke(ctx, id, user.ID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrAPITokenNotFound
+	}
+	return nil
+}
+
+// audit 记录令牌创建和撤销的审计事件
+func (s *APITokenService) audit(ctx context.Context, action string, user *model.User, tokenID uint, err error) {
+	event := auditActor(model.AuditEvent{
+		Action:     action,
+		TargetType: model.AuditTargetToken,
+		TargetID:   auditTarget(tokenID),
+	}, user)
+	recordAudit(ctx, s.auditor, event, err)
+}
+
+// uniqueScopes 去除重复的权限，保持原有顺序
+func uniqueScopes(scopes []string) []string {
+	seen := make(map[string]bool, len(scopes))
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+	return result
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"blog/internal/model"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockAPITokenRepository 模拟个人访问令牌仓库
+type MockAPITokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPITokenRepository) Create(ctx context.Context, token *model.APIToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockAPITokenRepository) FindByHash(ctx context.Context, tokenHash string) (*model.APIToken, error) {
+	args := m.Called(tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.APIToken), args.Error(1)
+}
+
+func (m *MockAPITokenRepository) ListActiveByUser(ctx context.Context, userID uint) ([]model.APIToken, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]model.APIToken), args.Error(1)
+}
+
+func (m *MockAPITokenRepository) CountActiveByUser(ctx context.Context, userID uint) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAPITokenRepository) Revoke(ctx context.Context, id, userID uint) (bool, error) {
+	args := m.Called(id, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAPITokenRepository) RevokeAllByUser(ctx context.Context, userID uint) (int64, error) {
+	args := m.Called(userID)
+	return int64(args.Int(0)), args.Error(1)
+}
+
+func (m *MockAPITokenRepository) TouchLastUsed(ctx context.Context, id uint, at time.Time) error {
+	args := m.Called(id, at)
+	return args.Error(0)
+}
+
+func TestAPITokenService_Create(t *testing.T) {
+	user := &model.User{ID: 1}
+
+	// 测试用例1：令牌只以哈希入库，明文只返回一次
+	t.Run("创建成功", func(t *testing.T) {
+		mockRepo := new(MockAPITokenRepository)
+		tokenService := &APITokenService{tokenRepo: mockRepo}
+		days := 30
+
+		var stored *model.APIToken
+		mockRepo.On("CountActiveByUser", user.ID).Return(int64(0), nil)
+		mockRepo.On("Create", mock.AnythingOfType("*model.APIToken")).
+			Run(func(args mock.Arguments) { stored = args.Get(0).(*model.APIToken) }).
+			Return(nil)
+
+		created, err := tokenService.Create(context.Background(), user, &model.CreateAPITokenRequest{
+			Name:          "ci",
+			Scopes:        []string{model.ScopeArticlesWrite, model.ScopeArticlesRead, model.ScopeArticlesWrite},
+			ExpiresInDays: &days,
+		})
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(created.Token, model.APITokenPrefix))
+		assert.Equal(t, hashToken(created.Token), stored.TokenHash)
+		assert.True(t, strings.HasPrefix(created.Token, stored.Prefix))
+		assert.NotEqual(t, created.Token, stored.Prefix)
+		assert.Equal(t, []string{model.ScopeArticlesWrite, model.ScopeArticlesRead}, stored.Scopes)
+		require.NotNil(t, stored.ExpiresAt)
+		assert.WithinDuration(t, time.Now().AddDate(0, 0, 30), *stored.ExpiresAt, time.Minute)
+	})
+
+	// 测试用例2：令牌数量达到上限
+	t.Run("数量上限", func(t *testing.T) {
+		mockRepo := new(MockAPITokenRepository)
+		tokenService := &APITokenService{tokenRepo: mockRepo}
+
+		mockRepo.On("CountActiveByUser", user.ID).Return(int64(maxAPITokensPerUser), nil)
+
+		_, err := tokenService.Create(context.Background(), user, &model.CreateAPITokenRequest{Name: "ci", Scopes: []string{model.ScopeArticlesRead}})
+		assert.ErrorIs(t, err, ErrTooManyAPITokens)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestAPITokenService_Revoke(t *testing.T) {
+	mockRepo := new(MockAPITokenRepository)
+	tokenService := &APITokenService{tokenRepo: mockRepo}
+	user := &model.User{ID: 1}
+
+	mockRepo.On("Revoke", uint(5), user.ID).Return(true, nil)
+	mockRepo.On("Revoke", uint(6), user.ID).Return(false, nil)
+
+	assert.NoError(t, tokenService.Revoke(context.Background(), user, 5))
+	// 他人的令牌或已撤销的令牌
+	assert.ErrorIs(t, tokenService.Revoke(context.Background(), user, 6), ErrAPITokenNotFound)
+}
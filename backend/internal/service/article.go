@@ -1,87 +1,563 @@
 package service
 
 import (
+	"blog/config"
+	"blog/internal/audit"
+	"blog/internal/clientinfo"
+	"blog/internal/events"
+	"blog/internal/httpcache"
 	"blog/internal/model"
 	"blog/internal/repository"
+	"blog/internal/sitemap"
+	"blog/internal/tracing"
+	"blog/internal/viewcount"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type ArticleService struct {
-	articleRepo repository.IArticleRepository
+	articleRepo  repository.IArticleRepository
+	reactionRepo repository.IReactionRepository
+	viewRepo     repository.IViewRepository
+	seriesRepo   repository.ISeriesRepository
+	collabRepo   repository.ICollaboratorRepository
+	reviewRepo   repository.IReviewRepository
+	views        viewcount.Recorder
+	auditor      audit.Recorder
+	// 已发布文章变化时需要失效的缓存：站点地图、公开接口的响应缓存
+	publicCaches []cacheInvalidator
+}
+
+type cacheInvalidator interface {
+	Invalidate()
 }
 
 func NewArticleService() *ArticleService {
 	return &ArticleService{
-		articleRepo: repository.NewArticleRepository(),
+		articleRepo:  repository.NewArticleRepository(),
+		reactionRepo: repository.NewReactionRepository(),
+		viewRepo:     repository.NewViewRepository(),
+		seriesRepo:   repository.NewSeriesRepository(),
+		collabRepo:   repository.NewCollaboratorRepository(),
+		reviewRepo:   repository.NewReviewRepository(),
+		views:        viewcount.GetTracker(),
+		auditor:      audit.GetLogger(),
+		publicCaches: []cacheInvalidator{sitemap.GetCache(), httpcache.GetStore()},
 	}
 }
 
-// CreateArticle 创建文章
-func (s *ArticleService) CreateArticle(article *model.Article) error {
-	return s.articleRepo.Create(article)
+// CreateArticle 创建文章，placement 不为空时放入指定系列
+func (s *ArticleService) CreateArticle(ctx context.Context, user *model.User, article *model.Article, placement *model.SeriesPlacement) (err error) {
+	ctx, span := tracing.Start(ctx, "ArticleService.CreateArticle",
+		attribute.Int("article.author_id", int(article.AuthorID)))
+	defer func() { tracing.End(span, err) }()
+	defer func() { s.audit(ctx, model.AuditActionArticleCreate, user, article.ID, err) }()
+
+	if err := checkPublishAllowed(user, article.Status); err != nil {
+		return err
+	}
+	if article.Status == model.ArticleStatusPublished && config.AppConfig.Review.RequireBeforePublish {
+		return ErrReviewRequired
+	}
+	if err := s.checkPlacement(ctx, user, placement); err != nil {
+		return err
+	}
+
+	if err := s.articleRepo.Create(ctx, article, placement, articleEmitter(events.ArticleCreated, "")); err != nil {
+		return saveError(err)
+	}
+	s.invalidatePublic(article.Status)
+	return nil
 }
 
 // UpdateArticle 更新文章
-func (s *ArticleService) UpdateArticle(article *model.Article) error {
+func (s *ArticleService) UpdateArticle(ctx context.Context, user *model.User, article *model.Article) (err error) {
+	ctx, span := tracing.Start(ctx, "ArticleService.UpdateArticle",
+		attribute.Int("article.id", int(article.ID)))
+	defer func() { tracing.End(span, err) }()
+	defer func() { s.audit(ctx, model.AuditActionArticleUpdate, user, article.ID, err) }()
+
+	if err := checkPublishAllowed(user, article.Status); err != nil {
+		return err
+	}
+
 	// 检查文章是否存在
-	existingArticle, err := s.articleRepo.FindByID(article.ID)
+	existingArticle, err := s.articleRepo.FindByID(ctx, article.ID)
 	if err != nil {
 		return err
 	}
 	if existingArticle == nil {
-		return errors.New("文章不存在")
+		return ErrArticleNotFound
+	}
+	if _, err := s.checkEditable(ctx, user, existingArticle); err != nil {
+		return err
+	}
+	var tagNames []string
+	for _, tag := range article.Tags {
+		tagNames = append(tagNames, tag.Name)
+	}
+	// 未提供标签时保持原有标签
+	changed := contentChanged(existingArticle, article, tagNames, len(tagNames) > 0)
+	status, err := reviewedStatus(existingArticle, article.Status, changed)
+	if err != nil {
+		return err
+	}
+	if status != existingArticle.Status {
+		if err := s.checkTransition(ctx, user, existingArticle, status); err != nil {
+			return err
+		}
 	}
 
-	// 确保作者ID不变
+	// 确保作者ID不变，状态按审核流程与内容在同一事务中变更
 	article.AuthorID = existingArticle.AuthorID
-	return s.articleRepo.Update(article)
+	article.Status = existingArticle.Status
+	transition := newTransition(user, existingArticle, status, "")
+	err = s.articleRepo.Update(ctx, article, transition, articleEmitter(events.ArticleUpdated, existingArticle.Status))
+	if transition != nil {
+		s.auditTransition(ctx, user, transition, err)
+	}
+	if err != nil {
+		return saveError(err)
+	}
+	s.invalidatePublic(existingArticle.Status, article.Status)
+	return nil
 }
 
-// UpdateArticleWithTags 更新文章和标签
-func (s *ArticleService) UpdateArticleWithTags(article *model.Article, tagNames []string) error {
+// UpdateArticleWithTags 更新文章和标签，placement 不为空时调整所在系列
+func (s *ArticleService) UpdateArticleWithTags(ctx context.Context, user *model.User, article *model.Article, tagNames []string, placement *model.SeriesPlacement) (err error) {
+	ctx, span := tracing.Start(ctx, "ArticleService.UpdateArticleWithTags",
+		attribute.Int("article.id", int(article.ID)))
+	defer func() { tracing.End(span, err) }()
+	defer func() { s.audit(ctx, model.AuditActionArticleUpdate, user, article.ID, err) }()
+
+	if err := checkPublishAllowed(user, article.Status); err != nil {
+		return err
+	}
+
 	// 检查文章是否存在
-	existingArticle, err := s.articleRepo.FindByID(article.ID)
+	existingArticle, err := s.articleRepo.FindByID(ctx, article.ID)
 	if err != nil {
 		return err
 	}
 	if existingArticle == nil {
-		return errors.New("文章不存在")
+		return ErrArticleNotFound
+	}
+	role, err := s.checkEditable(ctx, user, existingArticle)
+	if err != nil {
+		return err
+	}
+	// 调整所在系列只有作者可以操作
+	if placement != nil && role != articleRoleOwner {
+		return ErrArticleForbidden
+	}
+	if err := s.checkPlacement(ctx, user, placement); err != nil {
+		return err
+	}
+	moved, err := s.placementChanged(ctx, article.ID, placement)
+	if err != nil {
+		return err
+	}
+	changed := moved || contentChanged(existingArticle, article, tagNames, true)
+	status, err := reviewedStatus(existingArticle, article.Status, changed)
+	if err != nil {
+		return err
+	}
+	if status != existingArticle.Status {
+		if err := s.checkTransition(ctx, user, existingArticle, status); err != nil {
+			return err
+		}
 	}
 
-	// 确保作者ID不变
+	// 确保作者ID不变，状态按审核流程与内容在同一事务中变更
 	article.AuthorID = existingArticle.AuthorID
+	article.Status = existingArticle.Status
 
 	// 更新文章和标签
-	return s.articleRepo.UpdateTags(article, tagNames)
+	transition := newTransition(user, existingArticle, status, "")
+	err = s.articleRepo.UpdateTags(ctx, article, tagNames, placement, transition, articleEmitter(events.ArticleUpdated, existingArticle.Status))
+	if transition != nil {
+		s.auditTransition(ctx, user, transition, err)
+	}
+	if err != nil {
+		return saveError(err)
+	}
+	s.invalidatePublic(existingArticle.Status, article.Status)
+	return nil
+}
+
+// saveError 把保存文章时仓库发现的并发修改转换为业务错误
+func saveError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrArticleStatusChanged):
+		return ErrArticleStatusChanged
+	case errors.Is(err, repository.ErrSeriesNotFound):
+		return ErrSeriesNotFound
+	default:
+		return err
+	}
 }
 
 // DeleteArticle 删除文章（软删除）
-func (s *ArticleService) DeleteArticle(id uint, authorID uint) error {
-	// 检查文章是否存在且属于该作者
-	article, err := s.articleRepo.FindByID(id)
+func (s *ArticleService) DeleteArticle(ctx context.Context, user *model.User, id uint) (err error) {
+	ctx, span := tracing.Start(ctx, "ArticleService.DeleteArticle",
+		attribute.Int("article.id", int(id)))
+	defer func() { tracing.End(span, err) }()
+	defer func() { s.audit(ctx, model.AuditActionArticleDelete, user, id, err) }()
+
+	// 检查文章是否存在且属于该作者，协作者不能删除文章
+	article, err := s.articleRepo.FindByID(ctx, id)
 	if err != nil {
 		return err
 	}
-	if article == nil || article.AuthorID != authorID {
-		return errors.New("文章不存在或无权限删除")
+	if article == nil {
+		return ErrArticleNotFound
+	}
+	if article.AuthorID != user.ID {
+		return ErrArticleForbidden
 	}
 
-	return s.articleRepo.Delete(id, authorID)
+	// 删除时仓库只知道文章 ID，事件内容使用删除前读到的文章
+	deleted := func(*model.Article) []events.Event {
+		return articleEvents(events.ArticleDeleted, article, "")
+	}
+	if err := s.articleRepo.Delete(ctx, id, user.ID, deleted); err != nil {
+		return err
+	}
+	s.invalidatePublic(article.Status)
+	return nil
 }
 
-// GetArticle 获取文章详情
-func (s *ArticleService) GetArticle(id uint) (*model.Article, error) {
-	article, err := s.articleRepo.FindByID(id)
+// GetArticle 获取文章详情，登录和公开接口共用；未发布的文章只有作者、协作者和审核员可见，viewer 不为空时标记其是否点赞、收藏
+func (s *ArticleService) GetArticle(ctx context.Context, viewer *model.User, id uint) (_ *model.Article, err error) {
+	ctx, span := tracing.Start(ctx, "ArticleService.GetArticle",
+		attribute.Int("article.id", int(id)))
+	defer func() { tracing.End(span, err) }()
+
+	article, err := s.articleRepo.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 	if article == nil {
-		return nil, errors.New("文章不存在")
+		return nil, ErrArticleNotFound
+	}
+	if err := s.checkVisible(ctx, viewer, article); err != nil {
+		return nil, err
+	}
+	if err := s.markViewerReactions(ctx, viewer, article); err != nil {
+		return nil, err
 	}
+	if err := s.attachSeriesNav(ctx, viewer, article); err != nil {
+		return nil, err
+	}
+	s.recordView(ctx, viewer, article)
 	return article, nil
 }
 
 // ListArticles 获取文章列表
-func (s *ArticleService) ListArticles(page, pageSize int, status string, authorID uint, tag string) ([]model.Article, int64, error) {
-	return s.articleRepo.List(page, pageSize, status, authorID, tag)
-}
\ No newline at end of file
+func (s *ArticleService) ListArticles(ctx context.Context, viewer *model.User, page, pageSize int, status string, authorID uint, tag string) (_ []model.Article, _ int64, err error) {
+	ctx, span := tracing.Start(ctx, "ArticleService.ListArticles",
+		attribute.Int("page", page),
+		attribute.Int("page_size", pageSize))
+	defer func() { tracing.End(span, err) }()
+
+	articles, total, err := s.articleRepo.List(ctx, page, pageSize, status, authorID, tag, articleViewer(viewer))
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := s.markViewerReactions(ctx, viewer, articlePointers(articles)...); err != nil {
+		return nil, 0, err
+	}
+	return articles, total, nil
+}
+
+// ListEditableArticles 分页获取用户可以编辑的文章：自己的文章和作为编辑者参与的文章
+func (s *ArticleService) ListEditableArticles(ctx context.Context, user *model.User, page, pageSize int) (_ []model.Article, _ int64, err error) {
+	ctx, span := tracing.Start(ctx, "ArticleService.ListEditableArticles",
+		attribute.Int("page", page),
+		attribute.Int("page_size", pageSize))
+	defer func() { tracing.End(span, err) }()
+
+	articles, total, err := s.collabRepo.ListEditable(ctx, user.ID, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := s.markViewerReactions(ctx, user, articlePointers(articles)...); err != nil {
+		return nil, 0, err
+	}
+	return articles, total, nil
+}
+
+// SetReaction 点赞/收藏或取消，重复请求结果相同；只能对已发布的文章或自己可见的草稿操作
+func (s *ArticleService) SetReaction(ctx context.Context, user *model.User, articleID uint, kind string, active bool) (_ *model.ReactionResult, err error) {
+	ctx, span := tracing.Start(ctx, "ArticleService.SetReaction",
+		attribute.Int("article.id", int(articleID)),
+		attribute.String("reaction.kind", kind),
+		attribute.Bool("reaction.active", active))
+	defer func() { tracing.End(span, err) }()
+
+	article, err := s.articleRepo.FindByID(ctx, articleID)
+	if err != nil {
+		return nil, err
+	}
+	if article == nil {
+		return nil, ErrArticleNotFound
+	}
+	if err := s.checkVisible(ctx, user, article); err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if active {
+		count, err = s.reactionRepo.Add(ctx, user.ID, articleID, kind)
+	} else {
+		count, err = s.reactionRepo.Remove(ctx, user.ID, articleID, kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &model.ReactionResult{Active: active, Count: count}, nil
+}
+
+// ListBookmarks 分页获取用户收藏的文章
+func (s *ArticleService) ListBookmarks(ctx context.Context, user *model.User, page, pageSize int) (_ []model.Article, _ int64, err error) {
+	ctx, span := tracing.Start(ctx, "ArticleService.ListBookmarks",
+		attribute.Int("page", page),
+		attribute.Int("page_size", pageSize))
+	defer func() { tracing.End(span, err) }()
+
+	articles, total, err := s.reactionRepo.ListBookmarked(ctx, user.ID, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := s.markViewerReactions(ctx, user, articlePointers(articles)...); err != nil {
+		return nil, 0, err
+	}
+	return articles, total, nil
+}
+
+// PopularArticles 获取指定统计范围内浏览最多的已发布文章
+func (s *ArticleService) PopularArticles(ctx context.Context, window string, limit int) (_ []model.PopularArticle, err error) {
+	ctx, span := tracing.Start(ctx, "ArticleService.PopularArticles",
+		attribute.String("window", window),
+		attribute.Int("limit", limit))
+	defer func() { tracing.End(span, err) }()
+
+	duration, ok := viewcount.Windows()[window]
+	if !ok {
+		return nil, ErrInvalidPopularWindow.WithArgs(window)
+	}
+	popular, err := s.viewRepo.Popular(ctx, time.Now().Add(-duration), limit)
+	if err != nil {
+		return nil, err
+	}
+	if popular == nil {
+		popular = []model.PopularArticle{}
+	}
+	return popular, nil
+}
+
+// articleViewer 查询文章列表时的可见范围
+func articleViewer(user *model.User) *model.ArticleViewer {
+	if user == nil {
+		return nil
+	}
+	return &model.ArticleViewer{UserID: user.ID, Reviewer: isReviewer(user)}
+}
+
+// articleRoleOwner 文章作者本人的角色，拥有全部权限
+const articleRoleOwner = "owner"
+
+// articleRole 用户对文章的角色：作者、编辑者、查看者，无关用户返回空字符串
+func articleRole(ctx context.Context, collabRepo repository.ICollaboratorRepository, user *model.User, article *model.Article) (string, error) {
+	if user == nil {
+		return "", nil
+	}
+	if user.ID == article.AuthorID {
+		return articleRoleOwner, nil
+	}
+	if collabRepo == nil {
+		return "", nil
+	}
+	return collabRepo.FindRole(ctx, article.ID, user.ID)
+}
+
+// checkVisible 已发布的文章所有人可见，草稿只有作者和协作者可见，其他未发布的文章审核员也可见，其他人视为不存在
+func (s *ArticleService) checkVisible(ctx context.Context, user *model.User, article *model.Article) error {
+	if article.Status == model.ArticleStatusPublished || reviewerVisible(user, article) {
+		return nil
+	}
+	role, err := articleRole(ctx, s.collabRepo, user, article)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return ErrArticleNotFound
+	}
+	return nil
+}
+
+// checkEditable 作者和编辑者可以修改文章，返回用户的角色
+func (s *ArticleService) checkEditable(ctx context.Context, user *model.User, article *model.Article) (string, error) {
+	role, err := articleRole(ctx, s.collabRepo, user, article)
+	if err != nil {
+		return "", err
+	}
+	switch role {
+	case articleRoleOwner, model.CollaboratorEditor:
+		return role, nil
+	case "":
+		return "", hiddenArticleError(article)
+	default:
+		return "", ErrArticleForbidden
+	}
+}
+
+// checkPlacement 文章只能放入自己的系列
+func (s *ArticleService) checkPlacement(ctx context.Context, user *model.User, placement *model.SeriesPlacement) error {
+	if placement == nil || placement.SeriesID == 0 {
+		return nil
+	}
+	_, err := findOwnedSeries(ctx, s.seriesRepo, user, placement.SeriesID)
+	return err
+}
+
+// placementChanged placement 是否会改变文章所在系列或位置
+func (s *ArticleService) placementChanged(ctx context.Context, articleID uint, placement *model.SeriesPlacement) (bool, error) {
+	if placement == nil {
+		return false, nil
+	}
+	membership, err := s.seriesRepo.FindByArticle(ctx, articleID)
+	if err != nil {
+		return false, err
+	}
+	if membership == nil {
+		return placement.SeriesID != 0, nil
+	}
+	return membership.SeriesID != placement.SeriesID ||
+		(placement.Position != 0 && membership.Position != placement.Position), nil
+}
+
+// attachSeriesNav 文章属于系列时填充上一篇、下一篇；他人的草稿不计入导航
+func (s *ArticleService) attachSeriesNav(ctx context.Context, viewer *model.User, article *model.Article) error {
+	if s.seriesRepo == nil {
+		return nil
+	}
+	membership, err := s.seriesRepo.FindByArticle(ctx, article.ID)
+	if err != nil || membership == nil {
+		return err
+	}
+	series, err := s.seriesRepo.FindByID(ctx, membership.SeriesID)
+	if err != nil || series == nil {
+		return err
+	}
+	entries, err := s.seriesRepo.ListEntries(ctx, series.ID)
+	if err != nil {
+		return err
+	}
+	article.Series = seriesNav(series, visibleEntries(viewer, series, entries), article.ID)
+	return nil
+}
+
+// recordView 记录已发布文章的浏览，作者浏览自己的文章不计数
+func (s *ArticleService) recordView(ctx context.Context, viewer *model.User, article *model.Article) {
+	if s.views == nil || article.Status != model.ArticleStatusPublished {
+		return
+	}
+	if viewer != nil && viewer.ID == article.AuthorID {
+		return
+	}
+	s.views.Record(article.ID, viewerKey(ctx, viewer))
+}
+
+// viewerKey 浏览去重使用的访客标识，登录用户按用户 ID，匿名访客按 IP 的摘要
+// User-Agent 由客户端任意设置，不参与去重，否则更换 User-Agent 即可刷浏览数
+func viewerKey(ctx context.Context, viewer *model.User) string {
+	if viewer != nil {
+		return "u:" + strconv.FormatUint(uint64(viewer.ID), 10)
+	}
+	sum := sha256.Sum256([]byte(clientinfo.From(ctx).IP))
+	return "a:" + hex.EncodeToString(sum[:8])
+}
+
+// markViewerReactions 一次查询标记查看者对多篇文章的点赞、收藏状态，匿名访问时不处理
+func (s *ArticleService) markViewerReactions(ctx context.Context, viewer *model.User, articles ...*model.Article) error {
+	if viewer == nil || len(articles) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(articles))
+	byID := make(map[uint]*model.Article, len(articles))
+	for i, article := range articles {
+		ids[i] = article.ID
+		byID[article.ID] = article
+	}
+
+	reactions, err := s.reactionRepo.FindByUser(ctx, viewer.ID, ids)
+	if err != nil {
+		return err
+	}
+	for _, reaction := range reactions {
+		article, ok := byID[reaction.ArticleID]
+		if !ok {
+			continue
+		}
+		switch reaction.Kind {
+		case model.ReactionLike:
+			article.Liked = true
+		case model.ReactionBookmark:
+			article.Bookmarked = true
+		}
+	}
+	return nil
+}
+
+// articlePointers 返回指向切片元素的指针，便于原地修改
+func articlePointers(articles []model.Article) []*model.Article {
+	pointers := make([]*model.Article, len(articles))
+	for i := range articles {
+		pointers[i] = &articles[i]
+	}
+	return pointers
+}
+
+// invalidatePublic 变更前后任一状态为已发布时，公开内容的缓存需要失效
+func (s *ArticleService) invalidatePublic(statuses ...string) {
+	for _, status := range statuses {
+		if status == model.ArticleStatusPublished {
+			for _, c := range s.publicCaches {
+				c.Invalidate()
+			}
+			return
+		}
+	}
+}
+
+// audit 记录文章写操作的审计事件
+func (s *ArticleService) audit(ctx context.Context, action string, user *model.User, articleID uint, err error) {
+	event := model.AuditEvent{
+		Action:     action,
+		TargetType: model.AuditTargetArticle,
+		TargetID:   auditTarget(articleID),
+	}
+	if user != nil {
+		event.ActorID = user.ID
+		event.Actor = user.Username
+	}
+	recordAudit(ctx, s.auditor, event, err)
+}
+
+// checkPublishAllowed 开启邮箱验证要求时，未验证邮箱的用户只能保存草稿
+func checkPublishAllowed(user *model.User, status string) error {
+	if status == model.ArticleStatusPublished &&
+		config.AppConfig.Auth.RequireEmailVerification &&
+		!user.EmailVerified {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"blog/internal/apperr"
+	"blog/internal/events"
+	"blog/internal/model"
+	"blog/internal/repository"
+	"blog/internal/tracing/tracingtest"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// MockArticleRepository 模拟文章仓库，写操作成功时记录会写入 outbox 的事件
+type MockArticleRepository struct {
+	mock.Mock
+	events []events.Event
+}
+
+func (m *MockArticleRepository) Create(ctx context.Context, article *model.Article, placement *model.SeriesPlacement, emit repository.ArticleEventsFunc) error {
+	args := m.Called(article, placement)
+	return m.emit(args.Error(0), emit, article)
+}
+
+func (m *MockArticleRepository) Update(ctx context.Context, article *model.Article, transition *model.ArticleTransition, emit repository.ArticleEventsFunc) error {
+	args := m.Called(article, transition)
+	return m.emit(args.Error(0), emit, transitioned(article, transition))
+}
+
+func (m *MockArticleRepository) Delete(ctx context.Context, id uint, authorID uint, emit repository.ArticleEventsFunc) error {
+	args := m.Called(id, authorID)
+	return m.emit(args.Error(0), emit, &model.Article{ID: id, AuthorID: authorID})
+}
+
+func (m *MockArticleRepository) FindByID(ctx context.Context, id uint) (*model.Article, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Article), args.Error(1)
+}
+
+func (m *MockArticleRepository) List(ctx context.Context, page, pageSize int, status string, authorID uint, tag string, viewer *model.ArticleViewer) ([]model.Article, int64, error) {
+	args := m.Called(page, pageSize, status, authorID, tag, viewer)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]model.Article), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockArticleRepository) UpdateTags(ctx context.Context, article *model.Article, tags []string, placement *model.SeriesPlacement, transition *model.ArticleTransition, emit repository.ArticleEventsFunc) error {
+	args := m.Called(article, tags, placement, transition)
+	return m.emit(args.Error(0), emit, transitioned(article, transition))
+}
+
+// transitioned 与仓库一样在保存内容时应用状态变更
+func transitioned(article *model.Article, transition *model.ArticleTransition) *model.Article {
+	if transition != nil {
+		article.Status = transition.ToStatus
+	}
+	return article
+}
+
+// Transition 返回值为 nil 时表示文章状态已被并发修改，也可以返回按变更记录生成文章的函数
+func (m *MockArticleRepository) Transition(ctx context.Context, transition *model.ArticleTransition, emit repository.ArticleEventsFunc) (*model.Article, error) {
+	args := m.Called(transition)
+	article, _ := args.Get(0).(*model.Article)
+	if build, ok := args.Get(0).(func(*model.ArticleTransition) *model.Article); ok {
+		article = build(transition)
+	}
+	if article == nil {
+		return nil, args.Error(1)
+	}
+	return article, m.emit(args.Error(1), emit, article)
+}
+
+func (m *MockArticleRepository) emit(err error, emit repository.ArticleEventsFunc, article *model.Article) error {
+	if err == nil && emit != nil {
+		m.events = append(m.events, emit(article)...)
+	}
+	return err
+}
+
+func TestArticleService_Tracing(t *testing.T) {
+	exporter, shutdown := tracingtest.NewInMemoryTracer()
+	defer shutdown(context.Background())
+
+	// 测试用例1：成功调用产生 span
+	t.Run("成功调用产生span", func(t *testing.T) {
+		exporter.Reset()
+		mockRepo := new(MockArticleRepository)
+		articleService := &ArticleService{articleRepo: mockRepo}
+
+		article := &model.Article{Title: "title", Content: "content", Status: "draft", AuthorID: 1}
+		mockRepo.On("Create", article, (*model.SeriesPlacement)(nil)).Return(nil)
+
+		err := articleService.CreateArticle(context.Background(), &model.User{ID: 1}, article, nil)
+		assert.NoError(t, err)
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, "ArticleService.CreateArticle", spans[0].Name)
+		assert.Equal(t, codes.Unset, spans[0].Status.Code)
+	})
+
+	// 测试用例2：错误记录到 span
+	t.Run("错误记录到span", func(t *testing.T) {
+		exporter.Reset()
+		mockRepo := new(MockArticleRepository)
+		articleService := &ArticleService{articleRepo: mockRepo}
+
+		mockRepo.On("FindByID", uint(1)).Return(nil, errors.New("database error"))
+
+		article, err := articleService.GetArticle(context.Background(), nil, 1)
+		assert.Error(t, err)
+		assert.Nil(t, article)
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, "ArticleService.GetArticle", spans[0].Name)
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+		assert.Equal(t, "database error", spans[0].Status.Description)
+	})
+}
+
+func TestArticleService_DeleteArticle(t *testing.T) {
+	// 测试用例1：文章不存在
+	t.Run("文章不存在", func(t *testing.T) {
+		mockRepo := new(MockArticleRepository)
+		articleService := &ArticleService{articleRepo: mockRepo}
+
+		mockRepo.On("FindByID", uint(1)).Return(nil, nil)
+
+		err := articleService.DeleteArticle(context.Background(), &model.User{ID: 1}, 1)
+		assert.ErrorIs(t, err, ErrArticleNotFound)
+		assert.ErrorIs(t, err, apperr.ErrNotFound)
+	})
+
+	// 测试用例2：非作者无权删除
+	t.Run("非作者无权删除", func(t *testing.T) {
+		mockRepo := new(MockArticleRepository)
+		articleService := &ArticleService{articleRepo: mockRepo}
+
+		mockRepo.On("FindByID", uint(1)).Return(&model.Article{ID: 1, AuthorID: 2}, nil)
+
+		err := articleService.DeleteArticle(context.Background(), &model.User{ID: 1}, 1)
+		assert.ErrorIs(t, err, ErrArticleForbidden)
+		assert.ErrorIs(t, err, apperr.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Delete", uint(1), uint(1))
+	})
+
+	// 测试用例3：成功删除
+	t.Run("成功删除", func(t *testing.T) {
+		mockRepo := new(MockArticleRepository)
+		articleService := &ArticleService{articleRepo: mockRepo}
+
+		mockRepo.On("FindByID", uint(1)).Return(&model.Article{ID: 1, AuthorID: 1}, nil)
+		mockRepo.On("Delete", uint(1), uint(1)).Return(nil)
+
+		err := articleService.DeleteArticle(context.Background(), &model.User{ID: 1}, 1)
+		assert.NoError(t, err)
+	})
+}
+
+// countingInvalidator 记录失效次数
+type countingInvalidator struct {
+	count int
+}
+
+func (c *countingInvalidator) Invalidate() {
+	c.count++
+}
+
+func TestArticleService_InvalidatePublic(t *testing.T) {
+	// 测试用例1：草稿变化不影响公开内容
+	t.Run("草稿变化不失效", func(t *testing.T) {
+		mockRepo := new(MockArticleRepository)
+		caches := &countingInvalidator{}
+		articleService := &ArticleService{articleRepo: mockRepo, publicCaches: []cacheInvalidator{caches}}
+
+		article := &model.Article{Title: "title", Content: "content", Status: model.ArticleStatusDraft, AuthorID: 1}
+		mockRepo.On("Create", article, (*model.SeriesPlacement)(nil)).Return(nil)
+
+		assert.NoError(t, articleService.CreateArticle(context.Background(), &model.User{ID: 1}, article, nil))
+		assert.Equal(t, 0, caches.count)
+	})
+
+	// 测试用例2：删除已发布的文章
+	t.Run("删除已发布文章后失效", func(t *testing.T) {
+		mockRepo := new(MockArticleRepository)
+		caches := &countingInvalidator{}
+		articleService := &ArticleService{articleRepo: mockRepo, publicCaches: []cacheInvalidator{caches, caches}}
+
+		mockRepo.On("FindByID", uint(1)).Return(&model.Article{ID: 1, AuthorID: 1, Status: model.ArticleStatusPublished}, nil)
+		mockRepo.On("Delete", uint(1), uint(1)).Return(nil)
+
+		assert.NoError(t, articleService.DeleteArticle(context.Background(), &model.User{ID: 1}, 1))
+		assert.Equal(t, 2, caches.count)
+	})
+}
+
+func TestArticleService_GetArticleHidesDrafts(t *testing.T) {
+	draft := &model.Article{ID: 1, AuthorID: 1, Status: model.ArticleStatusDraft}
+
+	// 测试用例1：匿名访客和其他用户看不到草稿
+	t.Run("草稿对他人不可见", func(t *testing.T) {
+		mockRepo := new(MockArticleRepository)
+		articleService := &ArticleService{articleRepo: mockRepo}
+		mockRepo.On("FindByID", uint(1)).Return(draft, nil)
+
+		_, err := articleService.GetArticle(context.Background(), nil, 1)
+		assert.ErrorIs(t, err, ErrArticleNotFound)
+		_, err = articleService.GetArticle(context.Background(), &model.User{ID: 2}, 1)
+		assert.ErrorIs(t, err, ErrArticleNotFound)
+	})
+
+	// 测试用例2：作者可以看到自己的草稿
+	t.Run("作者可见自己的草稿", func(t *testing.T) {
+		mockRepo := new(MockArticleRepository)
+		reactionRepo := new(MockReactionRepository)
+		articleService := &ArticleService{articleRepo: mockRepo, reactionRepo: reactionRepo}
+		mockRepo.On("FindByID", uint(1)).Return(draft, nil)
+		reactionRepo.On("FindByUser", uint(1), []uint{1}).Return([]model.ArticleReaction{}, nil)
+
+		article, err := articleService.GetArticle(context.Background(), &model.User{ID: 1}, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), article.ID)
+	})
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"blog/internal/apperr"
+	"blog/internal/audit"
+	"blog/internal/model"
+	"blog/internal/repository"
+	"blog/internal/tracing"
+	"context"
+	"errors"
+	"strconv"
+)
+
+type AuditService struct {
+	auditRepo repository.IAuditRepository
+}
+
+func NewAuditService() *AuditService {
+	return &AuditService{
+		auditRepo: repository.NewAuditRepository(),
+	}
+}
+
+// List 按条件分页查询审计事件
+func (s *AuditService) List(ctx context.Context, query *model.AuditQuery) (_ []model.AuditEvent, _ int64, err error) {
+	ctx, span := tracing.Start(ctx, "AuditService.List")
+	defer func() { tracing.End(span, err) }()
+
+	if query.Since != nil && query.Until != nil && !query.Since.Before(*query.Until) {
+		return nil, 0, ErrInvalidAuditRange
+	}
+	return s.auditRepo.List(ctx, query)
+}
+
+// recordAudit 记录审计事件，未配置记录器时忽略；err 非空时记为失败并保存错误码
+func recordAudit(ctx context.Context, recorder audit.Recorder, event model.AuditEvent, err error) {
+	if recorder == nil {
+		return
+	}
+	event.Outcome = model.AuditOutcomeSuccess
+	if err != nil {
+		event.Outcome = model.AuditOutcomeFailure
+		event.Detail = auditErrorCode(err)
+	}
+	recorder.Record(ctx, event)
+}
+
+// auditActor 以用户作为审计事件的操作者
+func auditActor(event model.AuditEvent, user *model.User) model.AuditEvent {
+	if user != nil {
+		event.ActorID = user.ID
+		event.Actor = user.Username
+	}
+	return event
+}
+
+// recordAccountAudit 记录用户对自己账号安全设置的操作，目标为用户本人
+func recordAccountAudit(ctx context.Context, recorder audit.Recorder, action string, user *model.User, detail string, err error) {
+	event := auditActor(model.AuditEvent{
+		Action:     action,
+		TargetType: model.AuditTargetUser,
+		TargetID:   auditTarget(user.ID),
+		Detail:     detail,
+	}, user)
+	recordAudit(ctx, recorder, event, err)
+}
+
+// auditTarget 审计事件目标的 ID 字符串
+func auditTarget(id uint) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// auditErrorCode 只保存错误码，不把内部错误信息写入审计表
+func auditErrorCode(err error) string {
+	var appErr *apperr.Error
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return apperr.CodeInternal
+}
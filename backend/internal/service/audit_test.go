@@ -0,0 +1,184 @@
+package service
+
+import (
+	"blog/internal/model"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryAuditRecorder 同步记录审计事件，便于断言
+type memoryAuditRecorder struct {
+	events []model.AuditEvent
+}
+
+func (r *memoryAuditRecorder) Record(ctx context.Context, event model.AuditEvent) {
+	r.events = append(r.events, event)
+}
+
+func TestAuditService_ListRejectsInvertedRange(t *testing.T) {
+	since := time.Now()
+	until := since.Add(-time.Hour)
+	s := &AuditService{}
+
+	_, _, err := s.List(context.Background(), &model.AuditQuery{Since: &since, Until: &until, Page: 1, PageSize: 20})
+	assert.ErrorIs(t, err, ErrInvalidAuditRange)
+}
+
+func TestAuthService_LoginAudit(t *testing.T) {
+	withJWTSecret(t)
+	hash, err := testHasher.Hash("password123")
+	require.NoError(t, err)
+	user := &model.User{ID: 7, Username: "testuser", Email: "test@example.com", Password: hash}
+
+	mockRepo := new(MockUserRepository)
+	mockRepo.On("FindByUsername", "testuser").Return(user, nil)
+	mockRepo.On("FindByUsername", "ghost").Return(nil, nil)
+	recorder := &memoryAuditRecorder{}
+	authService := &AuthService{userRepo: mockRepo, sessionRepo: acceptSessions(), hasher: testHasher, auditor: recorder}
+
+	_, err = authService.Login(context.Background(), &model.LoginRequest{Account: "ghost", Password: "password123"})
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = authService.Login(context.Background(), &model.LoginRequest{Account: "testuser", Password: "wrong"})
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = authService.Login(context.Background(), &model.LoginRequest{Account: "testuser", Password: "password123"})
+	require.NoError(t, err)
+
+	require.Len(t, recorder.events, 3)
+	// 用户不存在时记录尝试的账号
+	assert.Equal(t, model.AuditEvent{
+		Action: model.AuditActionLogin, Actor: "ghost", TargetType: model.AuditTargetUser,
+		Outcome: model.AuditOutcomeFailure, Detail: "invalid_credentials",
+	}, recorder.events[0])
+	assert.Equal(t, uint(7), recorder.events[1].ActorID)
+	assert.Equal(t, model.AuditOutcomeFailure, recorder.events[1].Outcome)
+	assert.Equal(t, model.AuditOutcomeSuccess, recorder.events[2].Outcome)
+	assert.Equal(t, "7", recorder.events[2].TargetID)
+}
+
+func TestArticleService_DeleteAudit(t *testing.T) {
+	mockRepo := new(MockArticleRepository)
+	recorder := &memoryAuditRecorder{}
+	articleService := &ArticleService{articleRepo: mockRepo, auditor: recorder}
+	user := &model.User{ID: 1, Username: "author"}
+
+	mockRepo.On("FindByID", uint(3)).Return(&model.Article{ID: 3, AuthorID: 2}, nil)
+	mockRepo.On("FindByID", uint(4)).Return(&model.Article{ID: 4, AuthorID: 1}, nil)
+	mockRepo.On("Delete", uint(4), uint(1)).Return(errors.New("database error"))
+
+	assert.ErrorIs(t, articleService.DeleteArticle(context.Background(), user, 3), ErrArticleForbidden)
+	assert.Error(t, articleService.DeleteArticle(context.Background(), user, 4))
+
+	require.Len(t, recorder.events, 2)
+	assert.Equal(t, model.AuditActionArticleDelete, recorder.events[0].Action)
+	assert.Equal(t, "3", recorder.events[0].TargetID)
+	assert.Equal(t, "article_forbidden", recorder.events[0].Detail)
+	// 内部错误只记录统一错误码，不写入原始错误信息
+	assert.Equal(t, "internal_error", recorder.events[1].Detail)
+}
+
+func TestUserService_ChangeRole(t *testing.T) {
+	admin := &model.User{ID: 1, Username: "admin", Role: model.RoleAdmin}
+
+	t.Run("不能修改自己的角色", func(t *testing.T) {
+		recorder := &memoryAuditRecorder{}
+		userService := &UserService{userRepo: new(MockUserRepository), auditor: recorder}
+
+		err := userService.ChangeRole(context.Background(), admin, 1, model.RoleUser)
+		assert.ErrorIs(t, err, ErrCannotChangeOwnRole)
+		require.Len(t, recorder.events, 1)
+		assert.Equal(t, model.AuditOutcomeFailure, recorder.events[0].Outcome)
+	})
+
+	t.Run("修改成功", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		recorder := &memoryAuditRecorder{}
+		userService := &UserService{userRepo: mockRepo, auditor: recorder}
+		mockRepo.On("FindByID", uint(2)).Return(&model.User{ID: 2, Role: model.RoleUser}, nil)
+		mockRepo.On("UpdateRole", uint(2), model.RoleAdmin).Return(nil)
+
+		require.NoError(t, userService.ChangeRole(context.Background(), admin, 2, model.RoleAdmin))
+		mockRepo.AssertExpectations(t)
+		require.Len(t, recorder.events, 1)
+		assert.Equal(t, "user->admin", recorder.events[0].Detail)
+		assert.Equal(t, "2", recorder.events[0].TargetID)
+		assert.Equal(t, uint(1), recorder.events[0].ActorID)
+	})
+}
+
+func TestAccountSecurityAudit(t *testing.T) {
+	// 测试用例1：修改密码时旧密码错误也记录审计
+	t.Run("修改密码", func(t *testing.T) {
+		recorder := &memoryAuditRecorder{}
+		userService := &UserService{userRepo: new(MockUserRepository), hasher: testHasher, auditor: recorder}
+		user := hashedUser(t, "oldpassword")
+
+		err := userService.ChangePassword(context.Background(), user, 5, &model.ChangePasswordRequest{
+			OldPassword: "wrongpassword", NewPassword: "newpassword",
+		})
+		assert.ErrorIs(t, err, ErrIncorrectPassword)
+		require.Len(t, recorder.events, 1)
+		assert.Equal(t, model.AuditActionPasswordChange, recorder.events[0].Action)
+		assert.Equal(t, model.AuditOutcomeFailure, recorder.events[0].Outcome)
+		assert.Equal(t, user.ID, recorder.events[0].ActorID)
+	})
+
+	// 测试用例2：创建和撤销个人访问令牌，目标为令牌 ID
+	t.Run("个人访问令牌", func(t *testing.T) {
+		mockTokens := new(MockAPITokenRepository)
+		recorder := &memoryAuditRecorder{}
+		tokenService := &APITokenService{tokenRepo: mockTokens, auditor: recorder}
+		user := &model.User{ID: 1, Username: "ci"}
+
+		mockTokens.On("CountActiveByUser", user.ID).Return(int64(0), nil)
+		mockTokens.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(0).(*model.APIToken).ID = 9
+		}).Return(nil)
+		mockTokens.On("Revoke", uint(9), user.ID).Return(true, nil)
+
+		_, err := tokenService.Create(context.Background(), user, &model.CreateAPITokenRequest{Name: "deploy", Scopes: []string{model.ScopeArticlesRead}})
+		require.NoError(t, err)
+		require.NoError(t, tokenService.Revoke(context.Background(), user, 9))
+
+		require.Len(t, recorder.events, 2)
+		assert.Equal(t, model.AuditActionAPITokenCreate, recorder.events[0].Action)
+		assert.Equal(t, model.AuditTargetToken, recorder.events[0].TargetType)
+		assert.Equal(t, "9", recorder.events[0].TargetID)
+		assert.Equal(t, model.AuditActionAPITokenRevoke, recorder.events[1].Action)
+		assert.Equal(t, model.AuditOutcomeSuccess, recorder.events[1].Outcome)
+	})
+
+	// 测试用例3：撤销不存在的会话记为失败
+	t.Run("撤销会话", func(t *testing.T) {
+		mockSessions := new(MockSessionRepository)
+		recorder := &memoryAuditRecorder{}
+		sessionService := &SessionService{sessionRepo: mockSessions, auditor: recorder}
+		user := &model.User{ID: 1, Username: "testuser"}
+
+		mockSessions.On("Revoke", uint(4), user.ID).Return(false, nil)
+
+		assert.ErrorIs(t, sessionService.Revoke(context.Background(), user, 4), ErrSessionNotFound)
+		require.Len(t, recorder.events, 1)
+		assert.Equal(t, model.AuditActionSessionRevoke, recorder.events[0].Action)
+		assert.Equal(t, "4", recorder.events[0].TargetID)
+		assert.Equal(t, "session_not_found", recorder.events[0].Detail)
+	})
+
+	// 测试用例4：关闭两步验证
+	t.Run("关闭两步验证", func(t *testing.T) {
+		recorder := &memoryAuditRecorder{}
+		twoFactorService := &TwoFactorService{hasher: testHasher, auditor: recorder}
+		user := &model.User{ID: 1, Username: "testuser"}
+
+		assert.ErrorIs(t, twoFactorService.Disable(context.Background(), user, "password", "123456"), ErrTwoFactorNotEnabled)
+		require.Len(t, recorder.events, 1)
+		assert.Equal(t, model.AuditActionTwoFactorDisable, recorder.events[0].Action)
+		assert.Equal(t, model.AuditTargetUser, recorder.events[0].TargetType)
+		assert.Equal(t, "1", recorder.events[0].TargetID)
+	})
+}
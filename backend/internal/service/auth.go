@@ -2,87 +2,323 @@ package service
 
 import (
 	"blog/config"
+	"blog/internal/audit"
+	"blog/internal/clientinfo"
+	"blog/internal/jobs"
+	"blog/internal/jwtkeys"
+	"blog/internal/mailer"
 	"blog/internal/model"
+	"blog/internal/oauth"
+	"blog/internal/password"
+	"blog/internal/ratelimit"
 	"blog/internal/repository"
-	"errors"
+	"blog/internal/tracing"
+	"context"
+	"log"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthService struct {
-	userRepo repository.IUserRepository
+	userRepo     repository.IUserRepository
+	tokenRepo    repository.ITokenRepository
+	recoveryRepo repository.IRecoveryCodeRepository
+	sessionRepo  repository.ISessionRepository
+	apiTokenRepo repository.IAPITokenRepository
+	// 第三方登录
+	identityRepo   repository.IIdentityRepository
+	oauthStateRepo repository.IOAuthStateRepository
+	oauthProviders *oauth.Registry
+	mailer         mailer.Mailer
+	loginGuard     *ratelimit.LoginGuard
+	hasher         password.Hasher
+	policy         *password.Policy
+	keySet         *jwtkeys.KeySet
+	auditor        audit.Recorder
 }
 
 func NewAuthService() *AuthService {
 	return &AuthService{
-		userRepo: repository.NewUserRepository(),
+		userRepo:       repository.NewUserRepository(),
+		tokenRepo:      repository.NewTokenRepository(),
+		recoveryRepo:   repository.NewRecoveryCodeRepository(),
+		sessionRepo:    repository.NewSessionRepository(),
+		apiTokenRepo:   repository.NewAPITokenRepository(),
+		identityRepo:   repository.NewIdentityRepository(),
+		oauthStateRepo: repository.NewOAuthStateRepository(),
+		oauthProviders: oauth.GetRegistry(),
+		mailer:         jobs.GetMailer(),
+		loginGuard:     ratelimit.NewDefaultLoginGuard(),
+		hasher:         password.GetHasher(),
+		policy:         password.GetPolicy(),
+		keySet:         jwtkeys.GetKeySet(),
+		auditor:        audit.GetLogger(),
 	}
 }
 
-func (s *AuthService) Register(req *model.RegisterRequest) (*model.User, error) {
+func (s *AuthService) Register(ctx context.Context, req *model.RegisterRequest) (_ *model.User, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Register")
+	defer func() { tracing.End(span, err) }()
+
+	var user *model.User
+	defer func() { s.auditAuth(ctx, model.AuditActionRegister, req.Username, user, "", err) }()
+
+	if err := s.policy.Validate(req.Password); err != nil {
+		return nil, err
+	}
+
 	// 检查邮箱是否已存在
-	existingUser, err := s.userRepo.FindByEmail(req.Email)
+	existingUser, err := s.userRepo.FindByEmail(ctx, req.Email)
+	if err != nil {
+		return nil, err
+	}
+	if existingUser != nil {
+		return nil, ErrEmailExists
+	}
+
+	// 检查用户名是否已存在
+	existingUser, err = s.userRepo.FindByUsername(ctx, req.Username)
 	if err != nil {
 		return nil, err
 	}
 	if existingUser != nil {
-		return nil, errors.New("email already exists")
+		return nil, ErrUsernameExists
+	}
+
+	hash, err := s.hasher.Hash(req.Password)
+	if err != nil {
+		return nil, err
 	}
 
 	// 创建新用户
-	user := &model.User{
+	user = &model.User{
 		Username: req.Username,
 		Email:    req.Email,
-		Password: req.Password,
+		Password: hash,
 	}
 
-	err = s.userRepo.Create(user)
+	err = s.userRepo.Create(ctx, user, userRegisteredEvents)
 	if err != nil {
 		return nil, err
 	}
 
+	// 发送验证邮件失败不影响注册，用户可稍后重新发送
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		log.Printf("[WARN] failed to send verification email to user %d: %v", user.ID, err)
+	}
+
 	return user, nil
 }
 
-func (s *AuthService) Login(req *model.LoginRequest) (*model.LoginResponse, error) {
+func (s *AuthService) Login(ctx context.Context, req *model.LoginRequest) (resp *model.LoginResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Login")
+	defer func() { tracing.End(span, err) }()
+
+	account := strings.ToLower(req.Identifier())
+	var user *model.User
+	defer func() {
+		s.auditAuth(ctx, model.AuditActionLogin, account, user, twoFactorDetail(resp), err)
+	}()
+
+	// 检查账号是否被锁定或登录过于频繁
+	if s.loginGuard != nil {
+		if err := s.loginGuard.Check(ctx, account); err != nil {
+			return nil, err
+		}
+	}
+
 	// 查找用户
-	user, err := s.userRepo.FindByEmail(req.Email)
+	user, err = s.findByAccount(ctx, req.Identifier())
 	if err != nil {
 		return nil, err
 	}
 	if user == nil {
-		return nil, errors.New("invalid credentials")
+		s.loginFailed(ctx, account)
+		return nil, ErrInvalidCredentials
 	}
 
 	// 验证密码
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
+	if err := s.hasher.Compare(user.Password, req.Password); err != nil {
+		s.loginFailed(ctx, account)
+		return nil, ErrInvalidCredentials
+	}
+	s.rehashIfNeeded(ctx, user, req.Password)
+
+	// 开启两步验证时先返回挑战令牌，失败计数在验证动态码后才清除
+	if user.TwoFactorEnabled {
+		ttl := tokenTTL(config.AppConfig.Auth.TwoFactor.ChallengeTTL, defaultChallengeTTL)
+		challenge, err := issueToken(ctx, s.tokenRepo, user.ID, model.TokenPurposeLoginChallenge, ttl, account)
+		if err != nil {
+			return nil, err
+		}
+		return &model.LoginResponse{TwoFactorRequired: true, ChallengeToken: challenge}, nil
+	}
+	s.loginSucceeded(ctx, account)
+
+	return s.loginResponse(ctx, user)
+}
+
+// LoginTwoFactor 使用挑战令牌和动态码（或恢复码）完成登录，挑战令牌只能使用一次
+func (s *AuthService) LoginTwoFactor(ctx context.Context, challenge, code string) (_ *model.LoginResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.LoginTwoFactor")
+	defer func() { tracing.End(span, err) }()
+
+	var account string
+	var user *model.User
+	defer func() { s.auditAuth(ctx, model.AuditActionLoginTwoFactor, account, user, "", err) }()
+
+	token, err := s.tokenRepo.Consume(ctx, model.TokenPurposeLoginChallenge, hashToken(challenge))
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, ErrInvalidChallenge
+	}
+
+	// 挑战令牌中记录了登录时使用的账号，动态码错误同样计入该账号的失败次数
+	account = token.Payload
+	if s.loginGuard != nil {
+		if err := s.loginGuard.Check(ctx, account); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err = s.userRepo.FindByID(ctx, token.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.TwoFactorEnabled {
+		return nil, ErrInvalidChallenge
+	}
+
+	if err := verifyTwoFactorCode(ctx, s.userRepo, s.recoveryRepo, user, code); err != nil {
+		s.loginFailed(ctx, account)
+		return nil, err
+	}
+	s.loginSucceeded(ctx, account)
+
+	return s.loginResponse(ctx, user)
+}
+
+// loginResponse 创建登录会话，签发关联该会话的 JWT 并组装登录结果
+func (s *AuthService) loginResponse(ctx context.Context, user *model.User) (*model.LoginResponse, error) {
+	keySet := s.tokenKeySet()
+	tokenID, err := newRawToken()
 	if err != nil {
-		return nil, errors.New("invalid credentials")
+		return nil, err
+	}
+
+	now := time.Now()
+	client := clientinfo.From(ctx)
+	session := &model.Session{
+		UserID:     user.ID,
+		TokenID:    tokenID,
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(keySet.TTL()),
+	}
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, err
 	}
 
-	// 生成 token
-	token, err := s.generateToken(user)
+	token, err := s.generateToken(user, tokenID)
 	if err != nil {
 		return nil, err
 	}
 
 	return &model.LoginResponse{
 		Token: token,
-		User:  *user,
+		User:  user,
 	}, nil
 }
 
-func (s *AuthService) generateToken(user *model.User) (string, error) {
-	claims := jwt.MapClaims{
+// findByAccount 按用户名或邮箱查找用户，用户名不允许包含 @，据此区分两者
+func (s *AuthService) findByAccount(ctx context.Context, account string) (*model.User, error) {
+	if strings.Contains(account, "@") {
+		return s.userRepo.FindByEmail(ctx, account)
+	}
+	return s.userRepo.FindByUsername(ctx, account)
+}
+
+// rehashIfNeeded 哈希 cost 调高后，在登录成功时用明文密码重新生成哈希，失败不影响登录
+func (s *AuthService) rehashIfNeeded(ctx context.Context, user *model.User, plain string) {
+	if !s.hasher.NeedsRehash(user.Password) {
+		return
+	}
+	hash, err := s.hasher.Hash(plain)
+	if err == nil {
+		err = s.userRepo.UpdatePassword(ctx, user.ID, hash)
+	}
+	if err != nil {
+		log.Printf("[WARN] failed to rehash password for user %d: %v", user.ID, err)
+		return
+	}
+	user.Password = hash
+}
+
+// loginFailed 记录登录失败，失败次数过多时锁定账号
+func (s *AuthService) loginFailed(ctx context.Context, account string) {
+	if s.loginGuard == nil {
+		return
+	}
+	if err := s.loginGuard.Failed(ctx, account); err != nil {
+		log.Printf("[WARN] failed to record login failure: %v", err)
+	}
+}
+
+// loginSucceeded 登录成功后清除失败记录
+func (s *AuthService) loginSucceeded(ctx context.Context, account string) {
+	if s.loginGuard == nil {
+		return
+	}
+	if err := s.loginGuard.Succeeded(ctx, account); err != nil {
+		log.Printf("[WARN] failed to reset login failures: %v", err)
+	}
+}
+
+// auditAuth 记录认证相关的审计事件，识别出用户时以该用户为操作者，否则记录尝试的账号
+func (s *AuthService) auditAuth(ctx context.Context, action, account string, user *model.User, detail string, err error) {
+	event := model.AuditEvent{
+		Action:     action,
+		Actor:      truncate(account, 100),
+		TargetType: model.AuditTargetUser,
+		Detail:     detail,
+	}
+	if user != nil && user.ID != 0 {
+		event.ActorID = user.ID
+		event.Actor = user.Username
+		event.TargetID = auditTarget(user.ID)
+	}
+	recordAudit(ctx, s.auditor, event, err)
+}
+
+// twoFactorDetail 密码验证通过但仍需动态码时，在审计事件中注明
+func twoFactorDetail(resp *model.LoginResponse) string {
+	if resp != nil && resp.TwoFactorRequired {
+		return "two_factor_required"
+	}
+	return ""
+}
+
+// generateToken 使用当前签名密钥签发访问令牌，iss、aud、exp 由密钥集统一补充
+func (s *AuthService) generateToken(user *model.User, sessionID string) (string, error) {
+	return s.tokenKeySet().Sign(jwt.MapClaims{
+		"sub":      strconv.FormatUint(uint64(user.ID), 10),
+		"sid":      sessionID,
 		"user_id":  user.ID,
 		"username": user.Username,
 		"email":    user.Email,
-		"role":     "user",
-		"exp":      time.Now().Add(time.Hour * 24).Unix(),
-	}
+		"role":     user.Role,
+	})
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(config.AppConfig.JWT.Secret))
+func (s *AuthService) tokenKeySet() *jwtkeys.KeySet {
+	if s.keySet == nil {
+		return jwtkeys.GetKeySet()
+	}
+	return s.keySet
 }
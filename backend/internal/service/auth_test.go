@@ -1,25 +1,48 @@
 package service
 
 import (
+	"blog/config"
+	"blog/internal/apperr"
+	"blog/internal/jwtkeys"
+	"blog/internal/mailer"
 	"blog/internal/model"
+	"blog/internal/password"
+	"blog/internal/ratelimit"
+	"blog/internal/repository"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
+// testHasher 测试使用最低 cost，避免拖慢测试
+var testHasher = password.NewBcryptHasher(bcrypt.MinCost)
+
+// matchesPassword 匹配由 plain 生成的密码哈希
+func matchesPassword(plain string) interface{} {
+	return mock.MatchedBy(func(hash string) bool {
+		return testHasher.Compare(hash, plain) == nil
+	})
+}
+
 // MockUserRepository 模拟用户仓库
 type MockUserRepository struct {
 	mock.Mock
 }
 
-func (m *MockUserRepository) Create(user *model.User) error {
+func (m *MockUserRepository) Create(ctx context.Context, user *model.User, emit repository.UserEventsFunc) error {
 	args := m.Called(user)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) FindByEmail(email string) (*model.User, error) {
+func (m *MockUserRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
 	args := m.Called(email)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -27,7 +50,7 @@ func (m *MockUserRepository) FindByEmail(email string) (*model.User, error) {
 	return args.Get(0).(*model.User), args.Error(1)
 }
 
-func (m *MockUserRepository) FindByID(id uint) (*model.User, error) {
+func (m *MockUserRepository) FindByID(ctx context.Context, id uint) (*model.User, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -35,12 +58,67 @@ func (m *MockUserRepository) FindByID(id uint) (*model.User, error) {
 	return args.Get(0).(*model.User), args.Error(1)
 }
 
-func TestAuthService_Register(t *testing.T) {
-	mockRepo := new(MockUserRepository)
-	authService := &AuthService{userRepo: mockRepo}
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, id uint, password string) error {
+	args := m.Called(id, password)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) MarkEmailVerified(ctx context.Context, id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) FindByUsername(ctx context.Context, username string) (*model.User, error) {
+	args := m.Called(username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateProfile(ctx context.Context, id uint, updates map[string]interface{}) error {
+	args := m.Called(id, updates)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateEmail(ctx context.Context, id uint, email string) error {
+	args := m.Called(id, email)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SetTOTPSecret(ctx context.Context, id uint, secret string) error {
+	args := m.Called(id, secret)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) EnableTwoFactor(ctx context.Context, id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) DisableTwoFactor(ctx context.Context, id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
 
+func (m *MockUserRepository) AdvanceTOTPStep(ctx context.Context, id uint, step int64) (bool, error) {
+	args := m.Called(id, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateRole(ctx context.Context, id uint, role string) error {
+	args := m.Called(id, role)
+	return args.Error(0)
+}
+
+func TestAuthService_Register(t *testing.T) {
 	// 测试用例1：成功注册
 	t.Run("成功注册", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockTokenRepo := new(MockTokenRepository)
+		memMailer := mailer.NewMemoryMailer()
+		authService := &AuthService{userRepo: mockRepo, tokenRepo: mockTokenRepo, mailer: memMailer, hasher: testHasher}
+
 		req := &model.RegisterRequest{
 			Username: "testuser",
 			Email:    "test@example.com",
@@ -48,17 +126,33 @@ func TestAuthService_Register(t *testing.T) {
 		}
 
 		mockRepo.On("FindByEmail", req.Email).Return(nil, nil)
-		mockRepo.On("Create", mock.AnythingOfType("*model.User")).Return(nil)
+		mockRepo.On("FindByUsername", req.Username).Return(nil, nil)
+		// 入库的是密码哈希
+		mockRepo.On("Create", mock.MatchedBy(func(user *model.User) bool {
+			return user.Password != req.Password && testHasher.Compare(user.Password, req.Password) == nil
+		})).Return(nil)
+		mockTokenRepo.On("DeleteByUser", uint(0), model.TokenPurposeVerifyEmail).Return(nil)
+		mockTokenRepo.On("Create", mock.AnythingOfType("*model.UserToken")).Return(nil)
 
-		user, err := authService.Register(req)
+		user, err := authService.Register(context.Background(), req)
 		assert.NoError(t, err)
 		assert.NotNil(t, user)
 		assert.Equal(t, req.Username, user.Username)
 		assert.Equal(t, req.Email, user.Email)
+		assert.False(t, user.EmailVerified)
+
+		// 注册后发送验证邮件
+		msg, ok := memMailer.Last()
+		assert.True(t, ok)
+		assert.Equal(t, req.Email, msg.To)
+		assert.Contains(t, msg.Body, "/auth/verify?token=")
 	})
 
 	// 测试用例2：邮箱已存在
 	t.Run("邮箱已存在", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		authService := &AuthService{userRepo: mockRepo}
+
 		req := &model.RegisterRequest{
 			Username: "testuser",
 			Email:    "existing@example.com",
@@ -72,14 +166,60 @@ func TestAuthService_Register(t *testing.T) {
 
 		mockRepo.On("FindByEmail", req.Email).Return(existingUser, nil)
 
-		user, err := authService.Register(req)
+		user, err := authService.Register(context.Background(), req)
 		assert.Error(t, err)
 		assert.Nil(t, user)
-		assert.Equal(t, "email already exists", err.Error())
+		assert.ErrorIs(t, err, ErrEmailExists)
+		assert.ErrorIs(t, err, apperr.ErrConflict)
 	})
 
-	// 测试用例3：数据库错误
+	// 测试用例3：用户名已存在
+	t.Run("用户名已存在", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		authService := &AuthService{userRepo: mockRepo}
+
+		req := &model.RegisterRequest{
+			Username: "existinguser",
+			Email:    "new@example.com",
+			Password: "password123",
+		}
+
+		mockRepo.On("FindByEmail", req.Email).Return(nil, nil)
+		mockRepo.On("FindByUsername", req.Username).Return(&model.User{Username: req.Username}, nil)
+
+		user, err := authService.Register(context.Background(), req)
+		assert.Nil(t, user)
+		assert.ErrorIs(t, err, ErrUsernameExists)
+		assert.ErrorIs(t, err, apperr.ErrConflict)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	// 测试用例4：密码不符合策略
+	t.Run("弱密码", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		policy := &password.Policy{MinLength: 8, MinClasses: 2}
+		policy.SetDenylist([]string{"Password123"})
+		authService := &AuthService{userRepo: mockRepo, hasher: testHasher, policy: policy}
+
+		for pw, want := range map[string]error{
+			"short1":       password.ErrTooShort,
+			"alllowercase": password.ErrTooWeak,
+			"password123":  password.ErrBreached,
+		} {
+			req := &model.RegisterRequest{Username: "testuser", Email: "test@example.com", Password: pw}
+			user, err := authService.Register(context.Background(), req)
+			assert.Nil(t, user)
+			assert.ErrorIs(t, err, want, pw)
+			assert.ErrorIs(t, err, apperr.ErrValidation, pw)
+		}
+		mockRepo.AssertNotCalled(t, "FindByEmail", mock.Anything)
+	})
+
+	// 测试用例5：数据库错误
 	t.Run("数据库错误", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		authService := &AuthService{userRepo: mockRepo}
+
 		req := &model.RegisterRequest{
 			Username: "testuser",
 			Email:    "test@example.com",
@@ -89,7 +229,7 @@ func TestAuthService_Register(t *testing.T) {
 		dbErr := errors.New("database error")
 		mockRepo.On("FindByEmail", req.Email).Return(nil, dbErr)
 
-		user, err := authService.Register(req)
+		user, err := authService.Register(context.Background(), req)
 		assert.Error(t, err)
 		assert.Nil(t, user)
 		assert.Equal(t, dbErr, err)
@@ -97,11 +237,11 @@ func TestAuthService_Register(t *testing.T) {
 }
 
 func TestAuthService_Login(t *testing.T) {
-	mockRepo := new(MockUserRepository)
-	authService := &AuthService{userRepo: mockRepo}
-
 	// 测试用例1：成功登录
 	t.Run("成功登录", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		authService := &AuthService{userRepo: mockRepo, hasher: testHasher}
+
 		req := &model.LoginRequest{
 			Email:    "test@example.com",
 			Password: "password123",
@@ -116,14 +256,17 @@ func TestAuthService_Login(t *testing.T) {
 
 		mockRepo.On("FindByEmail", req.Email).Return(user, nil)
 
-		response, err := authService.Login(req)
+		response, err := authService.Login(context.Background(), req)
 		assert.Error(t, err) // 由于密码验证会失败，我们期望有错误
 		assert.Nil(t, response)
-		assert.Equal(t, "invalid credentials", err.Error())
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
 	})
 
 	// 测试用例2：用户不存在
 	t.Run("用户不存在", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		authService := &AuthService{userRepo: mockRepo}
+
 		req := &model.LoginRequest{
 			Email:    "nonexistent@example.com",
 			Password: "password123",
@@ -131,14 +274,17 @@ func TestAuthService_Login(t *testing.T) {
 
 		mockRepo.On("FindByEmail", req.Email).Return(nil, nil)
 
-		response, err := authService.Login(req)
+		response, err := authService.Login(context.Background(), req)
 		assert.Error(t, err)
 		assert.Nil(t, response)
-		assert.Equal(t, "invalid credentials", err.Error())
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
 	})
 
 	// 测试用例3：数据库错误
 	t.Run("数据库错误", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		authService := &AuthService{userRepo: mockRepo}
+
 		req := &model.LoginRequest{
 			Email:    "test@example.com",
 			Password: "password123",
@@ -147,9 +293,124 @@ func TestAuthService_Login(t *testing.T) {
 		dbErr := errors.New("database error")
 		mockRepo.On("FindByEmail", req.Email).Return(nil, dbErr)
 
-		response, err := authService.Login(req)
+		response, err := authService.Login(context.Background(), req)
 		assert.Error(t, err)
 		assert.Nil(t, response)
 		assert.Equal(t, dbErr, err)
 	})
+
+	// 测试用例4：使用用户名或邮箱登录
+	t.Run("用户名或邮箱登录", func(t *testing.T) {
+		withJWTSecret(t)
+		hash, err := testHasher.Hash("password123")
+		require.NoError(t, err)
+		user := &model.User{ID: 1, Username: "testuser", Email: "test@example.com", Password: hash}
+
+		mockRepo := new(MockUserRepository)
+		authService := &AuthService{userRepo: mockRepo, sessionRepo: acceptSessions(), hasher: testHasher}
+		mockRepo.On("FindByUsername", "testuser").Return(user, nil)
+		mockRepo.On("FindByEmail", "test@example.com").Return(user, nil)
+
+		for _, req := range []*model.LoginRequest{
+			{Account: "testuser", Password: "password123"},
+			{Account: "test@example.com", Password: "password123"},
+			{Email: "test@example.com", Password: "password123"},
+		} {
+			response, err := authService.Login(context.Background(), req)
+			require.NoError(t, err)
+			assert.NotEmpty(t, response.Token)
+			assert.Equal(t, user.ID, response.User.ID)
+		}
+		mockRepo.AssertNumberOfCalls(t, "FindByUsername", 1)
+		mockRepo.AssertNumberOfCalls(t, "FindByEmail", 2)
+		// cost 未变化时不重新哈希
+		mockRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthService_LoginRehash(t *testing.T) {
+	withJWTSecret(t)
+	oldHash, err := testHasher.Hash("password123")
+	require.NoError(t, err)
+	user := &model.User{ID: 1, Username: "testuser", Password: oldHash}
+
+	// cost 调高后，登录成功时透明升级哈希
+	hasher := password.NewBcryptHasher(bcrypt.MinCost + 1)
+	mockRepo := new(MockUserRepository)
+	authService := &AuthService{userRepo: mockRepo, sessionRepo: acceptSessions(), hasher: hasher}
+	mockRepo.On("FindByUsername", "testuser").Return(user, nil)
+	mockRepo.On("UpdatePassword", user.ID, mock.MatchedBy(func(hash string) bool {
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err == nil && cost == bcrypt.MinCost+1 && hasher.Compare(hash, "password123") == nil
+	})).Return(nil)
+
+	_, err = authService.Login(context.Background(), &model.LoginRequest{Account: "testuser", Password: "password123"})
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	assert.False(t, hasher.NeedsRehash(user.Password))
+
+	// 密码错误时不升级
+	mockRepo.Calls = nil
+	user.Password = oldHash
+	_, err = authService.Login(context.Background(), &model.LoginRequest{Account: "testuser", Password: "wrong"})
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	mockRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything)
+}
+
+func TestAuthService_GenerateToken(t *testing.T) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key, err := jwtkeys.NewPrivateKey("2026-10", jwtkeys.AlgEdDSA, private)
+	require.NoError(t, err)
+	keySet, err := jwtkeys.NewKeySet("2026-10", jwtkeys.Options{Issuer: "blog", Audience: "blog-api"}, key)
+	require.NoError(t, err)
+
+	authService := &AuthService{keySet: keySet}
+	tokenString, err := authService.generateToken(&model.User{ID: 5, Username: "admin", Role: model.RoleAdmin}, "session-1")
+	require.NoError(t, err)
+
+	claims, err := keySet.Parse(tokenString)
+	require.NoError(t, err)
+	assert.Equal(t, "5", claims["sub"])
+	assert.Equal(t, float64(5), claims["user_id"])
+	// 角色取自用户，而不是固定为 user
+	assert.Equal(t, model.RoleAdmin, claims["role"])
+	assert.Equal(t, "session-1", claims["sid"])
+}
+
+// withJWTSecret 为测试设置 JWT 密钥，结束后恢复
+func withJWTSecret(t *testing.T) {
+	original := config.AppConfig.JWT.Secret
+	config.AppConfig.JWT.Secret = "test-secret"
+	t.Cleanup(func() { config.AppConfig.JWT.Secret = original })
+}
+
+func TestAuthService_LoginLockout(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	authService := &AuthService{
+		userRepo: mockRepo,
+		loginGuard: ratelimit.NewLoginGuard(ratelimit.NewMemoryStore(), ratelimit.Limit{Rate: 100, Burst: 100}, ratelimit.LockoutPolicy{
+			MaxFailures:  3,
+			BaseDuration: time.Minute,
+			MaxDuration:  time.Hour,
+			Window:       time.Hour,
+		}),
+	}
+
+	req := &model.LoginRequest{
+		Email:    "test@example.com",
+		Password: "wrongpassword",
+	}
+	mockRepo.On("FindByEmail", req.Email).Return(nil, nil)
+
+	// 前几次失败返回凭证错误
+	for i := 0; i < 3; i++ {
+		_, err := authService.Login(context.Background(), req)
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	}
+
+	// 达到阈值后账号被锁定，且不再查询数据库
+	_, err := authService.Login(context.Background(), req)
+	assert.ErrorIs(t, err, ratelimit.ErrAccountLocked)
+	mockRepo.AssertNumberOfCalls(t, "FindByEmail", 3)
 }
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"blog/internal/audit"
+	"blog/internal/model"
+	"blog/internal/repository"
+	"blog/internal/tracing"
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type CollaboratorService struct {
+	articleRepo repository.IArticleRepository
+	userRepo    repository.IUserRepository
+	collabRepo  repository.ICollaboratorRepository
+	auditor     audit.Recorder
+}
+
+func NewCollaboratorService() *CollaboratorService {
+	return &CollaboratorService{
+		articleRepo: repository.NewArticleRepository(),
+		userRepo:    repository.NewUserRepository(),
+		collabRepo:  repository.NewCollaboratorRepository(),
+		auditor:     audit.GetLogger(),
+	}
+}
+
+// List 列出文章的协作者，作者和协作者可以查看
+func (s *CollaboratorService) List(ctx context.Context, user *model.User, articleID uint) (_ []model.ArticleCollaborator, err error) {
+	ctx, span := tracing.Start(ctx, "CollaboratorService.List",
+		attribute.Int("article.id", int(articleID)))
+	defer func() { tracing.End(span, err) }()
+
+	article, err := s.findArticle(ctx, articleID)
+	if err != nil {
+		return nil, err
+	}
+	role, err := articleRole(ctx, s.collabRepo, user, article)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, hiddenArticleError(article)
+	}
+	return s.collabRepo.ListByArticle(ctx, articleID)
+}
+
+// Invite 作者按用户名添加协作者，用户已是协作者时修改其角色
+func (s *CollaboratorService) Invite(ctx context.Context, user *model.User, articleID uint, req *model.InviteCollaboratorRequest) (_ *model.ArticleCollaborator, err error) {
+	ctx, span := tracing.Start(ctx, "CollaboratorService.Invite",
+		attribute.Int("article.id", int(articleID)),
+		attribute.String("collaborator.role", req.Role))
+	defer func() { tracing.End(span, err) }()
+	defer func() {
+		s.audit(ctx, model.AuditActionCollaboratorInvite, user, articleID, req.Username+":"+req.Role, err)
+	}()
+
+	article, err := s.findOwnedArticle(ctx, user, articleID)
+	if err != nil {
+		return nil, err
+	}
+	invitee, err := s.userRepo.FindByUsername(ctx, req.Username)
+	if err != nil {
+		return nil, err
+	}
+	if invitee == nil {
+		return nil, ErrUserNotFound
+	}
+	if invitee.ID == article.AuthorID {
+		return nil, ErrCannotInviteAuthor
+	}
+
+	collaborator := &model.ArticleCollaborator{
+		ArticleID: articleID,
+		UserID:    invitee.ID,
+		User:      invitee.Public(),
+		Role:      req.Role,
+		InvitedBy: user.ID,
+	}
+	if err := s.collabRepo.Upsert(ctx, collaborator); err != nil {
+		return nil, err
+	}
+	return collaborator, nil
+}
+
+// Remove 作者移除协作者，协作者也可以移除自己退出协作
+func (s *CollaboratorService) Remove(ctx context.Context, user *model.User, articleID, userID uint) (err error) {
+	ctx, span := tracing.Start(ctx, "CollaboratorService.Remove",
+		attribute.Int("article.id", int(articleID)),
+		attribute.Int("collaborator.user_id", int(userID)))
+	defer func() { tracing.End(span, err) }()
+	defer func() {
+		s.audit(ctx, model.AuditActionCollaboratorRemove, user, articleID, auditTarget(userID), err)
+	}()
+
+	if userID != user.ID {
+		if _, err := s.findOwnedArticle(ctx, user, articleID); err != nil {
+			return err
+		}
+	}
+	removed, err := s.collabRepo.Remove(ctx, articleID, userID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrCollaboratorNotFound
+	}
+	return nil
+}
+
+func (s *CollaboratorService) findArticle(ctx context.Context, articleID uint) (*model.Article, error) {
+	article, err := s.articleRepo.FindByID(ctx, articleID)
+	if err != nil {
+		return nil, err
+	}
+	if article == nil {
+		return nil, ErrArticleNotFound
+	}
+	return article, nil
+}
+
+// findOwnedArticle 管理协作者只有作者可以操作
+func (s *CollaboratorService) findOwnedArticle(ctx context.Context, user *model.User, articleID uint) (*model.Article, error) {
+	article, err := s.findArticle(ctx, articleID)
+	if err != nil {
+		return nil, err
+	}
+	if article.AuthorID == user.ID {
+		return article, nil
+	}
+	role, err := s.collabRepo.FindRole(ctx, articleID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, hiddenArticleError(article)
+	}
+	return nil, ErrArticleForbidden
+}
+
+// audit 记录协作者变更的审计事件，detail 为被操作的用户
+func (s *CollaboratorService) audit(ctx context.Context, action string, user *model.User, articleID uint, detail string, err error) {
+	event := auditActor(model.AuditEvent{
+		Action:     action,
+		TargetType: model.AuditTargetArticle,
+		TargetID:   auditTarget(articleID),
+		Detail:     detail,
+	}, user)
+	recordAudit(ctx, s.auditor, event, err)
+}
+
+// hiddenArticleError 无关用户访问草稿时视为不存在，访问已发布文章的受限操作时为无权限
+func hiddenArticleError(article *model.Article) error {
+	if article.Status == model.ArticleStatusPublished {
+		return ErrArticleForbidden
+	}
+	return ErrArticleNotFound
+}
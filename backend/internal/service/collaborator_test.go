@@ -0,0 +1,180 @@
+package service
+
+import (
+	"blog/internal/model"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockCollaboratorRepository 模拟文章协作者仓库
+type MockCollaboratorRepository struct {
+	mock.Mock
+}
+
+func (m *MockCollaboratorRepository) Upsert(ctx context.Context, collaborator *model.ArticleCollaborator) error {
+	return m.Called(collaborator).Error(0)
+}
+
+func (m *MockCollaboratorRepository) Remove(ctx context.Context, articleID, userID uint) (bool, error) {
+	args := m.Called(articleID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCollaboratorRepository) ListByArticle(ctx context.Context, articleID uint) ([]model.ArticleCollaborator, error) {
+	args := m.Called(articleID)
+	return args.Get(0).([]model.ArticleCollaborator), args.Error(1)
+}
+
+func (m *MockCollaboratorRepository) FindRole(ctx context.Context, articleID, userID uint) (string, error) {
+	args := m.Called(articleID, userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockCollaboratorRepository) ListEditable(ctx context.Context, userID uint, page, pageSize int) ([]model.Article, int64, error) {
+	args := m.Called(userID, page, pageSize)
+	return args.Get(0).([]model.Article), args.Get(1).(int64), args.Error(2)
+}
+
+// sharedDraftRepos 作者为 1 的草稿，用户 2 是编辑者，用户 3 是查看者
+func sharedDraftRepos() (*MockArticleRepository, *MockCollaboratorRepository) {
+	articleRepo := new(MockArticleRepository)
+	articleRepo.On("FindByID", uint(1)).Return(&model.Article{ID: 1, AuthorID: 1, Status: model.ArticleStatusDraft}, nil)
+	collabRepo := new(MockCollaboratorRepository)
+	collabRepo.On("FindRole", uint(1), uint(2)).Return(model.CollaboratorEditor, nil)
+	collabRepo.On("FindRole", uint(1), uint(3)).Return(model.CollaboratorViewer, nil)
+	collabRepo.On("FindRole", uint(1), uint(4)).Return("", nil)
+	return articleRepo, collabRepo
+}
+
+func TestArticleService_CollaboratorPermissions(t *testing.T) {
+	articleRepo, collabRepo := sharedDraftRepos()
+	articleRepo.On("UpdateTags", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	reactionRepo := new(MockReactionRepository)
+	reactionRepo.On("FindByUser", mock.Anything, mock.Anything).Return([]model.ArticleReaction{}, nil)
+	s := &ArticleService{articleRepo: articleRepo, reactionRepo: reactionRepo, collabRepo: collabRepo}
+	ctx := context.Background()
+	update := func(userID uint) error {
+		article := &model.Article{ID: 1, Title: "t", Content: "c", Status: model.ArticleStatusDraft}
+		return s.UpdateArticleWithTags(ctx, &model.User{ID: userID}, article, nil, nil)
+	}
+
+	// 编辑者可以修改，查看者不能修改，无关用户看不到草稿
+	assert.NoError(t, update(2))
+	assert.ErrorIs(t, update(3), ErrArticleForbidden)
+	assert.ErrorIs(t, update(4), ErrArticleNotFound)
+
+	// 协作者都能看到草稿
+	for _, userID := range []uint{2, 3} {
+		_, err := s.GetArticle(ctx, &model.User{ID: userID}, 1)
+		assert.NoError(t, err, "user %d", userID)
+	}
+	_, err := s.GetArticle(ctx, &model.User{ID: 4}, 1)
+	assert.ErrorIs(t, err, ErrArticleNotFound)
+
+	// 只有作者可以删除
+	assert.ErrorIs(t, s.DeleteArticle(ctx, &model.User{ID: 2}, 1), ErrArticleForbidden)
+
+	// 编辑者不能调整文章所在系列
+	article := &model.Article{ID: 1, Status: model.ArticleStatusDraft}
+	err = s.UpdateArticleWithTags(ctx, &model.User{ID: 2}, article, nil, &model.SeriesPlacement{})
+	assert.ErrorIs(t, err, ErrArticleForbidden)
+}
+
+func TestCollaboratorService_Invite(t *testing.T) {
+	author := &model.User{ID: 1, Username: "author"}
+	articleRepo, collabRepo := sharedDraftRepos()
+	collabRepo.On("Upsert", mock.Anything).Return(nil)
+	userRepo := new(MockUserRepository)
+	userRepo.On("FindByUsername", "bob").Return(&model.User{ID: 5, Username: "bob"}, nil)
+	userRepo.On("FindByUsername", "author").Return(author, nil)
+	userRepo.On("FindByUsername", "ghost").Return(nil, nil)
+	recorder := &memoryAuditRecorder{}
+	s := &CollaboratorService{articleRepo: articleRepo, userRepo: userRepo, collabRepo: collabRepo, auditor: recorder}
+	ctx := context.Background()
+
+	collaborator, err := s.Invite(ctx, author, 1, &model.InviteCollaboratorRequest{Username: "bob", Role: model.CollaboratorEditor})
+	require.NoError(t, err)
+	assert.Equal(t, uint(5), collaborator.UserID)
+	assert.Equal(t, uint(1), collaborator.InvitedBy)
+
+	_, err = s.Invite(ctx, author, 1, &model.InviteCollaboratorRequest{Username: "ghost", Role: model.CollaboratorViewer})
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	_, err = s.Invite(ctx, author, 1, &model.InviteCollaboratorRequest{Username: "author", Role: model.CollaboratorViewer})
+	assert.ErrorIs(t, err, ErrCannotInviteAuthor)
+
+	// 编辑者不能邀请他人，无关用户看不到草稿
+	_, err = s.Invite(ctx, &model.User{ID: 2}, 1, &model.InviteCollaboratorRequest{Username: "bob", Role: model.CollaboratorViewer})
+	assert.ErrorIs(t, err, ErrArticleForbidden)
+	_, err = s.Invite(ctx, &model.User{ID: 4}, 1, &model.InviteCollaboratorRequest{Username: "bob", Role: model.CollaboratorViewer})
+	assert.ErrorIs(t, err, ErrArticleNotFound)
+
+	collabRepo.AssertNumberOfCalls(t, "Upsert", 1)
+	require.Len(t, recorder.events, 5)
+	assert.Equal(t, model.AuditEvent{
+		ActorID: 1, Actor: "author", Action: model.AuditActionCollaboratorInvite, TargetType: model.AuditTargetArticle,
+		TargetID: "1", Outcome: model.AuditOutcomeSuccess, Detail: "bob:editor",
+	}, recorder.events[0])
+}
+
+func TestCollaboratorService_ListHidesAccountFields(t *testing.T) {
+	articleRepo, collabRepo := sharedDraftRepos()
+	editor := model.User{ID: 2, Username: "bob", Email: "bob@example.com", Role: model.RoleAdmin, EmailVerified: true}
+	collabRepo.On("ListByArticle", uint(1)).Return([]model.ArticleCollaborator{
+		{ArticleID: 1, UserID: 2, User: editor.Public(), Role: model.CollaboratorEditor},
+	}, nil)
+	s := &CollaboratorService{articleRepo: articleRepo, collabRepo: collabRepo}
+
+	// 查看者也能列出协作者，但只能看到其他协作者的公开资料
+	collaborators, err := s.List(context.Background(), &model.User{ID: 3}, 1)
+	require.NoError(t, err)
+	require.Len(t, collaborators, 1)
+	data, err := json.Marshal(collaborators[0].User)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"username":"bob"`)
+	for _, field := range []string{`"email"`, `"role"`, `"email_verified"`, `"two_factor_enabled"`} {
+		assert.NotContains(t, string(data), field)
+	}
+}
+
+func TestCollaboratorService_Remove(t *testing.T) {
+	articleRepo, collabRepo := sharedDraftRepos()
+	collabRepo.On("Remove", uint(1), uint(2)).Return(true, nil)
+	collabRepo.On("Remove", uint(1), uint(3)).Return(true, nil)
+	collabRepo.On("Remove", uint(1), uint(9)).Return(false, nil)
+	s := &CollaboratorService{articleRepo: articleRepo, collabRepo: collabRepo}
+	ctx := context.Background()
+
+	// 作者移除协作者，协作者退出，其他协作者不能移除别人
+	assert.NoError(t, s.Remove(ctx, &model.User{ID: 1}, 1, 2))
+	assert.NoError(t, s.Remove(ctx, &model.User{ID: 3}, 1, 3))
+	assert.ErrorIs(t, s.Remove(ctx, &model.User{ID: 2}, 1, 3), ErrArticleForbidden)
+	assert.ErrorIs(t, s.Remove(ctx, &model.User{ID: 1}, 1, 9), ErrCollaboratorNotFound)
+}
+
+func TestArticleService_ListArticlesVisibility(t *testing.T) {
+	articleRepo := new(MockArticleRepository)
+	articleRepo.On("List", 1, 10, model.ArticleStatusDraft, uint(1), "", mock.Anything).Return([]model.Article{}, int64(0), nil)
+	reactionRepo := new(MockReactionRepository)
+	reactionRepo.On("FindByUser", mock.Anything, mock.Anything).Return([]model.ArticleReaction{}, nil)
+	s := &ArticleService{articleRepo: articleRepo, reactionRepo: reactionRepo}
+	ctx := context.Background()
+
+	// 按状态查询他人的文章时，仓库只返回当前用户作为作者、协作者或审核员可见的
+	_, _, err := s.ListArticles(ctx, &model.User{ID: 2}, 1, 10, model.ArticleStatusDraft, 1, "")
+	require.NoError(t, err)
+	_, _, err = s.ListArticles(ctx, &model.User{ID: 4, Role: model.RoleReviewer}, 1, 10, model.ArticleStatusDraft, 1, "")
+	require.NoError(t, err)
+	_, _, err = s.ListArticles(ctx, nil, 1, 10, model.ArticleStatusDraft, 1, "")
+	require.NoError(t, err)
+
+	var viewers []*model.ArticleViewer
+	for _, call := range articleRepo.Calls {
+		viewers = append(viewers, call.Arguments.Get(5).(*model.ArticleViewer))
+	}
+	assert.Equal(t, []*model.ArticleViewer{{UserID: 2}, {UserID: 4, Reviewer: true}, nil}, viewers)
+}
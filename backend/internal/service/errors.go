@@ -0,0 +1,100 @@
+package service
+
+import "blog/internal/apperr"
+
+// 文章相关错误
+var (
+	ErrArticleNotFound      = apperr.New(apperr.ErrNotFound, "article_not_found", "文章不存在")
+	ErrArticleForbidden     = apperr.New(apperr.ErrForbidden, "article_forbidden", "无权限操作该文章")
+	ErrInvalidPopularWindow = apperr.New(apperr.ErrValidation, "invalid_popular_window", "不支持的统计范围：%s")
+	ErrEmailNotVerified     = apperr.New(apperr.ErrForbidden, "email_not_verified", "请先验证邮箱后再发布文章")
+)
+
+// 用户认证相关错误
+var (
+	ErrEmailExists          = apperr.New(apperr.ErrConflict, "email_exists", "邮箱已存在")
+	ErrUsernameExists       = apperr.New(apperr.ErrConflict, "username_exists", "用户名已存在")
+	ErrInvalidCredentials   = apperr.New(apperr.ErrUnauthorized, "invalid_credentials", "账号或密码错误")
+	ErrInvalidToken         = apperr.New(apperr.ErrValidation, "invalid_or_expired_token", "链接无效或已过期")
+	ErrEmailAlreadyVerified = apperr.New(apperr.ErrConflict, "email_already_verified", "邮箱已验证")
+)
+
+// 用户资料相关错误
+var (
+	ErrUserNotFound      = apperr.New(apperr.ErrNotFound, "user_not_found", "用户不存在")
+	ErrIncorrectPassword = apperr.New(apperr.ErrValidation, "incorrect_password", "密码错误")
+	ErrSameEmail         = apperr.New(apperr.ErrValidation, "same_email", "新邮箱不能与当前邮箱相同")
+)
+
+// 两步验证相关错误
+var (
+	ErrTwoFactorAlreadyEnabled = apperr.New(apperr.ErrConflict, "two_factor_already_enabled", "两步验证已开启")
+	ErrTwoFactorNotEnabled     = apperr.New(apperr.ErrValidation, "two_factor_not_enabled", "两步验证未开启")
+	ErrTwoFactorNotSetup       = apperr.New(apperr.ErrValidation, "two_factor_not_setup", "请先获取两步验证密钥")
+	ErrInvalidTwoFactorCode    = apperr.New(apperr.ErrValidation, "invalid_two_factor_code", "动态码或恢复码错误")
+	ErrInvalidChallenge        = apperr.New(apperr.ErrUnauthorized, "two_factor_challenge_invalid", "登录验证已失效，请重新登录")
+	ErrTwoFactorRequired       = apperr.New(apperr.ErrForbidden, "two_factor_required", "管理员账号必须开启两步验证")
+)
+
+// 第三方登录相关错误
+var (
+	ErrOAuthProviderNotFound = apperr.New(apperr.ErrNotFound, "oauth_provider_not_found", "不支持该第三方登录方式")
+	ErrOAuthInvalidState     = apperr.New(apperr.ErrValidation, "oauth_invalid_state", "授权已失效，请重新登录")
+	ErrOAuthFailed           = apperr.New(apperr.ErrUnauthorized, "oauth_failed", "第三方登录失败，请稍后重试")
+	ErrOAuthEmailUnverified  = apperr.New(apperr.ErrForbidden, "oauth_email_unverified", "第三方账号没有已验证的邮箱，无法登录")
+)
+
+// 个人访问令牌相关错误
+var (
+	ErrAPITokenNotFound = apperr.New(apperr.ErrNotFound, "api_token_not_found", "令牌不存在")
+	ErrTooManyAPITokens = apperr.New(apperr.ErrValidation, "too_many_api_tokens", "最多只能创建 %d 个令牌")
+)
+
+// 登录会话相关错误
+var (
+	ErrSessionNotFound = apperr.New(apperr.ErrNotFound, "session_not_found", "会话不存在")
+)
+
+// 审计与用户管理相关错误
+var (
+	ErrInvalidAuditRange   = apperr.New(apperr.ErrValidation, "invalid_audit_range", "开始时间必须早于结束时间")
+	ErrCannotChangeOwnRole = apperr.New(apperr.ErrForbidden, "cannot_change_own_role", "不能修改自己的角色")
+)
+
+// webhook 相关错误
+var (
+	ErrWebhookNotFound         = apperr.New(apperr.ErrNotFound, "webhook_not_found", "webhook 不存在")
+	ErrWebhookDeliveryNotFound = apperr.New(apperr.ErrNotFound, "webhook_delivery_not_found", "投递记录不存在")
+	ErrTooManyWebhooks         = apperr.New(apperr.ErrValidation, "too_many_webhooks", "最多只能创建 %d 个 webhook")
+	ErrInvalidWebhookURL       = apperr.New(apperr.ErrValidation, "invalid_webhook_url", "webhook 地址必须是 http 或 https 地址")
+	ErrWebhookEventForbidden   = apperr.New(apperr.ErrForbidden, "webhook_event_forbidden", "无权订阅事件：%s")
+)
+
+// 文章审核相关错误
+var (
+	ErrInvalidTransition      = apperr.New(apperr.ErrValidation, "invalid_status_transition", "文章不能从 %s 变更为 %s")
+	ErrTransitionForbidden    = apperr.New(apperr.ErrForbidden, "transition_forbidden", "无权限将文章变更为 %s")
+	ErrReviewRequired         = apperr.New(apperr.ErrValidation, "review_required", "文章需要审核通过后才能发布")
+	ErrArticleStatusChanged   = apperr.New(apperr.ErrConflict, "article_status_changed", "文章状态已被修改，请刷新后重试")
+	ErrCannotReviewOwnArticle = apperr.New(apperr.ErrForbidden, "cannot_review_own_article", "不能审核自己编写的文章")
+)
+
+// 文章协作者相关错误
+var (
+	ErrCollaboratorNotFound = apperr.New(apperr.ErrNotFound, "collaborator_not_found", "协作者不存在")
+	ErrCannotInviteAuthor   = apperr.New(apperr.ErrValidation, "cannot_invite_author", "不能把作者添加为协作者")
+)
+
+// 系列文章相关错误
+var (
+	ErrSeriesNotFound     = apperr.New(apperr.ErrNotFound, "series_not_found", "系列不存在")
+	ErrSeriesForbidden    = apperr.New(apperr.ErrForbidden, "series_forbidden", "无权限操作该系列")
+	ErrArticleNotInSeries = apperr.New(apperr.ErrNotFound, "article_not_in_series", "文章不在该系列中")
+	ErrInvalidSeriesOrder = apperr.New(apperr.ErrValidation, "invalid_series_order", "排序必须包含系列中的全部文章且不能重复")
+)
+
+// 后台任务相关错误
+var (
+	ErrJobNotFound     = apperr.New(apperr.ErrNotFound, "job_not_found", "任务不存在")
+	ErrJobNotRetryable = apperr.New(apperr.ErrConflict, "job_not_retryable", "只能重试死信任务")
+)
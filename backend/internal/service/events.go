@@ -0,0 +1,44 @@
+package service
+
+import (
+	"blog/config"
+	"blog/internal/events"
+	"blog/internal/model"
+	"blog/internal/repository"
+	"strconv"
+	"strings"
+)
+
+// articleEmitter 返回在仓库事务内生成文章事件的函数，事件随文章一起写入 outbox
+func articleEmitter(eventType, previousStatus string) repository.ArticleEventsFunc {
+	return func(article *model.Article) []events.Event {
+		return articleEvents(eventType, article, previousStatus)
+	}
+}
+
+// articleEvents 文章写操作对应的事件：首次变为已发布时在 created/updated 之后追加 published
+func articleEvents(eventType string, article *model.Article, previousStatus string) []events.Event {
+	data := events.ArticleData{
+		ID:             article.ID,
+		Title:          article.Title,
+		Status:         article.Status,
+		PreviousStatus: previousStatus,
+		AuthorID:       article.AuthorID,
+		URL:            strings.TrimRight(config.AppConfig.Site.URL, "/") + "/articles/" + strconv.FormatUint(uint64(article.ID), 10),
+	}
+	for _, tag := range article.Tags {
+		data.Tags = append(data.Tags, tag.Name)
+	}
+
+	result := []events.Event{events.New(eventType, article.AuthorID, data)}
+	if eventType != events.ArticleDeleted &&
+		article.Status == model.ArticleStatusPublished && previousStatus != model.ArticleStatusPublished {
+		result = append(result, events.New(events.ArticlePublished, article.AuthorID, data))
+	}
+	return result
+}
+
+// userRegisteredEvents 新用户注册事件，只推送给管理员
+func userRegisteredEvents(user *model.User) []events.Event {
+	return []events.Event{events.New(events.UserRegistered, user.ID, events.UserData{ID: user.ID, Username: user.Username})}
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"blog/config"
+	"blog/internal/feed"
+	"blog/internal/model"
+	"blog/internal/repository"
+	"blog/internal/tracing"
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	defaultFeedItems = 20
+	maxFeedItems     = 100
+)
+
+type FeedService struct {
+	articleRepo repository.IArticleRepository
+	userRepo    repository.IUserRepository
+}
+
+func NewFeedService() *FeedService {
+	return &FeedService{
+		articleRepo: repository.NewArticleRepository(),
+		userRepo:    repository.NewUserRepository(),
+	}
+}
+
+// Build 生成已发布文章的订阅源，username 或 tag 不为空时只包含该作者或标签的文章
+// selfPath 为订阅源自身的请求路径
+func (s *FeedService) Build(ctx context.Context, username, tag, selfPath string) (_ *feed.Feed, err error) {
+	ctx, span := tracing.Start(ctx, "FeedService.Build",
+		attribute.String("feed.author", username),
+		attribute.String("feed.tag", tag))
+	defer func() { tracing.End(span, err) }()
+
+	site := config.AppConfig.Site
+	siteURL := strings.TrimRight(site.URL, "/")
+	f := &feed.Feed{
+		Title:       site.Title,
+		Description: site.Description,
+		Link:        siteURL,
+		SelfURL:     strings.TrimRight(site.APIURL, "/") + selfPath,
+	}
+
+	var authorID uint
+	if username != "" {
+		author, err := s.userRepo.FindByUsername(ctx, username)
+		if err != nil {
+			return nil, err
+		}
+		if author == nil {
+			return nil, ErrUserNotFound
+		}
+		authorID = author.ID
+		f.Title += " - " + displayName(author.Public())
+		f.Link = siteURL + "/users/" + url.PathEscape(author.Username)
+	}
+	if tag != "" {
+		f.Title += " - #" + tag
+		f.Link = siteURL + "/tags/" + url.PathEscape(tag)
+	}
+
+	articles, _, err := s.articleRepo.List(ctx, 1, feedItemCount(), model.ArticleStatusPublished, authorID, tag, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, article := range articles {
+		content, err := feed.RenderMarkdown(article.Content)
+		if err != nil {
+			return nil, err
+		}
+		link := siteURL + "/articles/" + strconv.FormatUint(uint64(article.ID), 10)
+		entry := feed.Entry{
+			ID:          link,
+			Title:       article.Title,
+			Link:        link,
+			Author:      displayName(article.Author),
+			Summary:     feed.Summary(article.Content),
+			ContentHTML: content,
+			Published:   article.CreatedAt,
+			Updated:     article.UpdatedAt,
+		}
+		for _, t := range article.Tags {
+			entry.Categories = append(entry.Categories, t.Name)
+		}
+		f.Entries = append(f.Entries, entry)
+		if article.UpdatedAt.After(f.Updated) {
+			f.Updated = article.UpdatedAt
+		}
+	}
+	return f, nil
+}
+
+// feedItemCount 订阅源文章数，未配置时使用默认值
+func feedItemCount() int {
+	n := config.AppConfig.Feed.ItemCount
+	if n <= 0 {
+		return defaultFeedItems
+	}
+	if n > maxFeedItems {
+		return maxFeedItems
+	}
+	return n
+}
+
+// displayName 优先使用昵称
+func displayName(user model.PublicUser) string {
+	if user.DisplayName != "" {
+		return user.DisplayName
+	}
+	return user.Username
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"blog/config"
+	"blog/internal/model"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeedService_Build(t *testing.T) {
+	original := config.AppConfig
+	t.Cleanup(func() { config.AppConfig = original })
+	config.AppConfig.Site.Title = "Blog"
+	config.AppConfig.Site.URL = "https://example.com/"
+	config.AppConfig.Site.APIURL = "https://api.example.com"
+	config.AppConfig.Feed.ItemCount = 0
+
+	t.Run("作者不存在", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		s := &FeedService{articleRepo: new(MockArticleRepository), userRepo: userRepo}
+		userRepo.On("FindByUsername", "ghost").Return(nil, nil)
+
+		_, err := s.Build(context.Background(), "ghost", "", "/authors/ghost/feed.xml")
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
+	t.Run("按作者筛选", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		articleRepo := new(MockArticleRepository)
+		s := &FeedService{articleRepo: articleRepo, userRepo: userRepo}
+		author := &model.User{ID: 3, Username: "tom", DisplayName: "Tom"}
+		older := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		newer := older.Add(24 * time.Hour)
+		userRepo.On("FindByUsername", "tom").Return(author, nil)
+		articleRepo.On("List", 1, defaultFeedItems, model.ArticleStatusPublished, uint(3), "", (*model.ArticleViewer)(nil)).Return([]model.Article{
+			{ID: 2, Title: "Second", Content: "**hi**", Author: author.Public(), CreatedAt: newer, UpdatedAt: newer, Tags: []model.Tag{{Name: "go"}}},
+			{ID: 1, Title: "First", Content: "text", Author: author.Public(), CreatedAt: older, UpdatedAt: older},
+		}, int64(2), nil)
+
+		f, err := s.Build(context.Background(), "tom", "", "/authors/tom/feed.xml")
+		require.NoError(t, err)
+		assert.Equal(t, "Blog - Tom", f.Title)
+		assert.Equal(t, "https://example.com/users/tom", f.Link)
+		assert.Equal(t, "https://api.example.com/authors/tom/feed.xml", f.SelfURL)
+		assert.Equal(t, newer, f.Updated)
+		require.Len(t, f.Entries, 2)
+		assert.Equal(t, "https://example.com/articles/2", f.Entries[0].Link)
+		assert.Equal(t, "Tom", f.Entries[0].Author)
+		assert.Equal(t, []string{"go"}, f.Entries[0].Categories)
+		assert.Contains(t, f.Entries[0].ContentHTML, "<strong>hi</strong>")
+	})
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"blog/internal/audit"
+	"blog/internal/model"
+	"blog/internal/repository"
+	"blog/internal/tracing"
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type JobService struct {
+	jobRepo repository.IJobRepository
+	auditor audit.Recorder
+}
+
+func NewJobService() *JobService {
+	return &JobService{
+		jobRepo: repository.NewJobRepository(),
+		auditor: audit.GetLogger(),
+	}
+}
+
+// List 按条件分页查询后台任务
+func (s *JobService) List(ctx context.Context, query *model.JobQuery) (_ []model.Job, _ int64, err error) {
+	ctx, span := tracing.Start(ctx, "JobService.List")
+	defer func() { tracing.End(span, err) }()
+
+	return s.jobRepo.List(ctx, query)
+}
+
+// Stats 各队列中每种状态的任务数
+func (s *JobService) Stats(ctx context.Context) (_ []model.JobStat, err error) {
+	ctx, span := tracing.Start(ctx, "JobService.Stats")
+	defer func() { tracing.End(span, err) }()
+
+	return s.jobRepo.Stats(ctx)
+}
+
+// Get 查看单个任务
+func (s *JobService) Get(ctx context.Context, id uint) (_ *model.Job, err error) {
+	ctx, span := tracing.Start(ctx, "JobService.Get", attribute.Int("job.id", int(id)))
+	defer func() { tracing.End(span, err) }()
+
+	job, err := s.jobRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrJobNotFound
+	}
+	return job, nil
+}
+
+// Retry 管理员把死信任务重新放回队列，从第一次尝试开始计数
+func (s *JobService) Retry(ctx context.Context, admin *model.User, id uint) (_ *model.Job, err error) {
+	ctx, span := tracing.Start(ctx, "JobService.Retry", attribute.Int("job.id", int(id)))
+	defer func() { tracing.End(span, err) }()
+
+	defer func() {
+		event := auditActor(model.AuditEvent{
+			Action:     model.AuditActionJobRetry,
+			TargetType: model.AuditTargetJob,
+			TargetID:   auditTarget(id),
+		}, admin)
+		recordAudit(ctx, s.auditor, event, err)
+	}()
+
+	retried, err := s.jobRepo.Retry(ctx, id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !retried {
+		// 区分任务不存在和任务不是死信
+		job, err := s.jobRepo.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if job == nil {
+			return nil, ErrJobNotFound
+		}
+		return nil, ErrJobNotRetryable
+	}
+	return s.jobRepo.FindByID(ctx, id)
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"blog/internal/model"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockJobRepository 模拟后台任务仓库
+type MockJobRepository struct {
+	mock.Mock
+}
+
+func (m *MockJobRepository) Create(ctx context.Context, job *model.Job) (bool, error) {
+	args := m.Called(job)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobRepository) Claim(ctx context.Context, queue string, types []string, now time.Time, lease time.Duration, limit int) ([]model.Job, error) {
+	args := m.Called(queue, types, limit)
+	return args.Get(0).([]model.Job), args.Error(1)
+}
+
+func (m *MockJobRepository) Finish(ctx context.Context, job *model.Job) error {
+	return m.Called(job).Error(0)
+}
+
+func (m *MockJobRepository) FindByID(ctx context.Context, id uint) (*model.Job, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Job), args.Error(1)
+}
+
+func (m *MockJobRepository) List(ctx context.Context, query *model.JobQuery) ([]model.Job, int64, error) {
+	args := m.Called(query)
+	return args.Get(0).([]model.Job), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockJobRepository) Stats(ctx context.Context) ([]model.JobStat, error) {
+	args := m.Called()
+	return args.Get(0).([]model.JobStat), args.Error(1)
+}
+
+func (m *MockJobRepository) Retry(ctx context.Context, id uint, now time.Time) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobRepository) DeleteSucceededBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	args := m.Called(limit)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestJobService_Retry(t *testing.T) {
+	admin := &model.User{ID: 1, Username: "admin", Role: model.RoleAdmin}
+	repo := new(MockJobRepository)
+	repo.On("Retry", uint(1)).Return(true, nil)
+	repo.On("FindByID", uint(1)).Return(&model.Job{ID: 1, Status: model.JobPending}, nil)
+	recorder := &memoryAuditRecorder{}
+	s := &JobService{jobRepo: repo, auditor: recorder}
+
+	job, err := s.Retry(context.Background(), admin, 1)
+	require.NoError(t, err)
+	assert.Equal(t, model.JobPending, job.Status)
+
+	require.Len(t, recorder.events, 1)
+	assert.Equal(t, model.AuditEvent{
+		ActorID: 1, Actor: "admin", Action: model.AuditActionJobRetry,
+		TargetType: model.AuditTargetJob, TargetID: "1", Outcome: model.AuditOutcomeSuccess,
+	}, recorder.events[0])
+}
+
+func TestJobService_RetryRejectsMissingOrNotDeadJobs(t *testing.T) {
+	admin := &model.User{ID: 1, Username: "admin", Role: model.RoleAdmin}
+	repo := new(MockJobRepository)
+	repo.On("Retry", mock.Anything).Return(false, nil)
+	repo.On("FindByID", uint(2)).Return(nil, nil)
+	repo.On("FindByID", uint(3)).Return(&model.Job{ID: 3, Status: model.JobRunning}, nil)
+	recorder := &memoryAuditRecorder{}
+	s := &JobService{jobRepo: repo, auditor: recorder}
+
+	_, err := s.Retry(context.Background(), admin, 2)
+	assert.ErrorIs(t, err, ErrJobNotFound)
+	_, err = s.Retry(context.Background(), admin, 3)
+	assert.ErrorIs(t, err, ErrJobNotRetryable)
+
+	require.Len(t, recorder.events, 2)
+	assert.Equal(t, "job_not_retryable", recorder.events[1].Detail)
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"blog/config"
+	"blog/internal/model"
+	"blog/internal/oauth"
+	"blog/internal/tracing"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/oauth2"
+)
+
+const defaultOAuthStateTTL = 10 * time.Minute
+
+// 第三方用户名中不能用于本地用户名的字符
+var invalidUsernameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// OAuthProviders 已启用的第三方登录提供方名称
+func (s *AuthService) OAuthProviders() []string {
+	return s.oauthProviders.Names()
+}
+
+// OAuthStart 生成第三方授权地址，state、PKCE 校验码和 nonce 保存在服务端；
+// 同时生成只交给发起授权的浏览器的绑定值，防止把他人发起的授权提交到自己的浏览器中登录
+func (s *AuthService) OAuthStart(ctx context.Context, providerName string) (_ *model.OAuthStartResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.OAuthStart")
+	defer func() { tracing.End(span, err) }()
+
+	provider, ok := s.oauthProviders.Get(providerName)
+	if !ok {
+		return nil, ErrOAuthProviderNotFound
+	}
+
+	state, err := newRawToken()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := newRawToken()
+	if err != nil {
+		return nil, err
+	}
+	binding, err := newRawToken()
+	if err != nil {
+		return nil, err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	expiresAt := time.Now().Add(tokenTTL(config.AppConfig.OAuth.StateTTL, defaultOAuthStateTTL))
+	err = s.oauthStateRepo.Create(ctx, &model.OAuthState{
+		StateHash:    hashToken(state),
+		Provider:     providerName,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		BindingHash:  hashToken(binding),
+		ExpiresAt:    expiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, verifier, nonce)
+	if err != nil {
+		log.Printf("[WARN] oauth provider %s unavailable: %v", providerName, err)
+		return nil, ErrOAuthFailed
+	}
+	return &model.OAuthStartResponse{AuthorizationURL: authURL, State: state, ExpiresAt: expiresAt, Binding: binding}, nil
+}
+
+// OAuthCallback 用授权码完成第三方登录，binding 为发起授权时交给浏览器的绑定值
+// 已关联的第三方账号直接登录；否则按已验证的邮箱关联到现有用户，或创建新用户
+func (s *AuthService) OAuthCallback(ctx context.Context, providerName, code, state, binding string) (resp *model.LoginResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.OAuthCallback")
+	defer func() { tracing.End(span, err) }()
+
+	var user *model.User
+	defer func() {
+		detail := "provider=" + providerName
+		if resp != nil && resp.TwoFactorRequired {
+			detail += " " + twoFactorDetail(resp)
+		}
+		s.auditAuth(ctx, model.AuditActionOAuthLogin, "", user, detail, err)
+	}()
+
+	provider, ok := s.oauthProviders.Get(providerName)
+	if !ok {
+		return nil, ErrOAuthProviderNotFound
+	}
+
+	saved, err := s.oauthStateRepo.Consume(ctx, hashToken(state))
+	if err != nil {
+		return nil, err
+	}
+	if saved == nil || saved.Provider != providerName {
+		return nil, ErrOAuthInvalidState
+	}
+	// state 必须由同一浏览器提交，state 已消费，绑定不符时需要重新发起授权
+	if subtle.ConstantTimeCompare([]byte(saved.BindingHash), []byte(hashToken(binding))) != 1 {
+		return nil, ErrOAuthInvalidState
+	}
+
+	identity, err := provider.Exchange(ctx, code, saved.CodeVerifier, saved.Nonce)
+	if err != nil {
+		log.Printf("[WARN] oauth exchange with %s failed: %v", providerName, err)
+		return nil, ErrOAuthFailed
+	}
+
+	user, err = s.resolveOAuthUser(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	// 第三方登录同样受两步验证保护，挑战令牌绑定的账号为邮箱
+	if user.TwoFactorEnabled {
+		ttl := tokenTTL(config.AppConfig.Auth.TwoFactor.ChallengeTTL, defaultChallengeTTL)
+		challenge, err := issueToken(ctx, s.tokenRepo, user.ID, model.TokenPurposeLoginChallenge, ttl, strings.ToLower(user.Email))
+		if err != nil {
+			return nil, err
+		}
+		return &model.LoginResponse{TwoFactorRequired: true, ChallengeToken: challenge}, nil
+	}
+	return s.loginResponse(ctx, user)
+}
+
+// resolveOAuthUser 找到或创建第三方账号对应的本地用户
+func (s *AuthService) resolveOAuthUser(ctx context.Context, identity *oauth.Identity) (*model.User, error) {
+	linked, err := s.identityRepo.Find(ctx, identity.Provider, identity.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if linked != nil {
+		user, err := s.userRepo.FindByID(ctx, linked.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, ErrUserNotFound
+		}
+		return user, nil
+	}
+
+	// 只有提供方确认过的邮箱才能用来关联或创建账号，否则任何人都能冒用他人邮箱
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, ErrOAuthEmailUnverified
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, identity.Email)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		if err := s.claimUnverifiedUser(ctx, user); err != nil {
+			return nil, err
+		}
+	} else {
+		user, err = s.createOAuthUser(ctx, identity)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.identityRepo.Create(ctx, &model.UserIdentity{
+		UserID:   user.ID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// claimUnverifiedUser 邮箱未验证的现有账号可能是他人抢注的，关联前作废其密码、两步验证、会话和个人访问令牌，
+// 邮箱真正的主人之后可通过找回密码设置新密码
+func (s *AuthService) claimUnverifiedUser(ctx context.Context, user *model.User) error {
+	if user.EmailVerified {
+		return nil
+	}
+
+	hash, err := s.randomPasswordHash()
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, hash); err != nil {
+		return err
+	}
+	if user.TwoFactorEnabled {
+		if err := s.userRepo.DisableTwoFactor(ctx, user.ID); err != nil {
+			return err
+		}
+		if err := s.recoveryRepo.DeleteByUser(ctx, user.ID); err != nil {
+			return err
+		}
+		user.TwoFactorEnabled = false
+	}
+	if err := s.userRepo.MarkEmailVerified(ctx, user.ID); err != nil {
+		return err
+	}
+	// 抢注者登录过的会话和创建的令牌一并撤销
+	if err := s.revokeCredentials(ctx, user.ID); err != nil {
+		return err
+	}
+
+	user.Password = hash
+	user.EmailVerified = true
+	return nil
+}
+
+// createOAuthUser 为第三方账号创建本地用户，用户没有可用的密码，需要时可通过找回密码设置
+func (s *AuthService) createOAuthUser(ctx context.Context, identity *oauth.Identity) (*model.User, error) {
+	username, err := s.availableUsername(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := s.randomPasswordHash()
+	if err != nil {
+		return nil, err
+	}
+
+	user := &model.User{
+		Username:      username,
+		Email:         identity.Email,
+		Password:      hash,
+		EmailVerified: true,
+		DisplayName:   truncate(identity.Name, 50),
+		AvatarURL:     truncate(identity.AvatarURL, 255),
+	}
+	if err := s.userRepo.Create(ctx, user, userRegisteredEvents); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// availableUsername 根据第三方用户名或邮箱前缀生成未被占用的本地用户名
+func (s *AuthService) availableUsername(ctx context.Context, identity *oauth.Identity) (string, error) {
+	base := identity.Username
+	if base == "" {
+		base, _, _ = strings.Cut(identity.Email, "@")
+	}
+	base = truncate(invalidUsernameChars.ReplaceAllString(base, ""), 40)
+	if len(base) < 3 {
+		base = "user"
+	}
+
+	candidate := base
+	for i := 0; i < 5; i++ {
+		existing, err := s.userRepo.FindByUsername(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if existing == nil {
+			return candidate, nil
+		}
+
+		n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+		if err != nil {
+			return "", err
+		}
+		candidate = fmt.Sprintf("%s-%06d", base, n.Int64())
+	}
+	return "", ErrUsernameExists
+}
+
+// randomPasswordHash 生成无人知晓的随机密码的哈希，使账号无法用密码登录
+func (s *AuthService) randomPasswordHash() (string, error) {
+	raw, err := newRawToken()
+	if err != nil {
+		return "", err
+	}
+	// bcrypt 只使用前 72 字节，随机令牌为 43 字节
+	return s.hasher.Hash(raw)
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	// 退回到字符边界，避免截断多字节字符
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"blog/config"
+	"blog/internal/model"
+	"blog/internal/oauth"
+	"blog/internal/oauth/oauthtest"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockIdentityRepository 模拟第三方账号仓库
+type MockIdentityRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdentityRepository) Find(ctx context.Context, provider, subject string) (*model.UserIdentity, error) {
+	args := m.Called(provider, subject)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.UserIdentity), args.Error(1)
+}
+
+func (m *MockIdentityRepository) Create(ctx context.Context, identity *model.UserIdentity) error {
+	args := m.Called(identity)
+	return args.Error(0)
+}
+
+// memoryOAuthStateRepository 内存中的授权状态仓库，state 由服务随机生成，无法预先设置 mock 返回值
+type memoryOAuthStateRepository struct {
+	states map[string]model.OAuthState
+}
+
+func (r *memoryOAuthStateRepository) Create(ctx context.Context, state *model.OAuthState) error {
+	r.states[state.StateHash] = *state
+	return nil
+}
+
+func (r *memoryOAuthStateRepository) Consume(ctx context.Context, stateHash string) (*model.OAuthState, error) {
+	state, ok := r.states[stateHash]
+	delete(r.states, stateHash)
+	if !ok || time.Now().After(state.ExpiresAt) {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+var oauthUser = oauthtest.User{ID: 42, Login: "octocat", Name: "The Octocat", Email: "octocat@example.com", EmailVerified: true}
+
+// newOAuthTestService 创建连接到假 OAuth 服务的认证服务，提供方名称为 github
+func newOAuthTestService(t *testing.T, server *oauthtest.Server) (*AuthService, *MockUserRepository, *MockIdentityRepository) {
+	withJWTSecret(t)
+	mockRepo := new(MockUserRepository)
+	mockIdentity := new(MockIdentityRepository)
+	provider := oauth.NewGitHubProvider("github", config.OAuthProviderConfig{
+		ClientID:     oauthtest.ClientID,
+		ClientSecret: oauthtest.ClientSecret,
+		RedirectURL:  "http://localhost:3000/auth/oauth/github/callback",
+		AuthURL:      server.URL + "/authorize",
+		TokenURL:     server.URL + "/token",
+		APIURL:       server.URL,
+	})
+	authService := &AuthService{
+		userRepo:       mockRepo,
+		tokenRepo:      new(MockTokenRepository),
+		recoveryRepo:   new(MockRecoveryCodeRepository),
+		sessionRepo:    acceptSessions(),
+		apiTokenRepo:   new(MockAPITokenRepository),
+		identityRepo:   mockIdentity,
+		oauthStateRepo: &memoryOAuthStateRepository{states: map[string]model.OAuthState{}},
+		oauthProviders: oauth.NewRegistry(provider),
+		hasher:         testHasher,
+	}
+	return authService, mockRepo, mockIdentity
+}
+
+// authorize 发起授权并模拟用户同意，返回回调中的 code 和 state
+func authorize(t *testing.T, authService *AuthService, server *oauthtest.Server) (string, string, string) {
+	start, err := authService.OAuthStart(context.Background(), "github")
+	require.NoError(t, err)
+	code, state, err := server.Authorize(start.AuthorizationURL)
+	require.NoError(t, err)
+	require.Equal(t, start.State, state)
+	require.NotEmpty(t, start.Binding)
+	return code, state, start.Binding
+}
+
+func TestAuthService_OAuthCallback(t *testing.T) {
+	server := oauthtest.NewServer(oauthUser)
+	defer server.Close()
+
+	// 测试用例1：新的第三方账号创建本地用户，邮箱视为已验证
+	t.Run("创建新用户", func(t *testing.T) {
+		authService, mockRepo, mockIdentity := newOAuthTestService(t, server)
+
+		mockIdentity.On("Find", "github", "42").Return(nil, nil)
+		mockRepo.On("FindByEmail", "octocat@example.com").Return(nil, nil)
+		mockRepo.On("FindByUsername", "octocat").Return(&model.User{ID: 9}, nil)
+		mockRepo.On("FindByUsername", mock.MatchedBy(func(name string) bool { return name != "octocat" })).Return(nil, nil)
+		mockRepo.On("Create", mock.MatchedBy(func(user *model.User) bool {
+			return user.EmailVerified && user.Email == "octocat@example.com" && user.DisplayName == "The Octocat"
+		})).Run(func(args mock.Arguments) { args.Get(0).(*model.User).ID = 7 }).Return(nil)
+		mockIdentity.On("Create", &model.UserIdentity{UserID: 7, Provider: "github", Subject: "42", Email: "octocat@example.com"}).Return(nil)
+
+		code, state, binding := authorize(t, authService, server)
+		response, err := authService.OAuthCallback(context.Background(), "github", code, state, binding)
+		require.NoError(t, err)
+		assert.NotEmpty(t, response.Token)
+		// 用户名 octocat 已被占用，追加随机后缀
+		assert.Regexp(t, `^octocat-\d{6}$`, response.User.Username)
+		mockIdentity.AssertExpectations(t)
+	})
+
+	// 测试用例2：已关联的第三方账号直接登录
+	t.Run("已关联账号", func(t *testing.T) {
+		authService, mockRepo, mockIdentity := newOAuthTestService(t, server)
+		user := &model.User{ID: 3, Username: "octo", Email: "other@example.com", EmailVerified: true}
+
+		mockIdentity.On("Find", "github", "42").Return(&model.UserIdentity{UserID: 3, Provider: "github", Subject: "42"}, nil)
+		mockRepo.On("FindByID", uint(3)).Return(user, nil)
+
+		code, state, binding := authorize(t, authService, server)
+		response, err := authService.OAuthCallback(context.Background(), "github", code, state, binding)
+		require.NoError(t, err)
+		assert.Equal(t, user, response.User)
+		mockIdentity.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	// 测试用例3：按已验证的邮箱关联到现有用户，密码保持不变
+	t.Run("关联已验证用户", func(t *testing.T) {
+		authService, mockRepo, mockIdentity := newOAuthTestService(t, server)
+		user := hashedUser(t, "password123")
+		user.Email = "octocat@example.com"
+		user.EmailVerified = true
+
+		mockIdentity.On("Find", "github", "42").Return(nil, nil)
+		mockRepo.On("FindByEmail", "octocat@example.com").Return(user, nil)
+		mockIdentity.On("Create", mock.MatchedBy(func(identity *model.UserIdentity) bool {
+			return identity.UserID == user.ID
+		})).Return(nil)
+
+		code, state, binding := authorize(t, authService, server)
+		response, err := authService.OAuthCallback(context.Background(), "github", code, state, binding)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, response.User.ID)
+		mockRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything)
+	})
+
+	// 测试用例4：邮箱未验证的现有账号可能被抢注，关联时作废原密码和抢注者的会话、令牌
+	t.Run("关联未验证用户", func(t *testing.T) {
+		authService, mockRepo, mockIdentity := newOAuthTestService(t, server)
+		user := hashedUser(t, "password123")
+		user.Email = "octocat@example.com"
+		mockSessions := authService.sessionRepo.(*MockSessionRepository)
+		mockSessions.On("RevokeAllByUser", user.ID, uint(0)).Return(2, nil)
+		mockTokens := authService.apiTokenRepo.(*MockAPITokenRepository)
+		mockTokens.On("RevokeAllByUser", user.ID).Return(1, nil)
+
+		mockIdentity.On("Find", "github", "42").Return(nil, nil)
+		mockRepo.On("FindByEmail", "octocat@example.com").Return(user, nil)
+		mockRepo.On("UpdatePassword", user.ID, mock.MatchedBy(func(hash string) bool {
+			return testHasher.Compare(hash, "password123") != nil
+		})).Return(nil)
+		mockRepo.On("MarkEmailVerified", user.ID).Return(nil)
+		mockIdentity.On("Create", mock.Anything).Return(nil)
+
+		code, state, binding := authorize(t, authService, server)
+		response, err := authService.OAuthCallback(context.Background(), "github", code, state, binding)
+		require.NoError(t, err)
+		assert.True(t, response.User.EmailVerified)
+		mockRepo.AssertExpectations(t)
+		mockSessions.AssertExpectations(t)
+		mockTokens.AssertExpectations(t)
+	})
+
+	// 测试用例5：第三方邮箱未验证时不能关联或创建账号
+	t.Run("邮箱未验证", func(t *testing.T) {
+		unverified := oauthtest.NewServer(oauthtest.User{ID: 43, Login: "mallory", Email: "octocat@example.com"})
+		defer unverified.Close()
+		authService, mockRepo, mockIdentity := newOAuthTestService(t, unverified)
+
+		mockIdentity.On("Find", "github", "43").Return(nil, nil)
+
+		code, state, binding := authorize(t, authService, unverified)
+		_, err := authService.OAuthCallback(context.Background(), "github", code, state, binding)
+		assert.ErrorIs(t, err, ErrOAuthEmailUnverified)
+		mockRepo.AssertNotCalled(t, "FindByEmail", mock.Anything)
+	})
+
+	// 测试用例6：state 只能使用一次
+	t.Run("state无效", func(t *testing.T) {
+		authService, mockRepo, mockIdentity := newOAuthTestService(t, server)
+		mockIdentity.On("Find", "github", "42").Return(&model.UserIdentity{UserID: 3}, nil)
+		mockRepo.On("FindByID", uint(3)).Return(&model.User{ID: 3}, nil)
+
+		code, state, binding := authorize(t, authService, server)
+		_, err := authService.OAuthCallback(context.Background(), "github", code, state, binding)
+		require.NoError(t, err)
+
+		_, err = authService.OAuthCallback(context.Background(), "github", code, state, binding)
+		assert.ErrorIs(t, err, ErrOAuthInvalidState)
+		_, err = authService.OAuthCallback(context.Background(), "github", code, "forged", binding)
+		assert.ErrorIs(t, err, ErrOAuthInvalidState)
+	})
+
+	// 测试用例7：state 有效但不是由发起授权的浏览器提交（登录 CSRF）
+	t.Run("绑定不符", func(t *testing.T) {
+		authService, mockRepo, mockIdentity := newOAuthTestService(t, server)
+
+		// 攻击者用自己发起的授权换到的 code 和 state，由受害者的浏览器（带着受害者自己的绑定）提交
+		code, state, _ := authorize(t, authService, server)
+		_, _, victimBinding := authorize(t, authService, server)
+		_, err := authService.OAuthCallback(context.Background(), "github", code, state, victimBinding)
+		assert.ErrorIs(t, err, ErrOAuthInvalidState)
+		_, err = authService.OAuthCallback(context.Background(), "github", code, state, "")
+		assert.ErrorIs(t, err, ErrOAuthInvalidState)
+		mockIdentity.AssertNotCalled(t, "Find", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "FindByID", mock.Anything)
+	})
+
+	// 测试用例8：授权码无效时换取失败
+	t.Run("授权码无效", func(t *testing.T) {
+		authService, _, _ := newOAuthTestService(t, server)
+
+		_, state, binding := authorize(t, authService, server)
+		_, err := authService.OAuthCallback(context.Background(), "github", "bad-code", state, binding)
+		assert.ErrorIs(t, err, ErrOAuthFailed)
+	})
+
+	// 测试用例9：开启两步验证的用户需要先验证动态码
+	t.Run("两步验证", func(t *testing.T) {
+		authService, mockRepo, mockIdentity := newOAuthTestService(t, server)
+		mockTokenRepo := new(MockTokenRepository)
+		authService.tokenRepo = mockTokenRepo
+		user := &model.User{ID: 3, Email: "Octo@Example.com", EmailVerified: true, TwoFactorEnabled: true}
+
+		mockIdentity.On("Find", "github", "42").Return(&model.UserIdentity{UserID: 3}, nil)
+		mockRepo.On("FindByID", uint(3)).Return(user, nil)
+		mockTokenRepo.On("DeleteByUser", user.ID, model.TokenPurposeLoginChallenge).Return(nil)
+		mockTokenRepo.On("Create", mock.MatchedBy(func(token *model.UserToken) bool {
+			return token.Payload == "octo@example.com"
+		})).Return(nil)
+
+		code, state, binding := authorize(t, authService, server)
+		response, err := authService.OAuthCallback(context.Background(), "github", code, state, binding)
+		require.NoError(t, err)
+		assert.True(t, response.TwoFactorRequired)
+		assert.Empty(t, response.Token)
+	})
+}
+
+func TestAuthService_OAuthStart(t *testing.T) {
+	authService := &AuthService{oauthProviders: oauth.NewRegistry()}
+
+	_, err := authService.OAuthStart(context.Background(), "gitlab")
+	assert.ErrorIs(t, err, ErrOAuthProviderNotFound)
+	assert.Empty(t, authService.OAuthProviders())
+}
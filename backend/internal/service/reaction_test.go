@@ -0,0 +1,174 @@
+package service
+
+import (
+	"blog/internal/clientinfo"
+	"blog/internal/model"
+	"blog/internal/repository"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockReactionRepository 模拟文章互动仓库
+type MockReactionRepository struct {
+	mock.Mock
+}
+
+func (m *MockReactionRepository) Add(ctx context.Context, userID, articleID uint, kind string) (int64, error) {
+	args := m.Called(userID, articleID, kind)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockReactionRepository) Remove(ctx context.Context, userID, articleID uint, kind string) (int64, error) {
+	args := m.Called(userID, articleID, kind)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockReactionRepository) FindByUser(ctx context.Context, userID uint, articleIDs []uint) ([]model.ArticleReaction, error) {
+	args := m.Called(userID, articleIDs)
+	return args.Get(0).([]model.ArticleReaction), args.Error(1)
+}
+
+func (m *MockReactionRepository) ListBookmarked(ctx context.Context, userID uint, page, pageSize int) ([]model.Article, int64, error) {
+	args := m.Called(userID, page, pageSize)
+	return args.Get(0).([]model.Article), args.Get(1).(int64), args.Error(2)
+}
+
+func TestArticleService_SetReaction(t *testing.T) {
+	user := &model.User{ID: 1}
+
+	t.Run("他人的草稿不可见", func(t *testing.T) {
+		articleRepo := new(MockArticleRepository)
+		reactionRepo := new(MockReactionRepository)
+		s := &ArticleService{articleRepo: articleRepo, reactionRepo: reactionRepo}
+		articleRepo.On("FindByID", uint(5)).Return(&model.Article{ID: 5, AuthorID: 2, Status: model.ArticleStatusDraft}, nil)
+
+		_, err := s.SetReaction(context.Background(), user, 5, model.ReactionLike, true)
+		assert.ErrorIs(t, err, ErrArticleNotFound)
+		reactionRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("点赞与取消", func(t *testing.T) {
+		articleRepo := new(MockArticleRepository)
+		reactionRepo := new(MockReactionRepository)
+		s := &ArticleService{articleRepo: articleRepo, reactionRepo: reactionRepo}
+		articleRepo.On("FindByID", uint(5)).Return(&model.Article{ID: 5, AuthorID: 2, Status: model.ArticleStatusPublished}, nil)
+		reactionRepo.On("Add", uint(1), uint(5), model.ReactionLike).Return(int64(3), nil)
+		reactionRepo.On("Remove", uint(1), uint(5), model.ReactionBookmark).Return(int64(0), nil)
+
+		result, err := s.SetReaction(context.Background(), user, 5, model.ReactionLike, true)
+		require.NoError(t, err)
+		assert.Equal(t, &model.ReactionResult{Active: true, Count: 3}, result)
+
+		result, err = s.SetReaction(context.Background(), user, 5, model.ReactionBookmark, false)
+		require.NoError(t, err)
+		assert.Equal(t, &model.ReactionResult{Active: false, Count: 0}, result)
+	})
+}
+
+func TestArticleService_ListArticlesMarksViewerReactions(t *testing.T) {
+	articleRepo := new(MockArticleRepository)
+	reactionRepo := new(MockReactionRepository)
+	s := &ArticleService{articleRepo: articleRepo, reactionRepo: reactionRepo}
+
+	articleRepo.On("List", 1, 10, "", uint(0), "", mock.Anything).Return([]model.Article{{ID: 1}, {ID: 2}}, int64(2), nil)
+	reactionRepo.On("FindByUser", uint(9), []uint{1, 2}).Return([]model.ArticleReaction{
+		{ArticleID: 1, Kind: model.ReactionLike},
+		{ArticleID: 2, Kind: model.ReactionBookmark},
+		{ArticleID: 2, Kind: model.ReactionLike},
+	}, nil)
+
+	articles, total, err := s.ListArticles(context.Background(), &model.User{ID: 9}, 1, 10, "", 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.True(t, articles[0].Liked)
+	assert.False(t, articles[0].Bookmarked)
+	assert.True(t, articles[1].Liked)
+	assert.True(t, articles[1].Bookmarked)
+
+	// 匿名访问不查询互动状态
+	_, _, err = s.ListArticles(context.Background(), nil, 1, 10, "", 0, "")
+	require.NoError(t, err)
+	reactionRepo.AssertNumberOfCalls(t, "FindByUser", 1)
+}
+
+// memoryViewRecorder 记录浏览，便于断言
+type memoryViewRecorder struct {
+	views []string
+}
+
+func (r *memoryViewRecorder) Record(articleID uint, viewer string) bool {
+	r.views = append(r.views, viewer)
+	return true
+}
+
+func TestArticleService_GetArticleRecordsView(t *testing.T) {
+	articleRepo := new(MockArticleRepository)
+	reactionRepo := new(MockReactionRepository)
+	views := &memoryViewRecorder{}
+	collabRepo := new(MockCollaboratorRepository)
+	s := &ArticleService{articleRepo: articleRepo, reactionRepo: reactionRepo, collabRepo: collabRepo, views: views}
+
+	articleRepo.On("FindByID", uint(1)).Return(&model.Article{ID: 1, AuthorID: 2, Status: model.ArticleStatusPublished}, nil)
+	articleRepo.On("FindByID", uint(2)).Return(&model.Article{ID: 2, AuthorID: 2, Status: model.ArticleStatusDraft}, nil)
+	reactionRepo.On("FindByUser", mock.Anything, mock.Anything).Return([]model.ArticleReaction{}, nil)
+	collabRepo.On("FindRole", uint(2), uint(1)).Return(model.CollaboratorViewer, nil)
+
+	ctx := clientinfo.WithInfo(context.Background(), clientinfo.Info{IP: "203.0.113.7", UserAgent: "curl/8.0"})
+	for _, viewer := range []*model.User{{ID: 1}, {ID: 2}, nil} {
+		_, err := s.GetArticle(ctx, viewer, 1)
+		require.NoError(t, err)
+	}
+	// 草稿不计数，查看者可以看到草稿
+	_, err := s.GetArticle(ctx, &model.User{ID: 1}, 2)
+	require.NoError(t, err)
+
+	// 作者本人浏览不计数，匿名访客按 IP 区分
+	require.Len(t, views.views, 2)
+	assert.Equal(t, "u:1", views.views[0])
+	assert.Contains(t, views.views[1], "a:")
+
+	// 同一 IP 更换 User-Agent 仍视为同一访客
+	other := clientinfo.WithInfo(context.Background(), clientinfo.Info{IP: "203.0.113.7", UserAgent: "bot/1.0"})
+	assert.Equal(t, viewerKey(ctx, nil), viewerKey(other, nil))
+}
+
+func TestArticleService_PopularArticlesRejectsUnknownWindow(t *testing.T) {
+	s := &ArticleService{}
+	_, err := s.PopularArticles(context.Background(), "decade", 10)
+	assert.ErrorIs(t, err, ErrInvalidPopularWindow)
+}
+
+// stubViewRepository 返回固定热门文章的浏览统计仓库
+type stubViewRepository struct {
+	repository.IViewRepository
+	popular []model.PopularArticle
+}
+
+func (r *stubViewRepository) Popular(ctx context.Context, since time.Time, limit int) ([]model.PopularArticle, error) {
+	return r.popular, nil
+}
+
+func TestArticleService_PopularArticlesHidesAuthorAccount(t *testing.T) {
+	author := &model.User{ID: 1, Username: "tom", Email: "tom@example.com", Role: model.RoleAdmin, TwoFactorEnabled: true}
+	viewRepo := &stubViewRepository{popular: []model.PopularArticle{
+		{Article: model.Article{ID: 1, Title: "hot", Author: author.Public()}, Views: 10},
+	}}
+	s := &ArticleService{viewRepo: viewRepo}
+
+	popular, err := s.PopularArticles(context.Background(), "week", 10)
+	require.NoError(t, err)
+	data, err := json.Marshal(popular)
+	require.NoError(t, err)
+
+	// 匿名可访问的热门列表只包含作者的公开信息
+	assert.Contains(t, string(data), `"username":"tom"`)
+	for _, field := range []string{"tom@example.com", `"role"`, `"email_verified"`, `"two_factor_enabled"`} {
+		assert.NotContains(t, string(data), field)
+	}
+}
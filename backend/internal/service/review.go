@@ -0,0 +1,284 @@
+package service
+
+import (
+	"blog/config"
+	"blog/internal/events"
+	"blog/internal/model"
+	"blog/internal/tracing"
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// 状态变更的发起方
+const (
+	transitionByAuthor   = "author"   // 作者和编辑者
+	transitionByReviewer = "reviewer" // 审核员和管理员
+)
+
+// articleTransitions 允许的状态变更及可以发起的一方：
+// 作者提交审核，审核员通过或驳回，通过后发布，发布后可以归档；被驳回、已通过、已发布的文章可以退回草稿继续修改，
+// 已通过、已发布的文章修改内容后重新提交审核
+var articleTransitions = map[string]map[string][]string{
+	model.ArticleStatusDraft: {
+		model.ArticleStatusInReview:  {transitionByAuthor},
+		model.ArticleStatusPublished: {transitionByAuthor}, // 未开启发布前审核时可以直接发布
+	},
+	model.ArticleStatusInReview: {
+		model.ArticleStatusDraft:    {transitionByAuthor}, // 撤回审核
+		model.ArticleStatusApproved: {transitionByReviewer},
+		model.ArticleStatusRejected: {transitionByReviewer},
+	},
+	model.ArticleStatusApproved: {
+		model.ArticleStatusPublished: {transitionByAuthor, transitionByReviewer},
+		model.ArticleStatusInReview:  {transitionByAuthor},
+		model.ArticleStatusDraft:     {transitionByAuthor},
+	},
+	model.ArticleStatusRejected: {
+		model.ArticleStatusInReview: {transitionByAuthor},
+		model.ArticleStatusDraft:    {transitionByAuthor},
+	},
+	model.ArticleStatusPublished: {
+		model.ArticleStatusArchived: {transitionByAuthor, transitionByReviewer},
+		model.ArticleStatusInReview: {transitionByAuthor},
+		model.ArticleStatusDraft:    {transitionByAuthor},
+	},
+	model.ArticleStatusArchived: {
+		model.ArticleStatusDraft: {transitionByAuthor},
+	},
+}
+
+// TransitionArticle 按审核流程变更文章状态，comment 随变更记录保存
+func (s *ArticleService) TransitionArticle(ctx context.Context, user *model.User, articleID uint, req *model.TransitionRequest) (_ *model.Article, err error) {
+	ctx, span := tracing.Start(ctx, "ArticleService.TransitionArticle",
+		attribute.Int("article.id", int(articleID)),
+		attribute.String("article.status", req.Status))
+	defer func() { tracing.End(span, err) }()
+
+	article, err := s.articleRepo.FindByID(ctx, articleID)
+	if err != nil {
+		return nil, err
+	}
+	if article == nil {
+		return nil, ErrArticleNotFound
+	}
+	return s.transition(ctx, user, article, req.Status, req.Comment)
+}
+
+// ListTransitions 查看文章的状态变更记录，作者、协作者和审核员可以查看
+func (s *ArticleService) ListTransitions(ctx context.Context, user *model.User, articleID uint) (_ []model.ArticleTransition, err error) {
+	ctx, span := tracing.Start(ctx, "ArticleService.ListTransitions",
+		attribute.Int("article.id", int(articleID)))
+	defer func() { tracing.End(span, err) }()
+
+	if _, err := s.findReviewable(ctx, user, articleID); err != nil {
+		return nil, err
+	}
+	return s.reviewRepo.ListTransitions(ctx, articleID)
+}
+
+// ListReviewComments 查看文章的审核评论，作者、协作者和审核员可以查看
+func (s *ArticleService) ListReviewComments(ctx context.Context, user *model.User, articleID uint) (_ []model.ReviewComment, err error) {
+	ctx, span := tracing.Start(ctx, "ArticleService.ListReviewComments",
+		attribute.Int("article.id", int(articleID)))
+	defer func() { tracing.End(span, err) }()
+
+	if _, err := s.findReviewable(ctx, user, articleID); err != nil {
+		return nil, err
+	}
+	return s.reviewRepo.ListComments(ctx, articleID)
+}
+
+// AddReviewComment 发表审核评论，审核员、作者和编辑者可以发表，查看者只能阅读
+func (s *ArticleService) AddReviewComment(ctx context.Context, user *model.User, articleID uint, req *model.CreateReviewCommentRequest) (_ *model.ReviewComment, err error) {
+	ctx, span := tracing.Start(ctx, "ArticleService.AddReviewComment",
+		attribute.Int("article.id", int(articleID)))
+	defer func() { tracing.End(span, err) }()
+
+	role, err := s.findReviewable(ctx, user, articleID)
+	if err != nil {
+		return nil, err
+	}
+	if role == model.CollaboratorViewer && !isReviewer(user) {
+		return nil, ErrArticleForbidden
+	}
+
+	comment := &model.ReviewComment{
+		ArticleID: articleID,
+		AuthorID:  user.ID,
+		Author:    user.Public(),
+		Body:      req.Body,
+	}
+	if err := s.reviewRepo.CreateComment(ctx, comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// transition 校验并执行状态变更，状态不变时直接返回原文章
+func (s *ArticleService) transition(ctx context.Context, user *model.User, article *model.Article, to, comment string) (_ *model.Article, err error) {
+	transition := newTransition(user, article, to, comment)
+	if transition == nil {
+		return article, nil
+	}
+	defer func() { s.auditTransition(ctx, user, transition, err) }()
+
+	if err := s.checkTransition(ctx, user, article, to); err != nil {
+		return nil, err
+	}
+	updated, err := s.articleRepo.Transition(ctx, transition, articleEmitter(events.ArticleUpdated, transition.FromStatus))
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrArticleStatusChanged
+	}
+	s.invalidatePublic(transition.FromStatus, to)
+	return updated, nil
+}
+
+// newTransition 用户把文章变更为 to 的记录，状态不变时返回 nil
+func newTransition(user *model.User, article *model.Article, to, comment string) *model.ArticleTransition {
+	if to == article.Status {
+		return nil
+	}
+	return &model.ArticleTransition{
+		ArticleID:  article.ID,
+		FromStatus: article.Status,
+		ToStatus:   to,
+		ActorID:    user.ID,
+		Comment:    comment,
+	}
+}
+
+// auditTransition 记录状态变更的审计事件
+func (s *ArticleService) auditTransition(ctx context.Context, user *model.User, transition *model.ArticleTransition, err error) {
+	event := auditActor(model.AuditEvent{
+		Action:     model.AuditActionArticleStatus,
+		TargetType: model.AuditTargetArticle,
+		TargetID:   auditTarget(transition.ArticleID),
+		Detail:     transition.FromStatus + "->" + transition.ToStatus,
+	}, user)
+	recordAudit(ctx, s.auditor, event, err)
+}
+
+// checkTransition 检查变更是否在流程中允许，以及用户是否是可以发起变更的一方
+func (s *ArticleService) checkTransition(ctx context.Context, user *model.User, article *model.Article, to string) error {
+	role, err := s.checkReviewable(ctx, user, article)
+	if err != nil {
+		return err
+	}
+	sides, ok := articleTransitions[article.Status][to]
+	if !ok {
+		return ErrInvalidTransition.WithArgs(article.Status, to)
+	}
+	if article.Status == model.ArticleStatusDraft && to == model.ArticleStatusPublished &&
+		config.AppConfig.Review.RequireBeforePublish {
+		return ErrReviewRequired
+	}
+	if err := checkPublishAllowed(user, to); err != nil {
+		return err
+	}
+
+	writer := role == articleRoleOwner || role == model.CollaboratorEditor
+	for _, side := range sides {
+		switch side {
+		case transitionByAuthor:
+			if writer {
+				return nil
+			}
+		case transitionByReviewer:
+			if isReviewer(user) && !writer {
+				return nil
+			}
+		}
+	}
+	// 审核员不能审核自己编写的文章
+	if writer && isReviewer(user) {
+		return ErrCannotReviewOwnArticle
+	}
+	return ErrTransitionForbidden.WithArgs(to)
+}
+
+// reviewedStatus 修改文章后应变更到的状态：已通过审核的文章（开启发布前审核时还包括已发布的文章）内容变化后需要重新审核，
+// 保持原状态或提交审核时转为待审核，可以退回草稿，但不能借此发布或归档未经审核的内容
+func reviewedStatus(existing *model.Article, requested string, changed bool) (string, error) {
+	if !changed || !needsReReview(existing.Status) {
+		return requested, nil
+	}
+	switch requested {
+	case existing.Status, model.ArticleStatusInReview:
+		return model.ArticleStatusInReview, nil
+	case model.ArticleStatusDraft:
+		return requested, nil
+	default:
+		return "", ErrReviewRequired
+	}
+}
+
+// needsReReview 处于该状态的文章修改内容后是否需要重新审核
+func needsReReview(status string) bool {
+	return status == model.ArticleStatusApproved ||
+		(status == model.ArticleStatusPublished && config.AppConfig.Review.RequireBeforePublish)
+}
+
+// contentChanged 标题、正文或标签是否变化，compareTags 为 false 时不比较标签
+func contentChanged(existing, article *model.Article, tagNames []string, compareTags bool) bool {
+	if existing.Title != article.Title || existing.Content != article.Content {
+		return true
+	}
+	if !compareTags {
+		return false
+	}
+	current := make(map[string]bool, len(existing.Tags))
+	for _, tag := range existing.Tags {
+		current[tag.Name] = true
+	}
+	wanted := make(map[string]bool, len(tagNames))
+	for _, name := range tagNames {
+		wanted[name] = true
+	}
+	if len(current) != len(wanted) {
+		return true
+	}
+	for name := range wanted {
+		if !current[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// findReviewable 查找文章并检查用户能否参与审核，返回用户对文章的角色
+func (s *ArticleService) findReviewable(ctx context.Context, user *model.User, articleID uint) (string, error) {
+	article, err := s.articleRepo.FindByID(ctx, articleID)
+	if err != nil {
+		return "", err
+	}
+	if article == nil {
+		return "", ErrArticleNotFound
+	}
+	return s.checkReviewable(ctx, user, article)
+}
+
+// checkReviewable 作者、协作者和审核员可以参与审核，审核员看不到未提交审核的草稿
+func (s *ArticleService) checkReviewable(ctx context.Context, user *model.User, article *model.Article) (string, error) {
+	role, err := articleRole(ctx, s.collabRepo, user, article)
+	if err != nil {
+		return "", err
+	}
+	if role != "" || reviewerVisible(user, article) {
+		return role, nil
+	}
+	return "", hiddenArticleError(article)
+}
+
+// reviewerVisible 审核员可以看到除草稿以外的所有文章
+func reviewerVisible(user *model.User, article *model.Article) bool {
+	return isReviewer(user) && article.Status != model.ArticleStatusDraft
+}
+
+// isReviewer 审核员和管理员可以审核文章
+func isReviewer(user *model.User) bool {
+	return user != nil && (user.Role == model.RoleReviewer || user.Role == model.RoleAdmin)
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"blog/config"
+	"blog/internal/events"
+	"blog/internal/model"
+	"blog/internal/repository"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockReviewRepository 模拟文章审核仓库
+type MockReviewRepository struct {
+	mock.Mock
+}
+
+func (m *MockReviewRepository) ListTransitions(ctx context.Context, articleID uint) ([]model.ArticleTransition, error) {
+	args := m.Called(articleID)
+	return args.Get(0).([]model.ArticleTransition), args.Error(1)
+}
+
+func (m *MockReviewRepository) CreateComment(ctx context.Context, comment *model.ReviewComment) error {
+	return m.Called(comment).Error(0)
+}
+
+func (m *MockReviewRepository) ListComments(ctx context.Context, articleID uint) ([]model.ReviewComment, error) {
+	args := m.Called(articleID)
+	return args.Get(0).([]model.ReviewComment), args.Error(1)
+}
+
+// withReviewRequired 在测试期间临时修改发布前审核要求
+func withReviewRequired(t *testing.T, required bool) {
+	original := config.AppConfig.Review.RequireBeforePublish
+	config.AppConfig.Review.RequireBeforePublish = required
+	t.Cleanup(func() {
+		config.AppConfig.Review.RequireBeforePublish = original
+	})
+}
+
+// reviewRepos 作者为 1、状态为 status 的文章，用户 2 是编辑者，用户 3 是查看者；变更总是成功
+func reviewRepos(status string) (*MockArticleRepository, *MockCollaboratorRepository) {
+	articleRepo := new(MockArticleRepository)
+	articleRepo.On("FindByID", uint(1)).Return(&model.Article{ID: 1, AuthorID: 1, Status: status}, nil)
+	articleRepo.On("Transition", mock.Anything).Return(func(transition *model.ArticleTransition) *model.Article {
+		return &model.Article{ID: 1, AuthorID: 1, Status: transition.ToStatus}
+	}, nil)
+	collabRepo := new(MockCollaboratorRepository)
+	collabRepo.On("FindRole", uint(1), uint(2)).Return(model.CollaboratorEditor, nil)
+	collabRepo.On("FindRole", uint(1), uint(3)).Return(model.CollaboratorViewer, nil)
+	collabRepo.On("FindRole", uint(1), mock.Anything).Return("", nil)
+	return articleRepo, collabRepo
+}
+
+func TestArticleService_TransitionArticle(t *testing.T) {
+	withReviewRequired(t, false)
+	author := &model.User{ID: 1, Username: "author"}
+	editor := &model.User{ID: 2}
+	viewer := &model.User{ID: 3}
+	reviewer := &model.User{ID: 4, Role: model.RoleReviewer}
+	admin := &model.User{ID: 5, Role: model.RoleAdmin}
+	stranger := &model.User{ID: 6}
+	reviewingAuthor := &model.User{ID: 1, Role: model.RoleReviewer}
+	adminEditor := &model.User{ID: 2, Role: model.RoleAdmin}
+
+	cases := []struct {
+		name string
+		from string
+		user *model.User
+		to   string
+		err  error
+	}{
+		{"作者提交审核", model.ArticleStatusDraft, author, model.ArticleStatusInReview, nil},
+		{"编辑者提交审核", model.ArticleStatusDraft, editor, model.ArticleStatusInReview, nil},
+		{"查看者不能提交审核", model.ArticleStatusDraft, viewer, model.ArticleStatusInReview, ErrTransitionForbidden},
+		{"审核员看不到草稿", model.ArticleStatusDraft, reviewer, model.ArticleStatusInReview, ErrArticleNotFound},
+		{"审核员通过", model.ArticleStatusInReview, reviewer, model.ArticleStatusApproved, nil},
+		{"管理员驳回", model.ArticleStatusInReview, admin, model.ArticleStatusRejected, nil},
+		{"作者不能自己通过", model.ArticleStatusInReview, author, model.ArticleStatusApproved, ErrTransitionForbidden},
+		{"身为审核员的作者不能通过自己的文章", model.ArticleStatusInReview, reviewingAuthor, model.ArticleStatusApproved, ErrCannotReviewOwnArticle},
+		{"身为管理员的编辑者不能驳回参与编写的文章", model.ArticleStatusInReview, adminEditor, model.ArticleStatusRejected, ErrCannotReviewOwnArticle},
+		{"身为审核员的作者可以发布已通过的文章", model.ArticleStatusApproved, reviewingAuthor, model.ArticleStatusPublished, nil},
+		{"作者撤回审核", model.ArticleStatusInReview, author, model.ArticleStatusDraft, nil},
+		{"驳回后重新提交", model.ArticleStatusRejected, author, model.ArticleStatusInReview, nil},
+		{"被驳回的文章不能发布", model.ArticleStatusRejected, author, model.ArticleStatusPublished, ErrInvalidTransition},
+		{"审核员发布已通过的文章", model.ArticleStatusApproved, reviewer, model.ArticleStatusPublished, nil},
+		{"未开启审核要求时草稿可以直接发布", model.ArticleStatusDraft, author, model.ArticleStatusPublished, nil},
+		{"审核员归档", model.ArticleStatusPublished, reviewer, model.ArticleStatusArchived, nil},
+		{"审核员不能退回草稿", model.ArticleStatusPublished, reviewer, model.ArticleStatusDraft, ErrTransitionForbidden},
+		{"无关用户不能归档", model.ArticleStatusPublished, stranger, model.ArticleStatusArchived, ErrArticleForbidden},
+		{"归档后恢复为草稿", model.ArticleStatusArchived, author, model.ArticleStatusDraft, nil},
+		{"无关用户看不到已归档的文章", model.ArticleStatusArchived, stranger, model.ArticleStatusDraft, ErrArticleNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			articleRepo, collabRepo := reviewRepos(tc.from)
+			s := &ArticleService{articleRepo: articleRepo, collabRepo: collabRepo}
+
+			article, err := s.TransitionArticle(context.Background(), tc.user, 1, &model.TransitionRequest{Status: tc.to})
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				articleRepo.AssertNotCalled(t, "Transition", mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.to, article.Status)
+			articleRepo.AssertCalled(t, "Transition", &model.ArticleTransition{
+				ArticleID: 1, FromStatus: tc.from, ToStatus: tc.to, ActorID: tc.user.ID,
+			})
+		})
+	}
+}
+
+func TestArticleService_TransitionRecordsHistoryAndEvents(t *testing.T) {
+	articleRepo, collabRepo := reviewRepos(model.ArticleStatusApproved)
+	recorder := &memoryAuditRecorder{}
+	s := &ArticleService{articleRepo: articleRepo, collabRepo: collabRepo, auditor: recorder}
+	reviewer := &model.User{ID: 4, Username: "reviewer", Role: model.RoleReviewer}
+
+	_, err := s.TransitionArticle(context.Background(), reviewer, 1, &model.TransitionRequest{Status: model.ArticleStatusPublished, Comment: "ok"})
+	require.NoError(t, err)
+	articleRepo.AssertCalled(t, "Transition", &model.ArticleTransition{
+		ArticleID: 1, FromStatus: model.ArticleStatusApproved, ToStatus: model.ArticleStatusPublished, ActorID: 4, Comment: "ok",
+	})
+	require.Len(t, articleRepo.events, 2)
+	assert.Equal(t, events.ArticlePublished, articleRepo.events[1].Type)
+	assert.Equal(t, model.ArticleStatusApproved, articleRepo.events[1].Data.(events.ArticleData).PreviousStatus)
+	require.Len(t, recorder.events, 1)
+	assert.Equal(t, "approved->published", recorder.events[0].Detail)
+	assert.Equal(t, model.AuditActionArticleStatus, recorder.events[0].Action)
+}
+
+func TestArticleService_TransitionConflict(t *testing.T) {
+	articleRepo := new(MockArticleRepository)
+	articleRepo.On("FindByID", uint(1)).Return(&model.Article{ID: 1, AuthorID: 1, Status: model.ArticleStatusInReview}, nil)
+	articleRepo.On("Transition", mock.Anything).Return(nil, nil)
+	s := &ArticleService{articleRepo: articleRepo}
+
+	// 读取后文章已被其他审核员处理
+	_, err := s.TransitionArticle(context.Background(), &model.User{ID: 4, Role: model.RoleReviewer}, 1,
+		&model.TransitionRequest{Status: model.ArticleStatusApproved})
+	assert.ErrorIs(t, err, ErrArticleStatusChanged)
+	assert.Empty(t, articleRepo.events)
+}
+
+func TestArticleService_RequireReviewBeforePublish(t *testing.T) {
+	withReviewRequired(t, true)
+	author := &model.User{ID: 1}
+	articleRepo, collabRepo := reviewRepos(model.ArticleStatusDraft)
+	articleRepo.On("UpdateTags", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	s := &ArticleService{articleRepo: articleRepo, collabRepo: collabRepo}
+	ctx := context.Background()
+
+	// 不能直接创建已发布的文章，也不能通过编辑把草稿改为已发布
+	err := s.CreateArticle(ctx, author, &model.Article{AuthorID: 1, Status: model.ArticleStatusPublished}, nil)
+	assert.ErrorIs(t, err, ErrReviewRequired)
+	article := &model.Article{ID: 1, Title: "t", Content: "c", Status: model.ArticleStatusPublished}
+	assert.ErrorIs(t, s.UpdateArticleWithTags(ctx, author, article, nil, nil), ErrReviewRequired)
+	articleRepo.AssertNotCalled(t, "UpdateTags", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// 编辑时提交审核，内容和状态都会保存
+	article = &model.Article{ID: 1, Title: "t", Content: "c", Status: model.ArticleStatusInReview}
+	require.NoError(t, s.UpdateArticleWithTags(ctx, author, article, nil, nil))
+	assert.Equal(t, model.ArticleStatusInReview, article.Status)
+	articleRepo.AssertCalled(t, "UpdateTags", mock.MatchedBy(func(a *model.Article) bool {
+		return a.ID == 1
+	}), []string(nil), (*model.SeriesPlacement)(nil), &model.ArticleTransition{
+		ArticleID: 1, FromStatus: model.ArticleStatusDraft, ToStatus: model.ArticleStatusInReview, ActorID: 1,
+	})
+	articleRepo.AssertNotCalled(t, "Transition", mock.Anything)
+}
+
+func TestArticleService_ReviewerVisibility(t *testing.T) {
+	reviewer := &model.User{ID: 4, Role: model.RoleReviewer}
+	reactionRepo := new(MockReactionRepository)
+	reactionRepo.On("FindByUser", mock.Anything, mock.Anything).Return([]model.ArticleReaction{}, nil)
+
+	// 审核员可以看到待审核的文章，看不到他人的草稿
+	articleRepo, collabRepo := reviewRepos(model.ArticleStatusInReview)
+	s := &ArticleService{articleRepo: articleRepo, reactionRepo: reactionRepo, collabRepo: collabRepo}
+	_, err := s.GetArticle(context.Background(), reviewer, 1)
+	assert.NoError(t, err)
+	_, err = s.GetArticle(context.Background(), &model.User{ID: 6}, 1)
+	assert.ErrorIs(t, err, ErrArticleNotFound)
+
+	articleRepo, collabRepo = reviewRepos(model.ArticleStatusDraft)
+	s = &ArticleService{articleRepo: articleRepo, reactionRepo: reactionRepo, collabRepo: collabRepo}
+	_, err = s.GetArticle(context.Background(), reviewer, 1)
+	assert.ErrorIs(t, err, ErrArticleNotFound)
+}
+
+func TestArticleService_ReviewComments(t *testing.T) {
+	articleRepo, collabRepo := reviewRepos(model.ArticleStatusInReview)
+	reviewRepo := new(MockReviewRepository)
+	reviewRepo.On("CreateComment", mock.Anything).Return(nil)
+	reviewRepo.On("ListComments", uint(1)).Return([]model.ReviewComment{{ID: 1, ArticleID: 1, AuthorID: 4}}, nil)
+	s := &ArticleService{articleRepo: articleRepo, collabRepo: collabRepo, reviewRepo: reviewRepo}
+	ctx := context.Background()
+	req := &model.CreateReviewCommentRequest{Body: "please fix the intro"}
+
+	comment, err := s.AddReviewComment(ctx, &model.User{ID: 4, Role: model.RoleReviewer}, 1, req)
+	require.NoError(t, err)
+	assert.Equal(t, uint(4), comment.AuthorID)
+	_, err = s.AddReviewComment(ctx, &model.User{ID: 1}, 1, req)
+	assert.NoError(t, err)
+
+	// 查看者只能阅读评论，无关用户看不到
+	_, err = s.AddReviewComment(ctx, &model.User{ID: 3}, 1, req)
+	assert.ErrorIs(t, err, ErrArticleForbidden)
+	comments, err := s.ListReviewComments(ctx, &model.User{ID: 3}, 1)
+	require.NoError(t, err)
+	assert.Len(t, comments, 1)
+	_, err = s.ListReviewComments(ctx, &model.User{ID: 6}, 1)
+	assert.ErrorIs(t, err, ErrArticleNotFound)
+	reviewRepo.AssertNumberOfCalls(t, "CreateComment", 2)
+}
+
+func TestArticleService_EditAfterApprovalRequiresReview(t *testing.T) {
+	author := &model.User{ID: 1}
+	ctx := context.Background()
+	approved := func() (*ArticleService, *MockArticleRepository) {
+		articleRepo := new(MockArticleRepository)
+		articleRepo.On("FindByID", uint(1)).Return(&model.Article{
+			ID: 1, AuthorID: 1, Title: "t", Content: "approved text", Status: model.ArticleStatusApproved,
+		}, nil).Once()
+		articleRepo.On("UpdateTags", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		return &ArticleService{articleRepo: articleRepo}, articleRepo
+	}
+
+	// 修改已通过的文章时不能同时发布
+	s, articleRepo := approved()
+	article := &model.Article{ID: 1, Title: "t", Content: "different text", Status: model.ArticleStatusPublished}
+	assert.ErrorIs(t, s.UpdateArticleWithTags(ctx, author, article, nil, nil), ErrReviewRequired)
+	articleRepo.AssertNotCalled(t, "UpdateTags", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// 保持状态修改内容时文章回到待审核，之后不能直接发布
+	s, articleRepo = approved()
+	article = &model.Article{ID: 1, Title: "t", Content: "different text", Status: model.ArticleStatusApproved}
+	require.NoError(t, s.UpdateArticleWithTags(ctx, author, article, nil, nil))
+	assert.Equal(t, model.ArticleStatusInReview, article.Status)
+	articleRepo.AssertCalled(t, "UpdateTags", mock.Anything, []string(nil), (*model.SeriesPlacement)(nil), &model.ArticleTransition{
+		ArticleID: 1, FromStatus: model.ArticleStatusApproved, ToStatus: model.ArticleStatusInReview, ActorID: 1,
+	})
+	articleRepo.On("FindByID", uint(1)).Return(&model.Article{ID: 1, AuthorID: 1, Status: model.ArticleStatusInReview}, nil)
+	_, err := s.TransitionArticle(ctx, author, 1, &model.TransitionRequest{Status: model.ArticleStatusPublished})
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+
+	// 内容不变时可以发布已通过的文章
+	s, articleRepo = approved()
+	article = &model.Article{ID: 1, Title: "t", Content: "approved text", Status: model.ArticleStatusPublished}
+	require.NoError(t, s.UpdateArticleWithTags(ctx, author, article, nil, nil))
+	assert.Equal(t, model.ArticleStatusPublished, article.Status)
+}
+
+func TestArticleService_EditPublishedRequiresReview(t *testing.T) {
+	withReviewRequired(t, true)
+	articleRepo, collabRepo := reviewRepos(model.ArticleStatusPublished)
+	articleRepo.On("UpdateTags", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	s := &ArticleService{articleRepo: articleRepo, collabRepo: collabRepo}
+
+	// 开启发布前审核时，编辑者修改已发布的文章后文章回到待审核
+	article := &model.Article{ID: 1, Title: "t", Content: "c", Status: model.ArticleStatusPublished}
+	require.NoError(t, s.UpdateArticleWithTags(context.Background(), &model.User{ID: 2}, article, nil, nil))
+	assert.Equal(t, model.ArticleStatusInReview, article.Status)
+}
+
+func TestArticleService_EditStatusSavedAtomically(t *testing.T) {
+	author := &model.User{ID: 1}
+	ctx := context.Background()
+	edit := func(saveErr error) (*MockArticleRepository, error) {
+		articleRepo := new(MockArticleRepository)
+		articleRepo.On("FindByID", uint(1)).Return(&model.Article{
+			ID: 1, AuthorID: 1, Title: "t", Content: "approved text", Status: model.ArticleStatusApproved,
+		}, nil)
+		articleRepo.On("UpdateTags", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(saveErr)
+		s := &ArticleService{articleRepo: articleRepo}
+		article := &model.Article{ID: 1, Title: "t", Content: "different text", Status: model.ArticleStatusApproved}
+		return articleRepo, s.UpdateArticleWithTags(ctx, author, article, nil, nil)
+	}
+
+	// 内容和状态变更一起保存，只产生一个 updated 事件
+	articleRepo, err := edit(nil)
+	require.NoError(t, err)
+	require.Len(t, articleRepo.events, 1)
+	assert.Equal(t, events.ArticleUpdated, articleRepo.events[0].Type)
+	assert.Equal(t, model.ArticleStatusInReview, articleRepo.events[0].Data.(events.ArticleData).Status)
+	articleRepo.AssertNotCalled(t, "Transition", mock.Anything)
+
+	// 保存时状态已被并发修改，内容也不会保存，不产生事件
+	articleRepo, err = edit(repository.ErrArticleStatusChanged)
+	assert.ErrorIs(t, err, ErrArticleStatusChanged)
+	assert.Empty(t, articleRepo.events)
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"blog/internal/model"
+	"blog/internal/repository"
+	"blog/internal/tracing"
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type SeriesService struct {
+	seriesRepo  repository.ISeriesRepository
+	articleRepo repository.IArticleRepository
+}
+
+func NewSeriesService() *SeriesService {
+	return &SeriesService{
+		seriesRepo:  repository.NewSeriesRepository(),
+		articleRepo: repository.NewArticleRepository(),
+	}
+}
+
+// Create 创建系列
+func (s *SeriesService) Create(ctx context.Context, user *model.User, req *model.CreateSeriesRequest) (_ *model.Series, err error) {
+	ctx, span := tracing.Start(ctx, "SeriesService.Create")
+	defer func() { tracing.End(span, err) }()
+
+	series := &model.Series{
+		Title:       req.Title,
+		Description: req.Description,
+		AuthorID:    user.ID,
+	}
+	if err := s.seriesRepo.Create(ctx, series); err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// Get 获取系列及其中的文章，未发布的文章只有作者本人可见
+func (s *SeriesService) Get(ctx context.Context, viewer *model.User, id uint) (_ *model.SeriesDetail, err error) {
+	ctx, span := tracing.Start(ctx, "SeriesService.Get",
+		attribute.Int("series.id", int(id)))
+	defer func() { tracing.End(span, err) }()
+
+	series, err := s.seriesRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if series == nil {
+		return nil, ErrSeriesNotFound
+	}
+	return s.detail(ctx, viewer, series)
+}
+
+// Update 修改系列的标题和简介
+func (s *SeriesService) Update(ctx context.Context, user *model.User, id uint, req *model.UpdateSeriesRequest) (_ *model.Series, err error) {
+	ctx, span := tracing.Start(ctx, "SeriesService.Update",
+		attribute.Int("series.id", int(id)))
+	defer func() { tracing.End(span, err) }()
+
+	series, err := findOwnedSeries(ctx, s.seriesRepo, user, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Title != nil {
+		series.Title = *req.Title
+	}
+	if req.Description != nil {
+		series.Description = *req.Description
+	}
+	if err := s.seriesRepo.Update(ctx, series); err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// Delete 删除系列，其中的文章保留
+func (s *SeriesService) Delete(ctx context.Context, user *model.User, id uint) (err error) {
+	ctx, span := tracing.Start(ctx, "SeriesService.Delete",
+		attribute.Int("series.id", int(id)))
+	defer func() { tracing.End(span, err) }()
+
+	if _, err := findOwnedSeries(ctx, s.seriesRepo, user, id); err != nil {
+		return err
+	}
+	return s.seriesRepo.Delete(ctx, id)
+}
+
+// AttachArticle 把自己的文章加入系列或调整其位置
+func (s *SeriesService) AttachArticle(ctx context.Context, user *model.User, id uint, req *model.AttachSeriesArticleRequest) (_ *model.SeriesDetail, err error) {
+	ctx, span := tracing.Start(ctx, "SeriesService.AttachArticle",
+		attribute.Int("series.id", int(id)),
+		attribute.Int("article.id", int(req.ArticleID)))
+	defer func() { tracing.End(span, err) }()
+
+	series, err := findOwnedSeries(ctx, s.seriesRepo, user, id)
+	if err != nil {
+		return nil, err
+	}
+	article, err := s.articleRepo.FindByID(ctx, req.ArticleID)
+	if err != nil {
+		return nil, err
+	}
+	if article == nil {
+		return nil, ErrArticleNotFound
+	}
+	if article.AuthorID != user.ID {
+		return nil, ErrArticleForbidden
+	}
+
+	if err := s.seriesRepo.Attach(ctx, id, req.ArticleID, req.Position); err != nil {
+		return nil, saveError(err)
+	}
+	return s.detail(ctx, user, series)
+}
+
+// DetachArticle 把文章移出系列
+func (s *SeriesService) DetachArticle(ctx context.Context, user *model.User, id, articleID uint) (err error) {
+	ctx, span := tracing.Start(ctx, "SeriesService.DetachArticle",
+		attribute.Int("series.id", int(id)),
+		attribute.Int("article.id", int(articleID)))
+	defer func() { tracing.End(span, err) }()
+
+	if _, err := findOwnedSeries(ctx, s.seriesRepo, user, id); err != nil {
+		return err
+	}
+	membership, err := s.seriesRepo.FindByArticle(ctx, articleID)
+	if err != nil {
+		return err
+	}
+	if membership == nil || membership.SeriesID != id {
+		return ErrArticleNotInSeries
+	}
+	_, err = s.seriesRepo.Detach(ctx, articleID)
+	return err
+}
+
+// Reorder 按给定顺序重新排列系列中的文章，必须恰好包含系列中的全部文章
+func (s *SeriesService) Reorder(ctx context.Context, user *model.User, id uint, req *model.ReorderSeriesRequest) (_ *model.SeriesDetail, err error) {
+	ctx, span := tracing.Start(ctx, "SeriesService.Reorder",
+		attribute.Int("series.id", int(id)))
+	defer func() { tracing.End(span, err) }()
+
+	series, err := findOwnedSeries(ctx, s.seriesRepo, user, id)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.seriesRepo.ListEntries(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !sameArticles(entries, req.ArticleIDs) {
+		return nil, ErrInvalidSeriesOrder
+	}
+
+	if err := s.seriesRepo.Reorder(ctx, id, req.ArticleIDs); err != nil {
+		return nil, err
+	}
+	return s.detail(ctx, user, series)
+}
+
+func (s *SeriesService) detail(ctx context.Context, viewer *model.User, series *model.Series) (*model.SeriesDetail, error) {
+	entries, err := s.seriesRepo.ListEntries(ctx, series.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.SeriesDetail{Series: *series, Articles: visibleEntries(viewer, series, entries)}, nil
+}
+
+// findOwnedSeries 查找用户自己的系列
+func findOwnedSeries(ctx context.Context, repo repository.ISeriesRepository, user *model.User, id uint) (*model.Series, error) {
+	series, err := repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if series == nil {
+		return nil, ErrSeriesNotFound
+	}
+	if series.AuthorID != user.ID {
+		return nil, ErrSeriesForbidden
+	}
+	return series, nil
+}
+
+// visibleEntries 系列作者可以看到全部文章，其他人只能看到已发布的文章
+func visibleEntries(viewer *model.User, series *model.Series, entries []model.SeriesEntry) []model.SeriesEntry {
+	visible := make([]model.SeriesEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Status == model.ArticleStatusPublished || (viewer != nil && viewer.ID == series.AuthorID) {
+			visible = append(visible, entry)
+		}
+	}
+	return visible
+}
+
+// seriesNav 根据可见的文章计算当前文章的位置和前后篇，当前文章不可见时返回 nil
+func seriesNav(series *model.Series, entries []model.SeriesEntry, articleID uint) *model.SeriesNav {
+	for i := range entries {
+		if entries[i].ArticleID != articleID {
+			continue
+		}
+		nav := &model.SeriesNav{
+			ID:       series.ID,
+			Title:    series.Title,
+			Position: i + 1,
+			Total:    len(entries),
+		}
+		if i > 0 {
+			nav.Prev = &entries[i-1]
+		}
+		if i < len(entries)-1 {
+			nav.Next = &entries[i+1]
+		}
+		return nav
+	}
+	return nil
+}
+
+// sameArticles 判断 ids 是否恰好是系列中的全部文章
+func sameArticles(entries []model.SeriesEntry, ids []uint) bool {
+	if len(entries) != len(ids) {
+		return false
+	}
+	members := make(map[uint]bool, len(entries))
+	for _, entry := range entries {
+		members[entry.ArticleID] = true
+	}
+	for _, id := range ids {
+		if !members[id] {
+			return false
+		}
+		delete(members, id)
+	}
+	return true
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"blog/internal/model"
+	"blog/internal/repository"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockSeriesRepository 模拟系列文章仓库
+type MockSeriesRepository struct {
+	mock.Mock
+}
+
+func (m *MockSeriesRepository) Create(ctx context.Context, series *model.Series) error {
+	return m.Called(series).Error(0)
+}
+
+func (m *MockSeriesRepository) FindByID(ctx context.Context, id uint) (*model.Series, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Series), args.Error(1)
+}
+
+func (m *MockSeriesRepository) Update(ctx context.Context, series *model.Series) error {
+	return m.Called(series).Error(0)
+}
+
+func (m *MockSeriesRepository) Delete(ctx context.Context, id uint) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockSeriesRepository) ListEntries(ctx context.Context, seriesID uint) ([]model.SeriesEntry, error) {
+	args := m.Called(seriesID)
+	return args.Get(0).([]model.SeriesEntry), args.Error(1)
+}
+
+func (m *MockSeriesRepository) FindByArticle(ctx context.Context, articleID uint) (*model.SeriesArticle, error) {
+	args := m.Called(articleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SeriesArticle), args.Error(1)
+}
+
+func (m *MockSeriesRepository) Attach(ctx context.Context, seriesID, articleID uint, position int) error {
+	return m.Called(seriesID, articleID, position).Error(0)
+}
+
+func (m *MockSeriesRepository) Detach(ctx context.Context, articleID uint) (bool, error) {
+	args := m.Called(articleID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSeriesRepository) Reorder(ctx context.Context, seriesID uint, articleIDs []uint) error {
+	return m.Called(seriesID, articleIDs).Error(0)
+}
+
+// tutorialEntries 第 2 篇尚未发布的三篇教程
+var tutorialEntries = []model.SeriesEntry{
+	{ArticleID: 10, Title: "Part 1", Status: model.ArticleStatusPublished, Position: 1},
+	{ArticleID: 11, Title: "Part 2", Status: model.ArticleStatusDraft, Position: 2},
+	{ArticleID: 12, Title: "Part 3", Status: model.ArticleStatusPublished, Position: 3},
+}
+
+func TestArticleService_GetArticleSeriesNav(t *testing.T) {
+	author := &model.User{ID: 1}
+	articleRepo := new(MockArticleRepository)
+	articleRepo.On("FindByID", uint(12)).Return(&model.Article{ID: 12, AuthorID: 1, Status: model.ArticleStatusPublished}, nil)
+	seriesRepo := new(MockSeriesRepository)
+	seriesRepo.On("FindByArticle", uint(12)).Return(&model.SeriesArticle{SeriesID: 5, ArticleID: 12, Position: 3}, nil)
+	seriesRepo.On("FindByID", uint(5)).Return(&model.Series{ID: 5, Title: "Go tutorial", AuthorID: 1}, nil)
+	seriesRepo.On("ListEntries", uint(5)).Return(tutorialEntries, nil)
+	s := &ArticleService{articleRepo: articleRepo, reactionRepo: new(MockReactionRepository), seriesRepo: seriesRepo}
+
+	// 读者看不到草稿，第 3 篇的上一篇是第 1 篇
+	article, err := s.GetArticle(context.Background(), nil, 12)
+	require.NoError(t, err)
+	require.NotNil(t, article.Series)
+	assert.Equal(t, 2, article.Series.Position)
+	assert.Equal(t, 2, article.Series.Total)
+	assert.Equal(t, uint(10), article.Series.Prev.ArticleID)
+	assert.Nil(t, article.Series.Next)
+
+	// 作者可以看到草稿
+	s.reactionRepo.(*MockReactionRepository).On("FindByUser", uint(1), []uint{12}).Return([]model.ArticleReaction{}, nil)
+	article, err = s.GetArticle(context.Background(), author, 12)
+	require.NoError(t, err)
+	assert.Equal(t, 3, article.Series.Position)
+	assert.Equal(t, uint(11), article.Series.Prev.ArticleID)
+}
+
+func TestArticleService_CreateArticleInSeries(t *testing.T) {
+	user := &model.User{ID: 1}
+	articleRepo := new(MockArticleRepository)
+	placement := &model.SeriesPlacement{SeriesID: 5, Position: 2}
+	articleRepo.On("Create", mock.Anything, placement).Return(nil).Once()
+	seriesRepo := new(MockSeriesRepository)
+	seriesRepo.On("FindByID", uint(5)).Return(&model.Series{ID: 5, AuthorID: 1}, nil)
+	seriesRepo.On("FindByID", uint(6)).Return(&model.Series{ID: 6, AuthorID: 2}, nil)
+	s := &ArticleService{articleRepo: articleRepo, seriesRepo: seriesRepo}
+
+	// 放入系列与创建文章在同一事务中完成
+	article := &model.Article{Title: "Part 2", Status: model.ArticleStatusDraft, AuthorID: 1}
+	require.NoError(t, s.CreateArticle(context.Background(), user, article, placement))
+	seriesRepo.AssertNotCalled(t, "Attach", mock.Anything, mock.Anything, mock.Anything)
+
+	// 不能放入他人的系列，文章不会被创建
+	err := s.CreateArticle(context.Background(), user, &model.Article{AuthorID: 1}, &model.SeriesPlacement{SeriesID: 6})
+	assert.ErrorIs(t, err, ErrSeriesForbidden)
+	articleRepo.AssertNumberOfCalls(t, "Create", 1)
+
+	// 保存时系列已被删除，文章随事务回滚
+	articleRepo.On("Create", mock.Anything, placement).Return(repository.ErrSeriesNotFound)
+	err = s.CreateArticle(context.Background(), user, &model.Article{AuthorID: 1}, placement)
+	assert.ErrorIs(t, err, ErrSeriesNotFound)
+	assert.Len(t, articleRepo.events, 1)
+}
+
+func TestSeriesService_Reorder(t *testing.T) {
+	user := &model.User{ID: 1}
+	seriesRepo := new(MockSeriesRepository)
+	seriesRepo.On("FindByID", uint(5)).Return(&model.Series{ID: 5, AuthorID: 1}, nil)
+	seriesRepo.On("ListEntries", uint(5)).Return(tutorialEntries, nil)
+	seriesRepo.On("Reorder", uint(5), []uint{12, 10, 11}).Return(nil)
+	s := &SeriesService{seriesRepo: seriesRepo}
+
+	_, err := s.Reorder(context.Background(), user, 5, &model.ReorderSeriesRequest{ArticleIDs: []uint{12, 10, 11}})
+	require.NoError(t, err)
+
+	for _, ids := range [][]uint{{10, 11}, {10, 10, 12}, {10, 11, 13}} {
+		_, err = s.Reorder(context.Background(), user, 5, &model.ReorderSeriesRequest{ArticleIDs: ids})
+		assert.ErrorIs(t, err, ErrInvalidSeriesOrder, "ids %v", ids)
+	}
+	seriesRepo.AssertNumberOfCalls(t, "Reorder", 1)
+
+	_, err = s.Reorder(context.Background(), &model.User{ID: 2}, 5, &model.ReorderSeriesRequest{ArticleIDs: []uint{12, 10, 11}})
+	assert.ErrorIs(t, err, ErrSeriesForbidden)
+}
+
+func TestSeriesService_AttachAndDetach(t *testing.T) {
+	user := &model.User{ID: 1}
+	articleRepo := new(MockArticleRepository)
+	articleRepo.On("FindByID", uint(10)).Return(&model.Article{ID: 10, AuthorID: 1}, nil)
+	articleRepo.On("FindByID", uint(30)).Return(&model.Article{ID: 30, AuthorID: 2}, nil)
+	seriesRepo := new(MockSeriesRepository)
+	seriesRepo.On("FindByID", uint(5)).Return(&model.Series{ID: 5, AuthorID: 1}, nil)
+	seriesRepo.On("ListEntries", uint(5)).Return(tutorialEntries, nil)
+	seriesRepo.On("Attach", uint(5), uint(10), 1).Return(nil)
+	seriesRepo.On("FindByArticle", uint(10)).Return(&model.SeriesArticle{SeriesID: 5, ArticleID: 10}, nil)
+	seriesRepo.On("FindByArticle", uint(30)).Return(nil, nil)
+	seriesRepo.On("Detach", uint(10)).Return(true, nil)
+	s := &SeriesService{seriesRepo: seriesRepo, articleRepo: articleRepo}
+
+	detail, err := s.AttachArticle(context.Background(), user, 5, &model.AttachSeriesArticleRequest{ArticleID: 10, Position: 1})
+	require.NoError(t, err)
+	assert.Len(t, detail.Articles, 3)
+
+	// 不能把他人的文章加入系列
+	_, err = s.AttachArticle(context.Background(), user, 5, &model.AttachSeriesArticleRequest{ArticleID: 30})
+	assert.ErrorIs(t, err, ErrArticleForbidden)
+
+	require.NoError(t, s.DetachArticle(context.Background(), user, 5, 10))
+	assert.ErrorIs(t, s.DetachArticle(context.Background(), user, 5, 30), ErrArticleNotInSeries)
+}
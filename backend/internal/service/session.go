@@ -0,0 +1,60 @@
+package service
+
+import (
+	"blog/internal/audit"
+	"blog/internal/model"
+	"blog/internal/repository"
+	"blog/internal/tracing"
+	"context"
+)
+
+type SessionService struct {
+	sessionRepo repository.ISessionRepository
+	auditor     audit.Recorder
+}
+
+func NewSessionService() *SessionService {
+	return &SessionService{
+		sessionRepo: repository.NewSessionRepository(),
+		auditor:     audit.GetLogger(),
+	}
+}
+
+// List 列出用户的有效会话，currentID 为发起请求的会话，会被标记为 current
+func (s *SessionService) List(ctx context.Context, user *model.User, currentID uint) (_ []model.Session, err error) {
+	ctx, span := tracing.Start(ctx, "SessionService.List")
+	defer func() { tracing.End(span, err) }()
+
+	sessions, err := s.sessionRepo.ListActiveByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentID
+	}
+	return sessions, nil
+}
+
+// Revoke 撤销会话，对应设备上的令牌随即失效；撤销当前会话等同于退出登录
+func (s *SessionService) Revoke(ctx context.Context, user *model.User, id uint) (err error) {
+	ctx, span := tracing.Start(ctx, "SessionService.Revoke")
+	defer func() { tracing.End(span, err) }()
+
+	defer func() {
+		event := auditActor(model.AuditEvent{
+			Action:     model.AuditActionSessionRevoke,
+			TargetType: model.AuditTargetSession,
+			TargetID:   auditTarget(id),
+		}, user)
+		recordAudit(ctx, s.auditor, event, err)
+	}()
+
+	revoked, err := s.sessionRepo.Revoke(ctx, id, user.ID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrSessionNotFound
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"blog/internal/clientinfo"
+	"blog/internal/model"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockSessionRepository 模拟会话仓库
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepository) Create(ctx context.Context, session *model.Session) error {
+	args := m.Called(session)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) FindByTokenID(ctx context.Context, tokenID string) (*model.Session, error) {
+	args := m.Called(tokenID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) ListActiveByUser(ctx context.Context, userID uint) ([]model.Session, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]model.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) Revoke(ctx context.Context, id, userID uint) (bool, error) {
+	args := m.Called(id, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSessionRepository) RevokeAllByUser(ctx context.Context, userID, exceptID uint) (int64, error) {
+	args := m.Called(userID, exceptID)
+	return int64(args.Int(0)), args.Error(1)
+}
+
+func (m *MockSessionRepository) UpdateLastSeen(ctx context.Context, lastSeen map[uint]time.Time) error {
+	args := m.Called(lastSeen)
+	return args.Error(0)
+}
+
+// acceptSessions 返回接受任意新会话的会话仓库，供只关心登录结果的测试使用
+func acceptSessions() *MockSessionRepository {
+	mockSessions := new(MockSessionRepository)
+	mockSessions.On("Create", mock.Anything).Return(nil)
+	return mockSessions
+}
+
+func TestAuthService_LoginCreatesSession(t *testing.T) {
+	withJWTSecret(t)
+	user := hashedUser(t, "password123")
+	mockRepo := new(MockUserRepository)
+	mockSessions := new(MockSessionRepository)
+	authService := &AuthService{userRepo: mockRepo, sessionRepo: mockSessions, hasher: testHasher}
+
+	var session *model.Session
+	mockRepo.On("FindByUsername", user.Username).Return(user, nil)
+	mockSessions.On("Create", mock.AnythingOfType("*model.Session")).
+		Run(func(args mock.Arguments) { session = args.Get(0).(*model.Session) }).
+		Return(nil)
+
+	ctx := clientinfo.WithInfo(context.Background(), clientinfo.Info{IP: "203.0.113.7", UserAgent: "curl/8.0"})
+	response, err := authService.Login(ctx, &model.LoginRequest{Account: user.Username, Password: "password123"})
+	require.NoError(t, err)
+
+	require.NotNil(t, session)
+	assert.Equal(t, user.ID, session.UserID)
+	assert.Equal(t, "203.0.113.7", session.IP)
+	assert.Equal(t, "curl/8.0", session.UserAgent)
+	assert.True(t, session.ExpiresAt.After(time.Now()))
+
+	// 令牌中的 sid 指向新建的会话
+	claims, err := authService.tokenKeySet().Parse(response.Token)
+	require.NoError(t, err)
+	assert.Equal(t, session.TokenID, claims["sid"])
+}
+
+func TestSessionService(t *testing.T) {
+	mockSessions := new(MockSessionRepository)
+	sessionService := &SessionService{sessionRepo: mockSessions}
+	user := &model.User{ID: 1}
+
+	mockSessions.On("ListActiveByUser", user.ID).Return([]model.Session{{ID: 2}, {ID: 3}}, nil)
+	sessions, err := sessionService.List(context.Background(), user, 3)
+	require.NoError(t, err)
+	assert.False(t, sessions[0].Current)
+	assert.True(t, sessions[1].Current)
+
+	mockSessions.On("Revoke", uint(2), user.ID).Return(true, nil)
+	mockSessions.On("Revoke", uint(9), user.ID).Return(false, nil)
+	assert.NoError(t, sessionService.Revoke(context.Background(), user, 2))
+	// 其他用户的会话或已撤销的会话
+	assert.ErrorIs(t, sessionService.Revoke(context.Background(), user, 9), ErrSessionNotFound)
+}
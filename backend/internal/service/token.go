@@ -0,0 +1,80 @@
+package service
+
+import (
+	"blog/config"
+	"blog/internal/model"
+	"blog/internal/repository"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// issueToken 为用户生成新的一次性令牌，同用途的旧令牌随之失效
+// payload 用于保存与令牌绑定的数据，如待更换的新邮箱
+func issueToken(ctx context.Context, tokenRepo repository.ITokenRepository, userID uint, purpose string, ttl time.Duration, payload string) (string, error) {
+	if err := tokenRepo.DeleteByUser(ctx, userID, purpose); err != nil {
+		return "", err
+	}
+
+	rawToken, err := newRawToken()
+	if err != nil {
+		return "", err
+	}
+
+	token := &model.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashToken(rawToken),
+		Payload:   payload,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := tokenRepo.Create(ctx, token); err != nil {
+		return "", err
+	}
+	return rawToken, nil
+}
+
+// newRawToken 生成 256 位随机令牌
+func newRawToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken 令牌只以 SHA-256 哈希形式入库
+func hashToken(rawToken string) string {
+	sum := sha256.Sum256([]byte(rawToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// linkURL 生成邮件中指向前端页面的链接
+func linkURL(path, rawToken string) string {
+	base := strings.TrimRight(config.AppConfig.Mail.LinkBaseURL, "/")
+	return base + path + "?token=" + url.QueryEscape(rawToken)
+}
+
+func tokenTTL(configured, fallback time.Duration) time.Duration {
+	if configured > 0 {
+		return configured
+	}
+	return fallback
+}
+
+// formatTTL 将有效期格式化为 24h、30m 这样的简短形式
+func formatTTL(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
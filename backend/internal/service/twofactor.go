@@ -0,0 +1,227 @@
+package service
+
+import (
+	"blog/config"
+	"blog/internal/audit"
+	"blog/internal/model"
+	"blog/internal/password"
+	"blog/internal/repository"
+	"blog/internal/totp"
+	"blog/internal/tracing"
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultChallengeTTL 密码验证通过后输入动态码的默认时限
+	defaultChallengeTTL = 5 * time.Minute
+	// recoveryCodeCount 每次生成的恢复码数量
+	recoveryCodeCount = 10
+	defaultIssuer     = "Blog"
+)
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type TwoFactorService struct {
+	userRepo     repository.IUserRepository
+	recoveryRepo repository.IRecoveryCodeRepository
+	hasher       password.Hasher
+	auditor      audit.Recorder
+}
+
+func NewTwoFactorService() *TwoFactorService {
+	return &TwoFactorService{
+		userRepo:     repository.NewUserRepository(),
+		recoveryRepo: repository.NewRecoveryCodeRepository(),
+		hasher:       password.GetHasher(),
+		auditor:      audit.GetLogger(),
+	}
+}
+
+// Setup 校验密码后生成新的 TOTP 密钥，需调用 Enable 确认后才生效
+func (s *TwoFactorService) Setup(ctx context.Context, user *model.User, password string) (_ *model.TwoFactorSetupResponse, err error) {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.Setup")
+	defer func() { tracing.End(span, err) }()
+
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if s.hasher.Compare(user.Password, password) != nil {
+		return nil, ErrIncorrectPassword
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.SetTOTPSecret(ctx, user.ID, secret); err != nil {
+		return nil, err
+	}
+
+	issuer := config.AppConfig.Auth.TwoFactor.Issuer
+	if issuer == "" {
+		issuer = defaultIssuer
+	}
+	return &model.TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.ProvisioningURI(issuer, user.Email, secret),
+	}, nil
+}
+
+// Enable 用认证器生成的动态码确认密钥并开启两步验证，返回一次性恢复码
+func (s *TwoFactorService) Enable(ctx context.Context, user *model.User, code string) (_ []string, err error) {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.Enable")
+	defer func() { tracing.End(span, err) }()
+	defer func() { recordAccountAudit(ctx, s.auditor, model.AuditActionTwoFactorEnable, user, "", err) }()
+
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorNotSetup
+	}
+	// 确认时只接受动态码，此时还没有恢复码
+	if err := verifyTOTP(ctx, s.userRepo, user, code); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.EnableTwoFactor(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable 校验密码和动态码（或恢复码）后关闭两步验证
+func (s *TwoFactorService) Disable(ctx context.Context, user *model.User, password, code string) (err error) {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.Disable")
+	defer func() { tracing.End(span, err) }()
+	defer func() { recordAccountAudit(ctx, s.auditor, model.AuditActionTwoFactorDisable, user, "", err) }()
+
+	if !user.TwoFactorEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+	if twoFactorRequired(user) {
+		return ErrTwoFactorRequired
+	}
+	if s.hasher.Compare(user.Password, password) != nil {
+		return ErrIncorrectPassword
+	}
+	if err := verifyTwoFactorCode(ctx, s.userRepo, s.recoveryRepo, user, code); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.DisableTwoFactor(ctx, user.ID); err != nil {
+		return err
+	}
+	return s.recoveryRepo.DeleteByUser(ctx, user.ID)
+}
+
+// RegenerateRecoveryCodes 校验动态码后重新生成恢复码，旧恢复码全部失效
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, user *model.User, code string) (_ []string, err error) {
+	ctx, span := tracing.Start(ctx, "TwoFactorService.RegenerateRecoveryCodes")
+	defer func() { tracing.End(span, err) }()
+	defer func() { recordAccountAudit(ctx, s.auditor, model.AuditActionRecoveryCodesRenew, user, "", err) }()
+
+	if !user.TwoFactorEnabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	if err := verifyTOTP(ctx, s.userRepo, user, code); err != nil {
+		return nil, err
+	}
+	return s.replaceRecoveryCodes(ctx, user.ID)
+}
+
+// replaceRecoveryCodes 生成新的恢复码，入库的只有哈希
+func (s *TwoFactorService) replaceRecoveryCodes(ctx context.Context, userID uint) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		hashes[i] = hashToken(normalizeRecoveryCode(code))
+	}
+
+	if err := s.recoveryRepo.Replace(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// verifyTwoFactorCode 校验动态码或恢复码，6 位数字按动态码处理，其余按恢复码处理
+func verifyTwoFactorCode(ctx context.Context, userRepo repository.IUserRepository, recoveryRepo repository.IRecoveryCodeRepository, user *model.User, code string) error {
+	code = strings.TrimSpace(code)
+	if isTOTPCode(code) {
+		return verifyTOTP(ctx, userRepo, user, code)
+	}
+
+	ok, err := recoveryRepo.Consume(ctx, user.ID, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// verifyTOTP 校验动态码，同一周期的动态码只能使用一次
+func verifyTOTP(ctx context.Context, userRepo repository.IUserRepository, user *model.User, code string) error {
+	step, ok := totp.Validate(user.TOTPSecret, strings.TrimSpace(code), time.Now())
+	if !ok || step <= user.TOTPLastStep {
+		return ErrInvalidTwoFactorCode
+	}
+
+	// 条件更新防止并发请求重复使用同一个动态码
+	advanced, err := userRepo.AdvanceTOTPStep(ctx, user.ID, step)
+	if err != nil {
+		return err
+	}
+	if !advanced {
+		return ErrInvalidTwoFactorCode
+	}
+	user.TOTPLastStep = step
+	return nil
+}
+
+// twoFactorRequired 配置要求管理员必须开启两步验证
+func twoFactorRequired(user *model.User) bool {
+	return config.AppConfig.Auth.TwoFactor.RequireForAdmins && user.Role == model.RoleAdmin
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != totp.Digits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRecoveryCode 生成形如 abcde-fghij 的恢复码
+func newRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(recoveryEncoding.EncodeToString(b))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeRecoveryCode 忽略大小写、空格和连字符
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
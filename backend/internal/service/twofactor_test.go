@@ -0,0 +1,219 @@
+package service
+
+import (
+	"blog/config"
+	"blog/internal/model"
+	"blog/internal/ratelimit"
+	"blog/internal/totp"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockRecoveryCodeRepository 模拟恢复码仓库
+type MockRecoveryCodeRepository struct {
+	mock.Mock
+}
+
+func (m *MockRecoveryCodeRepository) Replace(ctx context.Context, userID uint, codeHashes []string) error {
+	args := m.Called(userID, codeHashes)
+	return args.Error(0)
+}
+
+func (m *MockRecoveryCodeRepository) Consume(ctx context.Context, userID uint, codeHash string) (bool, error) {
+	args := m.Called(userID, codeHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRecoveryCodeRepository) DeleteByUser(ctx context.Context, userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+// currentCode 计算当前周期的动态码
+func currentCode(t *testing.T, secret string) (string, int64) {
+	step := totp.Step(time.Now())
+	code, err := totp.Code(secret, step)
+	require.NoError(t, err)
+	return code, step
+}
+
+func TestTwoFactorService_Enrolment(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockRecovery := new(MockRecoveryCodeRepository)
+	twoFactorService := &TwoFactorService{userRepo: mockRepo, recoveryRepo: mockRecovery, hasher: testHasher}
+	user := hashedUser(t, "password123")
+
+	// 密码错误不生成密钥
+	_, err := twoFactorService.Setup(context.Background(), user, "wrong")
+	assert.ErrorIs(t, err, ErrIncorrectPassword)
+
+	mockRepo.On("SetTOTPSecret", user.ID, mock.AnythingOfType("string")).Return(nil)
+	setup, err := twoFactorService.Setup(context.Background(), user, "password123")
+	require.NoError(t, err)
+	assert.NotEmpty(t, setup.Secret)
+	assert.Contains(t, setup.OTPAuthURI, "secret="+setup.Secret)
+	user.TOTPSecret = setup.Secret
+
+	// 错误的动态码不能开启
+	_, err = twoFactorService.Enable(context.Background(), user, "000000")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+
+	code, step := currentCode(t, setup.Secret)
+	var stored []string
+	mockRepo.On("AdvanceTOTPStep", user.ID, step).Return(true, nil).Once()
+	mockRecovery.On("Replace", user.ID, mock.Anything).
+		Run(func(args mock.Arguments) { stored = args.Get(1).([]string) }).
+		Return(nil)
+	mockRepo.On("EnableTwoFactor", user.ID).Return(nil)
+
+	codes, err := twoFactorService.Enable(context.Background(), user, code)
+	require.NoError(t, err)
+	require.Len(t, codes, recoveryCodeCount)
+	// 恢复码只以哈希入库
+	require.Len(t, stored, recoveryCodeCount)
+	assert.Equal(t, hashToken(normalizeRecoveryCode(codes[0])), stored[0])
+	assert.NotContains(t, stored, codes[0])
+	mockRepo.AssertCalled(t, "EnableTwoFactor", user.ID)
+
+	// 同一动态码不能重复使用
+	user.TwoFactorEnabled = true
+	_, err = twoFactorService.RegenerateRecoveryCodes(context.Background(), user, code)
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+}
+
+func TestTwoFactorService_Disable(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+
+	// 测试用例1：使用恢复码关闭
+	t.Run("恢复码关闭", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockRecovery := new(MockRecoveryCodeRepository)
+		twoFactorService := &TwoFactorService{userRepo: mockRepo, recoveryRepo: mockRecovery, hasher: testHasher}
+		user := hashedUser(t, "password123")
+		user.TwoFactorEnabled = true
+		user.TOTPSecret = secret
+
+		mockRecovery.On("Consume", user.ID, hashToken("abcdefghij")).Return(true, nil)
+		mockRepo.On("DisableTwoFactor", user.ID).Return(nil)
+		mockRecovery.On("DeleteByUser", user.ID).Return(nil)
+
+		err := twoFactorService.Disable(context.Background(), user, "password123", "ABCDE-fghij")
+		assert.NoError(t, err)
+		mockRepo.AssertCalled(t, "DisableTwoFactor", user.ID)
+	})
+
+	// 测试用例2：配置要求时管理员不能关闭
+	t.Run("管理员不能关闭", func(t *testing.T) {
+		withAdminTwoFactor(t)
+		mockRepo := new(MockUserRepository)
+		twoFactorService := &TwoFactorService{userRepo: mockRepo, hasher: testHasher}
+		user := hashedUser(t, "password123")
+		user.Role = model.RoleAdmin
+		user.TwoFactorEnabled = true
+
+		err := twoFactorService.Disable(context.Background(), user, "password123", "123456")
+		assert.ErrorIs(t, err, ErrTwoFactorRequired)
+		mockRepo.AssertNotCalled(t, "DisableTwoFactor", mock.Anything)
+	})
+}
+
+func TestAuthService_LoginTwoFactor(t *testing.T) {
+	withJWTSecret(t)
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+
+	newUser := func() *model.User {
+		user := hashedUser(t, "password123")
+		user.TwoFactorEnabled = true
+		user.TOTPSecret = secret
+		return user
+	}
+
+	// 测试用例1：密码正确后返回挑战令牌，验证动态码后签发 JWT
+	t.Run("动态码登录", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockTokenRepo := new(MockTokenRepository)
+		authService := &AuthService{userRepo: mockRepo, tokenRepo: mockTokenRepo, sessionRepo: acceptSessions(), hasher: testHasher}
+		user := newUser()
+
+		mockRepo.On("FindByUsername", "testuser").Return(user, nil)
+		mockTokenRepo.On("DeleteByUser", user.ID, model.TokenPurposeLoginChallenge).Return(nil)
+		mockTokenRepo.On("Create", mock.MatchedBy(func(token *model.UserToken) bool {
+			return token.Purpose == model.TokenPurposeLoginChallenge && token.Payload == "testuser"
+		})).Return(nil)
+
+		response, err := authService.Login(context.Background(), &model.LoginRequest{Account: "testuser", Password: "password123"})
+		require.NoError(t, err)
+		assert.True(t, response.TwoFactorRequired)
+		assert.Empty(t, response.Token)
+		assert.Nil(t, response.User)
+		require.NotEmpty(t, response.ChallengeToken)
+
+		code, step := currentCode(t, secret)
+		mockTokenRepo.On("Consume", model.TokenPurposeLoginChallenge, hashToken(response.ChallengeToken)).
+			Return(&model.UserToken{UserID: user.ID, Payload: "testuser"}, nil)
+		mockRepo.On("FindByID", user.ID).Return(user, nil)
+		mockRepo.On("AdvanceTOTPStep", user.ID, step).Return(true, nil)
+
+		final, err := authService.LoginTwoFactor(context.Background(), response.ChallengeToken, code)
+		require.NoError(t, err)
+		assert.NotEmpty(t, final.Token)
+		assert.Equal(t, user.ID, final.User.ID)
+	})
+
+	// 测试用例2：挑战令牌无效或已使用
+	t.Run("挑战令牌无效", func(t *testing.T) {
+		mockTokenRepo := new(MockTokenRepository)
+		authService := &AuthService{tokenRepo: mockTokenRepo}
+
+		mockTokenRepo.On("Consume", model.TokenPurposeLoginChallenge, hashToken("used")).Return(nil, nil)
+
+		_, err := authService.LoginTwoFactor(context.Background(), "used", "123456")
+		assert.ErrorIs(t, err, ErrInvalidChallenge)
+	})
+
+	// 测试用例3：动态码错误计入登录失败次数，达到阈值后锁定
+	t.Run("动态码错误", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockTokenRepo := new(MockTokenRepository)
+		mockRecovery := new(MockRecoveryCodeRepository)
+		authService := &AuthService{
+			userRepo:     mockRepo,
+			tokenRepo:    mockTokenRepo,
+			recoveryRepo: mockRecovery,
+			hasher:       testHasher,
+			loginGuard: ratelimit.NewLoginGuard(ratelimit.NewMemoryStore(), ratelimit.Limit{Rate: 100, Burst: 100}, ratelimit.LockoutPolicy{
+				MaxFailures:  2,
+				BaseDuration: time.Minute,
+				MaxDuration:  time.Hour,
+				Window:       time.Hour,
+			}),
+		}
+		user := newUser()
+
+		mockTokenRepo.On("Consume", model.TokenPurposeLoginChallenge, mock.Anything).
+			Return(&model.UserToken{UserID: user.ID, Payload: "testuser"}, nil)
+		mockRepo.On("FindByID", user.ID).Return(user, nil)
+		mockRecovery.On("Consume", user.ID, mock.Anything).Return(false, nil)
+
+		for i := 0; i < 2; i++ {
+			_, err := authService.LoginTwoFactor(context.Background(), "challenge", "not-a-code")
+			assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+		}
+		_, err := authService.LoginTwoFactor(context.Background(), "challenge", "not-a-code")
+		assert.ErrorIs(t, err, ratelimit.ErrAccountLocked)
+	})
+}
+
+// withAdminTwoFactor 为测试开启管理员强制两步验证，结束后恢复
+func withAdminTwoFactor(t *testing.T) {
+	original := config.AppConfig.Auth.TwoFactor.RequireForAdmins
+	config.AppConfig.Auth.TwoFactor.RequireForAdmins = true
+	t.Cleanup(func() { config.AppConfig.Auth.TwoFactor.RequireForAdmins = original })
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"blog/config"
+	"blog/internal/audit"
+	"blog/internal/httpcache"
+	"blog/internal/i18n"
+	"blog/internal/jobs"
+	"blog/internal/mailer"
+	"blog/internal/model"
+	"blog/internal/password"
+	"blog/internal/repository"
+	"blog/internal/tracing"
+	"context"
+	"strings"
+)
+
+type UserService struct {
+	userRepo     repository.IUserRepository
+	articleRepo  repository.IArticleRepository
+	tokenRepo    repository.ITokenRepository
+	sessionRepo  repository.ISessionRepository
+	apiTokenRepo repository.IAPITokenRepository
+	mailer       mailer.Mailer
+	hasher       password.Hasher
+	policy       *password.Policy
+	auditor      audit.Recorder
+	// 文章中带有作者的昵称和头像，资料变化时需要失效的公开接口响应缓存
+	publicCaches []cacheInvalidator
+}
+
+// articleListInvalidator 带缓存的文章仓库，作者资料变化时使列表缓存失效
+type articleListInvalidator interface {
+	InvalidateLists(ctx context.Context)
+}
+
+func NewUserService() *UserService {
+	return &UserService{
+		userRepo:     repository.NewUserRepository(),
+		articleRepo:  repository.NewArticleRepository(),
+		tokenRepo:    repository.NewTokenRepository(),
+		sessionRepo:  repository.NewSessionRepository(),
+		apiTokenRepo: repository.NewAPITokenRepository(),
+		mailer:       jobs.GetMailer(),
+		hasher:       password.GetHasher(),
+		policy:       password.GetPolicy(),
+		auditor:      audit.GetLogger(),
+		publicCaches: []cacheInvalidator{httpcache.GetStore()},
+	}
+}
+
+// GetCurrentUser 获取当前用户的最新资料
+func (s *UserService) GetCurrentUser(ctx context.Context, userID uint) (_ *model.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetCurrentUser")
+	defer func() { tracing.End(span, err) }()
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// UpdateProfile 更新个人资料，只修改请求中出现的字段
+func (s *UserService) UpdateProfile(ctx context.Context, userID uint, req *model.UpdateProfileRequest) (_ *model.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateProfile")
+	defer func() { tracing.End(span, err) }()
+
+	fields := map[string]interface{}{}
+	if req.DisplayName != nil {
+		fields["display_name"] = strings.TrimSpace(*req.DisplayName)
+	}
+	if req.Bio != nil {
+		fields["bio"] = strings.TrimSpace(*req.Bio)
+	}
+	if req.AvatarURL != nil {
+		fields["avatar_url"] = strings.TrimSpace(*req.AvatarURL)
+	}
+	if req.Website != nil {
+		fields["website"] = strings.TrimSpace(*req.Website)
+	}
+
+	if len(fields) > 0 {
+		if err := s.userRepo.UpdateProfile(ctx, userID, fields); err != nil {
+			return nil, err
+		}
+	}
+	if req.DisplayName != nil || req.AvatarURL != nil {
+		s.invalidateAuthorCaches(ctx)
+	}
+	return s.GetCurrentUser(ctx, userID)
+}
+
+// invalidateAuthorCaches 使带有作者资料的文章列表和响应缓存失效，文章详情缓存随过期刷新
+func (s *UserService) invalidateAuthorCaches(ctx context.Context) {
+	if lists, ok := s.articleRepo.(articleListInvalidator); ok {
+		lists.InvalidateLists(ctx)
+	}
+	for _, c := range s.publicCaches {
+		c.Invalidate()
+	}
+}
+
+// ChangePassword 校验旧密码后修改密码，撤销当前会话以外的会话；请求中选择时同时撤销全部个人访问令牌
+func (s *UserService) ChangePassword(ctx context.Context, user *model.User, currentSessionID uint, req *model.ChangePasswordRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "UserService.ChangePassword")
+	defer func() { tracing.End(span, err) }()
+
+	defer func() {
+		detail := ""
+		if req.RevokeAPITokens {
+			detail = "revoke_api_tokens"
+		}
+		recordAccountAudit(ctx, s.auditor, model.AuditActionPasswordChange, user, detail, err)
+	}()
+
+	if s.hasher.Compare(user.Password, req.OldPassword) != nil {
+		return ErrIncorrectPassword
+	}
+	if err := s.policy.Validate(req.NewPassword); err != nil {
+		return err
+	}
+
+	hash, err := s.hasher.Hash(req.NewPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, hash); err != nil {
+		return err
+	}
+	// 旧密码可能已泄露，只保留发起修改的会话
+	if _, err := s.sessionRepo.RevokeAllByUser(ctx, user.ID, currentSessionID); err != nil {
+		return err
+	}
+	if req.RevokeAPITokens {
+		if _, err := s.apiTokenRepo.RevokeAllByUser(ctx, user.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RequestEmailChange 校验密码后向新邮箱发送确认邮件，确认前邮箱保持不变
+func (s *UserService) RequestEmailChange(ctx context.Context, user *model.User, newEmail, password string) (err error) {
+	ctx, span := tracing.Start(ctx, "UserService.RequestEmailChange")
+	defer func() { tracing.End(span, err) }()
+	defer func() { recordAccountAudit(ctx, s.auditor, model.AuditActionEmailChangeRequest, user, "", err) }()
+
+	if s.hasher.Compare(user.Password, password) != nil {
+		return ErrIncorrectPassword
+	}
+	if strings.EqualFold(newEmail, user.Email) {
+		return ErrSameEmail
+	}
+	if err := s.ensureEmailAvailable(ctx, newEmail); err != nil {
+		return err
+	}
+
+	ttl := tokenTTL(config.AppConfig.Auth.VerifyTokenTTL, defaultVerifyTokenTTL)
+	rawToken, err := issueToken(ctx, s.tokenRepo, user.ID, model.TokenPurposeChangeEmail, ttl, newEmail)
+	if err != nil {
+		return err
+	}
+
+	locale := i18n.LocaleFrom(ctx)
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      newEmail,
+		Subject: i18n.Message(locale, "mail_change_email_subject"),
+		Body:    i18n.Message(locale, "mail_change_email_body", user.Username, linkURL("/auth/confirm-email", rawToken), formatTTL(ttl)),
+	})
+}
+
+// ConfirmEmailChange 使用确认邮件中的令牌完成邮箱更换
+func (s *UserService) ConfirmEmailChange(ctx context.Context, rawToken string) (err error) {
+	ctx, span := tracing.Start(ctx, "UserService.ConfirmEmailChange")
+	defer func() { tracing.End(span, err) }()
+
+	token, err := s.tokenRepo.Consume(ctx, model.TokenPurposeChangeEmail, hashToken(rawToken))
+	if err != nil {
+		return err
+	}
+	if token == nil || token.Payload == "" {
+		return ErrInvalidToken
+	}
+	// 令牌有效后才能确定账号，无效令牌不记录审计
+	defer func() {
+		recordAccountAudit(ctx, s.auditor, model.AuditActionEmailChange, &model.User{ID: token.UserID}, "", err)
+	}()
+
+	// 发出确认邮件后新邮箱可能已被他人注册
+	if err := s.ensureEmailAvailable(ctx, token.Payload); err != nil {
+		return err
+	}
+	return s.userRepo.UpdateEmail(ctx, token.UserID, token.Payload)
+}
+
+// GetPublicProfile 获取用户公开主页及其已发布的文章
+func (s *UserService) GetPublicProfile(ctx context.Context, username string, page, pageSize int) (_ *model.PublicProfile, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetPublicProfile")
+	defer func() { tracing.End(span, err) }()
+
+	user, err := s.userRepo.FindByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	articles, total, err := s.articleRepo.List(ctx, page, pageSize, model.ArticleStatusPublished, user.ID, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return &model.PublicProfile{
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		AvatarURL:   user.AvatarURL,
+		Website:     user.Website,
+		CreatedAt:   user.CreatedAt,
+		Articles:    articles,
+		Total:       total,
+	}, nil
+}
+
+// ChangeRole 管理员修改其他用户的角色，不能修改自己的角色以免误操作失去管理权限
+func (s *UserService) ChangeRole(ctx context.Context, admin *model.User, userID uint, role string) (err error) {
+	ctx, span := tracing.Start(ctx, "UserService.ChangeRole")
+	defer func() { tracing.End(span, err) }()
+
+	var previous string
+	defer func() {
+		event := model.AuditEvent{
+			ActorID:    admin.ID,
+			Actor:      admin.Username,
+			Action:     model.AuditActionRoleChange,
+			TargetType: model.AuditTargetUser,
+			TargetID:   auditTarget(userID),
+			Detail:     previous + "->" + role,
+		}
+		recordAudit(ctx, s.auditor, event, err)
+	}()
+
+	if userID == admin.ID {
+		return ErrCannotChangeOwnRole
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	previous = user.Role
+	if user.Role == role {
+		return nil
+	}
+	return s.userRepo.UpdateRole(ctx, userID, role)
+}
+
+func (s *UserService) ensureEmailAvailable(ctx context.Context, email string) error {
+	existing, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return ErrEmailExists
+	}
+	return nil
+}
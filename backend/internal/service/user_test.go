@@ -0,0 +1,242 @@
+package service
+
+import (
+	"blog/internal/mailer"
+	"blog/internal/model"
+	"blog/internal/password"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// hashedUser 构造一个带密码哈希的用户
+func hashedUser(t *testing.T, plain string) *model.User {
+	hash, err := testHasher.Hash(plain)
+	require.NoError(t, err)
+	return &model.User{ID: 1, Username: "testuser", Email: "old@example.com", Password: hash}
+}
+
+func TestUserService_UpdateProfile(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	userService := &UserService{userRepo: mockRepo}
+
+	displayName := "  Test User "
+	website := "https://example.com"
+	req := &model.UpdateProfileRequest{DisplayName: &displayName, Website: &website}
+
+	// 只更新请求中出现的字段
+	mockRepo.On("UpdateProfile", uint(1), map[string]interface{}{
+		"display_name": "Test User",
+		"website":      "https://example.com",
+	}).Return(nil)
+	mockRepo.On("FindByID", uint(1)).Return(&model.User{ID: 1, DisplayName: "Test User", Website: website}, nil)
+
+	user, err := userService.UpdateProfile(context.Background(), 1, req)
+	assert.NoError(t, err)
+	assert.Equal(t, "Test User", user.DisplayName)
+	mockRepo.AssertExpectations(t)
+}
+
+// listInvalidatingArticleRepository 记录列表缓存失效次数的文章仓库
+type listInvalidatingArticleRepository struct {
+	*MockArticleRepository
+	lists int
+}
+
+func (r *listInvalidatingArticleRepository) InvalidateLists(ctx context.Context) {
+	r.lists++
+}
+
+func TestUserService_UpdateProfileInvalidatesArticleCaches(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	articles := &listInvalidatingArticleRepository{MockArticleRepository: new(MockArticleRepository)}
+	responses := &countingInvalidator{}
+	userService := &UserService{userRepo: mockRepo, articleRepo: articles, publicCaches: []cacheInvalidator{responses}}
+
+	mockRepo.On("UpdateProfile", uint(1), mock.Anything).Return(nil)
+	mockRepo.On("FindByID", uint(1)).Return(&model.User{ID: 1}, nil)
+
+	// 简介不出现在文章中，不失效缓存
+	bio := "hello"
+	_, err := userService.UpdateProfile(context.Background(), 1, &model.UpdateProfileRequest{Bio: &bio})
+	require.NoError(t, err)
+	assert.Equal(t, 0, articles.lists)
+	assert.Equal(t, 0, responses.count)
+
+	// 昵称、头像随文章的作者资料返回，列表和响应缓存立即失效
+	avatar := "https://example.com/a.png"
+	_, err = userService.UpdateProfile(context.Background(), 1, &model.UpdateProfileRequest{AvatarURL: &avatar})
+	require.NoError(t, err)
+	assert.Equal(t, 1, articles.lists)
+	assert.Equal(t, 1, responses.count)
+}
+
+func TestUserService_ChangePassword(t *testing.T) {
+	// 测试用例1：旧密码正确，只保留当前会话
+	t.Run("修改成功", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockSessions := new(MockSessionRepository)
+		userService := &UserService{userRepo: mockRepo, sessionRepo: mockSessions, hasher: testHasher}
+		user := hashedUser(t, "oldpassword")
+
+		mockRepo.On("UpdatePassword", user.ID, matchesPassword("newpassword")).Return(nil)
+		mockSessions.On("RevokeAllByUser", user.ID, uint(5)).Return(2, nil)
+
+		err := userService.ChangePassword(context.Background(), user, 5, &model.ChangePasswordRequest{OldPassword: "oldpassword", NewPassword: "newpassword"})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockSessions.AssertExpectations(t)
+	})
+
+	// 测试用例2：选择撤销个人访问令牌
+	t.Run("撤销令牌", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockSessions := new(MockSessionRepository)
+		mockTokens := new(MockAPITokenRepository)
+		userService := &UserService{userRepo: mockRepo, sessionRepo: mockSessions, apiTokenRepo: mockTokens, hasher: testHasher}
+		user := hashedUser(t, "oldpassword")
+
+		mockRepo.On("UpdatePassword", user.ID, matchesPassword("newpassword")).Return(nil)
+		mockSessions.On("RevokeAllByUser", user.ID, uint(5)).Return(2, nil)
+		mockTokens.On("RevokeAllByUser", user.ID).Return(1, nil)
+
+		err := userService.ChangePassword(context.Background(), user, 5, &model.ChangePasswordRequest{
+			OldPassword: "oldpassword", NewPassword: "newpassword", RevokeAPITokens: true,
+		})
+		assert.NoError(t, err)
+		mockTokens.AssertExpectations(t)
+	})
+
+	// 测试用例3：旧密码错误
+	t.Run("旧密码错误", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		userService := &UserService{userRepo: mockRepo, hasher: testHasher}
+		user := hashedUser(t, "oldpassword")
+
+		err := userService.ChangePassword(context.Background(), user, 5, &model.ChangePasswordRequest{OldPassword: "wrong", NewPassword: "newpassword"})
+		assert.ErrorIs(t, err, ErrIncorrectPassword)
+		mockRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything)
+	})
+
+	// 测试用例4：新密码不符合策略
+	t.Run("新密码不符合策略", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		userService := &UserService{userRepo: mockRepo, hasher: testHasher, policy: &password.Policy{MinLength: 8, MinClasses: 3}}
+		user := hashedUser(t, "oldpassword")
+
+		err := userService.ChangePassword(context.Background(), user, 5, &model.ChangePasswordRequest{OldPassword: "oldpassword", NewPassword: "newpassword"})
+		assert.ErrorIs(t, err, password.ErrTooWeak)
+		mockRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserService_ChangeEmail(t *testing.T) {
+	// 测试用例1：完整的更换流程
+	t.Run("申请并确认", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockTokenRepo := new(MockTokenRepository)
+		memMailer := mailer.NewMemoryMailer()
+		userService := &UserService{userRepo: mockRepo, tokenRepo: mockTokenRepo, mailer: memMailer, hasher: testHasher}
+		user := hashedUser(t, "password123")
+
+		mockRepo.On("FindByEmail", "new@example.com").Return(nil, nil)
+		mockTokenRepo.On("DeleteByUser", user.ID, model.TokenPurposeChangeEmail).Return(nil)
+		mockTokenRepo.On("Create", mock.MatchedBy(func(token *model.UserToken) bool {
+			return token.Payload == "new@example.com"
+		})).Return(nil)
+
+		err := userService.RequestEmailChange(context.Background(), user, "new@example.com", "password123")
+		require.NoError(t, err)
+
+		// 确认邮件发送到新邮箱
+		msg, ok := memMailer.Last()
+		require.True(t, ok)
+		assert.Equal(t, "new@example.com", msg.To)
+		assert.Contains(t, msg.Body, "/auth/confirm-email?token=")
+
+		raw := tokenFromMail(t, msg.Body)
+		mockTokenRepo.On("Consume", model.TokenPurposeChangeEmail, hashToken(raw)).
+			Return(&model.UserToken{UserID: user.ID, Payload: "new@example.com"}, nil)
+		mockRepo.On("UpdateEmail", user.ID, "new@example.com").Return(nil)
+
+		err = userService.ConfirmEmailChange(context.Background(), raw)
+		assert.NoError(t, err)
+		mockRepo.AssertCalled(t, "UpdateEmail", user.ID, "new@example.com")
+	})
+
+	// 测试用例2：新邮箱已被占用
+	t.Run("邮箱已存在", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockTokenRepo := new(MockTokenRepository)
+		userService := &UserService{userRepo: mockRepo, tokenRepo: mockTokenRepo, mailer: mailer.NewMemoryMailer(), hasher: testHasher}
+		user := hashedUser(t, "password123")
+
+		mockRepo.On("FindByEmail", "taken@example.com").Return(&model.User{ID: 2}, nil)
+
+		err := userService.RequestEmailChange(context.Background(), user, "taken@example.com", "password123")
+		assert.ErrorIs(t, err, ErrEmailExists)
+		mockTokenRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	// 测试用例3：与当前邮箱相同
+	t.Run("邮箱相同", func(t *testing.T) {
+		userService := &UserService{userRepo: new(MockUserRepository), hasher: testHasher}
+		user := hashedUser(t, "password123")
+
+		err := userService.RequestEmailChange(context.Background(), user, "OLD@example.com", "password123")
+		assert.ErrorIs(t, err, ErrSameEmail)
+	})
+
+	// 测试用例4：令牌无效
+	t.Run("令牌无效", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockTokenRepo := new(MockTokenRepository)
+		userService := &UserService{userRepo: mockRepo, tokenRepo: mockTokenRepo}
+
+		mockTokenRepo.On("Consume", model.TokenPurposeChangeEmail, hashToken("bad")).Return(nil, nil)
+
+		err := userService.ConfirmEmailChange(context.Background(), "bad")
+		assert.ErrorIs(t, err, ErrInvalidToken)
+		mockRepo.AssertNotCalled(t, "UpdateEmail", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserService_GetPublicProfile(t *testing.T) {
+	// 测试用例1：返回已发布文章且隐藏邮箱
+	t.Run("获取成功", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockArticleRepo := new(MockArticleRepository)
+		userService := &UserService{userRepo: mockRepo, articleRepo: mockArticleRepo}
+
+		user := &model.User{ID: 3, Username: "author", Email: "author@example.com", Bio: "hello"}
+		mockRepo.On("FindByUsername", "author").Return(user, nil)
+		mockArticleRepo.On("List", 1, 10, model.ArticleStatusPublished, uint(3), "", (*model.ArticleViewer)(nil)).
+			Return([]model.Article{{ID: 1, Title: "t", Author: user.Public()}}, int64(1), nil)
+
+		profile, err := userService.GetPublicProfile(context.Background(), "author", 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", profile.Bio)
+		assert.Equal(t, int64(1), profile.Total)
+		require.Len(t, profile.Articles, 1)
+		data, err := json.Marshal(profile)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "author@example.com")
+		assert.NotContains(t, string(data), "two_factor_enabled")
+	})
+
+	// 测试用例2：用户不存在
+	t.Run("用户不存在", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		userService := &UserService{userRepo: mockRepo}
+
+		mockRepo.On("FindByUsername", "nobody").Return(nil, nil)
+
+		profile, err := userService.GetPublicProfile(context.Background(), "nobody", 1, 10)
+		assert.Nil(t, profile)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"blog/config"
+	"blog/internal/i18n"
+	"blog/internal/mailer"
+	"blog/internal/model"
+	"blog/internal/tracing"
+	"context"
+	"log"
+	"strings"
+	"time"
+)
+
+// 令牌默认有效期
+const (
+	defaultVerifyTokenTTL = 24 * time.Hour
+	defaultResetTokenTTL  = 30 * time.Minute
+)
+
+// VerifyEmail 使用邮件中的令牌验证邮箱
+func (s *AuthService) VerifyEmail(ctx context.Context, rawToken string) (err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.VerifyEmail")
+	defer func() { tracing.End(span, err) }()
+
+	token, err := s.tokenRepo.Consume(ctx, model.TokenPurposeVerifyEmail, hashToken(rawToken))
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return ErrInvalidToken
+	}
+
+	return s.userRepo.MarkEmailVerified(ctx, token.UserID)
+}
+
+// ResendVerification 重新发送验证邮件，之前的验证链接随之失效
+func (s *AuthService) ResendVerification(ctx context.Context, user *model.User) (err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.ResendVerification")
+	defer func() { tracing.End(span, err) }()
+
+	if user.EmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+	return s.sendVerificationEmail(ctx, user)
+}
+
+// ForgotPassword 发送密码重置邮件
+// 无论邮箱是否注册都返回成功，避免被用来探测账号
+func (s *AuthService) ForgotPassword(ctx context.Context, email string) (err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.ForgotPassword")
+	defer func() { tracing.End(span, err) }()
+
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+
+	ttl := tokenTTL(config.AppConfig.Auth.ResetTokenTTL, defaultResetTokenTTL)
+	rawToken, err := issueToken(ctx, s.tokenRepo, user.ID, model.TokenPurposeResetPassword, ttl, "")
+	if err != nil {
+		return err
+	}
+
+	locale := i18n.LocaleFrom(ctx)
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: i18n.Message(locale, "mail_reset_subject"),
+		Body:    i18n.Message(locale, "mail_reset_body", user.Username, linkURL("/auth/reset-password", rawToken), formatTTL(ttl)),
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		log.Printf("[WARN] failed to send password reset email to user %d: %v", user.ID, err)
+	}
+	return nil
+}
+
+// ResetPassword 使用邮件中的令牌重置密码
+func (s *AuthService) ResetPassword(ctx context.Context, rawToken, password string) (err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.ResetPassword")
+	defer func() { tracing.End(span, err) }()
+
+	// 先校验密码强度，避免不合格的密码消耗掉一次性令牌
+	if err := s.policy.Validate(password); err != nil {
+		return err
+	}
+
+	// 无效令牌的尝试同样记录，便于发现猜测重置链接的行为
+	var user *model.User
+	defer func() { s.auditAuth(ctx, model.AuditActionPasswordReset, "", user, "", err) }()
+
+	token, err := s.tokenRepo.Consume(ctx, model.TokenPurposeResetPassword, hashToken(rawToken))
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return ErrInvalidToken
+	}
+
+	user, err = s.userRepo.FindByID(ctx, token.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrInvalidToken
+	}
+
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, hash); err != nil {
+		return err
+	}
+	// 能收到重置邮件说明邮箱属于该用户
+	if !user.EmailVerified {
+		if err := s.userRepo.MarkEmailVerified(ctx, user.ID); err != nil {
+			return err
+		}
+	}
+	// 账号可能已被他人登录，撤销全部会话和个人访问令牌
+	if err := s.revokeCredentials(ctx, user.ID); err != nil {
+		return err
+	}
+	// 使其他未使用的重置链接失效，并解除登录锁定
+	if err := s.tokenRepo.DeleteByUser(ctx, user.ID, model.TokenPurposeResetPassword); err != nil {
+		return err
+	}
+	s.loginSucceeded(ctx, strings.ToLower(user.Email))
+	s.loginSucceeded(ctx, strings.ToLower(user.Username))
+
+	return nil
+}
+
+// revokeCredentials 撤销用户全部会话和个人访问令牌，用于账号可能已落入他人之手的场景
+func (s *AuthService) revokeCredentials(ctx context.Context, userID uint) error {
+	if _, err := s.sessionRepo.RevokeAllByUser(ctx, userID, 0); err != nil {
+		return err
+	}
+	_, err := s.apiTokenRepo.RevokeAllByUser(ctx, userID)
+	return err
+}
+
+// sendVerificationEmail 生成邮箱验证令牌并发送验证邮件
+func (s *AuthService) sendVerificationEmail(ctx context.Context, user *model.User) error {
+	ttl := tokenTTL(config.AppConfig.Auth.VerifyTokenTTL, defaultVerifyTokenTTL)
+	rawToken, err := issueToken(ctx, s.tokenRepo, user.ID, model.TokenPurposeVerifyEmail, ttl, "")
+	if err != nil {
+		return err
+	}
+
+	locale := i18n.LocaleFrom(ctx)
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: i18n.Message(locale, "mail_verify_subject"),
+		Body:    i18n.Message(locale, "mail_verify_body", user.Username, linkURL("/auth/verify", rawToken), formatTTL(ttl)),
+	})
+}
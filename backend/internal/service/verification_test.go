@@ -0,0 +1,190 @@
+package service
+
+import (
+	"blog/config"
+	"blog/internal/mailer"
+	"blog/internal/model"
+	"blog/internal/password"
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockTokenRepository 模拟令牌仓库
+type MockTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockTokenRepository) Create(ctx context.Context, token *model.UserToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockTokenRepository) Consume(ctx context.Context, purpose, tokenHash string) (*model.UserToken, error) {
+	args := m.Called(purpose, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.UserToken), args.Error(1)
+}
+
+func (m *MockTokenRepository) DeleteByUser(ctx context.Context, userID uint, purpose string) error {
+	args := m.Called(userID, purpose)
+	return args.Error(0)
+}
+
+// tokenFromMail 从邮件正文中提取令牌
+func tokenFromMail(t *testing.T, body string) string {
+	start := strings.Index(body, "?token=")
+	require.NotEqual(t, -1, start)
+	rest := body[start+len("?token="):]
+	if end := strings.IndexAny(rest, " \n"); end != -1 {
+		rest = rest[:end]
+	}
+	token, err := url.QueryUnescape(rest)
+	require.NoError(t, err)
+	return token
+}
+
+func TestAuthService_VerifyEmail(t *testing.T) {
+	// 测试用例1：令牌有效
+	t.Run("令牌有效", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockTokenRepo := new(MockTokenRepository)
+		authService := &AuthService{userRepo: mockRepo, tokenRepo: mockTokenRepo}
+
+		mockTokenRepo.On("Consume", model.TokenPurposeVerifyEmail, hashToken("raw-token")).
+			Return(&model.UserToken{UserID: 7}, nil)
+		mockRepo.On("MarkEmailVerified", uint(7)).Return(nil)
+
+		err := authService.VerifyEmail(context.Background(), "raw-token")
+		assert.NoError(t, err)
+		mockRepo.AssertCalled(t, "MarkEmailVerified", uint(7))
+	})
+
+	// 测试用例2：令牌无效或已使用
+	t.Run("令牌无效", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockTokenRepo := new(MockTokenRepository)
+		authService := &AuthService{userRepo: mockRepo, tokenRepo: mockTokenRepo}
+
+		mockTokenRepo.On("Consume", model.TokenPurposeVerifyEmail, hashToken("used-token")).Return(nil, nil)
+
+		err := authService.VerifyEmail(context.Background(), "used-token")
+		assert.ErrorIs(t, err, ErrInvalidToken)
+		mockRepo.AssertNotCalled(t, "MarkEmailVerified", mock.Anything)
+	})
+}
+
+func TestAuthService_ResendVerification(t *testing.T) {
+	authService := &AuthService{}
+
+	err := authService.ResendVerification(context.Background(), &model.User{ID: 1, EmailVerified: true})
+	assert.ErrorIs(t, err, ErrEmailAlreadyVerified)
+}
+
+func TestAuthService_PasswordReset(t *testing.T) {
+	// 测试用例1：未注册邮箱不发送邮件也不报错
+	t.Run("未注册邮箱", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		memMailer := mailer.NewMemoryMailer()
+		authService := &AuthService{userRepo: mockRepo, tokenRepo: new(MockTokenRepository), mailer: memMailer}
+
+		mockRepo.On("FindByEmail", "nobody@example.com").Return(nil, nil)
+
+		err := authService.ForgotPassword(context.Background(), "nobody@example.com")
+		assert.NoError(t, err)
+		assert.Empty(t, memMailer.Messages())
+	})
+
+	// 测试用例2：完整的重置流程
+	t.Run("重置密码", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockTokenRepo := new(MockTokenRepository)
+		memMailer := mailer.NewMemoryMailer()
+		mockSessions := new(MockSessionRepository)
+		mockAPITokens := new(MockAPITokenRepository)
+		authService := &AuthService{
+			userRepo: mockRepo, tokenRepo: mockTokenRepo, sessionRepo: mockSessions, apiTokenRepo: mockAPITokens,
+			mailer: memMailer, hasher: testHasher,
+		}
+
+		user := &model.User{ID: 3, Username: "testuser", Email: "test@example.com"}
+		mockRepo.On("FindByEmail", user.Email).Return(user, nil)
+		mockRepo.On("FindByID", user.ID).Return(user, nil)
+		mockTokenRepo.On("DeleteByUser", user.ID, model.TokenPurposeResetPassword).Return(nil)
+
+		var stored *model.UserToken
+		mockTokenRepo.On("Create", mock.AnythingOfType("*model.UserToken")).
+			Run(func(args mock.Arguments) { stored = args.Get(0).(*model.UserToken) }).
+			Return(nil)
+
+		require.NoError(t, authService.ForgotPassword(context.Background(), user.Email))
+
+		msg, ok := memMailer.Last()
+		require.True(t, ok)
+		assert.Equal(t, user.Email, msg.To)
+		rawToken := tokenFromMail(t, msg.Body)
+
+		// 入库的是令牌哈希而不是原文
+		require.NotNil(t, stored)
+		assert.Equal(t, hashToken(rawToken), stored.TokenHash)
+		assert.NotContains(t, stored.TokenHash, rawToken)
+
+		mockTokenRepo.On("Consume", model.TokenPurposeResetPassword, hashToken(rawToken)).
+			Return(&model.UserToken{UserID: user.ID}, nil)
+		mockRepo.On("UpdatePassword", user.ID, matchesPassword("newpassword")).Return(nil)
+		mockRepo.On("MarkEmailVerified", user.ID).Return(nil)
+		mockSessions.On("RevokeAllByUser", user.ID, uint(0)).Return(1, nil)
+		mockAPITokens.On("RevokeAllByUser", user.ID).Return(1, nil)
+
+		err := authService.ResetPassword(context.Background(), rawToken, "newpassword")
+		assert.NoError(t, err)
+		mockRepo.AssertCalled(t, "UpdatePassword", user.ID, matchesPassword("newpassword"))
+		// 重置后撤销全部已登录的会话和个人访问令牌
+		mockSessions.AssertExpectations(t)
+		mockAPITokens.AssertExpectations(t)
+	})
+
+	// 测试用例3：弱密码不消耗令牌
+	t.Run("弱密码", func(t *testing.T) {
+		mockTokenRepo := new(MockTokenRepository)
+		authService := &AuthService{tokenRepo: mockTokenRepo, hasher: testHasher, policy: &password.Policy{MinLength: 8}}
+
+		err := authService.ResetPassword(context.Background(), "raw-token", "short")
+		assert.ErrorIs(t, err, password.ErrTooShort)
+		mockTokenRepo.AssertNotCalled(t, "Consume", mock.Anything, mock.Anything)
+	})
+}
+
+func TestArticleService_UnverifiedPublish(t *testing.T) {
+	mockRepo := new(MockArticleRepository)
+	articleService := &ArticleService{articleRepo: mockRepo}
+	unverified := &model.User{ID: 1}
+
+	withEmailVerification(t, true)
+
+	// 未验证用户不能发布
+	article := &model.Article{Title: "t", Content: "c", Status: model.ArticleStatusPublished, AuthorID: 1}
+	err := articleService.CreateArticle(context.Background(), unverified, article, nil)
+	assert.ErrorIs(t, err, ErrEmailNotVerified)
+
+	// 但可以保存草稿
+	draft := &model.Article{Title: "t", Content: "c", Status: model.ArticleStatusDraft, AuthorID: 1}
+	mockRepo.On("Create", draft, (*model.SeriesPlacement)(nil)).Return(nil)
+	assert.NoError(t, articleService.CreateArticle(context.Background(), unverified, draft, nil))
+}
+
+// withEmailVerification 在测试期间临时修改邮箱验证要求
+func withEmailVerification(t *testing.T, required bool) {
+	original := config.AppConfig.Auth.RequireEmailVerification
+	config.AppConfig.Auth.RequireEmailVerification = required
+	t.Cleanup(func() {
+		config.AppConfig.Auth.RequireEmailVerification = original
+	})
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"blog/config"
+	"blog/internal/model"
+	"blog/internal/repository"
+	"blog/internal/tracing"
+	"blog/internal/webhook"
+	"context"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// defaultMaxWebhooksPerUser 每个用户最多可配置的 webhook 数量
+	defaultMaxWebhooksPerUser = 10
+	// webhookSecretPrefix 签名密钥前缀，便于密钥扫描工具识别
+	webhookSecretPrefix = "whsec_"
+)
+
+// webhookWaker 新建投递记录后通知投递器立即处理
+type webhookWaker interface {
+	Wake()
+}
+
+type WebhookService struct {
+	webhookRepo repository.IWebhookRepository
+	dispatcher  webhookWaker
+}
+
+func NewWebhookService() *WebhookService {
+	return &WebhookService{
+		webhookRepo: repository.NewWebhookRepository(),
+		dispatcher:  webhook.GetDispatcher(),
+	}
+}
+
+// Create 创建 webhook，签名密钥只在返回值中出现一次
+func (s *WebhookService) Create(ctx context.Context, user *model.User, req *model.CreateWebhookRequest) (_ *model.WebhookCreated, err error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.Create")
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateWebhook(user, req.URL, req.Events); err != nil {
+		return nil, err
+	}
+	count, err := s.webhookRepo.CountByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if limit := maxWebhooksPerUser(); count >= int64(limit) {
+		return nil, ErrTooManyWebhooks.WithArgs(limit)
+	}
+
+	secret, err := newRawToken()
+	if err != nil {
+		return nil, err
+	}
+	hook := &model.Webhook{
+		UserID: user.ID,
+		URL:    req.URL,
+		Secret: webhookSecretPrefix + secret,
+		Events: uniqueScopes(req.Events),
+		Active: req.Active == nil || *req.Active,
+	}
+	if err := s.webhookRepo.Create(ctx, hook); err != nil {
+		return nil, err
+	}
+	return &model.WebhookCreated{Secret: hook.Secret, Webhook: hook}, nil
+}
+
+// List 列出用户的 webhook
+func (s *WebhookService) List(ctx context.Context, user *model.User) (_ []model.Webhook, err error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.List")
+	defer func() { tracing.End(span, err) }()
+
+	return s.webhookRepo.ListByUser(ctx, user.ID)
+}
+
+// Update 修改地址、订阅的事件或启用状态
+func (s *WebhookService) Update(ctx context.Context, user *model.User, id uint, req *model.UpdateWebhookRequest) (_ *model.Webhook, err error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.Update",
+		attribute.Int("webhook.id", int(id)))
+	defer func() { tracing.End(span, err) }()
+
+	hook, err := s.findOwned(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.URL != nil {
+		hook.URL = *req.URL
+	}
+	if req.Events != nil {
+		hook.Events = uniqueScopes(req.Events)
+	}
+	if req.Active != nil {
+		hook.Active = *req.Active
+	}
+	if err := validateWebhook(user, hook.URL, hook.Events); err != nil {
+		return nil, err
+	}
+	if err := s.webhookRepo.Update(ctx, hook); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+// Delete 删除 webhook 及其投递记录
+func (s *WebhookService) Delete(ctx context.Context, user *model.User, id uint) (err error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.Delete",
+		attribute.Int("webhook.id", int(id)))
+	defer func() { tracing.End(span, err) }()
+
+	deleted, err := s.webhookRepo.Delete(ctx, id, user.ID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// ListDeliveries 分页查看 webhook 的投递记录
+func (s *WebhookService) ListDeliveries(ctx context.Context, user *model.User, id uint, query *model.WebhookDeliveryQuery) (_ []model.WebhookDelivery, _ int64, err error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.ListDeliveries",
+		attribute.Int("webhook.id", int(id)))
+	defer func() { tracing.End(span, err) }()
+
+	if _, err := s.findOwned(ctx, user, id); err != nil {
+		return nil, 0, err
+	}
+	return s.webhookRepo.ListDeliveries(ctx, id, query.Page, query.PageSize)
+}
+
+// Redeliver 以原请求体重新投递一次，新建投递记录并从第一次尝试开始计数
+func (s *WebhookService) Redeliver(ctx context.Context, user *model.User, id, deliveryID uint) (_ *model.WebhookDelivery, err error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.Redeliver",
+		attribute.Int("webhook.id", int(id)),
+		attribute.Int("webhook.delivery_id", int(deliveryID)))
+	defer func() { tracing.End(span, err) }()
+
+	if _, err := s.findOwned(ctx, user, id); err != nil {
+		return nil, err
+	}
+	original, err := s.webhookRepo.FindDelivery(ctx, id, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if original == nil {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+
+	now := time.Now()
+	deliveries := []model.WebhookDelivery{{
+		WebhookID:     id,
+		EventID:       original.EventID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        model.WebhookDeliveryPending,
+		NextAttemptAt: &now,
+		RedeliveryOf:  &original.ID,
+	}}
+	if err := s.webhookRepo.CreateDeliveries(ctx, deliveries); err != nil {
+		return nil, err
+	}
+	if s.dispatcher != nil {
+		s.dispatcher.Wake()
+	}
+	return &deliveries[0], nil
+}
+
+// findOwned 查找用户自己的 webhook，他人的 webhook 视为不存在
+func (s *WebhookService) findOwned(ctx context.Context, user *model.User, id uint) (*model.Webhook, error) {
+	hook, err := s.webhookRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if hook == nil || hook.UserID != user.ID {
+		return nil, ErrWebhookNotFound
+	}
+	return hook, nil
+}
+
+// validateWebhook 只允许 http(s) 地址；新用户注册事件只有管理员可以订阅
+func validateWebhook(user *model.User, rawURL string, eventTypes []string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+	for _, eventType := range eventTypes {
+		if !webhook.AllowedEvent(user, eventType) {
+			return ErrWebhookEventForbidden.WithArgs(eventType)
+		}
+	}
+	return nil
+}
+
+func maxWebhooksPerUser() int {
+	if n := config.AppConfig.Webhooks.MaxPerUser; n > 0 {
+		return n
+	}
+	return defaultMaxWebhooksPerUser
+}
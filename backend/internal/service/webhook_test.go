@@ -0,0 +1,202 @@
+package service
+
+import (
+	"blog/internal/apperr"
+	"blog/internal/events"
+	"blog/internal/model"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockWebhookRepository 模拟 webhook 仓库
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) Create(ctx context.Context, hook *model.Webhook) error {
+	return m.Called(hook).Error(0)
+}
+
+func (m *MockWebhookRepository) FindByID(ctx context.Context, id uint) (*model.Webhook, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ListByUser(ctx context.Context, userID uint) ([]model.Webhook, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]model.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) CountByUser(ctx context.Context, userID uint) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ListActive(ctx context.Context) ([]model.Webhook, error) {
+	args := m.Called()
+	return args.Get(0).([]model.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) Update(ctx context.Context, hook *model.Webhook) error {
+	return m.Called(hook).Error(0)
+}
+
+func (m *MockWebhookRepository) Delete(ctx context.Context, id, userID uint) (bool, error) {
+	args := m.Called(id, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWebhookRepository) CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error {
+	return m.Called(deliveries).Error(0)
+}
+
+func (m *MockWebhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.WebhookDelivery, error) {
+	args := m.Called(now, lease, limit)
+	return args.Get(0).([]model.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return m.Called(delivery).Error(0)
+}
+
+func (m *MockWebhookRepository) ListDeliveries(ctx context.Context, webhookID uint, page, pageSize int) ([]model.WebhookDelivery, int64, error) {
+	args := m.Called(webhookID, page, pageSize)
+	return args.Get(0).([]model.WebhookDelivery), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockWebhookRepository) FindDelivery(ctx context.Context, webhookID, id uint) (*model.WebhookDelivery, error) {
+	args := m.Called(webhookID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookDelivery), args.Error(1)
+}
+
+// wakeCounter 记录通知投递器的次数
+type wakeCounter struct {
+	count int
+}
+
+func (w *wakeCounter) Wake() {
+	w.count++
+}
+
+func TestWebhookService_Create(t *testing.T) {
+	user := &model.User{ID: 1, Role: model.RoleUser}
+
+	// 测试用例1：成功创建，返回一次性密钥
+	t.Run("成功创建", func(t *testing.T) {
+		repo := new(MockWebhookRepository)
+		s := &WebhookService{webhookRepo: repo}
+		repo.On("CountByUser", uint(1)).Return(int64(0), nil)
+		repo.On("Create", mock.Anything).Return(nil)
+
+		created, err := s.Create(context.Background(), user, &model.CreateWebhookRequest{
+			URL:    "https://example.com/hook",
+			Events: []string{events.ArticlePublished, events.ArticlePublished},
+		})
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(created.Secret, webhookSecretPrefix))
+		assert.Equal(t, created.Secret, created.Webhook.Secret)
+		assert.Equal(t, []string{events.ArticlePublished}, created.Events)
+		assert.True(t, created.Active)
+	})
+
+	// 测试用例2：只允许 http(s) 地址
+	t.Run("非法地址", func(t *testing.T) {
+		s := &WebhookService{webhookRepo: new(MockWebhookRepository)}
+		_, err := s.Create(context.Background(), user, &model.CreateWebhookRequest{
+			URL:    "ftp://example.com/hook",
+			Events: []string{events.ArticleCreated},
+		})
+		assert.ErrorIs(t, err, ErrInvalidWebhookURL)
+	})
+
+	// 测试用例3：普通用户不能订阅新用户注册事件
+	t.Run("无权订阅注册事件", func(t *testing.T) {
+		s := &WebhookService{webhookRepo: new(MockWebhookRepository)}
+		_, err := s.Create(context.Background(), user, &model.CreateWebhookRequest{
+			URL:    "https://example.com/hook",
+			Events: []string{events.UserRegistered},
+		})
+		assert.ErrorIs(t, err, apperr.ErrForbidden)
+	})
+}
+
+func TestWebhookService_Redeliver(t *testing.T) {
+	user := &model.User{ID: 1}
+
+	// 测试用例1：他人的 webhook 视为不存在
+	t.Run("他人的webhook", func(t *testing.T) {
+		repo := new(MockWebhookRepository)
+		s := &WebhookService{webhookRepo: repo}
+		repo.On("FindByID", uint(1)).Return(&model.Webhook{ID: 1, UserID: 2}, nil)
+
+		_, err := s.Redeliver(context.Background(), user, 1, 5)
+		assert.ErrorIs(t, err, ErrWebhookNotFound)
+	})
+
+	// 测试用例2：复制原请求体新建投递记录
+	t.Run("重新投递", func(t *testing.T) {
+		repo := new(MockWebhookRepository)
+		waker := &wakeCounter{}
+		s := &WebhookService{webhookRepo: repo, dispatcher: waker}
+		repo.On("FindByID", uint(1)).Return(&model.Webhook{ID: 1, UserID: 1}, nil)
+		repo.On("FindDelivery", uint(1), uint(5)).Return(&model.WebhookDelivery{
+			ID: 5, WebhookID: 1, EventID: "e1", EventType: events.ArticleDeleted, Payload: `{"id":"e1"}`,
+			Status: model.WebhookDeliveryFailed, Attempts: 8,
+		}, nil)
+		repo.On("CreateDeliveries", mock.Anything).Return(nil)
+
+		delivery, err := s.Redeliver(context.Background(), user, 1, 5)
+		require.NoError(t, err)
+		assert.Equal(t, model.WebhookDeliveryPending, delivery.Status)
+		assert.Equal(t, 0, delivery.Attempts)
+		assert.Equal(t, `{"id":"e1"}`, delivery.Payload)
+		assert.Equal(t, uint(5), *delivery.RedeliveryOf)
+		assert.Equal(t, 1, waker.count)
+	})
+}
+
+func TestArticleService_Events(t *testing.T) {
+	mockRepo := new(MockArticleRepository)
+	s := &ArticleService{articleRepo: mockRepo}
+	user := &model.User{ID: 1, EmailVerified: true}
+
+	article := &model.Article{ID: 3, Title: "title", Content: "content", Status: model.ArticleStatusDraft, AuthorID: 1}
+	mockRepo.On("Create", article, (*model.SeriesPlacement)(nil)).Return(nil)
+	require.NoError(t, s.CreateArticle(context.Background(), user, article, nil))
+
+	// 草稿变为已发布时追加 article.published
+	mockRepo.On("FindByID", uint(3)).Return(&model.Article{ID: 3, AuthorID: 1, Status: model.ArticleStatusDraft}, nil)
+	updated := &model.Article{ID: 3, Title: "title", Content: "content", Status: model.ArticleStatusPublished}
+	mockRepo.On("Update", updated, mock.Anything).Return(nil)
+	require.NoError(t, s.UpdateArticle(context.Background(), user, updated))
+
+	var types []string
+	for _, event := range mockRepo.events {
+		types = append(types, event.Type)
+		assert.Equal(t, uint(1), event.OwnerID)
+	}
+	assert.Equal(t, []string{events.ArticleCreated, events.ArticleUpdated, events.ArticlePublished}, types)
+	data := mockRepo.events[2].Data.(events.ArticleData)
+	assert.Equal(t, model.ArticleStatusDraft, data.PreviousStatus)
+	assert.True(t, strings.HasSuffix(data.URL, "/articles/3"))
+
+	// 删除时仓库只传入 ID，事件内容来自删除前读到的文章
+	mockRepo.events = nil
+	mockRepo.On("Delete", uint(3), uint(1)).Return(nil)
+	require.NoError(t, s.DeleteArticle(context.Background(), user, 3))
+	require.Len(t, mockRepo.events, 1)
+	assert.Equal(t, events.ArticleDeleted, mockRepo.events[0].Type)
+	assert.Equal(t, model.ArticleStatusDraft, mockRepo.events[0].Data.(events.ArticleData).Status)
+}
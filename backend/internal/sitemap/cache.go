@@ -0,0 +1,153 @@
+package sitemap
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultTTL = time.Hour
+
+// Source 提供站点地图中的全部页面
+type Source interface {
+	URLs(ctx context.Context) ([]URL, error)
+}
+
+// Invalidator 文章变化时通知站点地图重新生成，服务层只依赖该接口
+type Invalidator interface {
+	Invalidate()
+}
+
+// Options 缓存配置
+type Options struct {
+	BaseURL string        // 分页文件的访问地址前缀，分页地址为 {BaseURL}/sitemaps/sitemap-{n}.xml
+	PerFile int           // 单个文件最多包含的 URL 数，为 0 时使用协议上限
+	TTL     time.Duration // 即使没有文章变化，超过该时间也重新生成，用于反映作者、标签等变化
+}
+
+// Cache 缓存生成好的站点地图，失效后在下一次请求时重新生成
+type Cache struct {
+	source Source
+	opts   Options
+	now    func() time.Time
+
+	stale atomic.Bool
+	mu    sync.Mutex
+	built *build
+}
+
+// build 一次生成的结果，URL 数不超过单个文件上限时 pages 为空
+type build struct {
+	root    []byte
+	pages   [][]byte
+	lastMod time.Time
+	at      time.Time
+}
+
+// NewCache 创建站点地图缓存
+func NewCache(source Source, opts Options) *Cache {
+	if opts.PerFile <= 0 || opts.PerFile > MaxURLs {
+		opts.PerFile = MaxURLs
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = defaultTTL
+	}
+	return &Cache{source: source, opts: opts, now: time.Now}
+}
+
+// Invalidate 标记缓存失效，不阻塞调用方
+func (c *Cache) Invalidate() {
+	c.stale.Store(true)
+}
+
+// Root 返回 /sitemap.xml 的内容：URL 较少时为 urlset，否则为索引
+func (c *Cache) Root(ctx context.Context) ([]byte, time.Time, error) {
+	b, err := c.get(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return b.root, b.lastMod, nil
+}
+
+// Page 返回第 n 个分页文件（从 1 开始），不存在时 ok 为 false
+func (c *Cache) Page(ctx context.Context, n int) (_ []byte, _ time.Time, ok bool, err error) {
+	b, err := c.get(ctx)
+	if err != nil {
+		return nil, time.Time{}, false, err
+	}
+	if n < 1 || n > len(b.pages) {
+		return nil, time.Time{}, false, nil
+	}
+	return b.pages[n-1], b.lastMod, true, nil
+}
+
+// get 返回缓存，失效或过期时重新生成；并发请求只生成一次
+func (c *Cache) get(ctx context.Context) (*build, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// 先清除失效标记，生成期间再次失效的会在下一次请求时重新生成
+	stale := c.stale.Swap(false)
+	if c.built != nil && !stale && c.now().Sub(c.built.at) < c.opts.TTL {
+		return c.built, nil
+	}
+
+	b, err := c.generate(ctx)
+	if err != nil {
+		if stale {
+			c.stale.Store(true)
+		}
+		return nil, err
+	}
+	c.built = b
+	return b, nil
+}
+
+func (c *Cache) generate(ctx context.Context) (*build, error) {
+	urls, err := c.source.URLs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &build{at: c.now()}
+	for _, u := range urls {
+		if u.LastMod.After(b.lastMod) {
+			b.lastMod = u.LastMod
+		}
+	}
+
+	if len(urls) <= c.opts.PerFile {
+		b.root, err = EncodeURLSet(urls)
+		return b, err
+	}
+
+	var index []URL
+	for start := 0; start < len(urls); start += c.opts.PerFile {
+		end := start + c.opts.PerFile
+		if end > len(urls) {
+			end = len(urls)
+		}
+		page, err := EncodeURLSet(urls[start:end])
+		if err != nil {
+			return nil, err
+		}
+		b.pages = append(b.pages, page)
+
+		var lastMod time.Time
+		for _, u := range urls[start:end] {
+			if u.LastMod.After(lastMod) {
+				lastMod = u.LastMod
+			}
+		}
+		index = append(index, URL{Loc: c.opts.BaseURL + PagePath(len(b.pages)), LastMod: lastMod})
+	}
+	b.root, err = EncodeIndex(index)
+	return b, err
+}
+
+// PagePath 第 n 个分页文件的路径
+func PagePath(n int) string {
+	return "/sitemaps/sitemap-" + strconv.Itoa(n) + ".xml"
+}
@@ -0,0 +1,114 @@
+package sitemap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSource 返回固定 URL 并记录生成次数
+type fakeSource struct {
+	urls  []URL
+	err   error
+	calls int
+}
+
+func (s *fakeSource) URLs(ctx context.Context) ([]URL, error) {
+	s.calls++
+	return s.urls, s.err
+}
+
+func testURLs(n int) []URL {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	urls := make([]URL, n)
+	for i := range urls {
+		urls[i] = URL{Loc: fmt.Sprintf("https://example.com/articles/%d", i+1), LastMod: base.Add(time.Duration(i) * time.Hour)}
+	}
+	return urls
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("URL 较少时直接输出 urlset", func(t *testing.T) {
+		source := &fakeSource{urls: testURLs(3)}
+		cache := NewCache(source, Options{BaseURL: "https://api.example.com", PerFile: 10})
+
+		body, lastMod, err := cache.Root(ctx)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "<urlset")
+		assert.Contains(t, string(body), "https://example.com/articles/3")
+		assert.Equal(t, source.urls[2].LastMod, lastMod)
+
+		_, _, ok, err := cache.Page(ctx, 1)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("超过单文件上限时拆分为索引", func(t *testing.T) {
+		source := &fakeSource{urls: testURLs(5)}
+		cache := NewCache(source, Options{BaseURL: "https://api.example.com", PerFile: 2})
+
+		body, _, err := cache.Root(ctx)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "<sitemapindex")
+		assert.Contains(t, string(body), "https://api.example.com/sitemaps/sitemap-3.xml")
+		assert.NotContains(t, string(body), "sitemap-4.xml")
+
+		page, _, ok, err := cache.Page(ctx, 3)
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Contains(t, string(page), "https://example.com/articles/5")
+		assert.NotContains(t, string(page), "https://example.com/articles/4<")
+
+		_, _, ok, err = cache.Page(ctx, 4)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, 1, source.calls)
+	})
+
+	t.Run("失效或过期后重新生成", func(t *testing.T) {
+		source := &fakeSource{urls: testURLs(1)}
+		cache := NewCache(source, Options{TTL: time.Hour})
+		now := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+		cache.now = func() time.Time { return now }
+
+		_, _, err := cache.Root(ctx)
+		require.NoError(t, err)
+		_, _, err = cache.Root(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, source.calls)
+
+		cache.Invalidate()
+		_, _, err = cache.Root(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, source.calls)
+
+		now = now.Add(2 * time.Hour)
+		_, _, err = cache.Root(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 3, source.calls)
+	})
+
+	t.Run("生成失败时保留失效标记", func(t *testing.T) {
+		source := &fakeSource{urls: testURLs(1)}
+		cache := NewCache(source, Options{})
+		_, _, err := cache.Root(ctx)
+		require.NoError(t, err)
+
+		cache.Invalidate()
+		source.err = errors.New("db down")
+		_, _, err = cache.Root(ctx)
+		assert.Error(t, err)
+
+		source.err = nil
+		_, _, err = cache.Root(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 3, source.calls)
+	})
+}
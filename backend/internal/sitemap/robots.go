@@ -0,0 +1,25 @@
+package sitemap
+
+import (
+	"blog/config"
+	"strings"
+)
+
+// Robots 生成 robots.txt，配置了完整内容时直接使用，否则按禁止路径生成并附上站点地图地址
+func Robots() string {
+	cfg := config.AppConfig.Sitemap.Robots
+	if cfg.Content != "" {
+		return cfg.Content
+	}
+
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+	for _, path := range cfg.Disallow {
+		b.WriteString("Disallow: " + path + "\n")
+	}
+	if len(cfg.Disallow) == 0 {
+		b.WriteString("Disallow:\n")
+	}
+	b.WriteString("\nSitemap: " + strings.TrimRight(config.AppConfig.Site.APIURL, "/") + "/sitemap.xml\n")
+	return b.String()
+}
@@ -0,0 +1,69 @@
+// Package sitemap 生成 sitemaps.org 格式的站点地图，URL 超过单个文件上限时拆分为索引和多个分页
+package sitemap
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// MaxURLs 协议规定单个站点地图文件最多包含的 URL 数
+const MaxURLs = 50000
+
+const xmlns = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// URL 站点地图中的一个页面
+type URL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+type urlSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []urlElement `xml:"url"`
+}
+
+type urlElement struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []urlElement `xml:"sitemap"`
+}
+
+// EncodeURLSet 生成包含 urls 的 urlset 文档
+func EncodeURLSet(urls []URL) ([]byte, error) {
+	doc := urlSet{Xmlns: xmlns, URLs: make([]urlElement, len(urls))}
+	for i, u := range urls {
+		doc.URLs[i] = element(u)
+	}
+	return marshal(doc)
+}
+
+// EncodeIndex 生成引用多个站点地图文件的索引文档
+func EncodeIndex(sitemaps []URL) ([]byte, error) {
+	doc := sitemapIndex{Xmlns: xmlns, Sitemaps: make([]urlElement, len(sitemaps))}
+	for i, u := range sitemaps {
+		doc.Sitemaps[i] = element(u)
+	}
+	return marshal(doc)
+}
+
+func element(u URL) urlElement {
+	e := urlElement{Loc: u.Loc}
+	if !u.LastMod.IsZero() {
+		e.LastMod = u.LastMod.UTC().Format(time.RFC3339)
+	}
+	return e
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,77 @@
+package sitemap
+
+import (
+	"blog/config"
+	"blog/internal/repository"
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RepositorySource 从数据库读取首页、已发布文章、标签页和作者主页
+type RepositorySource struct {
+	repo    repository.ISitemapRepository
+	siteURL string
+}
+
+// NewRepositorySource 创建数据库来源，siteURL 为前端站点地址
+func NewRepositorySource(repo repository.ISitemapRepository, siteURL string) *RepositorySource {
+	return &RepositorySource{repo: repo, siteURL: strings.TrimRight(siteURL, "/")}
+}
+
+// URLs 返回全部页面，首页的最后修改时间取最近更新的文章
+func (s *RepositorySource) URLs(ctx context.Context) ([]URL, error) {
+	articles, err := s.repo.Articles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := s.repo.Tags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	authors, err := s.repo.Authors(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]URL, 0, 1+len(articles)+len(tags)+len(authors))
+	urls = append(urls, URL{Loc: s.siteURL + "/"})
+	var latest time.Time
+	for _, a := range articles {
+		urls = append(urls, URL{Loc: s.siteURL + "/articles/" + strconv.FormatUint(uint64(a.ID), 10), LastMod: a.UpdatedAt})
+		if a.UpdatedAt.After(latest) {
+			latest = a.UpdatedAt
+		}
+	}
+	urls[0].LastMod = latest
+	for _, t := range tags {
+		urls = append(urls, URL{Loc: s.siteURL + "/tags/" + url.PathEscape(t.Name), LastMod: t.LastMod})
+	}
+	for _, a := range authors {
+		urls = append(urls, URL{Loc: s.siteURL + "/users/" + url.PathEscape(a.Username), LastMod: a.LastMod})
+	}
+	return urls, nil
+}
+
+var cache *Cache
+
+// InitCache 根据配置创建全局站点地图缓存
+func InitCache() {
+	cache = NewCache(
+		NewRepositorySource(repository.NewSitemapRepository(), config.AppConfig.Site.URL),
+		Options{
+			BaseURL: strings.TrimRight(config.AppConfig.Site.APIURL, "/"),
+			TTL:     config.AppConfig.Sitemap.CacheTTL,
+		},
+	)
+}
+
+// GetCache 获取全局站点地图缓存，未初始化时使用默认配置创建
+func GetCache() *Cache {
+	if cache == nil {
+		InitCache()
+	}
+	return cache
+}
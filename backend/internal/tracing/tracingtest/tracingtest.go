@@ -0,0 +1,23 @@
+// Package tracingtest 提供导出到内存的 TracerProvider，用于在测试中断言生成的 span
+package tracingtest
+
+import (
+	"blog/internal/tracing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// NewInMemoryTracer 安装一个同步导出到内存的全局 TracerProvider
+func NewInMemoryTracer() (*tracetest.InMemoryExporter, tracing.ShutdownFunc) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	return exporter, tp.Shutdown
+}
@@ -0,0 +1,206 @@
+// Package viewcount 在内存中去重并汇总文章浏览，定期批量写入数据库，避免每次浏览都写库
+package viewcount
+
+import (
+	"blog/config"
+	"blog/internal/model"
+	"blog/internal/repository"
+	"container/list"
+	"context"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFlushInterval = 30 * time.Second
+	defaultDedupWindow   = 30 * time.Minute
+	defaultMaxTracked    = 100000
+	pruneInterval        = time.Hour
+)
+
+// Store 批量保存浏览数并清理过期的统计
+type Store interface {
+	IncrementViews(ctx context.Context, counts map[model.ViewBucket]int64) error
+	DeleteStatsBefore(ctx context.Context, before time.Time) error
+}
+
+// Recorder 记录一次文章浏览，服务层只依赖该接口
+type Recorder interface {
+	Record(articleID uint, viewer string) bool
+}
+
+// Options 记录器配置，零值使用默认值
+type Options struct {
+	FlushInterval time.Duration
+	DedupWindow   time.Duration
+	MaxTracked    int           // 去重记录的最大数量，超出后淘汰最早计数的记录
+	Retention     time.Duration // 每小时统计的保留时间，为 0 时不清理
+}
+
+// Tracker 按访客和时间窗口去重后累计浏览数
+type Tracker struct {
+	store Store
+	opts  Options
+	now   func() time.Time
+
+	mu        sync.Mutex
+	seen      map[string]*list.Element // 访客对应的去重记录
+	order     *list.List               // 按计数时间排列的 seenEntry，最早的在前
+	pending   map[model.ViewBucket]int64
+	lastPrune time.Time
+}
+
+// seenEntry 访客最近一次被计数的时间
+type seenEntry struct {
+	key string
+	at  time.Time
+}
+
+// NewTracker 创建浏览记录器
+func NewTracker(store Store, opts Options) *Tracker {
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultFlushInterval
+	}
+	if opts.DedupWindow <= 0 {
+		opts.DedupWindow = defaultDedupWindow
+	}
+	if opts.MaxTracked <= 0 {
+		opts.MaxTracked = defaultMaxTracked
+	}
+	return &Tracker{
+		store:   store,
+		opts:    opts,
+		now:     time.Now,
+		seen:    map[string]*list.Element{},
+		order:   list.New(),
+		pending: map[model.ViewBucket]int64{},
+	}
+}
+
+// Record 记录 viewer 浏览了文章，同一访客在去重窗口内重复浏览不计数，返回是否计数
+// 去重记录达到上限时淘汰最早计数的记录，被淘汰的访客再次浏览会重新计数
+func (t *Tracker) Record(articleID uint, viewer string) bool {
+	now := t.now()
+	key := viewer + "|" + strconv.FormatUint(uint64(articleID), 10)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if elem, ok := t.seen[key]; ok {
+		entry := elem.Value.(*seenEntry)
+		if now.Sub(entry.at) < t.opts.DedupWindow {
+			return false
+		}
+		entry.at = now
+		t.order.MoveToBack(elem)
+	} else {
+		t.seen[key] = t.order.PushBack(&seenEntry{key: key, at: now})
+		for t.order.Len() > t.opts.MaxTracked {
+			t.evict(t.order.Front())
+		}
+	}
+	t.pending[model.ViewBucket{ArticleID: articleID, Hour: now.UTC().Truncate(time.Hour)}]++
+	return true
+}
+
+// Flush 写入尚未保存的浏览数，失败时放回等待下次写入；同时清理过期的去重记录
+func (t *Tracker) Flush(ctx context.Context) error {
+	now := t.now()
+
+	t.mu.Lock()
+	batch := t.pending
+	t.pending = make(map[model.ViewBucket]int64, len(batch))
+	for elem := t.order.Front(); elem != nil && now.Sub(elem.Value.(*seenEntry).at) >= t.opts.DedupWindow; elem = t.order.Front() {
+		t.evict(elem)
+	}
+	t.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	if err := t.store.IncrementViews(ctx, batch); err != nil {
+		t.mu.Lock()
+		for bucket, n := range batch {
+			t.pending[bucket] += n
+		}
+		t.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// evict 删除一条去重记录，调用方需持有锁
+func (t *Tracker) evict(elem *list.Element) {
+	t.order.Remove(elem)
+	delete(t.seen, elem.Value.(*seenEntry).key)
+}
+
+// Run 定期写入并清理过期统计，直到 ctx 结束；退出时调用方需再调用 Flush 写入剩余数据
+func (t *Tracker) Run(ctx context.Context) {
+	ticker := time.NewTicker(t.opts.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := t.Flush(ctx); err != nil {
+				log.Printf("[WARN] failed to flush article views: %v", err)
+			}
+			t.prune(ctx)
+		}
+	}
+}
+
+// prune 每小时最多清理一次超过保留时间的统计
+func (t *Tracker) prune(ctx context.Context) {
+	now := t.now()
+	if t.opts.Retention <= 0 || now.Sub(t.lastPrune) < pruneInterval {
+		return
+	}
+	t.lastPrune = now
+	if err := t.store.DeleteStatsBefore(ctx, now.Add(-t.opts.Retention)); err != nil {
+		log.Printf("[WARN] failed to prune article view stats: %v", err)
+	}
+}
+
+// Windows 热门文章可选的统计范围，未配置时使用默认的日、周、月
+func Windows() map[string]time.Duration {
+	if windows := config.AppConfig.Views.PopularWindows; len(windows) > 0 {
+		return windows
+	}
+	return map[string]time.Duration{
+		"day":   24 * time.Hour,
+		"week":  7 * 24 * time.Hour,
+		"month": 30 * 24 * time.Hour,
+	}
+}
+
+var tracker *Tracker
+
+// InitTracker 根据配置创建全局记录器，统计保留到最长的热门范围
+func InitTracker() {
+	var retention time.Duration
+	for _, window := range Windows() {
+		if window > retention {
+			retention = window
+		}
+	}
+	cfg := config.AppConfig.Views
+	tracker = NewTracker(repository.NewViewRepository(), Options{
+		FlushInterval: cfg.FlushInterval,
+		DedupWindow:   cfg.DedupWindow,
+		MaxTracked:    cfg.MaxTracked,
+		Retention:     retention + time.Hour,
+	})
+}
+
+// GetTracker 获取全局记录器，未初始化时使用默认配置创建
+func GetTracker() *Tracker {
+	if tracker == nil {
+		InitTracker()
+	}
+	return tracker
+}
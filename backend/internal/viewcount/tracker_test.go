@@ -0,0 +1,110 @@
+package viewcount
+
+import (
+	"blog/internal/model"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore 记录每次批量写入的内容
+type memoryStore struct {
+	mu      sync.Mutex
+	batches []map[model.ViewBucket]int64
+	err     error
+}
+
+func (s *memoryStore) IncrementViews(ctx context.Context, counts map[model.ViewBucket]int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.batches = append(s.batches, counts)
+	return nil
+}
+
+func (s *memoryStore) DeleteStatsBefore(ctx context.Context, before time.Time) error {
+	return nil
+}
+
+// newTestTracker 创建使用可控时钟的记录器
+func newTestTracker(store Store) (*Tracker, *time.Time) {
+	now := time.Date(2026, 1, 2, 10, 15, 0, 0, time.UTC)
+	tracker := NewTracker(store, Options{FlushInterval: time.Hour, DedupWindow: 30 * time.Minute})
+	tracker.now = func() time.Time { return now }
+	return tracker, &now
+}
+
+func TestTracker_Dedup(t *testing.T) {
+	store := &memoryStore{}
+	tracker, now := newTestTracker(store)
+
+	// 同一访客在窗口内重复浏览只计一次，不同访客分别计数
+	assert.True(t, tracker.Record(1, "u:1"))
+	assert.False(t, tracker.Record(1, "u:1"))
+	assert.True(t, tracker.Record(1, "u:2"))
+	assert.True(t, tracker.Record(2, "u:1"))
+
+	// 超过去重窗口后再次计数，并落入新的小时
+	*now = now.Add(time.Hour)
+	assert.True(t, tracker.Record(1, "u:1"))
+
+	require.NoError(t, tracker.Flush(context.Background()))
+	require.Len(t, store.batches, 1)
+	hour := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, map[model.ViewBucket]int64{
+		{ArticleID: 1, Hour: hour}:                2,
+		{ArticleID: 2, Hour: hour}:                1,
+		{ArticleID: 1, Hour: hour.Add(time.Hour)}: 1,
+	}, store.batches[0])
+
+	// 没有新的浏览时不写库
+	require.NoError(t, tracker.Flush(context.Background()))
+	assert.Len(t, store.batches, 1)
+}
+
+func TestTracker_FlushFailureRetries(t *testing.T) {
+	store := &memoryStore{err: errors.New("db down")}
+	tracker, _ := newTestTracker(store)
+
+	tracker.Record(1, "u:1")
+	assert.Error(t, tracker.Flush(context.Background()))
+
+	// 写入失败的数据与之后的浏览合并，下次一并写入
+	tracker.Record(1, "u:2")
+	store.err = nil
+	require.NoError(t, tracker.Flush(context.Background()))
+	require.Len(t, store.batches, 1)
+	for _, n := range store.batches[0] {
+		assert.Equal(t, int64(2), n)
+	}
+}
+
+func TestTracker_FlushPrunesSeen(t *testing.T) {
+	tracker, now := newTestTracker(&memoryStore{})
+	tracker.Record(1, "u:1")
+
+	*now = now.Add(31 * time.Minute)
+	require.NoError(t, tracker.Flush(context.Background()))
+	assert.Empty(t, tracker.seen)
+}
+
+func TestTracker_MaxTrackedEvictsOldest(t *testing.T) {
+	tracker := NewTracker(&memoryStore{}, Options{DedupWindow: 30 * time.Minute, MaxTracked: 2})
+
+	assert.True(t, tracker.Record(1, "a:1"))
+	assert.True(t, tracker.Record(1, "a:2"))
+	assert.True(t, tracker.Record(1, "a:3"))
+
+	// 超出上限时淘汰最早的记录，其余记录仍在去重窗口内
+	assert.Len(t, tracker.seen, 2)
+	assert.False(t, tracker.Record(1, "a:2"))
+	assert.False(t, tracker.Record(1, "a:3"))
+	assert.True(t, tracker.Record(1, "a:1"))
+}
@@ -0,0 +1,355 @@
+// Package webhook 把领域事件推送到用户配置的 webhook，失败时按指数退避重试。
+// 事件先写成投递记录再由后台发送，重启后未完成的投递会继续；同一事件重复发布时只投递一次
+package webhook
+
+import (
+	"blog/config"
+	"blog/internal/events"
+	"blog/internal/model"
+	"blog/internal/repository"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 请求头
+const (
+	HeaderEvent     = "X-Blog-Event"
+	HeaderDelivery  = "X-Blog-Delivery"
+	HeaderTimestamp = "X-Blog-Timestamp"
+	HeaderSignature = "X-Blog-Signature"
+)
+
+const (
+	defaultMaxAttempts  = 8
+	defaultBaseBackoff  = 30 * time.Second
+	defaultMaxBackoff   = time.Hour
+	defaultTimeout      = 10 * time.Second
+	defaultPollInterval = 5 * time.Second
+	defaultConcurrency  = 4
+	maxResponseBody     = 1000
+)
+
+// Store 读取 webhook 配置并保存投递记录
+type Store interface {
+	FindByID(ctx context.Context, id uint) (*model.Webhook, error)
+	ListActive(ctx context.Context) ([]model.Webhook, error)
+	CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error
+}
+
+// Options 投递配置
+type Options struct {
+	MaxAttempts         int           // 包括首次投递在内的最多尝试次数
+	BaseBackoff         time.Duration // 第一次重试的等待时间，之后每次翻倍
+	MaxBackoff          time.Duration
+	Timeout             time.Duration // 单次请求超时
+	PollInterval        time.Duration // 检查到期重试的间隔
+	Concurrency         int
+	AllowPrivateTargets bool // 允许推送到内网地址，仅用于开发环境
+}
+
+// Dispatcher 订阅事件总线，为匹配的 webhook 创建投递记录并在后台发送
+type Dispatcher struct {
+	store  Store
+	client *http.Client
+	opts   Options
+	wake   chan struct{}
+	now    func() time.Time
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewDispatcher 创建投递器
+func NewDispatcher(store Store, opts Options) *Dispatcher {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+	if opts.BaseBackoff <= 0 {
+		opts.BaseBackoff = defaultBaseBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = defaultMaxBackoff
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultConcurrency
+	}
+	return &Dispatcher{
+		store:  store,
+		client: newClient(opts),
+		opts:   opts,
+		wake:   make(chan struct{}, 1),
+		now:    time.Now,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Handle 事件总线的订阅函数，写成投递记录后返回，失败时由发布方重新发布
+func (d *Dispatcher) Handle(ctx context.Context, event events.Event) error {
+	return d.Enqueue(ctx, event)
+}
+
+// Wake 有新的投递记录时立即处理，不必等到下一次轮询
+func (d *Dispatcher) Wake() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run 在后台发送到期的投递，直到调用 Close
+func (d *Dispatcher) Run() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(d.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-d.wake:
+		case <-d.stop:
+			return
+		}
+		if _, err := d.DeliverDue(context.Background()); err != nil {
+			log.Printf("[ERROR] failed to claim webhook deliveries: %v", err)
+		}
+	}
+}
+
+// Close 停止处理，正在发送的一批投递完成后返回，ctx 结束时不再等待
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.stopOnce.Do(func() { close(d.stop) })
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Enqueue 同步为订阅了该事件且有权收到的 webhook 各创建一条投递记录，已为该事件创建过的跳过
+func (d *Dispatcher) Enqueue(ctx context.Context, event events.Event) error {
+	hooks, err := d.store.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	var payload []byte
+	var deliveries []model.WebhookDelivery
+	now := d.now()
+	for i := range hooks {
+		hook := &hooks[i]
+		if !hook.Subscribes(event.Type) || !Permitted(hook, event) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(event); err != nil {
+				return err
+			}
+		}
+		dedupKey := strconv.FormatUint(uint64(hook.ID), 10) + ":" + event.ID
+		deliveries = append(deliveries, model.WebhookDelivery{
+			WebhookID:     hook.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       string(payload),
+			Status:        model.WebhookDeliveryPending,
+			NextAttemptAt: &now,
+			DedupKey:      &dedupKey,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := d.store.CreateDeliveries(ctx, deliveries); err != nil {
+		return err
+	}
+	d.Wake()
+	return nil
+}
+
+// DeliverDue 发送所有到期的投递，返回处理的记录数
+func (d *Dispatcher) DeliverDue(ctx context.Context) (int, error) {
+	limit := d.opts.Concurrency * 10
+	// 租约覆盖一批请求的最长耗时，处理者崩溃后记录会在租约到期后重新被取出
+	lease := d.opts.Timeout*time.Duration(limit/d.opts.Concurrency+1) + time.Minute
+
+	total := 0
+	for {
+		batch, err := d.store.ClaimDueDeliveries(ctx, d.now(), lease, limit)
+		if err != nil {
+			return total, err
+		}
+
+		sem := make(chan struct{}, d.opts.Concurrency)
+		var wg sync.WaitGroup
+		for i := range batch {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(delivery *model.WebhookDelivery) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				d.deliver(ctx, delivery)
+			}(&batch[i])
+		}
+		wg.Wait()
+
+		total += len(batch)
+		if len(batch) < limit {
+			return total, nil
+		}
+	}
+}
+
+// deliver 发送一次并保存结果，失败时安排下一次重试
+func (d *Dispatcher) deliver(ctx context.Context, delivery *model.WebhookDelivery) {
+	hook, err := d.store.FindByID(ctx, delivery.WebhookID)
+	if err != nil {
+		// 租约到期后重试
+		log.Printf("[ERROR] failed to load webhook %d: %v", delivery.WebhookID, err)
+		return
+	}
+
+	delivery.Attempts++
+	if hook == nil || !hook.Active {
+		delivery.Status = model.WebhookDeliveryFailed
+		delivery.NextAttemptAt = nil
+		delivery.Error = "webhook deleted or disabled"
+	} else {
+		d.attempt(ctx, hook, delivery)
+	}
+
+	if err := d.store.UpdateDelivery(ctx, delivery); err != nil {
+		log.Printf("[ERROR] failed to save webhook delivery %d: %v", delivery.ID, err)
+	}
+}
+
+func (d *Dispatcher) attempt(ctx context.Context, hook *model.Webhook, delivery *model.WebhookDelivery) {
+	status, body, err := d.send(ctx, hook, delivery)
+	now := d.now()
+	delivery.ResponseStatus = status
+	delivery.ResponseBody = body
+	delivery.Error = ""
+
+	if err == nil && status >= 200 && status < 300 {
+		delivery.Status = model.WebhookDeliverySucceeded
+		delivery.NextAttemptAt = nil
+		delivery.DeliveredAt = &now
+		return
+	}
+
+	if err != nil {
+		delivery.Error = truncate(err.Error(), 500)
+	} else {
+		delivery.Error = "unexpected status " + strconv.Itoa(status)
+	}
+	if delivery.Attempts >= d.opts.MaxAttempts {
+		delivery.Status = model.WebhookDeliveryFailed
+		delivery.NextAttemptAt = nil
+		return
+	}
+	next := now.Add(d.Backoff(delivery.Attempts))
+	delivery.NextAttemptAt = &next
+}
+
+// send 发送签名后的请求，返回状态码和截断后的响应体
+func (d *Dispatcher) send(ctx context.Context, hook *model.Webhook, delivery *model.WebhookDelivery) (int, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.opts.Timeout)
+	defer cancel()
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	timestamp := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "blog-webhook/1.0")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(hook.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	return resp.StatusCode, truncate(string(data), maxResponseBody), nil
+}
+
+// Backoff 第 attempt 次失败后的等待时间
+func (d *Dispatcher) Backoff(attempt int) time.Duration {
+	backoff := d.opts.BaseBackoff
+	for i := 1; i < attempt && backoff < d.opts.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, d.opts.MaxBackoff)
+}
+
+// Permitted 用户是否有权收到该事件：管理员收到全部事件，其他用户只收到自己文章的事件
+func Permitted(hook *model.Webhook, event events.Event) bool {
+	if hook.Owner.Role == model.RoleAdmin {
+		return true
+	}
+	return AllowedEvent(&hook.Owner, event.Type) && event.OwnerID != 0 && event.OwnerID == hook.UserID
+}
+
+// AllowedEvent 用户能否订阅该类型的事件，新用户注册只推送给管理员
+func AllowedEvent(user *model.User, eventType string) bool {
+	return eventType != events.UserRegistered || user.Role == model.RoleAdmin
+}
+
+// truncate 按字节截断并去掉被截断的不完整字符
+func truncate(s string, n int) string {
+	if len(s) > n {
+		s = s[:n]
+	}
+	return strings.ToValidUTF8(s, "")
+}
+
+var dispatcher *Dispatcher
+
+// InitDispatcher 根据配置创建全局投递器
+func InitDispatcher() {
+	cfg := config.AppConfig.Webhooks
+	dispatcher = NewDispatcher(repository.NewWebhookRepository(), Options{
+		MaxAttempts:         cfg.MaxAttempts,
+		BaseBackoff:         cfg.BaseBackoff,
+		MaxBackoff:          cfg.MaxBackoff,
+		Timeout:             cfg.Timeout,
+		PollInterval:        cfg.PollInterval,
+		Concurrency:         cfg.Concurrency,
+		AllowPrivateTargets: cfg.AllowPrivateTargets,
+	})
+}
+
+// GetDispatcher 获取全局投递器，未初始化时使用默认配置创建
+func GetDispatcher() *Dispatcher {
+	if dispatcher == nil {
+		InitDispatcher()
+	}
+	return dispatcher
+}
@@ -0,0 +1,215 @@
+package webhook
+
+import (
+	"blog/internal/events"
+	"blog/internal/model"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore 内存中的 webhook 和投递记录
+type memoryStore struct {
+	mu         sync.Mutex
+	hooks      []model.Webhook
+	deliveries []model.WebhookDelivery
+}
+
+func (s *memoryStore) FindByID(ctx context.Context, id uint) (*model.Webhook, error) {
+	for i := range s.hooks {
+		if s.hooks[i].ID == id {
+			hook := s.hooks[i]
+			return &hook, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *memoryStore) ListActive(ctx context.Context) ([]model.Webhook, error) {
+	var hooks []model.Webhook
+	for _, hook := range s.hooks {
+		if hook.Active {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks, nil
+}
+
+func (s *memoryStore) CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, delivery := range deliveries {
+		if s.exists(delivery.DedupKey) {
+			continue
+		}
+		delivery.ID = uint(len(s.deliveries) + 1)
+		s.deliveries = append(s.deliveries, delivery)
+	}
+	return nil
+}
+
+func (s *memoryStore) exists(dedupKey *string) bool {
+	for _, d := range s.deliveries {
+		if dedupKey != nil && d.DedupKey != nil && *d.DedupKey == *dedupKey {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *memoryStore) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []model.WebhookDelivery
+	for i := range s.deliveries {
+		d := &s.deliveries[i]
+		if d.Status == model.WebhookDeliveryPending && !d.NextAttemptAt.After(now) && len(due) < limit {
+			due = append(due, *d)
+			next := now.Add(lease)
+			d.NextAttemptAt = &next
+		}
+	}
+	return due, nil
+}
+
+func (s *memoryStore) UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries[delivery.ID-1] = *delivery
+	return nil
+}
+
+func (s *memoryStore) delivery(id uint) model.WebhookDelivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.deliveries[id-1]
+}
+
+// newTestDispatcher 创建允许访问本机地址、使用可控时钟的投递器
+func newTestDispatcher(store Store) (*Dispatcher, *time.Time) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	d := NewDispatcher(store, Options{MaxAttempts: 3, BaseBackoff: time.Minute, MaxBackoff: 90 * time.Second, AllowPrivateTargets: true})
+	d.now = func() time.Time { return now }
+	return d, &now
+}
+
+func TestDispatcher_Deliver(t *testing.T) {
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	var requests []received
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, received{header: r.Header.Clone(), body: body})
+		w.WriteHeader(status)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	store := &memoryStore{hooks: []model.Webhook{
+		{ID: 1, UserID: 1, URL: server.URL, Secret: "secret", Events: []string{events.ArticlePublished}, Active: true},
+		{ID: 2, UserID: 2, URL: server.URL, Secret: "other", Events: []string{events.ArticlePublished}, Active: true},
+	}}
+	d, now := newTestDispatcher(store)
+
+	event := events.New(events.ArticlePublished, 1, events.ArticleData{ID: 10, Title: "hello"})
+	require.NoError(t, d.Enqueue(context.Background(), event))
+	// 其他用户的 webhook 收不到该作者的文章事件
+	require.Len(t, store.deliveries, 1)
+	// 同一事件重复发布时不重复创建投递
+	require.NoError(t, d.Handle(context.Background(), event))
+	require.Len(t, store.deliveries, 1)
+
+	t.Run("签名并记录成功", func(t *testing.T) {
+		n, err := d.DeliverDue(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		require.Len(t, requests, 1)
+
+		req := requests[0]
+		assert.Equal(t, events.ArticlePublished, req.header.Get(HeaderEvent))
+		assert.Equal(t, "1", req.header.Get(HeaderDelivery))
+		timestamp, err := strconv.ParseInt(req.header.Get(HeaderTimestamp), 10, 64)
+		require.NoError(t, err)
+		assert.Equal(t, now.Unix(), timestamp)
+		assert.True(t, Verify("secret", timestamp, req.body, req.header.Get(HeaderSignature)))
+		assert.Contains(t, string(req.body), `"title":"hello"`)
+
+		delivery := store.delivery(1)
+		assert.Equal(t, model.WebhookDeliverySucceeded, delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.Equal(t, http.StatusOK, delivery.ResponseStatus)
+		assert.Equal(t, "ok", delivery.ResponseBody)
+		assert.Nil(t, delivery.NextAttemptAt)
+	})
+
+	t.Run("失败后退避重试，超过次数后放弃", func(t *testing.T) {
+		status = http.StatusInternalServerError
+		require.NoError(t, d.Enqueue(context.Background(), events.New(events.ArticlePublished, 1, events.ArticleData{ID: 11})))
+		id := uint(len(store.deliveries))
+
+		_, err := d.DeliverDue(context.Background())
+		require.NoError(t, err)
+		delivery := store.delivery(id)
+		assert.Equal(t, model.WebhookDeliveryPending, delivery.Status)
+		assert.Equal(t, "unexpected status 500", delivery.Error)
+		assert.Equal(t, now.Add(time.Minute), *delivery.NextAttemptAt)
+
+		// 未到重试时间不会再次发送
+		n, _ := d.DeliverDue(context.Background())
+		assert.Equal(t, 0, n)
+
+		*now = now.Add(time.Minute)
+		d.DeliverDue(context.Background())
+		delivery = store.delivery(id)
+		assert.Equal(t, 2, delivery.Attempts)
+		assert.Equal(t, now.Add(90*time.Second), *delivery.NextAttemptAt)
+
+		*now = now.Add(90 * time.Second)
+		d.DeliverDue(context.Background())
+		delivery = store.delivery(id)
+		assert.Equal(t, model.WebhookDeliveryFailed, delivery.Status)
+		assert.Equal(t, 3, delivery.Attempts)
+		assert.Nil(t, delivery.NextAttemptAt)
+	})
+}
+
+func TestDispatcher_RejectsPrivateTargets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not reach the server")
+	}))
+	defer server.Close()
+
+	store := &memoryStore{hooks: []model.Webhook{
+		{ID: 1, UserID: 1, URL: server.URL, Secret: "secret", Events: []string{events.ArticleCreated}, Active: true},
+	}}
+	d := NewDispatcher(store, Options{})
+	require.NoError(t, d.Enqueue(context.Background(), events.New(events.ArticleCreated, 1, nil)))
+
+	_, err := d.DeliverDue(context.Background())
+	require.NoError(t, err)
+	delivery := store.delivery(1)
+	assert.Equal(t, model.WebhookDeliveryPending, delivery.Status)
+	assert.Contains(t, delivery.Error, "not a public address")
+}
+
+func TestPermitted(t *testing.T) {
+	admin := &model.Webhook{UserID: 1, Owner: model.User{ID: 1, Role: model.RoleAdmin}}
+	author := &model.Webhook{UserID: 2, Owner: model.User{ID: 2, Role: model.RoleUser}}
+
+	assert.True(t, Permitted(admin, events.New(events.ArticleDeleted, 2, nil)))
+	assert.True(t, Permitted(admin, events.New(events.UserRegistered, 3, nil)))
+	assert.True(t, Permitted(author, events.New(events.ArticleDeleted, 2, nil)))
+	assert.False(t, Permitted(author, events.New(events.ArticleDeleted, 3, nil)))
+	assert.False(t, Permitted(author, events.New(events.UserRegistered, 2, nil)))
+}
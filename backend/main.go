@@ -2,11 +2,34 @@ package main
 
 import (
 	"blog/config"
+	"blog/internal/activity"
+	"blog/internal/audit"
+	"blog/internal/cache"
+	"blog/internal/events"
 	"blog/internal/handler"
+	"blog/internal/httpcache"
+	"blog/internal/jobs"
+	"blog/internal/jwtkeys"
+	"blog/internal/mailer"
 	"blog/internal/middleware"
+	"blog/internal/model"
+	"blog/internal/oauth"
+	"blog/internal/outbox"
+	"blog/internal/password"
+	"blog/internal/ratelimit"
 	"blog/internal/repository"
 	"blog/internal/service"
+	"blog/internal/sitemap"
+	"blog/internal/tracing"
+	"blog/internal/viewcount"
+	"blog/internal/webhook"
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -21,51 +44,318 @@ func main() {
 	// 加载配置
 	config.LoadConfig()
 
+	// 监听退出信号
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// 初始化链路追踪
+	shutdownTracer, err := tracing.InitTracer(ctx)
+	if err != nil {
+		log.Fatalf("Failed to init tracer: %v", err)
+	}
+
 	// 初始化数据库
 	repository.InitDB()
 
+	// 初始化限流存储
+	ratelimit.InitStore()
+
+	// 初始化缓存，需在创建仓库之前
+	cache.InitBackend()
+	httpcache.InitStore()
+
+	// 初始化邮件发送器
+	mailer.InitMailer()
+	password.Init()
+	jwtkeys.Init()
+	oauth.InitProviders()
+
+	// 站点地图缓存，文章变化时失效
+	sitemap.InitCache()
+
+	// 会话最近活跃时间定期批量写入
+	activity.InitTracker()
+	go activity.GetTracker().Run(ctx)
+
+	// 文章浏览数定期批量写入
+	viewcount.InitTracker()
+	go viewcount.GetTracker().Run(ctx)
+
+	// 审计事件异步写入，并定期清理过期事件
+	audit.InitLogger()
+	go audit.GetLogger().Run()
+	go audit.RunDefaultRetention(ctx)
+
+	// 领域事件推送到用户配置的 webhook
+	webhook.InitDispatcher()
+	events.GetBus().Subscribe(webhook.GetDispatcher().Handle)
+	go webhook.GetDispatcher().Run()
+
+	// 把 outbox 表中的事件发布到事件总线
+	outbox.InitRelay()
+	go outbox.GetRelay().Run()
+
+	// 后台任务：发送邮件、清理过期任务等
+	jobs.InitQueue()
+	jobs.InitWorker()
+	go jobs.GetWorker().Run()
+
 	// 创建 Gin 引擎
 	r := gin.Default()
+	if err := r.SetTrustedProxies(config.AppConfig.Server.TrustedProxies); err != nil {
+		log.Fatalf("Invalid trusted proxies: %v", err)
+	}
 
 	// 添加全局中间件
+	r.Use(middleware.TracingMiddleware())
 	r.Use(middleware.CORSMiddleware())
+	r.Use(middleware.LocaleMiddleware())
+	r.Use(middleware.ClientInfoMiddleware())
+	r.Use(middleware.ErrorMiddleware())
 
 	// 初始化服务和处理器
 	authService := service.NewAuthService()
 	articleService := service.NewArticleService()
+	userService := service.NewUserService()
+	twoFactorService := service.NewTwoFactorService()
+	apiTokenService := service.NewAPITokenService()
+	sessionService := service.NewSessionService()
+	auditService := service.NewAuditService()
+	feedService := service.NewFeedService()
+	webhookService := service.NewWebhookService()
+	jobService := service.NewJobService()
+	seriesService := service.NewSeriesService()
+	collaboratorService := service.NewCollaboratorService()
 
 	authHandler := handler.NewAuthHandler(authService)
 	articleHandler := handler.NewArticleHandler(articleService)
+	userHandler := handler.NewUserHandler(userService)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorService)
+	apiTokenHandler := handler.NewAPITokenHandler(apiTokenService)
+	sessionHandler := handler.NewSessionHandler(sessionService)
+	auditHandler := handler.NewAuditHandler(auditService)
+	reactionHandler := handler.NewReactionHandler(articleService)
+	feedHandler := handler.NewFeedHandler(feedService)
+	sitemapHandler := handler.NewSitemapHandler(sitemap.GetCache())
+	cacheHandler := handler.NewCacheHandler(cache.AllStats)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	jobHandler := handler.NewJobHandler(jobService)
+	seriesHandler := handler.NewSeriesHandler(seriesService)
+	collaboratorHandler := handler.NewCollaboratorHandler(collaboratorService)
+	reviewHandler := handler.NewReviewHandler(articleService)
+	jwksHandler := handler.NewJWKSHandler(jwtkeys.GetKeySet())
+
+	// 公开的验证公钥
+	r.GET("/.well-known/jwks.json", jwksHandler.JWKS)
+
+	// 订阅源：全站、按作者、按标签
+	r.GET("/feed.xml", feedHandler.RSS)
+	r.GET("/atom.xml", feedHandler.Atom)
+	r.GET("/authors/:username/feed.xml", feedHandler.RSS)
+	r.GET("/authors/:username/atom.xml", feedHandler.Atom)
+	r.GET("/tags/:tag/feed.xml", feedHandler.RSS)
+	r.GET("/tags/:tag/atom.xml", feedHandler.Atom)
+
+	// 站点地图和 robots.txt
+	r.GET("/sitemap.xml", sitemapHandler.Sitemap)
+	r.GET("/sitemaps/:file", sitemapHandler.Page)
+	r.GET("/robots.txt", sitemapHandler.Robots)
 
 	// 注册路由
 	api := r.Group("/api/v1")
 	{
 		// 认证相关路由（无需认证）
 		auth := api.Group("/auth")
+		auth.Use(middleware.RateLimitMiddleware(ratelimit.GetStore(), ratelimit.PerMinute(
+			config.AppConfig.RateLimit.IP.RequestsPerMinute,
+			config.AppConfig.RateLimit.IP.Burst,
+		)))
 		{
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
+			auth.POST("/login/2fa", authHandler.LoginTwoFactor)
+			auth.POST("/verify", authHandler.VerifyEmail)
+			auth.POST("/forgot-password", authHandler.ForgotPassword)
+			auth.POST("/reset-password", authHandler.ResetPassword)
+			auth.POST("/confirm-email", userHandler.ConfirmEmailChange)
+			auth.GET("/oauth/providers", authHandler.OAuthProviders)
+			auth.GET("/oauth/:provider", authHandler.OAuthStart)
+			auth.POST("/oauth/:provider/callback", authHandler.OAuthCallback)
+		}
+
+		// 公开接口（无需认证）计算 ETag 并设置 Cache-Control；
+		// 列表的匿名响应可缓存在进程内，详情每次都经过处理器以便统计浏览数
+		var responseStore *httpcache.Store
+		if config.AppConfig.HTTPCache.ResponseCache.Enabled {
+			responseStore = httpcache.GetStore()
+		}
+		conditional := middleware.HTTPCache(config.AppConfig.HTTPCache.MaxAge, nil)
+		cachedList := middleware.HTTPCache(config.AppConfig.HTTPCache.MaxAge, responseStore)
+
+		// 用户公开主页
+		api.GET("/users/:username", conditional, userHandler.GetProfile)
+
+		// 已发布文章，带认证头时标记当前用户是否点赞、收藏
+		public := api.Group("/articles")
+		{
+			public.GET("", cachedList, middleware.OptionalAuthMiddleware(), articleHandler.ListPublishedArticles)
+			public.GET("/popular", cachedList, articleHandler.PopularArticles)
+			public.GET("/:id", conditional, middleware.OptionalAuthMiddleware(), articleHandler.GetPublishedArticle)
+		}
+
+		// 系列及其中的文章，带认证头时作者可以看到草稿
+		api.GET("/series/:id", conditional, middleware.OptionalAuthMiddleware(), seriesHandler.Get)
+
+		// 两步验证设置路由，不受管理员强制两步验证的限制
+		twoFactor := api.Group("/users/me/2fa")
+		twoFactor.Use(middleware.AuthMiddleware(), middleware.RejectAPIToken())
+		{
+			twoFactor.POST("/setup", twoFactorHandler.Setup)
+			twoFactor.POST("/enable", twoFactorHandler.Enable)
+			twoFactor.POST("/disable", twoFactorHandler.Disable)
+			twoFactor.POST("/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
 		}
 
 		// 需要认证的路由
 		authenticated := api.Group("")
-		authenticated.Use(middleware.AuthMiddleware())
+		authenticated.Use(middleware.AuthMiddleware(), middleware.TwoFactorMiddleware())
 		{
+			authenticated.POST("/auth/resend-verification", middleware.RejectAPIToken(), authHandler.ResendVerification)
+
+			// 当前用户相关路由，修改密码和邮箱不允许使用个人访问令牌
+			me := authenticated.Group("/users/me")
+			{
+				me.GET("", middleware.RequireScope(model.ScopeProfileRead), userHandler.GetMe)
+				me.PATCH("", middleware.RequireScope(model.ScopeProfileWrite), userHandler.UpdateMe)
+				me.POST("/password", middleware.RejectAPIToken(), userHandler.ChangePassword)
+				me.POST("/email", middleware.RejectAPIToken(), userHandler.ChangeEmail)
+				me.GET("/bookmarks", middleware.RequireScope(model.ScopeProfileRead), reactionHandler.ListBookmarks)
+			}
+
+			// 个人访问令牌管理，只能在登录后操作
+			tokens := authenticated.Group("/users/me/tokens", middleware.RejectAPIToken())
+			{
+				tokens.GET("", apiTokenHandler.List)
+				tokens.POST("", apiTokenHandler.Create)
+				tokens.DELETE("/:id", apiTokenHandler.Revoke)
+			}
+
+			// 已登录设备
+			sessions := authenticated.Group("/users/me/sessions", middleware.RejectAPIToken())
+			{
+				sessions.GET("", sessionHandler.List)
+				sessions.DELETE("/:id", sessionHandler.Revoke)
+			}
+
+			// webhook 管理，签名密钥只在创建时返回，不允许使用个人访问令牌
+			webhooks := authenticated.Group("/users/me/webhooks", middleware.RejectAPIToken())
+			{
+				webhooks.GET("", webhookHandler.List)
+				webhooks.POST("", webhookHandler.Create)
+				webhooks.PATCH("/:id", webhookHandler.Update)
+				webhooks.DELETE("/:id", webhookHandler.Delete)
+				webhooks.GET("/:id/deliveries", webhookHandler.ListDeliveries)
+				webhooks.POST("/:id/deliveries/:delivery_id/redeliver", webhookHandler.Redeliver)
+			}
+
 			// 文章相关路由
 			articles := authenticated.Group("/articles")
 			{
-				articles.POST("/create", articleHandler.CreateArticle)
-				articles.POST("/update", articleHandler.UpdateArticle)
-				articles.POST("/delete", articleHandler.DeleteArticle)
-				articles.POST("/detail", articleHandler.GetArticle)
-				articles.POST("/list", articleHandler.ListArticles)
+				articles.POST("/create", middleware.RequireScope(model.ScopeArticlesWrite), articleHandler.CreateArticle)
+				articles.POST("/update", middleware.RequireScope(model.ScopeArticlesWrite), articleHandler.UpdateArticle)
+				articles.POST("/delete", middleware.RequireScope(model.ScopeArticlesWrite), articleHandler.DeleteArticle)
+				articles.POST("/detail", middleware.RequireScope(model.ScopeArticlesRead), articleHandler.GetArticle)
+				articles.POST("/list", middleware.RequireScope(model.ScopeArticlesRead), articleHandler.ListArticles)
+
+				// 点赞、收藏属于个人操作，PUT 和 DELETE 均为幂等
+				articles.PUT("/:id/like", middleware.RequireScope(model.ScopeProfileWrite), reactionHandler.Like)
+				articles.DELETE("/:id/like", middleware.RequireScope(model.ScopeProfileWrite), reactionHandler.Unlike)
+				articles.PUT("/:id/bookmark", middleware.RequireScope(model.ScopeProfileWrite), reactionHandler.Bookmark)
+				articles.DELETE("/:id/bookmark", middleware.RequireScope(model.ScopeProfileWrite), reactionHandler.Unbookmark)
+
+				// 协作者，只有作者可以添加和移除，协作者可以退出
+				articles.GET("/:id/collaborators", middleware.RequireScope(model.ScopeArticlesRead), collaboratorHandler.List)
+				articles.POST("/:id/collaborators", middleware.RequireScope(model.ScopeArticlesWrite), collaboratorHandler.Invite)
+				articles.DELETE("/:id/collaborators/:user_id", middleware.RequireScope(model.ScopeArticlesWrite), collaboratorHandler.Remove)
+
+				// 审核流程：状态变更及其记录、审核评论
+				articles.POST("/:id/transitions", middleware.RequireScope(model.ScopeArticlesWrite), reviewHandler.Transition)
+				articles.GET("/:id/transitions", middleware.RequireScope(model.ScopeArticlesRead), reviewHandler.ListTransitions)
+				articles.GET("/:id/review-comments", middleware.RequireScope(model.ScopeArticlesRead), reviewHandler.ListComments)
+				articles.POST("/:id/review-comments", middleware.RequireScope(model.ScopeArticlesWrite), reviewHandler.AddComment)
+			}
+
+			// 系列管理，只能操作自己的系列和文章
+			series := authenticated.Group("/series", middleware.RequireScope(model.ScopeArticlesWrite))
+			{
+				series.POST("", seriesHandler.Create)
+				series.PATCH("/:id", seriesHandler.Update)
+				series.DELETE("/:id", seriesHandler.Delete)
+				series.POST("/:id/articles", seriesHandler.AttachArticle)
+				series.PUT("/:id/articles", seriesHandler.Reorder)
+				series.DELETE("/:id/articles/:article_id", seriesHandler.DetachArticle)
+			}
+
+			// 管理员路由
+			admin := authenticated.Group("/admin", middleware.RejectAPIToken(), middleware.RequireRole(model.RoleAdmin))
+			{
+				admin.GET("/audit-events", auditHandler.List)
+				admin.PUT("/users/:id/role", userHandler.UpdateRole)
+				admin.GET("/cache-stats", cacheHandler.Stats)
+				admin.GET("/jobs", jobHandler.List)
+				admin.GET("/jobs/stats", jobHandler.Stats)
+				admin.GET("/jobs/:id", jobHandler.Get)
+				admin.POST("/jobs/:id/retry", jobHandler.Retry)
 			}
 		}
 	}
 
 	// 启动服务器
-	log.Printf("Server starting on %s", config.AppConfig.Server.Port)
-	if err := r.Run(config.AppConfig.Server.Port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	srv := &http.Server{
+		Addr:    config.AppConfig.Server.Port,
+		Handler: r,
+	}
+	go func() {
+		log.Printf("Server starting on %s", config.AppConfig.Server.Port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	// 等待退出信号后优雅关闭
+	<-ctx.Done()
+	log.Println("Shutting down server...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server forced to shutdown: %v", err)
+	}
+	// 写入尚未保存的会话活跃时间
+	if err := activity.GetTracker().Flush(shutdownCtx); err != nil {
+		log.Printf("Failed to flush session activity: %v", err)
+	}
+	// 写入尚未保存的文章浏览数
+	if err := viewcount.GetTracker().Flush(shutdownCtx); err != nil {
+		log.Printf("Failed to flush article views: %v", err)
+	}
+	// 先停止事件发布，再停止 webhook 投递，未发布的事件留在 outbox 中下次启动继续
+	if err := outbox.GetRelay().Close(shutdownCtx); err != nil {
+		log.Printf("Failed to stop outbox relay: %v", err)
+	}
+	if err := webhook.GetDispatcher().Close(shutdownCtx); err != nil {
+		log.Printf("Failed to stop webhook dispatcher: %v", err)
+	}
+	// 等待执行中的任务完成，超时未完成的任务稍后重试
+	if err := jobs.GetWorker().Close(shutdownCtx); err != nil {
+		log.Printf("Failed to stop job worker: %v", err)
+	}
+	// 写入缓冲区中剩余的审计事件
+	if err := audit.GetLogger().Close(shutdownCtx); err != nil {
+		log.Printf("Failed to flush audit events: %v", err)
+	}
+	if err := shutdownTracer(shutdownCtx); err != nil {
+		log.Printf("Failed to shutdown tracer: %v", err)
 	}
-}
\ No newline at end of file
+}